@@ -14,6 +14,7 @@ import (
 	"swipelearn-api/internal/repositories"
 	"swipelearn-api/internal/routes"
 	"swipelearn-api/internal/services"
+	"swipelearn-api/internal/storage"
 	"swipelearn-api/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -40,17 +41,57 @@ func main() {
 	defer database.Close()
 
 	// Initialize layers (Dependency Injection)
-	flashcardRepo := repositories.NewFlashcardRepository(database.DB, logger)
-	flashcardService := services.NewFlashcardService(flashcardRepo, logger)
-	flashcardHandler := handlers.NewFlashcardHandler(flashcardService)
+	deckPresetRepo := repositories.NewDeckPresetRepository(database.DB, logger)
+	deckPresetService := services.NewDeckPresetService(deckPresetRepo, logger)
+	deckPresetHandler := handlers.NewDeckPresetHandler(deckPresetService)
+
+	deckRepo := repositories.NewDeckRepository(database.DB, logger)
+	deckService := services.NewDeckService(deckRepo, deckPresetRepo, logger)
+	deckHandler := handlers.NewDeckHandler(deckService)
 
 	userRepo := repositories.NewUserRepository(database.DB, logger)
+
+	flashcardRepo := repositories.NewFlashcardRepository(database.DB, logger)
+	reviewLogRepo := repositories.NewReviewLogRepository(database.DB, logger)
+	flashcardService := services.NewFlashcardService(flashcardRepo, deckRepo, deckPresetRepo, reviewLogRepo, userRepo, logger)
+
+	studySessionRepo := repositories.NewStudySessionRepository(database.DB, logger)
+	studySessionService := services.NewStudySessionService(studySessionRepo, flashcardRepo, deckRepo, flashcardService, logger)
+	flashcardHandler := handlers.NewFlashcardHandler(flashcardService, studySessionService)
+	studySessionHandler := handlers.NewStudySessionHandler(studySessionService)
+
+	filteredDeckService := services.NewFilteredDeckService(deckRepo, flashcardRepo, reviewLogRepo, userRepo, logger)
+	filteredDeckHandler := handlers.NewFilteredDeckHandler(filteredDeckService)
+
+	mediaStore, err := storage.NewStore(logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize media storage")
+	}
+	mediaRepo := repositories.NewMediaRepository(database.DB, logger)
+	mediaService := services.NewMediaService(mediaRepo, mediaStore, logger)
+	mediaService.StartGarbageCollector()
+	mediaHandler := handlers.NewMediaHandler(mediaService)
+
+	noteTypeRepo := repositories.NewNoteTypeRepository(database.DB, logger)
+	noteRepo := repositories.NewNoteRepository(database.DB, logger)
+	noteTypeService := services.NewNoteTypeService(noteTypeRepo, noteRepo, logger)
+	noteTypeHandler := handlers.NewNoteTypeHandler(noteTypeService)
+	noteService := services.NewNoteService(noteRepo, flashcardRepo, deckRepo, mediaRepo, noteTypeService, flashcardService, logger)
+	noteHandler := handlers.NewNoteHandler(noteService)
+
 	userService := services.NewUserService(userRepo, logger)
 	userHandler := handlers.NewUserHandler(userService)
 
-	deckRepo := repositories.NewDeckRepository(database.DB, logger)
-	deckService := services.NewDeckService(deckRepo, logger)
-	deckHandler := handlers.NewDeckHandler(deckService)
+	optimizerRunRepo := repositories.NewOptimizerRunRepository(database.DB, logger)
+	optimizerService := services.NewOptimizerService(optimizerRunRepo, reviewLogRepo, userRepo, logger)
+	if err := optimizerService.FailInterrupted(); err != nil {
+		logger.WithError(err).Error("Failed to clean up interrupted optimizer runs")
+	}
+	optimizerService.StartHeartbeat()
+	optimizerHandler := handlers.NewOptimizerHandler(optimizerService)
+
+	statsService := services.NewStatsService(flashcardRepo, deckRepo, reviewLogRepo, userRepo, flashcardService, logger)
+	statsHandler := handlers.NewStatsHandler(statsService)
 
 	// JWT and Auth services
 	jwtService := services.NewJWTService(logger)
@@ -62,6 +103,14 @@ func main() {
 	router := routes.SetupRouter(
 		flashcardHandler,
 		deckHandler,
+		filteredDeckHandler,
+		deckPresetHandler,
+		noteTypeHandler,
+		noteHandler,
+		mediaHandler,
+		studySessionHandler,
+		optimizerHandler,
+		statsHandler,
 		userHandler,
 		authHandler,
 		jwtService,
@@ -167,5 +216,15 @@ func main() {
 		logger.WithError(err).Error("Server forced to shutdown")
 	}
 
+	// Stop background optimizer runs; they are recorded as interrupted
+	if err := optimizerService.Shutdown(ctx); err != nil {
+		logger.WithError(err).Error("Optimizer runs did not stop in time")
+	}
+
+	// Stop the media garbage collector
+	if err := mediaService.Shutdown(ctx); err != nil {
+		logger.WithError(err).Error("Media garbage collection did not stop in time")
+	}
+
 	logger.Info("Server exited")
 }
@@ -57,6 +57,12 @@ func (h *DeckHandler) CreateDeck(c *gin.Context) {
 
 	deck, err := h.deckService.Create(&req, userID)
 	if err != nil {
+		if err.Error() == "unauthorized: deck preset does not belong to user" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to use this deck preset",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to create deck",
 			"details": err.Error(),
@@ -212,6 +218,12 @@ func (h *DeckHandler) UpdateDeck(c *gin.Context) {
 			})
 			return
 		}
+		if err.Error() == "unauthorized: deck preset does not belong to user" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to use this deck preset",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to update deck",
 			"details": err.Error(),
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"net/http"
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DeckPresetHandler struct {
+	presetService *services.DeckPresetService
+}
+
+func NewDeckPresetHandler(ps *services.DeckPresetService) *DeckPresetHandler {
+	return &DeckPresetHandler{
+		presetService: ps,
+	}
+}
+
+// CreateDeckPreset handles POST /api/v1/deck-presets
+func (h *DeckPresetHandler) CreateDeckPreset(c *gin.Context) {
+	var req models.CreateDeckPresetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	preset, err := h.presetService.Create(&req, userID)
+	if err != nil {
+		if err.Error() == "invalid deck preset: minimum ease cannot exceed starting ease" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid deck preset",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create deck preset",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, preset)
+}
+
+// GetDeckPresets handles GET /api/v1/deck-presets
+func (h *DeckPresetHandler) GetDeckPresets(c *gin.Context) {
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	presets, err := h.presetService.GetByUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve deck presets",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  presets,
+		"count": len(presets),
+	})
+}
+
+// GetDeckPreset handles GET /api/v1/deck-presets/:id
+func (h *DeckPresetHandler) GetDeckPreset(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid deck preset ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	preset, err := h.presetService.GetByIDWithOwnership(id, userID)
+	if err != nil {
+		if err.Error() == "unauthorized: deck preset does not belong to user" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to access this deck preset",
+			})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Deck preset not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, preset)
+}
+
+// UpdateDeckPreset handles PUT /api/v1/deck-presets/:id
+func (h *DeckPresetHandler) UpdateDeckPreset(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid deck preset ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	var req models.UpdateDeckPresetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	preset, err := h.presetService.UpdateWithOwnership(id, userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized: deck preset does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to update this deck preset",
+			})
+		case "invalid deck preset: minimum ease cannot exceed starting ease":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid deck preset",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to update deck preset",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, preset)
+}
+
+// DeleteDeckPreset handles DELETE /api/v1/deck-presets/:id
+func (h *DeckPresetHandler) DeleteDeckPreset(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid deck preset ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	err = h.presetService.DeleteWithOwnership(id, userID)
+	if err != nil {
+		if err.Error() == "unauthorized: deck preset does not belong to user" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to delete this deck preset",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete deck preset",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Deck preset deleted successfully",
+	})
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type FilteredDeckHandler struct {
+	filteredDeckService *services.FilteredDeckService
+}
+
+func NewFilteredDeckHandler(fds *services.FilteredDeckService) *FilteredDeckHandler {
+	return &FilteredDeckHandler{
+		filteredDeckService: fds,
+	}
+}
+
+// CreateFilteredDeck handles POST /api/v1/filtered-decks
+func (h *FilteredDeckHandler) CreateFilteredDeck(c *gin.Context) {
+	var req models.CreateFilteredDeckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	build, err := h.filteredDeckService.Create(&req, userID)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid search") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid search",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create filtered deck",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, build)
+}
+
+// UpdateFilteredDeck handles PUT /api/v1/filtered-decks/:id
+func (h *FilteredDeckHandler) UpdateFilteredDeck(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid deck ID",
+		})
+		return
+	}
+
+	var req models.UpdateFilteredDeckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	build, err := h.filteredDeckService.UpdateWithOwnership(id, userID, &req)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid search"):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid search",
+				"details": err.Error(),
+			})
+		case err.Error() == "deck is not a filtered deck":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Deck is not a filtered deck",
+			})
+		case err.Error() == "unauthorized: deck does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to access this deck",
+			})
+		case err.Error() == "deck not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Deck not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to update filtered deck",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, build)
+}
+
+// RebuildFilteredDeck handles POST /api/v1/filtered-decks/:id/rebuild
+func (h *FilteredDeckHandler) RebuildFilteredDeck(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid deck ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	build, err := h.filteredDeckService.RebuildWithOwnership(id, userID)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid search"):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid search",
+				"details": err.Error(),
+			})
+		case err.Error() == "deck is not a filtered deck":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Deck is not a filtered deck",
+			})
+		case err.Error() == "unauthorized: deck does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to access this deck",
+			})
+		case err.Error() == "deck not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Deck not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to rebuild filtered deck",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, build)
+}
+
+// EmptyFilteredDeck handles POST /api/v1/filtered-decks/:id/empty
+func (h *FilteredDeckHandler) EmptyFilteredDeck(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid deck ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	build, err := h.filteredDeckService.EmptyWithOwnership(id, userID)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid search"):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid search",
+				"details": err.Error(),
+			})
+		case err.Error() == "deck is not a filtered deck":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Deck is not a filtered deck",
+			})
+		case err.Error() == "unauthorized: deck does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to access this deck",
+			})
+		case err.Error() == "deck not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Deck not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to empty filtered deck",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, build)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"swipelearn-api/internal/models"
 	"swipelearn-api/internal/services"
 
@@ -12,11 +13,13 @@ import (
 
 type FlashcardHandler struct {
 	flashcardService *services.FlashcardService
+	sessionService   *services.StudySessionService
 }
 
-func NewFlashcardHandler(fs *services.FlashcardService) *FlashcardHandler {
+func NewFlashcardHandler(fs *services.FlashcardService, ss *services.StudySessionService) *FlashcardHandler {
 	return &FlashcardHandler{
 		flashcardService: fs,
+		sessionService:   ss,
 	}
 }
 
@@ -61,6 +64,12 @@ func (h *FlashcardHandler) CreateFlashcard(c *gin.Context) {
 
 	flashcard, err := h.flashcardService.Create(&req)
 	if err != nil {
+		if err.Error() == "cannot add flashcards to a filtered deck" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Flashcards cannot be added to a filtered deck",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to create flashcard",
 			"details": err.Error(),
@@ -175,15 +184,21 @@ func (h *FlashcardHandler) UpdateFlashcard(c *gin.Context) {
 
 	flashcard, err := h.flashcardService.UpdateWithOwnership(id, userID, &req)
 	if err != nil {
-		if err.Error() == "unauthorized: flashcard does not belong to user" {
+		switch err.Error() {
+		case "unauthorized: flashcard does not belong to user":
 			c.JSON(http.StatusForbidden, gin.H{
 				"error": "You are not authorized to update this flashcard",
 			})
-			return
+		case "flashcard belongs to a note; edit the note instead":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Flashcard belongs to a note",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update flashcard",
+			})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to update flashcard",
-		})
 		return
 	}
 
@@ -227,15 +242,21 @@ func (h *FlashcardHandler) DeleteFlashcard(c *gin.Context) {
 
 	err = h.flashcardService.DeleteWithOwnership(id, userID)
 	if err != nil {
-		if err.Error() == "unauthorized: flashcard does not belong to user" {
+		switch err.Error() {
+		case "unauthorized: flashcard does not belong to user":
 			c.JSON(http.StatusForbidden, gin.H{
 				"error": "You are not authorized to delete this flashcard",
 			})
-			return
+		case "flashcard belongs to a note; delete the note instead":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Flashcard belongs to a note",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to delete flashcard",
+			})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to delete flashcard",
-		})
 		return
 	}
 
@@ -288,7 +309,7 @@ func (h *FlashcardHandler) ReviewFlashcard(c *gin.Context) {
 		return
 	}
 
-	flashcard, err := h.flashcardService.ReviewFlashcardWithOwnership(id, userID, req.Quality)
+	flashcard, err := h.flashcardService.ReviewFlashcardWithOwnership(id, userID, &req)
 	if err != nil {
 		if err.Error() == "unauthorized: flashcard does not belong to user" {
 			c.JSON(http.StatusForbidden, gin.H{
@@ -306,9 +327,91 @@ func (h *FlashcardHandler) ReviewFlashcard(c *gin.Context) {
 	c.JSON(http.StatusOK, flashcard)
 }
 
-// GetDueFlashcards handles GET /api/v1/flashcards/due
-func (h *FlashcardHandler) GetDueFlashcards(c *gin.Context) {
-	// Get user_id from context (set by JWT middleware)
+// UndoReview handles POST /api/v1/flashcards/:id/review/undo
+func (h *FlashcardHandler) UndoReview(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid flashcard ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	var req models.UndoReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	flashcard, err := h.sessionService.UndoReview(id, userID, req.SessionID)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized: flashcard does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to undo reviews of this flashcard",
+			})
+		case "unauthorized: study session does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to access this study session",
+			})
+		case "no review to undo",
+			"last review does not belong to this study session",
+			"undo limit reached for this study session":
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Cannot undo review",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to undo review",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, flashcard)
+}
+
+// GetFlashcardReviews handles GET /api/v1/flashcards/:id/reviews
+func (h *FlashcardHandler) GetFlashcardReviews(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid flashcard ID",
+		})
+		return
+	}
+
+	// Get user_id from context
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{
@@ -333,17 +436,675 @@ func (h *FlashcardHandler) GetDueFlashcards(c *gin.Context) {
 		return
 	}
 
-	flashcards, err := h.flashcardService.GetDueCards(userID)
+	reviews, err := h.flashcardService.GetReviewHistoryWithOwnership(id, userID)
 	if err != nil {
+		if err.Error() == "unauthorized: flashcard does not belong to user" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to view this flashcard",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve due flashcards",
+			"error": "Failed to retrieve flashcard reviews",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  reviews,
+		"count": len(reviews),
+	})
+}
+
+// GetFlashcardPreview handles GET /api/v1/flashcards/:id/preview
+func (h *FlashcardHandler) GetFlashcardPreview(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid flashcard ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	previews, err := h.flashcardService.PreviewWithOwnership(id, userID)
+	if err != nil {
+		if err.Error() == "unauthorized: flashcard does not belong to user" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to view this flashcard",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to preview flashcard review",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  previews,
+		"count": len(previews),
+	})
+}
+
+// CheckAnswer handles POST /api/v1/flashcards/:id/answer
+func (h *FlashcardHandler) CheckAnswer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid flashcard ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	var req models.CheckAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	check, err := h.flashcardService.CheckAnswerWithOwnership(id, userID, &req)
+	if err != nil {
+		switch {
+		case err.Error() == "unauthorized: flashcard does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to answer this flashcard",
+			})
+		case strings.HasPrefix(err.Error(), "answer too long to check"):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Answer too long to check",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to check answer",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, check)
+}
+
+// GetMultipleChoice handles GET /api/v1/flashcards/:id/choices
+func (h *FlashcardHandler) GetMultipleChoice(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid flashcard ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	var req models.MultipleChoiceRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	question, err := h.flashcardService.MultipleChoiceWithOwnership(id, userID, &req)
+	if err != nil {
+		switch {
+		case err.Error() == "unauthorized: flashcard does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to study this flashcard",
+			})
+		case strings.HasPrefix(err.Error(), "not enough answers for multiple choice"):
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Not enough answers for multiple choice",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to build multiple choice question",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
+
+// GradeChoice handles POST /api/v1/flashcards/:id/choices
+func (h *FlashcardHandler) GradeChoice(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid flashcard ID",
 		})
 		return
 	}
 
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	var req models.GradeChoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	grade, err := h.flashcardService.GradeChoiceWithOwnership(id, userID, &req)
+	if err != nil {
+		if err.Error() == "unauthorized: flashcard does not belong to user" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to answer this flashcard",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to grade choice",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, grade)
+}
+
+// GetLeeches handles GET /api/v1/flashcards/leeches
+func (h *FlashcardHandler) GetLeeches(c *gin.Context) {
+	// Get user_id from context (set by JWT middleware)
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	leeches, err := h.flashcardService.GetLeeches(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve leeches",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  leeches,
+		"count": len(leeches),
+	})
+}
+
+// GetDueFlashcards handles GET /api/v1/flashcards/due
+func (h *FlashcardHandler) GetDueFlashcards(c *gin.Context) {
+	// Get user_id from context (set by JWT middleware)
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	queue, err := h.flashcardService.GetDueCards(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve due flashcards",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":      queue.Cards,
+		"count":     len(queue.Cards),
+		"due":       true,
+		"remaining": queue.Remaining,
+	})
+}
+
+// SuspendFlashcards handles POST /api/v1/flashcards/suspend
+func (h *FlashcardHandler) SuspendFlashcards(c *gin.Context) {
+	var req models.FlashcardIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	cards, err := h.flashcardService.SuspendWithOwnership(req.FlashcardIDs, userID, true)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized: flashcard does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to update these flashcards",
+			})
+		case "flashcard not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Flashcard not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to suspend flashcards",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  cards,
+		"count": len(cards),
+	})
+}
+
+// UnsuspendFlashcards handles POST /api/v1/flashcards/unsuspend
+func (h *FlashcardHandler) UnsuspendFlashcards(c *gin.Context) {
+	var req models.FlashcardIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	cards, err := h.flashcardService.SuspendWithOwnership(req.FlashcardIDs, userID, false)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized: flashcard does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to update these flashcards",
+			})
+		case "flashcard not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Flashcard not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to unsuspend flashcards",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  cards,
+		"count": len(cards),
+	})
+}
+
+// BuryFlashcards handles POST /api/v1/flashcards/bury
+func (h *FlashcardHandler) BuryFlashcards(c *gin.Context) {
+	var req models.BuryFlashcardsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	cards, err := h.flashcardService.BuryWithOwnership(req.FlashcardIDs, userID, req.Until)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized: flashcard does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to update these flashcards",
+			})
+		case "flashcard not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Flashcard not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to bury flashcards",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  cards,
+		"count": len(cards),
+	})
+}
+
+// ForgetFlashcards handles POST /api/v1/flashcards/forget
+func (h *FlashcardHandler) ForgetFlashcards(c *gin.Context) {
+	var req models.FlashcardIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	cards, err := h.flashcardService.ForgetWithOwnership(req.FlashcardIDs, userID)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized: flashcard does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to update these flashcards",
+			})
+		case "flashcard not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Flashcard not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to reset flashcards",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  cards,
+		"count": len(cards),
+	})
+}
+
+// SetFlashcardsDue handles POST /api/v1/flashcards/set-due
+func (h *FlashcardHandler) SetFlashcardsDue(c *gin.Context) {
+	var req models.SetDueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	cards, err := h.flashcardService.SetDueWithOwnership(req.FlashcardIDs, userID, req.Days, req.DaysMax)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized: flashcard does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to update these flashcards",
+			})
+		case "flashcard not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Flashcard not found",
+			})
+		case "invalid due range: days_max cannot be less than days":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid due range",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to set flashcard due dates",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"data":  flashcards,
-		"count": len(flashcards),
-		"due":   true,
+		"data":  cards,
+		"count": len(cards),
 	})
 }
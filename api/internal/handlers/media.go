@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"swipelearn-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// multipartOverhead is what a multipart upload may add to the file it carries
+const multipartOverhead = 1 << 20
+
+type MediaHandler struct {
+	mediaService *services.MediaService
+}
+
+func NewMediaHandler(ms *services.MediaService) *MediaHandler {
+	return &MediaHandler{
+		mediaService: ms,
+	}
+}
+
+// UploadMedia handles POST /api/v1/media with the file in the "file" field of a
+// multipart form
+func (h *MediaHandler) UploadMedia(c *gin.Context) {
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	maxSize := h.mediaService.MaxUploadSize()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": "Media too large",
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": "a file is required in the multipart field \"file\"",
+		})
+		return
+	}
+	if fileHeader.Size > maxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": "Media too large",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	media, created, err := h.mediaService.Upload(userID, data)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "media quota exceeded"):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":   "Media quota exceeded",
+				"details": err.Error(),
+			})
+		case strings.HasPrefix(err.Error(), "media too large"):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":   "Media too large",
+				"details": err.Error(),
+			})
+		case strings.HasPrefix(err.Error(), "unsupported media type"):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{
+				"error":   "Unsupported media type",
+				"details": err.Error(),
+			})
+		case strings.HasPrefix(err.Error(), "invalid media"):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid media",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to upload media",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	// Content the user already uploaded is returned as is
+	if !created {
+		c.JSON(http.StatusOK, media)
+		return
+	}
+	c.JSON(http.StatusCreated, media)
+}
+
+// GetMedia handles GET /api/v1/media/:hash
+func (h *MediaHandler) GetMedia(c *gin.Context) {
+	hash := c.Param("hash")
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	media, err := h.mediaService.Get(userID, hash)
+	if err != nil {
+		switch err.Error() {
+		case "media not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Media not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to retrieve media",
+			})
+		}
+		return
+	}
+
+	// Content never changes under a hash, so the hash is the ETag
+	etag := `"` + media.Hash + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, max-age=31536000, immutable")
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	content, err := h.mediaService.Open(media)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve media",
+		})
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, media.Size, media.ContentType, content, map[string]string{
+		"X-Content-Type-Options": "nosniff",
+	})
+}
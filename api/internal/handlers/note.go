@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type NoteHandler struct {
+	noteService *services.NoteService
+}
+
+func NewNoteHandler(ns *services.NoteService) *NoteHandler {
+	return &NoteHandler{
+		noteService: ns,
+	}
+}
+
+// CreateNote handles POST /api/v1/notes
+func (h *NoteHandler) CreateNote(c *gin.Context) {
+	var req models.CreateNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	note, err := h.noteService.Create(&req, userID)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid note"):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid note",
+				"details": err.Error(),
+			})
+		case err.Error() == "cannot add flashcards to a filtered deck":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Cannot add notes to a filtered deck",
+			})
+		case err.Error() == "unauthorized: deck does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to add notes to this deck",
+			})
+		case err.Error() == "unauthorized: note type does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to use this note type",
+			})
+		case err.Error() == "deck not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Deck not found",
+			})
+		case err.Error() == "note type not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Note type not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to create note",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+}
+
+// GetNote handles GET /api/v1/notes/:id
+func (h *NoteHandler) GetNote(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid note ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	note, err := h.noteService.GetByIDWithOwnership(id, userID)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized: note does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to access this note",
+			})
+		case "note not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Note not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to retrieve note",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+// UpdateNote handles PUT /api/v1/notes/:id
+func (h *NoteHandler) UpdateNote(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid note ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	var req models.UpdateNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	note, err := h.noteService.UpdateWithOwnership(id, userID, &req)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid note"):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid note",
+				"details": err.Error(),
+			})
+		case err.Error() == "unauthorized: note does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to update this note",
+			})
+		case err.Error() == "note not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Note not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to update note",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+// DeleteNote handles DELETE /api/v1/notes/:id
+func (h *NoteHandler) DeleteNote(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid note ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	err = h.noteService.DeleteWithOwnership(id, userID)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized: note does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to delete this note",
+			})
+		case "note not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Note not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to delete note",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Note deleted successfully",
+	})
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type NoteTypeHandler struct {
+	noteTypeService *services.NoteTypeService
+}
+
+func NewNoteTypeHandler(nts *services.NoteTypeService) *NoteTypeHandler {
+	return &NoteTypeHandler{
+		noteTypeService: nts,
+	}
+}
+
+// CreateNoteType handles POST /api/v1/note-types
+func (h *NoteTypeHandler) CreateNoteType(c *gin.Context) {
+	var req models.CreateNoteTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	noteType, err := h.noteTypeService.Create(&req, userID)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid note type") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid note type",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create note type",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, noteType)
+}
+
+// GetNoteTypes handles GET /api/v1/note-types
+func (h *NoteTypeHandler) GetNoteTypes(c *gin.Context) {
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	noteTypes, err := h.noteTypeService.GetByUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve note types",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  noteTypes,
+		"count": len(noteTypes),
+	})
+}
+
+// GetNoteType handles GET /api/v1/note-types/:id
+func (h *NoteTypeHandler) GetNoteType(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid note type ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	noteType, err := h.noteTypeService.GetByIDWithOwnership(id, userID)
+	if err != nil {
+		if err.Error() == "unauthorized: note type does not belong to user" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to access this note type",
+			})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Note type not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, noteType)
+}
+
+// DeleteNoteType handles DELETE /api/v1/note-types/:id
+func (h *NoteTypeHandler) DeleteNoteType(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid note type ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	err = h.noteTypeService.DeleteWithOwnership(id, userID)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized: note type does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to delete this note type",
+			})
+		case "cannot delete a built-in note type":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Built-in note types cannot be deleted",
+			})
+		case "note type is in use":
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Note type is used by notes",
+			})
+		case "failed to get note type: note type not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Note type not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to delete note type",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Note type deleted successfully",
+	})
+}
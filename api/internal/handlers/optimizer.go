@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"swipelearn-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OptimizerHandler struct {
+	optimizerService *services.OptimizerService
+}
+
+func NewOptimizerHandler(os *services.OptimizerService) *OptimizerHandler {
+	return &OptimizerHandler{
+		optimizerService: os,
+	}
+}
+
+// OptimizeScheduler handles POST /api/v1/users/me/scheduler/optimize
+func (h *OptimizerHandler) OptimizeScheduler(c *gin.Context) {
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	run, err := h.optimizerService.Start(userID)
+	if err != nil {
+		if err.Error() == "optimizer run already in progress" {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "An optimization is already in progress",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to start optimization",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, run)
+}
+
+// GetOptimizerRun handles GET /api/v1/users/me/scheduler/optimize/:id
+func (h *OptimizerHandler) GetOptimizerRun(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid optimizer run ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	run, err := h.optimizerService.GetByIDWithOwnership(id, userID)
+	if err != nil {
+		if err.Error() == "unauthorized: optimizer run does not belong to user" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to access this optimizer run",
+			})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Optimizer run not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
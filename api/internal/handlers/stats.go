@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type StatsHandler struct {
+	statsService *services.StatsService
+}
+
+func NewStatsHandler(ss *services.StatsService) *StatsHandler {
+	return &StatsHandler{
+		statsService: ss,
+	}
+}
+
+// GetForecast handles GET /api/v1/stats/forecast
+func (h *StatsHandler) GetForecast(c *gin.Context) {
+	var req models.ForecastRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	forecast, err := h.statsService.Forecast(c.Request.Context(), userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "deck_id is required to add new cards":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "deck_id is required when new_per_day is set",
+			})
+		case "deck not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Deck not found",
+			})
+		case "unauthorized: deck does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to access this deck",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to compute forecast",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
+
+// GetRetention handles GET /api/v1/stats/retention
+func (h *StatsHandler) GetRetention(c *gin.Context) {
+	var req models.RetentionRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	stats, err := h.statsService.Retention(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to compute retention",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
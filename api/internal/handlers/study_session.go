@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"net/http"
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type StudySessionHandler struct {
+	sessionService *services.StudySessionService
+}
+
+func NewStudySessionHandler(ss *services.StudySessionService) *StudySessionHandler {
+	return &StudySessionHandler{
+		sessionService: ss,
+	}
+}
+
+// CreateStudySession handles POST /api/v1/study-sessions
+func (h *StudySessionHandler) CreateStudySession(c *gin.Context) {
+	var req models.CreateStudySessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	session, err := h.sessionService.Create(&req, userID)
+	if err != nil {
+		switch err.Error() {
+		case "order is only supported in cram mode":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Order is only supported in cram mode",
+			})
+		case "deck not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Deck not found",
+			})
+		case "unauthorized: deck does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to study this deck",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to create study session",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
+
+// GetNextCard handles GET /api/v1/study-sessions/:id/next
+func (h *StudySessionHandler) GetNextCard(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid study session ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	next, err := h.sessionService.Next(id, userID)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized: study session does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to access this study session",
+			})
+		case "study session already finished":
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Study session already finished",
+			})
+		default:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Study session not found",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, next)
+}
+
+// AnswerCard handles POST /api/v1/study-sessions/:id/answer
+func (h *StudySessionHandler) AnswerCard(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid study session ID",
+		})
+		return
+	}
+
+	var req models.AnswerStudySessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	card, session, err := h.sessionService.Answer(id, userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized: study session does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to access this study session",
+			})
+		case "study session already finished":
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Study session already finished",
+			})
+		case "flashcard is not in the study session queue":
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Flashcard is not in the study session queue",
+			})
+		case "unauthorized: flashcard does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to review this flashcard",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to answer flashcard",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"card":    card,
+		"session": session,
+	})
+}
+
+// FinishStudySession handles POST /api/v1/study-sessions/:id/finish
+func (h *StudySessionHandler) FinishStudySession(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid study session ID",
+		})
+		return
+	}
+
+	// Get user_id from context
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	userIDStr, ok := userIDInterface.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID type in context",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	summary, err := h.sessionService.Finish(id, userID)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized: study session does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You are not authorized to access this study session",
+			})
+		case "study session already finished":
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Study session already finished",
+			})
+		default:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Study session not found",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
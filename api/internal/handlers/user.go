@@ -100,6 +100,13 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 
 	user, err := h.userService.Update(id, &req)
 	if err != nil {
+		if err.Error() == "invalid timezone" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid timezone",
+				"details": "timezone must be an IANA name such as America/Los_Angeles",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to update user",
 			"details": err.Error(),
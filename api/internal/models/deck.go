@@ -1,26 +1,111 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Scheduler names accepted on decks
+const (
+	SchedulerSM2  = "sm2"
+	SchedulerFSRS = "fsrs"
+)
+
+// DefaultDesiredRetention is the recall probability decks schedule reviews for unless set otherwise
+const DefaultDesiredRetention = 0.9
+
 type Deck struct {
-	ID          uuid.UUID `json:"id" db:"id"`
-	UserID      uuid.UUID `json:"user_id" db:"user_id"`
-	Name        string    `json:"name" db:"name"`
-	Description string    `json:"description" db:"description"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID               uuid.UUID   `json:"id" db:"id"`
+	UserID           uuid.UUID   `json:"user_id" db:"user_id"`
+	Name             string      `json:"name" db:"name"`
+	Description      string      `json:"description" db:"description"`
+	Scheduler        string      `json:"scheduler" db:"scheduler"`
+	PresetID         *uuid.UUID  `json:"preset_id" db:"preset_id"`                 // Option preset, nil for the server defaults
+	DesiredRetention float64     `json:"desired_retention" db:"desired_retention"` // Recall probability reviews are scheduled for
+	Filter           *DeckFilter `json:"filter" db:"filter"`                       // Search that fills a filtered deck, nil for regular decks
+	CreatedAt        time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at" db:"updated_at"`
 }
 
 type CreateDeckRequest struct {
+	Name             string     `json:"name" binding:"required"`
+	Description      string     `json:"description"`
+	Scheduler        string     `json:"scheduler" binding:"omitempty,oneof=sm2 fsrs"`
+	PresetID         *uuid.UUID `json:"preset_id"`
+	DesiredRetention *float64   `json:"desired_retention" binding:"omitempty,min=0.85,max=0.97"`
+}
+
+type UpdateDeckRequest struct {
+	Name             *string    `json:"name"`
+	Description      *string    `json:"description"`
+	Scheduler        *string    `json:"scheduler" binding:"omitempty,oneof=sm2 fsrs"`
+	PresetID         *uuid.UUID `json:"preset_id"`
+	ClearPreset      bool       `json:"clear_preset" binding:"excluded_with=PresetID"` // Detach the preset, going back to the default options
+	DesiredRetention *float64   `json:"desired_retention" binding:"omitempty,min=0.85,max=0.97"`
+}
+
+// Orders in which a filtered deck picks the cards matching its search
+const (
+	FilterOrderDue          = "due"           // Earliest next review first
+	FilterOrderRandom       = "random"        // Random order
+	FilterOrderAdded        = "added"         // Oldest cards first
+	FilterOrderLapses       = "lapses"        // Most lapses first
+	FilterOrderInterval     = "interval"      // Shortest interval first
+	FilterOrderIntervalDesc = "interval_desc" // Longest interval first
+)
+
+// DeckFilter defines a filtered deck: up to Limit cards matching Search, picked in
+// Order, are pulled out of their home decks until the deck is emptied or rebuilt.
+// Answers only change a card's schedule when Reschedule is set; otherwise they are
+// a preview and the card goes back to its home deck as it was.
+type DeckFilter struct {
+	Search     string `json:"search"`
+	Order      string `json:"order"`
+	Limit      int    `json:"limit"`
+	Reschedule bool   `json:"reschedule"`
+}
+
+// Value stores the filter as JSONB
+func (f DeckFilter) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
+
+// Scan reads the filter from a JSONB column
+func (f *DeckFilter) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	default:
+		return fmt.Errorf("cannot scan %T into DeckFilter", src)
+	}
+}
+
+type CreateFilteredDeckRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
+	Search      string `json:"search" binding:"required"`
+	Order       string `json:"order" binding:"omitempty,oneof=due random added lapses interval interval_desc"`
+	Limit       int    `json:"limit" binding:"omitempty,min=1,max=9999"`
+	Reschedule  *bool  `json:"reschedule"` // Defaults to true
 }
 
-type UpdateDeckRequest struct {
-	Name        *string `json:"name"`
-	Description *string `json:"description"`
+// UpdateFilteredDeckRequest changes the definition of a filtered deck, which is then rebuilt
+type UpdateFilteredDeckRequest struct {
+	Search     *string `json:"search" binding:"omitempty,min=1"`
+	Order      *string `json:"order" binding:"omitempty,oneof=due random added lapses interval interval_desc"`
+	Limit      *int    `json:"limit" binding:"omitempty,min=1,max=9999"`
+	Reschedule *bool   `json:"reschedule"`
+}
+
+// FilteredDeckBuild is a filtered deck together with how many cards it holds after
+// being built, rebuilt or emptied
+type FilteredDeckBuild struct {
+	Deck      *Deck `json:"deck"`
+	CardCount int   `json:"card_count"`
 }
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Leech actions. Tagging only flags the card as a leech, suspending also takes
+// it out of the due queue until the user unsuspends it.
+const (
+	LeechActionTag     = "tag"
+	LeechActionSuspend = "suspend"
+)
+
+// DeckPreset is a named set of scheduling options that any number of decks can share
+type DeckPreset struct {
+	ID                           uuid.UUID `json:"id" db:"id"`
+	UserID                       uuid.UUID `json:"user_id" db:"user_id"`
+	Name                         string    `json:"name" db:"name"`
+	StartingEase                 float64   `json:"starting_ease" db:"starting_ease"`                                     // Ease factor given to new cards
+	MinimumEase                  float64   `json:"minimum_ease" db:"minimum_ease"`                                       // Lowest ease factor a card can reach
+	IntervalModifier             float64   `json:"interval_modifier" db:"interval_modifier"`                             // Multiplier applied to review intervals
+	MaximumInterval              int       `json:"maximum_interval" db:"maximum_interval"`                               // Upper bound for review intervals, in days
+	LearningSteps                []int64   `json:"learning_steps" db:"learning_steps"`                                   // Learning steps, in minutes
+	RelearningSteps              []int64   `json:"relearning_steps" db:"relearning_steps"`                               // Relearning steps, in minutes
+	NewPerDay                    int       `json:"new_per_day" db:"new_per_day"`                                         // New cards introduced per day
+	ReviewsPerDay                int       `json:"reviews_per_day" db:"reviews_per_day"`                                 // Reviews shown per day
+	LeechThreshold               int       `json:"leech_threshold" db:"leech_threshold"`                                 // Lapses after which a card is a leech
+	LeechAction                  string    `json:"leech_action" db:"leech_action"`                                       // What happens to a card that becomes a leech
+	BurySiblings                 bool      `json:"bury_siblings" db:"bury_siblings"`                                     // Bury the other cards of a note once one is answered
+	TypedAnswerIgnoreCase        bool      `json:"typed_answer_ignore_case" db:"typed_answer_ignore_case"`               // Typed answers may differ in case
+	TypedAnswerIgnoreAccents     bool      `json:"typed_answer_ignore_accents" db:"typed_answer_ignore_accents"`         // Typed answers may leave out accents
+	TypedAnswerIgnorePunctuation bool      `json:"typed_answer_ignore_punctuation" db:"typed_answer_ignore_punctuation"` // Typed answers may differ in punctuation
+	CreatedAt                    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt                    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DeckPresetOptions holds the preset options that can be set on create and update.
+// Nil fields keep their current (or default) value.
+type DeckPresetOptions struct {
+	StartingEase                 *float64 `json:"starting_ease" binding:"omitempty,min=1.3"`
+	MinimumEase                  *float64 `json:"minimum_ease" binding:"omitempty,min=1"`
+	IntervalModifier             *float64 `json:"interval_modifier" binding:"omitempty,gt=0"`
+	MaximumInterval              *int     `json:"maximum_interval" binding:"omitempty,min=1"`
+	LearningSteps                []int64  `json:"learning_steps" binding:"omitempty,dive,min=1"`
+	RelearningSteps              []int64  `json:"relearning_steps" binding:"omitempty,dive,min=1"`
+	NewPerDay                    *int     `json:"new_per_day" binding:"omitempty,min=0"`
+	ReviewsPerDay                *int     `json:"reviews_per_day" binding:"omitempty,min=0"`
+	LeechThreshold               *int     `json:"leech_threshold" binding:"omitempty,min=1"`
+	LeechAction                  *string  `json:"leech_action" binding:"omitempty,oneof=tag suspend"`
+	BurySiblings                 *bool    `json:"bury_siblings"`
+	TypedAnswerIgnoreCase        *bool    `json:"typed_answer_ignore_case"`
+	TypedAnswerIgnoreAccents     *bool    `json:"typed_answer_ignore_accents"`
+	TypedAnswerIgnorePunctuation *bool    `json:"typed_answer_ignore_punctuation"`
+}
+
+type CreateDeckPresetRequest struct {
+	Name string `json:"name" binding:"required"`
+	DeckPresetOptions
+}
+
+type UpdateDeckPresetRequest struct {
+	Name *string `json:"name"`
+	DeckPresetOptions
+}
@@ -6,40 +6,223 @@ import (
 	"github.com/google/uuid"
 )
 
+// Card states. New cards enter learning on their first answer, graduate to
+// review once every learning step is passed, and fall into relearning on a lapse.
+const (
+	CardStateNew        = "new"
+	CardStateLearning   = "learning"
+	CardStateReview     = "review"
+	CardStateRelearning = "relearning"
+)
+
+// Content formats of a card's front and back, which decide how they are rendered to HTML
+const (
+	ContentFormatPlain    = "plain"    // Text shown as is
+	ContentFormatMarkdown = "markdown" // Markdown, with math left for the client to typeset
+	ContentFormatHTML     = "html"     // HTML, sanitized before it is shown
+)
+
 type Flashcard struct {
-	ID          uuid.UUID  `json:"id" db:"id"`
-	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
-	Front       string     `json:"front" db:"front"`
-	Back        string     `json:"back" db:"back"`
-	DeckID      uuid.UUID  `json:"deck_id" db:"deck_id"`
-	Difficulty  float64    `json:"difficulty" db:"difficulty"`
-	Interval    int        `json:"interval" db:"interval"`
-	EaseFactor  float64    `json:"ease_factor" db:"ease_factor"`
-	ReviewCount int        `json:"review_count" db:"review_count"`
-	LastReview  *time.Time `json:"last_review" db:"last_review"`
-	NextReview  *time.Time `json:"next_review" db:"next_review"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	ID             uuid.UUID  `json:"id" db:"id"`
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	Front          string     `json:"front" db:"front"`
+	Back           string     `json:"back" db:"back"`
+	Format         string     `json:"format" db:"format"`
+	FrontHTML      string     `json:"front_html" db:"front_html"` // Front rendered as sanitized HTML
+	BackHTML       string     `json:"back_html" db:"back_html"`   // Back rendered as sanitized HTML
+	DeckID         uuid.UUID  `json:"deck_id" db:"deck_id"`
+	HomeDeckID     *uuid.UUID `json:"home_deck_id" db:"home_deck_id"` // Deck the card returns to while it is in a filtered deck
+	NoteID         *uuid.UUID `json:"note_id" db:"note_id"`           // Note the card was generated from, nil for standalone cards
+	Ord            *int       `json:"ord" db:"ord"`                   // Index of the note type template the card was rendered with
+	Difficulty     float64    `json:"difficulty" db:"difficulty"`
+	Interval       int        `json:"interval" db:"interval"`
+	EaseFactor     float64    `json:"ease_factor" db:"ease_factor"`
+	ReviewCount    int        `json:"review_count" db:"review_count"`
+	Stability      float64    `json:"stability" db:"stability"`
+	Retrievability float64    `json:"retrievability" db:"retrievability"`
+	State          string     `json:"state" db:"state"`
+	Step           int        `json:"step" db:"step"` // Current learning or relearning step
+	LastReview     *time.Time `json:"last_review" db:"last_review"`
+	NextReview     *time.Time `json:"next_review" db:"next_review"`
+	Suspended      bool       `json:"suspended" db:"suspended"`       // Left out of the due queue until unsuspended
+	BuriedUntil    *time.Time `json:"buried_until" db:"buried_until"` // Left out of the due queue until this time
+	Lapses         int        `json:"lapses" db:"lapses"`             // Times the card was forgotten while in review
+	Leech          bool       `json:"leech" db:"leech"`               // Set once lapses reach the deck's leech threshold
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// SchedulingState is the subset of flashcard fields owned by a scheduler.
+// Difficulty holds the scheduler's own difficulty measure: the ease factor
+// for SM-2 and D (1-10) for FSRS.
+type SchedulingState struct {
+	Difficulty     float64    `json:"difficulty"`
+	Interval       int        `json:"interval"`
+	EaseFactor     float64    `json:"ease_factor"`
+	ReviewCount    int        `json:"review_count"`
+	Stability      float64    `json:"stability"`
+	Retrievability float64    `json:"retrievability"`
+	State          string     `json:"state"`
+	Step           int        `json:"step"`
+	LastReview     *time.Time `json:"last_review"`
+	NextReview     *time.Time `json:"next_review"`
+	Lapses         int        `json:"lapses"`
+	Leech          bool       `json:"leech"`
+}
+
+// CardState returns the card state, deriving it from the review history for
+// states recorded before the state machine existed
+func (s SchedulingState) CardState() string {
+	if s.State != "" {
+		return s.State
+	}
+	if s.ReviewCount > 0 || s.LastReview != nil {
+		return CardStateReview
+	}
+	return CardStateNew
+}
+
+// HomeDeck returns the deck the card belongs to: its home deck while it is in a
+// filtered deck, otherwise the deck it is in
+func (f *Flashcard) HomeDeck() uuid.UUID {
+	if f.HomeDeckID != nil {
+		return *f.HomeDeckID
+	}
+	return f.DeckID
+}
+
+// SchedulingState returns a copy of the card's scheduling fields
+func (f *Flashcard) SchedulingState() SchedulingState {
+	return SchedulingState{
+		Difficulty:     f.Difficulty,
+		Interval:       f.Interval,
+		EaseFactor:     f.EaseFactor,
+		ReviewCount:    f.ReviewCount,
+		Stability:      f.Stability,
+		Retrievability: f.Retrievability,
+		State:          f.State,
+		Step:           f.Step,
+		LastReview:     f.LastReview,
+		NextReview:     f.NextReview,
+		Lapses:         f.Lapses,
+		Leech:          f.Leech,
+	}
 }
 
 type CreateFlashcardRequest struct {
 	Front  string    `json:"front" binding:"required"`
 	Back   string    `json:"back" binding:"required"`
+	Format string    `json:"format" binding:"omitempty,oneof=plain markdown html"` // Defaults to plain
 	UserID uuid.UUID `json:"user_id" binding:"required"`
 	DeckID uuid.UUID `json:"deck_id" binding:"required"`
 }
 
 type UpdateFlashcardRequest struct {
-	Front       *string    `json:"front"`
-	Back        *string    `json:"back"`
-	Difficulty  *float64   `json:"difficulty"`
-	Interval    *int       `json:"interval"`
-	EaseFactor  *float64   `json:"ease_factor"`
-	ReviewCount *int       `json:"review_count"`
-	LastReview  *time.Time `json:"last_review"`
-	NextReview  *time.Time `json:"next_review"`
+	Front          *string    `json:"front"`
+	Back           *string    `json:"back"`
+	Format         *string    `json:"format" binding:"omitempty,oneof=plain markdown html"`
+	Difficulty     *float64   `json:"difficulty"`
+	Interval       *int       `json:"interval"`
+	EaseFactor     *float64   `json:"ease_factor"`
+	ReviewCount    *int       `json:"review_count"`
+	Stability      *float64   `json:"stability"`
+	Retrievability *float64   `json:"retrievability"`
+	LastReview     *time.Time `json:"last_review"`
+	NextReview     *time.Time `json:"next_review"`
 }
 
 type ReviewFlashcardRequest struct {
-	Quality int `json:"quality" binding:"required,min=0,max=5"`
+	Quality     int        `json:"quality" binding:"required,min=0,max=5"`
+	TimeTakenMs int        `json:"time_taken_ms" binding:"min=0"`
+	SessionID   *uuid.UUID `json:"session_id"` // Study session the answer belongs to, required for undo
+}
+
+// FlashcardIDsRequest selects the flashcards a bulk action applies to
+type FlashcardIDsRequest struct {
+	FlashcardIDs []uuid.UUID `json:"flashcard_ids" binding:"required,min=1,max=1000"`
+}
+
+type BuryFlashcardsRequest struct {
+	FlashcardIDs []uuid.UUID `json:"flashcard_ids" binding:"required,min=1,max=1000"`
+	Until        *time.Time  `json:"until"` // Defaults to the start of the next study day
+}
+
+// SetDueRequest moves flashcards to a day Days from today, or to a random day
+// between Days and DaysMax when DaysMax is set
+type SetDueRequest struct {
+	FlashcardIDs []uuid.UUID `json:"flashcard_ids" binding:"required,min=1,max=1000"`
+	Days         int         `json:"days" binding:"min=0"`
+	DaysMax      *int        `json:"days_max" binding:"omitempty,min=0"`
+}
+
+// ReviewPreview is the scheduling state a flashcard would get if answered with a quality
+type ReviewPreview struct {
+	Quality    int        `json:"quality"`
+	State      string     `json:"state"`
+	Interval   int        `json:"interval"`
+	EaseFactor float64    `json:"ease_factor"`
+	NextReview *time.Time `json:"next_review"`
+}
+
+// Operations of an answer diff, turning the typed answer into the expected one
+const (
+	DiffOpEqual  = "equal"  // Typed as expected
+	DiffOpDelete = "delete" // Typed but not expected
+	DiffOpInsert = "insert" // Expected but not typed
+)
+
+type CheckAnswerRequest struct {
+	Answer string `json:"answer" binding:"max=1000"`
+}
+
+// DiffSegment is a run of characters of an answer diff
+type DiffSegment struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// AnswerCheck compares a typed answer with the back of a card. SuggestedQuality can
+// be sent as the quality of a review of the card.
+type AnswerCheck struct {
+	Answer           string        `json:"answer"`
+	Expected         string        `json:"expected"` // Text of the card's back
+	Correct          bool          `json:"correct"`  // The answer only differs in what the deck preset ignores
+	Distance         int           `json:"distance"` // Characters to insert, delete or replace to correct the answer
+	Similarity       float64       `json:"similarity"`
+	SuggestedQuality int           `json:"suggested_quality"`
+	Diff             []DiffSegment `json:"diff"`
+}
+
+// MultipleChoiceRequest asks for a multiple-choice question with Distractors wrong
+// choices, 3 by default
+type MultipleChoiceRequest struct {
+	Distractors int `form:"distractors" binding:"omitempty,min=1,max=9"`
+}
+
+// Choice is an answer offered by a multiple-choice question
+type Choice struct {
+	Text string `json:"text"` // Sent back to grade the choice
+	HTML string `json:"html"`
+}
+
+// MultipleChoiceQuestion shows a flashcard's front with its back shuffled among the
+// backs of other cards of its deck
+type MultipleChoiceQuestion struct {
+	FlashcardID uuid.UUID `json:"flashcard_id"`
+	Front       string    `json:"front"`
+	FrontHTML   string    `json:"front_html"`
+	Choices     []Choice  `json:"choices"`
+}
+
+type GradeChoiceRequest struct {
+	Choice string `json:"choice" binding:"required,max=10000"`
+}
+
+// ChoiceGrade grades the choice made in a multiple-choice question. SuggestedQuality
+// can be sent as the quality of a review of the card.
+type ChoiceGrade struct {
+	Choice           string `json:"choice"`
+	Expected         string `json:"expected"`
+	Correct          bool   `json:"correct"`
+	SuggestedQuality int    `json:"suggested_quality"`
 }
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MediaReferencePrefix starts a reference to a stored media file in card content:
+// media:HASH points at the file that GET /api/v1/media/HASH returns
+const MediaReferencePrefix = "media:"
+
+// mediaReference matches a media:HASH reference
+var mediaReference = regexp.MustCompile(MediaReferencePrefix + `([0-9a-f]{64})`)
+
+// Media records that a user uploaded a file. Files are stored once per content, under
+// the hex SHA-256 hash of their bytes.
+type Media struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	Hash        string    `json:"hash" db:"hash"`
+	ContentType string    `json:"content_type" db:"content_type"` // Sniffed from the content
+	Size        int64     `json:"size" db:"size"`                 // Bytes
+	Width       *int      `json:"width" db:"width"`               // Pixels, nil when not an image with known dimensions
+	Height      *int      `json:"height" db:"height"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// MediaReferences returns the distinct hashes a text references with media:HASH, in
+// order of first appearance
+func MediaReferences(text string) []string {
+	var hashes []string
+	seen := make(map[string]bool)
+	for _, match := range mediaReference.FindAllStringSubmatch(text, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			hashes = append(hashes, match[1])
+		}
+	}
+	return hashes
+}
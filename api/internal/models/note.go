@@ -0,0 +1,209 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Built-in note types, available to every user
+var (
+	BasicNoteTypeID          = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	BasicReversedNoteTypeID  = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	ClozeNoteTypeID          = uuid.MustParse("00000000-0000-0000-0000-000000000003")
+	ImageOcclusionNoteTypeID = uuid.MustParse("00000000-0000-0000-0000-000000000004")
+)
+
+// Note type kinds. A standard note type generates one card per template; a cloze note
+// type has a single template and generates one card per cloze deletion index; an image
+// occlusion note type has a single template and generates one card per occlusion index.
+const (
+	NoteTypeKindStandard       = "standard"
+	NoteTypeKindCloze          = "cloze"
+	NoteTypeKindImageOcclusion = "image_occlusion"
+)
+
+// Occlusion shapes
+const (
+	OcclusionShapeRect    = "rect"
+	OcclusionShapePolygon = "polygon"
+)
+
+// NoteType describes the fields of its notes and the card templates that turn a note
+// into flashcards: one card per template
+type NoteType struct {
+	ID        uuid.UUID     `json:"id" db:"id"`
+	UserID    *uuid.UUID    `json:"user_id" db:"user_id"` // nil for built-in note types
+	Name      string        `json:"name" db:"name"`
+	Kind      string        `json:"kind" db:"kind"`
+	Fields    NoteFieldList `json:"fields" db:"fields"`
+	Templates CardTemplates `json:"templates" db:"templates"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// CardTemplate renders the front and back of a card from a note's fields. {{Field}}
+// inserts a field, and {{FrontSide}} on the back inserts the rendered front. In cloze
+// note types, {{cloze:Field}} inserts a field with the card's deletion masked on the
+// front and revealed on the back. In image occlusion note types, {{occlusion:Field}}
+// inserts the image whose media hash the field holds as an SVG, with the card's
+// occlusions masked on the front and revealed on the back.
+type CardTemplate struct {
+	Name  string `json:"name" binding:"required"`
+	Front string `json:"front" binding:"required"`
+	Back  string `json:"back" binding:"required"`
+}
+
+// NoteFieldList is the ordered list of field names of a note type
+type NoteFieldList []string
+
+// CardTemplates is the ordered list of card templates of a note type
+type CardTemplates []CardTemplate
+
+// Note holds the field values its cards are rendered from. Every card of a note is a
+// sibling of the others; editing the note updates all of them.
+type Note struct {
+	ID         uuid.UUID    `json:"id" db:"id"`
+	UserID     uuid.UUID    `json:"user_id" db:"user_id"`
+	DeckID     uuid.UUID    `json:"deck_id" db:"deck_id"` // Deck new cards of the note are added to
+	NoteTypeID uuid.UUID    `json:"note_type_id" db:"note_type_id"`
+	Fields     NoteFields   `json:"fields" db:"fields"`
+	Occlusions Occlusions   `json:"occlusions" db:"occlusions"` // Regions masked on the image of an image occlusion note
+	Cards      []*Flashcard `json:"cards,omitempty" db:"-"`
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// NoteFields maps field names to their values
+type NoteFields map[string]string
+
+// NoteCardChanges lists the writes to its cards that go with an update of a note
+type NoteCardChanges struct {
+	Create []*Flashcard // Cards of templates or indices the note did not generate before
+	Update []*Flashcard // Cards rendered again, with their new front and back
+	Delete []uuid.UUID  // Cards of deletion or occlusion indices the note no longer has
+}
+
+// Occlusion masks a region of the image of an image occlusion note. Coordinates are
+// fractions of the image's width and height, measured from its top left corner.
+// Occlusions sharing an index are masked together on the same card.
+type Occlusion struct {
+	Index  int              `json:"index" binding:"min=1"`
+	Shape  string           `json:"shape" binding:"required,oneof=rect polygon"`
+	Left   float64          `json:"left,omitempty"` // Rectangles only
+	Top    float64          `json:"top,omitempty"`
+	Width  float64          `json:"width,omitempty"`
+	Height float64          `json:"height,omitempty"`
+	Points []OcclusionPoint `json:"points,omitempty" binding:"omitempty,max=100"` // Polygons only
+}
+
+// OcclusionPoint is a corner of a polygon occlusion
+type OcclusionPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Occlusions is the list of occlusions of a note
+type Occlusions []Occlusion
+
+type CreateNoteTypeRequest struct {
+	Name      string         `json:"name" binding:"required"`
+	Kind      string         `json:"kind" binding:"omitempty,oneof=standard cloze image_occlusion"` // Defaults to standard
+	Fields    []string       `json:"fields" binding:"required,min=1,max=50,dive,required"`
+	Templates []CardTemplate `json:"templates" binding:"required,min=1,max=20,dive"`
+}
+
+type CreateNoteRequest struct {
+	DeckID     uuid.UUID  `json:"deck_id" binding:"required"`
+	NoteTypeID uuid.UUID  `json:"note_type_id" binding:"required"`
+	Fields     NoteFields `json:"fields" binding:"required"`
+	Occlusions Occlusions `json:"occlusions" binding:"omitempty,max=200,dive"` // Image occlusion notes only
+}
+
+type UpdateNoteRequest struct {
+	Fields     NoteFields `json:"fields"`                                      // Fields left out keep their value
+	Occlusions Occlusions `json:"occlusions" binding:"omitempty,max=200,dive"` // Replace the occlusions when set
+}
+
+// Value stores the field names as JSONB
+func (f NoteFieldList) Value() (driver.Value, error) {
+	if f == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(f)
+}
+
+// Scan reads the field names from a JSONB column
+func (f *NoteFieldList) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	default:
+		return fmt.Errorf("cannot scan %T into NoteFieldList", src)
+	}
+}
+
+// Value stores the templates as JSONB
+func (t CardTemplates) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+// Scan reads the templates from a JSONB column
+func (t *CardTemplates) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return fmt.Errorf("cannot scan %T into CardTemplates", src)
+	}
+}
+
+// Value stores the field values as JSONB
+func (f NoteFields) Value() (driver.Value, error) {
+	if f == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(f)
+}
+
+// Scan reads the field values from a JSONB column
+func (f *NoteFields) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	default:
+		return fmt.Errorf("cannot scan %T into NoteFields", src)
+	}
+}
+
+// Value stores the occlusions as JSONB
+func (o Occlusions) Value() (driver.Value, error) {
+	if o == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(o)
+}
+
+// Scan reads the occlusions from a JSONB column
+func (o *Occlusions) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, o)
+	case string:
+		return json.Unmarshal([]byte(v), o)
+	default:
+		return fmt.Errorf("cannot scan %T into Occlusions", src)
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Optimizer run statuses
+const (
+	OptimizerRunPending   = "pending"
+	OptimizerRunRunning   = "running"
+	OptimizerRunSucceeded = "succeeded"
+	OptimizerRunFailed    = "failed"
+)
+
+// OptimizerRun is a background job fitting a user's FSRS weights to their review
+// history. Log-loss and RMSE compare predicted recall with the actual answers,
+// before (current weights) and after (fitted weights) the fit.
+type OptimizerRun struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	Status        string     `json:"status" db:"status"`
+	ReviewCount   int        `json:"review_count" db:"review_count"` // Reviews the weights were fitted on
+	LogLossBefore *float64   `json:"log_loss_before" db:"log_loss_before"`
+	LogLossAfter  *float64   `json:"log_loss_after" db:"log_loss_after"`
+	RMSEBefore    *float64   `json:"rmse_before" db:"rmse_before"`
+	RMSEAfter     *float64   `json:"rmse_after" db:"rmse_after"`
+	Weights       []float64  `json:"weights" db:"weights"` // Fitted weights, set once the run succeeded
+	Error         *string    `json:"error" db:"error"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	StartedAt     *time.Time `json:"started_at" db:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at" db:"finished_at"`
+	HeartbeatAt   time.Time  `json:"heartbeat_at" db:"heartbeat_at"` // Last time the server fitting the run showed it was alive
+}
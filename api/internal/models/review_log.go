@@ -0,0 +1,51 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReviewLog records a single review of a flashcard together with the
+// scheduling state before and after it was answered
+type ReviewLog struct {
+	ID                 uuid.UUID       `json:"id" db:"id"`
+	FlashcardID        uuid.UUID       `json:"flashcard_id" db:"flashcard_id"`
+	UserID             uuid.UUID       `json:"user_id" db:"user_id"`
+	DeckID             uuid.UUID       `json:"deck_id" db:"deck_id"`
+	Scheduler          string          `json:"scheduler" db:"scheduler"`
+	Quality            int             `json:"quality" db:"quality"`
+	TimeTakenMs        int             `json:"time_taken_ms" db:"time_taken_ms"`
+	StateBefore        SchedulingState `json:"state_before" db:"state_before"`
+	StateAfter         SchedulingState `json:"state_after" db:"state_after"`
+	SessionID          *uuid.UUID      `json:"session_id" db:"session_id"`
+	ReviewedAt         time.Time       `json:"reviewed_at" db:"reviewed_at"`
+	UndoneAt           *time.Time      `json:"undone_at" db:"undone_at"`
+	Cram               bool            `json:"cram" db:"cram"`                                             // Answered without rescheduling, when cramming or previewing a filtered deck
+	BuriedSiblingIDs   []uuid.UUID     `json:"buried_sibling_ids,omitempty" db:"buried_sibling_ids"`       // Unburied again when the review is undone
+	ReturnedFromDeckID *uuid.UUID      `json:"returned_from_deck_id,omitempty" db:"returned_from_deck_id"` // Filtered deck the card goes back to when the review is undone
+}
+
+type UndoReviewRequest struct {
+	SessionID uuid.UUID `json:"session_id" binding:"required"`
+}
+
+// Value stores the scheduling state as JSONB
+func (s SchedulingState) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan reads the scheduling state from a JSONB column
+func (s *SchedulingState) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return fmt.Errorf("cannot scan %T into SchedulingState", src)
+	}
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// ReviewOutcomeCount is how many answers to cards in review a deck got in a period,
+// and how many of them were recalled (not answered "again")
+type ReviewOutcomeCount struct {
+	DeckID   uuid.UUID `json:"deck_id" db:"deck_id"`
+	Reviews  int       `json:"reviews" db:"reviews"`
+	Recalled int       `json:"recalled" db:"recalled"`
+}
+
+// ForecastRequest selects the horizon of a workload forecast. NewPerDay adds that many
+// hypothetical new cards to DeckID every day, to plan for a new deck or a higher limit.
+type ForecastRequest struct {
+	Days      int        `form:"days" binding:"omitempty,min=1,max=365"`
+	NewPerDay int        `form:"new_per_day" binding:"omitempty,min=0,max=200"`
+	DeckID    *uuid.UUID `form:"deck_id"` // Limits the forecast to one deck; required with new_per_day
+}
+
+// ForecastDay is the projected workload of one study day
+type ForecastDay struct {
+	Date            string  `json:"date"`             // Study day in the user's timezone (YYYY-MM-DD)
+	Scheduled       int     `json:"scheduled"`        // Cards already due that day, from their current next review
+	ExpectedReviews float64 `json:"expected_reviews"` // Mean number of answers across the simulations
+	ReviewsLow      int     `json:"reviews_low"`      // 10th percentile of the simulated answers
+	ReviewsHigh     int     `json:"reviews_high"`     // 90th percentile of the simulated answers
+	NewCards        int     `json:"new_cards"`        // Hypothetical new cards introduced that day
+}
+
+// Forecast projects a user's daily review workload. Days after the first also count
+// the reviews that answering earlier days creates, simulated under each card's scheduler.
+type Forecast struct {
+	Days                 int           `json:"days"`
+	NewPerDay            int           `json:"new_per_day"`
+	DeckID               *uuid.UUID    `json:"deck_id"`
+	Simulations          int           `json:"simulations"`
+	Retention            float64       `json:"retention"` // Recall rate assumed for cards without a memory model (SM-2)
+	TotalExpectedReviews float64       `json:"total_expected_reviews"`
+	Daily                []ForecastDay `json:"daily"`
+}
+
+// RetentionRequest selects the period retention is measured over
+type RetentionRequest struct {
+	Days int `form:"days" binding:"omitempty,min=1,max=365"`
+}
+
+// DeckRetention compares how often a deck's cards in review were recalled with the
+// retention the deck schedules for. ActualRetention is nil when there were no reviews.
+type DeckRetention struct {
+	DeckID          uuid.UUID `json:"deck_id"`
+	DeckName        string    `json:"deck_name"`
+	Scheduler       string    `json:"scheduler"`
+	TargetRetention float64   `json:"target_retention"`
+	ActualRetention *float64  `json:"actual_retention"`
+	Reviews         int       `json:"reviews"`
+	Recalled        int       `json:"recalled"`
+}
+
+// RetentionStats reports actual against target retention over the last Days days, per
+// deck and overall. The overall target is the mean deck target weighted by reviews.
+type RetentionStats struct {
+	Days            int             `json:"days"`
+	TargetRetention *float64        `json:"target_retention"`
+	ActualRetention *float64        `json:"actual_retention"`
+	Reviews         int             `json:"reviews"`
+	Recalled        int             `json:"recalled"`
+	Decks           []DeckRetention `json:"decks"`
+}
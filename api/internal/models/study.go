@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// StudyBudget is how many more new cards and reviews can be studied today
+type StudyBudget struct {
+	New     int `json:"new"`
+	Reviews int `json:"reviews"`
+}
+
+// RemainingBudget is the study budget left today across all decks and for each deck
+type RemainingBudget struct {
+	StudyBudget
+	Decks map[uuid.UUID]StudyBudget `json:"decks"`
+}
+
+// StudiedCount is how many new cards and reviews of a deck were answered in a period
+type StudiedCount struct {
+	DeckID  uuid.UUID `json:"deck_id" db:"deck_id"`
+	New     int       `json:"new" db:"new"`
+	Reviews int       `json:"reviews" db:"reviews"`
+}
+
+// DueQueue is the list of cards to study now together with the remaining daily budget
+type DueQueue struct {
+	Cards     []*Flashcard    `json:"cards"`
+	Remaining RemainingBudget `json:"remaining"`
+}
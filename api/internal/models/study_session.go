@@ -0,0 +1,114 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Study session statuses
+const (
+	StudySessionActive   = "active"
+	StudySessionFinished = "finished"
+)
+
+// Study session modes. Cram sessions drill cards without rescheduling them.
+const (
+	StudySessionModeNormal = "normal"
+	StudySessionModeCram   = "cram"
+)
+
+// Orders of the cards in a cram session
+const (
+	CramOrderRandom  = "random"
+	CramOrderHardest = "hardest" // Most lapses first, then lowest ease
+	CramOrderOldest  = "oldest"  // Least recently reviewed first
+)
+
+// StudySession is a server-side study run over a fixed queue of cards. The queue is
+// built once when the session starts, so answering cards never reorders it.
+type StudySession struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	DeckID        *uuid.UUID `json:"deck_id" db:"deck_id"` // nil when studying all decks
+	Status        string     `json:"status" db:"status"`
+	Mode          string     `json:"mode" db:"mode"`
+	CramOrder     *string    `json:"cram_order" db:"cram_order"` // Only set in cram mode
+	Queue         StudyQueue `json:"queue" db:"queue"`           // Cards still to answer
+	NewCount      int        `json:"new_count" db:"new_count"`
+	LearningCount int        `json:"learning_count" db:"learning_count"`
+	ReviewCount   int        `json:"review_count" db:"review_count"`
+	AgainCount    int        `json:"again_count" db:"again_count"`
+	TotalTimeMs   int        `json:"total_time_ms" db:"total_time_ms"`
+	StartedAt     time.Time  `json:"started_at" db:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at" db:"finished_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// StudyQueueItem is a card waiting in a study session. DueAt is set for cards on a
+// learning step, which come back later in the same session.
+type StudyQueueItem struct {
+	FlashcardID uuid.UUID  `json:"flashcard_id"`
+	State       string     `json:"state"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+}
+
+// StudyQueue is the ordered list of cards left in a study session
+type StudyQueue []StudyQueueItem
+
+type CreateStudySessionRequest struct {
+	DeckID *uuid.UUID `json:"deck_id"` // Omit to study all decks
+	Mode   string     `json:"mode" binding:"omitempty,oneof=normal cram"`
+	Order  string     `json:"order" binding:"omitempty,oneof=random hardest oldest"` // Cram mode only
+}
+
+type AnswerStudySessionRequest struct {
+	FlashcardID uuid.UUID `json:"flashcard_id" binding:"required"`
+	Quality     int       `json:"quality" binding:"min=0,max=5"`
+	TimeTakenMs int       `json:"time_taken_ms" binding:"min=0"`
+}
+
+// StudySessionNext is the next card to study together with how many cards are left
+type StudySessionNext struct {
+	Card      *Flashcard `json:"card"` // nil once the queue is empty
+	Remaining int        `json:"remaining"`
+}
+
+// StudySessionSummary describes a finished study session
+type StudySessionSummary struct {
+	SessionID     uuid.UUID `json:"session_id"`
+	Mode          string    `json:"mode"`
+	Answered      int       `json:"answered"`
+	NewCount      int       `json:"new_count"`
+	LearningCount int       `json:"learning_count"`
+	ReviewCount   int       `json:"review_count"`
+	AgainCount    int       `json:"again_count"`
+	Retention     float64   `json:"retention"` // Share of answers that were not "again"
+	Skipped       int       `json:"skipped"`   // Cards left in the queue when the session finished
+	TotalTimeMs   int       `json:"total_time_ms"`
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at"`
+}
+
+// Value stores the queue as JSONB
+func (q StudyQueue) Value() (driver.Value, error) {
+	if q == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(q)
+}
+
+// Scan reads the queue from a JSONB column
+func (q *StudyQueue) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, q)
+	case string:
+		return json.Unmarshal([]byte(v), q)
+	default:
+		return fmt.Errorf("cannot scan %T into StudyQueue", src)
+	}
+}
@@ -7,12 +7,17 @@ import (
 )
 
 type User struct {
-	ID           uuid.UUID `json:"id" db:"id"`
-	Email        string    `json:"email" db:"email"`
-	Name         string    `json:"name" db:"name"`
-	PasswordHash string    `json:"-" db:"password_hash"` // Never expose password hash in JSON
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID            uuid.UUID `json:"id" db:"id"`
+	Email         string    `json:"email" db:"email"`
+	Name          string    `json:"name" db:"name"`
+	PasswordHash  string    `json:"-" db:"password_hash"`                 // Never expose password hash in JSON
+	NewPerDay     *int      `json:"new_per_day" db:"new_per_day"`         // Daily new cards across all decks, nil for no limit
+	ReviewsPerDay *int      `json:"reviews_per_day" db:"reviews_per_day"` // Daily reviews across all decks, nil for no limit
+	Timezone      string    `json:"timezone" db:"timezone"`               // IANA name, e.g. "America/Los_Angeles"
+	DayStartHour  int       `json:"day_start_hour" db:"day_start_hour"`   // Local hour (0-23) at which the next study day starts
+	FSRSWeights   []float64 `json:"fsrs_weights" db:"fsrs_weights"`       // Weights fitted to the user's reviews, nil for the defaults
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
 }
 
 type CreateUserRequest struct {
@@ -21,8 +26,12 @@ type CreateUserRequest struct {
 }
 
 type UpdateUserRequest struct {
-	Email *string `json:"email"`
-	Name  *string `json:"name"`
+	Email         *string `json:"email"`
+	Name          *string `json:"name"`
+	NewPerDay     *int    `json:"new_per_day" binding:"omitempty,min=0"`
+	ReviewsPerDay *int    `json:"reviews_per_day" binding:"omitempty,min=0"`
+	Timezone      *string `json:"timezone"`
+	DayStartHour  *int    `json:"day_start_hour" binding:"omitempty,min=0,max=23"`
 }
 
 type LoginRequest struct {
@@ -0,0 +1,257 @@
+package render
+
+import (
+	"html"
+	"strings"
+)
+
+// Classes of highlighted code tokens, styled by clients
+const (
+	classKeyword = "hl-keyword"
+	classString  = "hl-string"
+	classComment = "hl-comment"
+	classNumber  = "hl-number"
+)
+
+// language describes the tokens of a programming language well enough to highlight it
+type language struct {
+	keywords      map[string]bool
+	caseless      bool        // Keywords match in any case
+	preprocessor  bool        // Keywords include preprocessor directives such as #include
+	lineComments  []string    // Start comments running to the end of the line
+	blockComments [][2]string // Start and end of comments spanning lines
+	quotes        []string    // Delimit strings, longest first
+	rawQuotes     []string    // Delimit strings that can span lines and have no escapes
+}
+
+// words builds a keyword set from a space separated list
+func words(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(list) {
+		set[word] = true
+	}
+	return set
+}
+
+var (
+	cStyleComments = [][2]string{{"/*", "*/"}}
+	cStyleQuotes   = []string{`"`, `'`}
+)
+
+// languages maps the names fenced code blocks use to their language
+var languages = func() map[string]*language {
+	golang := &language{
+		keywords: words(`break case chan const continue default defer else fallthrough for func go goto if
+			import interface map package range return select struct switch type var
+			true false nil iota bool byte error int int8 int16 int32 int64 uint uint8 uint16 uint32
+			uint64 uintptr float32 float64 complex64 complex128 rune string any`),
+		lineComments: []string{"//"}, blockComments: cStyleComments, quotes: cStyleQuotes, rawQuotes: []string{"`"},
+	}
+	python := &language{
+		keywords: words(`and as assert async await break class continue def del elif else except finally
+			for from global if import in is lambda nonlocal not or pass raise return try while with yield
+			True False None self`),
+		lineComments: []string{"#"}, quotes: cStyleQuotes, rawQuotes: []string{`"""`, `'''`},
+	}
+	javascript := &language{
+		keywords: words(`break case catch class const continue debugger default delete do else export
+			extends finally for function if import in instanceof let new return super switch this throw
+			try typeof var void while with yield async await of true false null undefined
+			interface type enum implements private protected public readonly as`),
+		lineComments: []string{"//"}, blockComments: cStyleComments, quotes: cStyleQuotes, rawQuotes: []string{"`"},
+	}
+	java := &language{
+		keywords: words(`abstract assert boolean break byte case catch char class const continue default do
+			double else enum extends final finally float for goto if implements import instanceof int
+			interface long native new package private protected public return short static strictfp super
+			switch synchronized this throw throws transient try void volatile while var record
+			true false null`),
+		lineComments: []string{"//"}, blockComments: cStyleComments, quotes: cStyleQuotes, rawQuotes: []string{`"""`},
+	}
+	c := &language{
+		keywords: words(`auto break case char const continue default do double else enum extern float for
+			goto if inline int long register restrict return short signed sizeof static struct switch
+			typedef union unsigned void volatile while bool true false NULL nullptr class namespace
+			template typename public private protected virtual override new delete this using
+			#include #define #ifdef #ifndef #endif #pragma`),
+		preprocessor: true,
+		lineComments: []string{"//"}, blockComments: cStyleComments, quotes: cStyleQuotes,
+	}
+	rust := &language{
+		keywords: words(`as async await break const continue crate dyn else enum extern false fn for if impl
+			in let loop match mod move mut pub ref return self Self static struct super trait true type
+			unsafe use where while i8 i16 i32 i64 i128 isize u8 u16 u32 u64 u128 usize f32 f64 bool char
+			str String Option Some None Result Ok Err`),
+		lineComments: []string{"//"}, blockComments: cStyleComments, quotes: []string{`"`},
+	}
+	sql := &language{
+		keywords: words(`select from where and or not in is null like between join inner left right outer
+			full on as group by order having limit offset insert into values update set delete create
+			alter drop table index view primary key foreign references unique default distinct union
+			all case when then else end exists with returning asc desc count sum avg min max`),
+		caseless:     true,
+		lineComments: []string{"--"}, blockComments: cStyleComments, quotes: []string{`'`},
+	}
+	shell := &language{
+		keywords: words(`if then else elif fi for while until do done case esac in function return local
+			export echo exit set unset readonly shift source`),
+		lineComments: []string{"#"}, quotes: []string{`"`}, rawQuotes: []string{`'`},
+	}
+
+	return map[string]*language{
+		"go": golang, "golang": golang,
+		"python": python, "py": python,
+		"javascript": javascript, "js": javascript, "typescript": javascript, "ts": javascript,
+		"java": java, "kotlin": java,
+		"c": c, "cpp": c, "c++": c, "h": c,
+		"rust": rust, "rs": rust,
+		"sql": sql, "postgresql": sql,
+		"bash": shell, "sh": shell, "shell": shell, "zsh": shell,
+	}
+}()
+
+// highlightCode renders a fenced code block, marking the keywords, strings, comments and
+// numbers of code in a known language with hl- classes. Code in other languages is only
+// escaped.
+func highlightCode(code, lang string) string {
+	var b strings.Builder
+	b.WriteString("<pre><code")
+	if name := languageClassName(lang); name != "" {
+		b.WriteString(` class="language-` + name + `"`)
+	}
+	b.WriteString(">")
+
+	if l, ok := languages[strings.ToLower(lang)]; ok {
+		l.highlight(&b, code)
+	} else {
+		b.WriteString(html.EscapeString(code))
+	}
+
+	b.WriteString("</code></pre>\n")
+	return b.String()
+}
+
+// languageClassName keeps the characters of a language name that are safe in a class
+func languageClassName(lang string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("+#-_", r) {
+			return r
+		}
+		return -1
+	}, lang)
+}
+
+// highlight writes code with its tokens wrapped in spans
+func (l *language) highlight(b *strings.Builder, code string) {
+	span := func(class, text string) {
+		b.WriteString(`<span class="` + class + `">` + html.EscapeString(text) + `</span>`)
+	}
+
+	for i := 0; i < len(code); {
+		rest := code[i:]
+
+		if end := l.comment(rest); end > 0 {
+			span(classComment, rest[:end])
+			i += end
+			continue
+		}
+		if end := l.str(rest); end > 0 {
+			span(classString, rest[:end])
+			i += end
+			continue
+		}
+
+		ch := code[i]
+		prevIdent := i > 0 && isIdentByte(code[i-1])
+		switch {
+		case isDigit(ch) && !prevIdent:
+			end := 1
+			for end < len(rest) && (isIdentByte(rest[end]) || rest[end] == '.' && end+1 < len(rest) && isDigit(rest[end+1])) {
+				end++
+			}
+			span(classNumber, rest[:end])
+			i += end
+		case isIdentByte(ch) || ch == '#' && l.preprocessor:
+			end := 1
+			for end < len(rest) && isIdentByte(rest[end]) {
+				end++
+			}
+			word := rest[:end]
+			if l.isKeyword(word) {
+				span(classKeyword, word)
+			} else {
+				b.WriteString(html.EscapeString(word))
+			}
+			i += end
+		default:
+			b.WriteString(html.EscapeString(rest[:1]))
+			i++
+		}
+	}
+}
+
+// comment returns the length of the comment code starts with, or 0
+func (l *language) comment(code string) int {
+	for _, start := range l.lineComments {
+		if strings.HasPrefix(code, start) {
+			if end := strings.IndexByte(code, '\n'); end >= 0 {
+				return end
+			}
+			return len(code)
+		}
+	}
+	for _, delims := range l.blockComments {
+		if strings.HasPrefix(code, delims[0]) {
+			if end := strings.Index(code[len(delims[0]):], delims[1]); end >= 0 {
+				return len(delims[0]) + end + len(delims[1])
+			}
+			return len(code)
+		}
+	}
+	return 0
+}
+
+// str returns the length of the string literal code starts with, or 0. Unterminated
+// strings end with the line, or with the code for raw strings.
+func (l *language) str(code string) int {
+	for _, quote := range l.rawQuotes {
+		if strings.HasPrefix(code, quote) {
+			if end := strings.Index(code[len(quote):], quote); end >= 0 {
+				return len(quote) + end + len(quote)
+			}
+			return len(code)
+		}
+	}
+	for _, quote := range l.quotes {
+		if !strings.HasPrefix(code, quote) {
+			continue
+		}
+		for i := len(quote); i < len(code); i++ {
+			switch {
+			case code[i] == '\\':
+				i++
+			case code[i] == '\n':
+				return i
+			case strings.HasPrefix(code[i:], quote):
+				return i + len(quote)
+			}
+		}
+		return len(code)
+	}
+	return 0
+}
+
+func (l *language) isKeyword(word string) bool {
+	if l.caseless {
+		word = strings.ToLower(word)
+	}
+	return l.keywords[word]
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isIdentByte(ch byte) bool {
+	return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || isDigit(ch)
+}
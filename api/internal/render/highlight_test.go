@@ -0,0 +1,57 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlightCode(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		lang string
+		want string
+	}{
+		{
+			name: "go",
+			code: "return `raw\\` + \"a\\\"b\" // done\n",
+			lang: "go",
+			want: `<pre><code class="language-go"><span class="hl-keyword">return</span> <span class="hl-string">` + "`raw\\`" + `</span> + ` +
+				`<span class="hl-string">&#34;a\&#34;b&#34;</span> <span class="hl-comment">// done</span>` + "\n</code></pre>\n",
+		},
+		{
+			name: "python",
+			code: "def f(x):\n    '''doc\n    string'''\n    return x + 1.5  # add",
+			lang: "Python",
+			want: `<pre><code class="language-Python"><span class="hl-keyword">def</span> f(x):` + "\n    " +
+				`<span class="hl-string">&#39;&#39;&#39;doc` + "\n    " + `string&#39;&#39;&#39;</span>` + "\n    " +
+				`<span class="hl-keyword">return</span> x + <span class="hl-number">1.5</span>  <span class="hl-comment"># add</span></code></pre>` + "\n",
+		},
+		{
+			name: "sql keywords in any case",
+			code: "SELECT id from cards -- all\nWHERE x1 = 'y'",
+			lang: "sql",
+			want: `<pre><code class="language-sql"><span class="hl-keyword">SELECT</span> id <span class="hl-keyword">from</span> cards ` +
+				`<span class="hl-comment">-- all</span>` + "\n" + `<span class="hl-keyword">WHERE</span> x1 = <span class="hl-string">&#39;y&#39;</span></code></pre>` + "\n",
+		},
+		{
+			name: "c preprocessor",
+			code: "#include <stdio.h>",
+			lang: "c",
+			want: `<pre><code class="language-c"><span class="hl-keyword">#include</span> &lt;stdio.h&gt;</code></pre>` + "\n",
+		},
+		{
+			name: "unknown language is escaped",
+			code: "<if>",
+			lang: `brainf"ck`,
+			want: `<pre><code class="language-brainfck">&lt;if&gt;</code></pre>` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, highlightCode(tt.code, tt.lang))
+		})
+	}
+}
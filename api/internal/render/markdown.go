@@ -0,0 +1,878 @@
+package render
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	atxHeading     = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))??(?:[ \t]+#+)?[ \t]*$`)
+	setextUnder    = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	thematicBreak  = regexp.MustCompile(`^ {0,3}([-*_])(?:[ \t]*[-*_]){2,}[ \t]*$`)
+	fenceOpen      = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \\t]*([^`]*?)[ \\t]*$")
+	blockquoteLine = regexp.MustCompile(`^ {0,3}> ?`)
+	listItem       = regexp.MustCompile(`^( {0,3})([-+*]|\d{1,9}[.)])(?:([ \t]+)(.*))?$`)
+	tableDelimiter = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	htmlBlockStart = regexp.MustCompile(`^ {0,3}(?:<!--|</?(?i:address|article|aside|blockquote|details|div|dl|fieldset|figcaption|figure|footer|h[1-6]|header|hr|li|ol|p|pre|section|summary|svg|table|tbody|td|tfoot|th|thead|tr|ul)(?:[\s/>]|$))`)
+	mathBlockOpen  = regexp.MustCompile(`^ {0,3}(\$\$|\\\[)`)
+
+	autolink  = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>]*)>`)
+	inlineTag = regexp.MustCompile("^(?:<!--[\\s\\S]*?-->|</?[A-Za-z][A-Za-z0-9-]*(?:\\s+[a-zA-Z_:][a-zA-Z0-9_.:-]*(?:\\s*=\\s*(?:[^\\s\"'=<>`]+|'[^']*'|\"[^\"]*\"))?)*\\s*/?>)")
+	entity    = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+)
+
+// markdownToHTML renders Markdown to HTML. It supports the CommonMark blocks cards are
+// written with, namely paragraphs, headings, fenced code, block quotes, lists and
+// thematic breaks, plus GitHub tables and strikethrough. Raw HTML passes through for
+// Sanitize to filter. Math is kept as text, see renderMath.
+func markdownToHTML(source string) string {
+	source = strings.ReplaceAll(strings.ReplaceAll(source, "\r\n", "\n"), "\r", "\n")
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(source, "\n"), false)
+	return b.String()
+}
+
+// renderBlocks renders lines as a sequence of blocks. The paragraphs of tight list items
+// are rendered without <p>.
+func renderBlocks(b *strings.Builder, lines []string, tight bool) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlank(line) {
+			i++
+			continue
+		}
+
+		switch {
+		case fenceOpen.MatchString(line):
+			i = renderFence(b, lines, i)
+		case mathBlockOpen.MatchString(line):
+			i = renderMathBlock(b, lines, i)
+		case atxHeading.MatchString(line):
+			match := atxHeading.FindStringSubmatch(line)
+			level := strconv.Itoa(len(match[1]))
+			b.WriteString("<h" + level + ">" + renderInline(match[2]) + "</h" + level + ">\n")
+			i++
+		case thematicBreak.MatchString(line):
+			b.WriteString("<hr>\n")
+			i++
+		case blockquoteLine.MatchString(line):
+			i = renderBlockquote(b, lines, i)
+		case listItem.MatchString(line):
+			i = renderList(b, lines, i)
+		case i+1 < len(lines) && strings.Contains(line, "|") && tableDelimiter.MatchString(lines[i+1]) &&
+			len(splitTableRow(line)) == len(splitTableRow(lines[i+1])):
+			i = renderTable(b, lines, i)
+		case htmlBlockStart.MatchString(line):
+			for ; i < len(lines) && !isBlank(lines[i]); i++ {
+				b.WriteString(lines[i] + "\n")
+			}
+		default:
+			i = renderParagraph(b, lines, i, tight)
+		}
+	}
+}
+
+// renderFence renders the fenced code block starting at lines[start] and returns the
+// index of the line after it. An unclosed fence runs to the end.
+func renderFence(b *strings.Builder, lines []string, start int) int {
+	match := fenceOpen.FindStringSubmatch(lines[start])
+	indent, fence := len(match[1]), match[2]
+	lang, _, _ := strings.Cut(html.UnescapeString(match[3]), " ")
+
+	var code []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimLeft(lines[i], " ")
+		if len(lines[i])-len(trimmed) <= 3 && strings.HasPrefix(trimmed, fence) &&
+			strings.Trim(trimmed, fence[:1]+" \t") == "" {
+			i++
+			break
+		}
+		code = append(code, trimLeadingSpaces(lines[i], indent))
+	}
+
+	content := strings.Join(code, "\n")
+	if len(code) > 0 {
+		content += "\n"
+	}
+	b.WriteString(highlightCode(content, lang))
+	return i
+}
+
+// renderMathBlock renders display math starting on its own line with $$ or \[ and
+// returns the index of the line after it. Its content is kept away from Markdown, so
+// blank lines, underscores and asterisks survive.
+func renderMathBlock(b *strings.Builder, lines []string, start int) int {
+	open := mathBlockOpen.FindStringSubmatch(lines[start])[1]
+	closing := "$$"
+	if open == `\[` {
+		closing = `\]`
+	}
+
+	rest := strings.TrimSpace(lines[start])[len(open):]
+	var body []string
+	i := start
+	for {
+		if end := strings.Index(rest, closing); end >= 0 {
+			body = append(body, rest[:end])
+			// Text after the closing delimiter is not part of a math block
+			if strings.TrimSpace(rest[end+len(closing):]) != "" {
+				return renderParagraph(b, lines, start, false)
+			}
+			break
+		}
+		body = append(body, rest)
+		i++
+		if i == len(lines) {
+			// Unclosed, so not math after all
+			return renderParagraph(b, lines, start, false)
+		}
+		rest = lines[i]
+	}
+
+	b.WriteString("<p>" + renderMath(strings.TrimSpace(strings.Join(body, "\n")), true) + "</p>\n")
+	return i + 1
+}
+
+// renderBlockquote renders the block quote starting at lines[start] and returns the
+// index of the line after it
+func renderBlockquote(b *strings.Builder, lines []string, start int) int {
+	var quoted []string
+	i := start
+	for ; i < len(lines); i++ {
+		if prefix := blockquoteLine.FindString(lines[i]); prefix != "" {
+			quoted = append(quoted, lines[i][len(prefix):])
+			continue
+		}
+		// A paragraph in the quote continues on lines without >
+		if isBlank(lines[i]) || len(quoted) == 0 || isBlank(quoted[len(quoted)-1]) || startsBlock(lines[i]) {
+			break
+		}
+		quoted = append(quoted, lines[i])
+	}
+
+	b.WriteString("<blockquote>\n")
+	renderBlocks(b, quoted, false)
+	b.WriteString("</blockquote>\n")
+	return i
+}
+
+// listMarker describes the marker of a list item
+type listMarker struct {
+	ordered bool
+	char    byte // Bullet, or delimiter after the number
+	start   int
+	indent  int // Column the item content starts at
+	content string
+}
+
+// parseListItem parses a line starting a list item
+func parseListItem(line string) (listMarker, bool) {
+	match := listItem.FindStringSubmatch(line)
+	if match == nil {
+		return listMarker{}, false
+	}
+	marker := match[2]
+	item := listMarker{char: marker[len(marker)-1], content: match[4]}
+	if n, err := strconv.Atoi(marker[:len(marker)-1]); err == nil {
+		item.ordered, item.start = true, n
+	}
+
+	spaces := len(strings.ReplaceAll(match[3], "\t", "    "))
+	if spaces == 0 || spaces > 4 {
+		// Content of an empty item, or indented five or more spaces, starts one space after the marker
+		item.content = strings.Repeat(" ", max(spaces-1, 0)) + item.content
+		spaces = 1
+	}
+	item.indent = len(match[1]) + len(marker) + spaces
+	return item, true
+}
+
+// renderList renders the list starting at lines[start] and returns the index of the line
+// after it. A list is loose, with its items' paragraphs in <p>, when a blank line
+// separates its items or blocks within an item.
+func renderList(b *strings.Builder, lines []string, start int) int {
+	first, _ := parseListItem(lines[start])
+	var items [][]string
+	loose := false
+
+	i := start
+	for i < len(lines) {
+		item, ok := parseListItem(lines[i])
+		if !ok || item.ordered != first.ordered || item.char != first.char {
+			break
+		}
+
+		content := []string{item.content}
+		i++
+		blank := false
+		for ; i < len(lines); i++ {
+			line := lines[i]
+			switch {
+			case isBlank(line):
+				blank = true
+				content = append(content, "")
+				continue
+			case len(line)-len(strings.TrimLeft(line, " ")) >= item.indent:
+				if blank && len(content) > 1 {
+					loose = true
+				}
+				blank = false
+				content = append(content, line[item.indent:])
+				continue
+			case !blank && !startsBlock(line) && !listItem.MatchString(line):
+				// Lazy continuation of the item's paragraph
+				content = append(content, line)
+				continue
+			}
+			break
+		}
+
+		items = append(items, content)
+		if blank {
+			next, ok := parseListItem(lineAt(lines, i))
+			if !ok || next.ordered != first.ordered || next.char != first.char {
+				break
+			}
+			loose = true
+		}
+	}
+
+	tag := "ul"
+	if first.ordered {
+		tag = "ol"
+	}
+	b.WriteString("<" + tag)
+	if first.ordered && first.start != 1 {
+		b.WriteString(` start="` + strconv.Itoa(first.start) + `"`)
+	}
+	b.WriteString(">\n")
+	for _, content := range items {
+		b.WriteString("<li>")
+		var item strings.Builder
+		renderBlocks(&item, content, !loose)
+		b.WriteString(strings.TrimSuffix(item.String(), "\n"))
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// renderTable renders the table whose header row is lines[start] and returns the index
+// of the line after it
+func renderTable(b *strings.Builder, lines []string, start int) int {
+	header := splitTableRow(lines[start])
+	var aligns []string
+	for _, cell := range splitTableRow(lines[start+1]) {
+		left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":")
+		switch {
+		case left && right:
+			aligns = append(aligns, "center")
+		case right:
+			aligns = append(aligns, "right")
+		case left:
+			aligns = append(aligns, "left")
+		default:
+			aligns = append(aligns, "")
+		}
+	}
+
+	row := func(cells []string, tag string) {
+		b.WriteString("<tr>\n")
+		for j, align := range aligns {
+			b.WriteString("<" + tag)
+			if align != "" {
+				b.WriteString(` align="` + align + `"`)
+			}
+			b.WriteString(">")
+			if j < len(cells) {
+				b.WriteString(renderInline(cells[j]))
+			}
+			b.WriteString("</" + tag + ">\n")
+		}
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("<table>\n<thead>\n")
+	row(header, "th")
+	b.WriteString("</thead>\n")
+
+	i := start + 2
+	if i < len(lines) && !isBlank(lines[i]) && !startsBlock(lines[i]) {
+		b.WriteString("<tbody>\n")
+		for ; i < len(lines) && !isBlank(lines[i]) && !startsBlock(lines[i]); i++ {
+			row(splitTableRow(lines[i]), "td")
+		}
+		b.WriteString("</tbody>\n")
+	}
+	b.WriteString("</table>\n")
+	return i
+}
+
+// splitTableRow splits a table row into its trimmed cells. Escaped pipes and pipes in
+// code spans do not separate cells.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '`':
+			inCode = !inCode
+			cell.WriteByte('`')
+		case line[i] == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// renderParagraph renders the paragraph starting at lines[start], or a setext heading
+// when it is underlined, and returns the index of the line after it
+func renderParagraph(b *strings.Builder, lines []string, start int, tight bool) int {
+	text := []string{strings.TrimLeft(lines[start], " \t")}
+	i := start + 1
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if match := setextUnder.FindStringSubmatch(line); match != nil {
+			level := "2"
+			if match[1][0] == '=' {
+				level = "1"
+			}
+			b.WriteString("<h" + level + ">" + renderInline(strings.Join(text, "\n")) + "</h" + level + ">\n")
+			return i + 1
+		}
+		if isBlank(line) || startsBlock(line) {
+			break
+		}
+		text = append(text, strings.TrimLeft(line, " \t"))
+	}
+
+	content := renderInline(strings.TrimRight(strings.Join(text, "\n"), " \t"))
+	if tight {
+		b.WriteString(content + "\n")
+	} else {
+		b.WriteString("<p>" + content + "</p>\n")
+	}
+	return i
+}
+
+// startsBlock reports whether a line starts a block that interrupts a paragraph
+func startsBlock(line string) bool {
+	if fenceOpen.MatchString(line) || atxHeading.MatchString(line) || thematicBreak.MatchString(line) ||
+		blockquoteLine.MatchString(line) || htmlBlockStart.MatchString(line) {
+		return true
+	}
+	// Only lists starting with content and, when numbered, at 1 interrupt a paragraph
+	item, ok := parseListItem(line)
+	return ok && strings.TrimSpace(item.content) != "" && (!item.ordered || item.start == 1)
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func lineAt(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
+
+// trimLeadingSpaces removes up to n leading spaces from a line
+func trimLeadingSpaces(line string, n int) string {
+	for n > 0 && strings.HasPrefix(line, " ") {
+		line = line[1:]
+		n--
+	}
+	return line
+}
+
+// maxInlineDepth bounds how deeply emphasis and links nest. Each level renders its
+// content again, so the time taken grows with the depth; delimiters deeper than this
+// are written as text.
+const maxInlineDepth = 16
+
+// renderInline renders the inline content of a block: emphasis, code spans, math,
+// links, images, autolinks, raw HTML and line breaks
+func renderInline(text string) string {
+	return renderNestedInline(text, 0)
+}
+
+// renderNestedInline renders inline content nested depth levels deep in emphasis or links
+func renderNestedInline(text string, depth int) string {
+	var b strings.Builder
+	closers := make(closerCache)
+	for i := 0; i < len(text); {
+		i = renderInlineAt(&b, text, i, depth, closers)
+	}
+	return b.String()
+}
+
+// renderInlineAt renders the inline element starting at text[i] and returns the index
+// after it. closers holds the closing delimiters already searched for in text.
+func renderInlineAt(b *strings.Builder, text string, i, depth int, closers closerCache) int {
+	rest := text[i:]
+	switch c := text[i]; c {
+	case '\\':
+		if math, end := mathSpan(text, i); end > 0 {
+			b.WriteString(math)
+			return end
+		}
+		if len(rest) > 1 && rest[1] == '\n' {
+			b.WriteString("<br>\n")
+			return i + 2
+		}
+		if len(rest) > 1 && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", rest[1]) >= 0 {
+			b.WriteString(html.EscapeString(rest[1:2]))
+			return i + 2
+		}
+		b.WriteByte('\\')
+		return i + 1
+
+	case '`':
+		if code, end := codeSpan(text, i); end > 0 {
+			b.WriteString("<code>" + html.EscapeString(code) + "</code>")
+			return end
+		}
+		n := runLength(text, i, '`')
+		b.WriteString(rest[:n])
+		return i + n
+
+	case '$':
+		if math, end := mathSpan(text, i); end > 0 {
+			b.WriteString(math)
+			return end
+		}
+		b.WriteByte('$')
+		return i + 1
+
+	case '<':
+		if match := autolink.FindStringSubmatch(rest); match != nil {
+			url := html.EscapeString(match[1])
+			b.WriteString(`<a href="` + url + `">` + url + `</a>`)
+			return i + len(match[0])
+		}
+		if tag := inlineTag.FindString(rest); tag != "" {
+			b.WriteString(tag)
+			return i + len(tag)
+		}
+		b.WriteString("&lt;")
+		return i + 1
+
+	case '!', '[':
+		if end := renderLink(b, text, i, depth); end > 0 {
+			return end
+		}
+		b.WriteByte(c)
+		return i + 1
+
+	case '*', '_', '~':
+		return renderEmphasis(b, text, i, depth, closers)
+
+	case ' ':
+		n := runLength(text, i, ' ')
+		switch {
+		case i+n < len(text) && text[i+n] == '\n' && n >= 2:
+			b.WriteString("<br>\n")
+			return i + n + 1
+		case i+n < len(text) && text[i+n] == '\n':
+			// Spaces at the end of a line are dropped
+		default:
+			b.WriteString(rest[:n])
+		}
+		return i + n
+
+	case '&':
+		if ref := entity.FindString(rest); ref != "" {
+			b.WriteString(ref)
+			return i + len(ref)
+		}
+		b.WriteString("&amp;")
+		return i + 1
+
+	default:
+		b.WriteString(html.EscapeString(rest[:1]))
+		return i + 1
+	}
+}
+
+// runLength returns how many times c repeats from text[i]
+func runLength(text string, i int, c byte) int {
+	n := 0
+	for i+n < len(text) && text[i+n] == c {
+		n++
+	}
+	return n
+}
+
+// codeSpan returns the content of the code span starting at text[i] and the index after
+// it, or 0 when the backticks are not closed
+func codeSpan(text string, i int) (string, int) {
+	n := runLength(text, i, '`')
+	for j := i + n; j < len(text); {
+		if text[j] != '`' {
+			j++
+			continue
+		}
+		m := runLength(text, j, '`')
+		if m == n {
+			code := strings.ReplaceAll(text[i+n:j], "\n", " ")
+			if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			return code, j + m
+		}
+		j += m
+	}
+	return "", 0
+}
+
+// mathSpan renders the math starting at text[i], delimited by \( \), \[ \], $ or $$,
+// and returns the index after it, or 0 when there is none. A single $ opens math only
+// when followed by a non-space and closes it only after a non-space and before a
+// non-digit, so prices such as $5 and $10 stay text.
+func mathSpan(text string, i int) (string, int) {
+	rest := text[i:]
+	delimited := func(open, close string, display bool) (string, int) {
+		if !strings.HasPrefix(rest, open) {
+			return "", 0
+		}
+		end := strings.Index(rest[len(open):], close)
+		if end <= 0 {
+			return "", 0
+		}
+		return renderMath(rest[len(open):len(open)+end], display), i + len(open) + end + len(close)
+	}
+
+	for _, d := range []struct {
+		open, close string
+		display     bool
+	}{{`\(`, `\)`, false}, {`\[`, `\]`, true}, {"$$", "$$", true}} {
+		if math, end := delimited(d.open, d.close, d.display); end > 0 {
+			return math, end
+		}
+	}
+
+	if len(rest) < 3 || rest[0] != '$' || rest[1] == '$' || rest[1] == ' ' || rest[1] == '\n' {
+		return "", 0
+	}
+	for j := 2; j < len(rest); j++ {
+		switch rest[j] {
+		case '\\':
+			j++
+		case '$':
+			if rest[j-1] == ' ' || rest[j-1] == '\n' || j+1 < len(rest) && isDigit(rest[j+1]) {
+				continue
+			}
+			return renderMath(rest[1:j], false), i + j + 1
+		}
+	}
+	return "", 0
+}
+
+// renderMath keeps math as escaped text between \( \) or \[ \] delimiters in a span
+// classed math inline or math display, for clients to typeset with KaTeX or MathJax
+func renderMath(tex string, display bool) string {
+	if display {
+		return `<span class="math display">\[` + html.EscapeString(tex) + `\]</span>`
+	}
+	return `<span class="math inline">\(` + html.EscapeString(tex) + `\)</span>`
+}
+
+// renderLink renders the link or image starting at text[i] and returns the index after
+// it, or 0 when it is not one
+func renderLink(b *strings.Builder, text string, i, depth int) int {
+	if depth >= maxInlineDepth {
+		return 0
+	}
+	image := text[i] == '!'
+	start := i
+	if image {
+		if i+1 >= len(text) || text[i+1] != '[' {
+			return 0
+		}
+		start++
+	}
+
+	labelEnd := closingBracket(text, start)
+	if labelEnd < 0 || labelEnd+1 >= len(text) || text[labelEnd+1] != '(' {
+		return 0
+	}
+	dest, title, end := linkDestination(text, labelEnd+2)
+	if end == 0 {
+		return 0
+	}
+	label := text[start+1 : labelEnd]
+
+	if image {
+		b.WriteString(`<img src="` + html.EscapeString(dest) + `" alt="` + html.EscapeString(plainText(label)) + `"`)
+		if title != "" {
+			b.WriteString(` title="` + html.EscapeString(title) + `"`)
+		}
+		b.WriteString(">")
+		return end
+	}
+
+	b.WriteString(`<a href="` + html.EscapeString(dest) + `"`)
+	if title != "" {
+		b.WriteString(` title="` + html.EscapeString(title) + `"`)
+	}
+	b.WriteString(">" + renderNestedInline(label, depth+1) + "</a>")
+	return end
+}
+
+// closingBracket returns the index of the ] closing the [ at text[i], or -1
+func closingBracket(text string, i int) int {
+	depth := 0
+	for j := i; j < len(text); j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '`':
+			if _, end := codeSpan(text, j); end > 0 {
+				j = end - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// linkDestination parses the destination and optional title of a link starting at
+// text[i], just after its opening parenthesis, and returns the index after the closing
+// parenthesis, or 0 when it is malformed
+func linkDestination(text string, i int) (dest, title string, end int) {
+	skipSpaces := func() {
+		for i < len(text) && (text[i] == ' ' || text[i] == '\n') {
+			i++
+		}
+	}
+
+	skipSpaces()
+	if i < len(text) && text[i] == '<' {
+		close := strings.IndexAny(text[i:], ">\n")
+		if close < 0 || text[i+close] != '>' {
+			return "", "", 0
+		}
+		dest = text[i+1 : i+close]
+		i += close + 1
+	} else {
+		depth, from := 0, i
+		for ; i < len(text) && text[i] > ' '; i++ {
+			if text[i] == '\\' {
+				i++
+			} else if text[i] == '(' {
+				depth++
+			} else if text[i] == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+		dest = text[from:min(i, len(text))]
+	}
+
+	skipSpaces()
+	if i < len(text) && strings.IndexByte(`"'(`, text[i]) >= 0 {
+		closing := text[i]
+		if closing == '(' {
+			closing = ')'
+		}
+		close := strings.IndexByte(text[i+1:], closing)
+		if close < 0 {
+			return "", "", 0
+		}
+		title = text[i+1 : i+1+close]
+		i += close + 2
+		skipSpaces()
+	}
+
+	if i >= len(text) || text[i] != ')' {
+		return "", "", 0
+	}
+	return unescapeBackslashes(dest), unescapeBackslashes(title), i + 1
+}
+
+// unescapeBackslashes removes the backslashes escaping punctuation
+func unescapeBackslashes(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return html.UnescapeString(b.String())
+}
+
+// plainText strips the markup characters from the label of an image for its alt text
+func plainText(label string) string {
+	return strings.NewReplacer("*", "", "_", "", "`", "", "[", "", "]", "", `\`, "").Replace(label)
+}
+
+// renderEmphasis renders the emphasis, strong emphasis or strikethrough opened by the
+// delimiter run at text[i] and returns the index after it. A run that opens nothing is
+// written as text.
+func renderEmphasis(b *strings.Builder, text string, i, depth int, closers closerCache) int {
+	c := text[i]
+	n := runLength(text, i, c)
+	if depth >= maxInlineDepth {
+		b.WriteString(text[i : i+n])
+		return i + n
+	}
+
+	if c == '~' {
+		if n == 2 && leftFlanking(text, i, n) {
+			if close := closers.closingDelimiter(text, i+n, c, 2); close > 0 {
+				b.WriteString("<del>" + renderNestedInline(text[i+2:close], depth+1) + "</del>")
+				return close + 2
+			}
+		}
+		b.WriteString(text[i : i+n])
+		return i + n
+	}
+
+	if leftFlanking(text, i, n) {
+		for _, size := range []int{2, 1} {
+			if size > n {
+				continue
+			}
+			// The outer delimiters of a longer run open first, so ***x*** nests <em> in <strong>
+			if close := closers.closingDelimiter(text, i+n, c, size); close > 0 {
+				tag := "em"
+				if size == 2 {
+					tag = "strong"
+				}
+				b.WriteString("<" + tag + ">" + renderNestedInline(text[i+size:close], depth+1) + "</" + tag + ">")
+				return close + size
+			}
+		}
+	}
+
+	b.WriteString(text[i : i+n])
+	return i + n
+}
+
+// closerKey identifies the searches for delimiters of one character closing emphasis
+// of one size
+type closerKey struct {
+	c    byte
+	size int
+}
+
+// closerCache holds, for one inline text, where the emphasis opened by each delimiter
+// run closes. Each table is filled from the end of the text in a single pass: finding
+// a closer means skipping the emphasis nested inside, which the runs further on already
+// know the closers of. Searching from every run instead takes exponential time on text
+// full of runs that never close.
+type closerCache map[closerKey][]int
+
+// closingDelimiter returns the index of the delimiters closing emphasis of the given
+// size opened by the delimiter run ending at text[from], or 0. Nested emphasis opened
+// with the same character is skipped, as are code spans and escaped characters. The
+// cache must only be used with the same text.
+func (closers closerCache) closingDelimiter(text string, from int, c byte, size int) int {
+	key := closerKey{c: c, size: size}
+	table, ok := closers[key]
+	if !ok {
+		table = closers.closerTable(text, c, size)
+		closers[key] = table
+	}
+	return table[from]
+}
+
+// closerTable returns, for every index just after a run of c, the index of the
+// delimiters closing emphasis of the given size opened by that run, or 0
+func (closers closerCache) closerTable(text string, c byte, size int) []int {
+	table := make([]int, len(text)+1)
+	nested := table
+	if size != 1 {
+		nested = closers.closerTable(text, c, 1)
+		closers[closerKey{c: c, size: 1}] = nested
+	}
+
+	for from := len(text); from > 0; from-- {
+		if text[from-1] != c || (from < len(text) && text[from] == c) {
+			continue
+		}
+		table[from] = nextCloser(text, from, c, size, table, nested)
+	}
+	return table
+}
+
+// nextCloser looks for the closing delimiters from the run ending at text[from] to the
+// next run of c, deferring to table and nested for what lies beyond it. Both hold the
+// closers of the runs ending after from.
+func nextCloser(text string, from int, c byte, size int, table, nested []int) int {
+	for j := from; j < len(text); {
+		switch text[j] {
+		case '\\':
+			j += 2
+			continue
+		case '`':
+			if _, end := codeSpan(text, j); end > 0 {
+				j = end
+				continue
+			}
+		case c:
+			m := runLength(text, j, c)
+			left, right := leftFlanking(text, j, m), rightFlanking(text, j, m)
+			if right && m >= size {
+				return j + m - size
+			}
+			if left && !right {
+				// Skip emphasis nested inside
+				if close := nested[j+m]; close > 0 {
+					return table[close+runLength(text, close, c)]
+				}
+			}
+			return table[j+m]
+		}
+		j++
+	}
+	return 0
+}
+
+// leftFlanking reports whether the delimiter run of length n at text[i] can open
+// emphasis: it is followed by a non-space and, for underscores, not inside a word
+func leftFlanking(text string, i, n int) bool {
+	if i+n >= len(text) || isSpace(text[i+n]) {
+		return false
+	}
+	return text[i] != '_' || i == 0 || !isIdentByte(text[i-1])
+}
+
+// rightFlanking reports whether the delimiter run of length n at text[i] can close
+// emphasis: it follows a non-space and, for underscores, is not inside a word
+func rightFlanking(text string, i, n int) bool {
+	if i == 0 || isSpace(text[i-1]) {
+		return false
+	}
+	return text[i] != '_' || i+n >= len(text) || !isIdentByte(text[i+n])
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
@@ -0,0 +1,155 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "heading and emphasis",
+			markdown: "# Krebs cycle\n\nTakes *place* in the **mitochondria**, ***always***, ~~never~~ `elsewhere`.",
+			want: "<h1>Krebs cycle</h1>\n" +
+				"<p>Takes <em>place</em> in the <strong>mitochondria</strong>, <strong><em>always</em></strong>, <del>never</del> <code>elsewhere</code>.</p>\n",
+		},
+		{
+			name:     "nested emphasis",
+			markdown: "*a **b** c* and snake_case_name",
+			want:     "<p><em>a <strong>b</strong> c</em> and snake_case_name</p>\n",
+		},
+		{
+			name:     "setext headings",
+			markdown: "Title\n===\n\nSubtitle\n---",
+			want:     "<h1>Title</h1>\n<h2>Subtitle</h2>\n",
+		},
+		{
+			name:     "line breaks",
+			markdown: "first  \nsecond\\\nthird\nfourth",
+			want:     "<p>first<br>\nsecond<br>\nthird\nfourth</p>\n",
+		},
+		{
+			name:     "tight lists",
+			markdown: "- one\n- two\n  - nested\n\n3. three\n4. four",
+			want:     "<ul>\n<li>one</li>\n<li>two\n<ul>\n<li>nested</li>\n</ul></li>\n</ul>\n<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>\n",
+		},
+		{
+			name:     "loose list",
+			markdown: "* one\n\n* two",
+			want:     "<ul>\n<li><p>one</p></li>\n<li><p>two</p></li>\n</ul>\n",
+		},
+		{
+			name:     "block quote with lazy continuation",
+			markdown: "> quoted\ncontinued\n\n---",
+			want:     "<blockquote>\n<p>quoted\ncontinued</p>\n</blockquote>\n<hr>\n",
+		},
+		{
+			name:     "table",
+			markdown: "| Term | Value |\n|:-----|------:|\n| `a\\|b` | 1 |",
+			want: "<table>\n<thead>\n<tr>\n<th align=\"left\">Term</th>\n<th align=\"right\">Value</th>\n</tr>\n</thead>\n" +
+				"<tbody>\n<tr>\n<td align=\"left\"><code>a|b</code></td>\n<td align=\"right\">1</td>\n</tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name:     "links and images",
+			markdown: `[docs](https://go.dev "Go") ![a *diagram*](media:abc) <https://example.com>`,
+			want: `<p><a href="https://go.dev" title="Go">docs</a> <img src="media:abc" alt="a diagram"> ` +
+				`<a href="https://example.com">https://example.com</a></p>` + "\n",
+		},
+		{
+			name:     "raw html passes through",
+			markdown: "<div>\n*kept*\n</div>\n\nText with <kbd>Ctrl</kbd> &amp; 1 < 2",
+			want:     "<div>\n*kept*\n</div>\n<p>Text with <kbd>Ctrl</kbd> &amp; 1 &lt; 2</p>\n",
+		},
+		{
+			name:     "escapes",
+			markdown: `\*not emphasis\* and \# not a heading`,
+			want:     "<p>*not emphasis* and # not a heading</p>\n",
+		},
+		{
+			name:     "unknown fenced code is escaped",
+			markdown: "~~~\n<b>*not markdown*</b>\n~~~",
+			want:     "<pre><code>&lt;b&gt;*not markdown*&lt;/b&gt;\n</code></pre>\n",
+		},
+		{
+			name:     "unclosed fence runs to the end",
+			markdown: "```python\nx = 1",
+			want:     "<pre><code class=\"language-python\">x = <span class=\"hl-number\">1</span>\n</code></pre>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, markdownToHTML(tt.markdown))
+		})
+	}
+}
+
+func TestMarkdownToHTML_Math(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "dollar inline math",
+			markdown: "Euler: $e^{i\\pi} + 1 = 0$",
+			want:     `<p>Euler: <span class="math inline">\(e^{i\pi} + 1 = 0\)</span></p>` + "\n",
+		},
+		{
+			name:     "markdown characters in math are kept",
+			markdown: `\(a_1 * b_2 * c\) and $x_1 < y_1$`,
+			want: `<p><span class="math inline">\(a_1 * b_2 * c\)</span> and ` +
+				`<span class="math inline">\(x_1 &lt; y_1\)</span></p>` + "\n",
+		},
+		{
+			name:     "prices are not math",
+			markdown: "It costs $5 or $10, or \\$20.",
+			want:     "<p>It costs $5 or $10, or $20.</p>\n",
+		},
+		{
+			name:     "inline display math",
+			markdown: `The sum $$\sum_i x_i$$ and \[y\]`,
+			want: `<p>The sum <span class="math display">\[\sum_i x_i\]</span> and ` +
+				`<span class="math display">\[y\]</span></p>` + "\n",
+		},
+		{
+			name:     "display math block",
+			markdown: "$$\n\\begin{aligned}\na &= b_1 \\\\\n\nc &= *d*\n\\end{aligned}\n$$",
+			want: `<p><span class="math display">\[\begin{aligned}` + "\na &amp;= b_1 \\\\\n\nc &amp;= *d*\n" +
+				`\end{aligned}\]</span></p>` + "\n",
+		},
+		{
+			name:     "math in code is code",
+			markdown: "`$x$`",
+			want:     "<p><code>$x$</code></p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, markdownToHTML(tt.markdown))
+		})
+	}
+}
+
+func TestMarkdownToHTML_UnclosedEmphasisIsFast(t *testing.T) {
+	for _, markdown := range []string{
+		strings.Repeat("*a ", 1000),
+		strings.Repeat("_a ", 1000),
+		strings.Repeat("~~a ", 1000),
+		strings.Repeat("**a ", 1000),
+		strings.Repeat("*a ", 500) + strings.Repeat("a* ", 500),
+	} {
+		start := time.Now()
+		html := markdownToHTML(markdown)
+		assert.Less(t, time.Since(start), time.Second, "rendering %q...", markdown[:8])
+		assert.NotEmpty(t, html)
+	}
+}
@@ -0,0 +1,77 @@
+// Package render turns the front and back of cards into HTML that is safe to show
+// whoever wrote them, so shared decks cannot run scripts in their readers' browsers.
+package render
+
+import (
+	"html"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+
+	"swipelearn-api/internal/models"
+)
+
+// HTML renders card content written in a content format to sanitized HTML. Plain text is
+// escaped with its line breaks kept, Markdown is rendered with its fenced code
+// highlighted, and HTML is sanitized as is. Math is left to clients: its delimiters and
+// TeX are kept as text, in spans classed math inline or math display when written in
+// Markdown.
+func HTML(format, content string) string {
+	switch format {
+	case models.ContentFormatMarkdown:
+		return Sanitize(markdownToHTML(content))
+	case models.ContentFormatHTML:
+		return Sanitize(content)
+	default:
+		return plainToHTML(content)
+	}
+}
+
+// plainToHTML escapes plain text and turns its line breaks into <br>
+func plainToHTML(text string) string {
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>\n")
+}
+
+// blockElements start on a new line when HTML is turned into text
+var blockElements = map[string]bool{
+	"blockquote": true, "br": true, "dd": true, "div": true, "dl": true, "dt": true, "figcaption": true,
+	"figure": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "hr": true,
+	"li": true, "ol": true, "p": true, "pre": true, "table": true, "tr": true, "ul": true,
+}
+
+// Text returns the text an HTML fragment shows, with a line break wherever a block
+// starts, for comparing card content with what users type
+func Text(fragment string) string {
+	tokenizer := nethtml.NewTokenizer(strings.NewReader(fragment))
+	var b strings.Builder
+	dropping := ""
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == nethtml.ErrorToken {
+			break
+		}
+
+		if tokenType == nethtml.TextToken {
+			if dropping == "" {
+				b.Write(tokenizer.Text())
+			}
+			continue
+		}
+
+		name, _ := tokenizer.TagName()
+		tag := string(name)
+		switch {
+		case tokenType == nethtml.StartTagToken && dropping == "" && droppedElements[tag]:
+			dropping = tag
+		case tokenType == nethtml.EndTagToken && tag == dropping:
+			dropping = ""
+		}
+		if blockElements[tag] && !strings.HasSuffix(b.String(), "\n") {
+			b.WriteByte('\n')
+		}
+	}
+
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,56 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"swipelearn-api/internal/models"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		content string
+		want    string
+	}{
+		{
+			name:    "plain text is escaped",
+			format:  models.ContentFormatPlain,
+			content: "if a < b\r\nthen <b>c</b> $x$",
+			want:    "if a &lt; b<br>\nthen &lt;b&gt;c&lt;/b&gt; $x$",
+		},
+		{
+			name:    "unknown formats render as plain text",
+			format:  "",
+			content: "<i>x</i>",
+			want:    "&lt;i&gt;x&lt;/i&gt;",
+		},
+		{
+			name:    "markdown is rendered and sanitized",
+			format:  models.ContentFormatMarkdown,
+			content: "**$x^2$** [click](javascript:alert(1)) <img src=x onerror=alert(1)>",
+			want: `<p><strong><span class="math inline">\(x^2\)</span></strong> <a rel="nofollow noopener noreferrer">click</a> ` +
+				`<img src="x"></p>` + "\n",
+		},
+		{
+			name:    "html is sanitized with math left as text",
+			format:  models.ContentFormatHTML,
+			content: `<p onclick="x">\(a &lt; b\)</p><script>x</script>`,
+			want:    `<p>\(a &lt; b\)</p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, HTML(tt.format, tt.content))
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	fragment := `<p>The <strong>capital</strong> &amp; seat</p><ul><li>one</li><li>two</li></ul><script>x</script>`
+
+	assert.Equal(t, "The capital & seat\none\ntwo", Text(fragment))
+}
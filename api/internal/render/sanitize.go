@@ -0,0 +1,191 @@
+package render
+
+import (
+	"html"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// globalAttributes are allowed on every allowed element
+var globalAttributes = map[string]bool{"class": true, "title": true, "lang": true, "dir": true}
+
+// allowedElements lists the elements kept by Sanitize with the attributes they may carry
+// besides the global ones. The SVG elements are those image occlusion cards draw.
+var allowedElements = map[string]map[string]bool{
+	"a": {"href": true}, "abbr": nil, "b": nil, "blockquote": nil, "br": nil, "caption": nil,
+	"cite": nil, "code": nil, "col": {"span": true}, "colgroup": {"span": true}, "dd": nil,
+	"del": nil, "details": {"open": true}, "div": nil, "dl": nil, "dt": nil, "em": nil,
+	"figcaption": nil, "figure": nil, "h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil,
+	"h6": nil, "hr": nil, "i": nil, "img": {"src": true, "alt": true, "width": true, "height": true},
+	"ins": nil, "kbd": nil, "li": {"value": true}, "mark": nil, "ol": {"start": true, "reversed": true},
+	"p": nil, "pre": nil, "q": nil, "rp": nil, "rt": nil, "ruby": nil, "s": nil, "samp": nil,
+	"small": nil, "span": nil, "strong": nil, "sub": nil, "summary": nil, "sup": nil,
+	"table": nil, "tbody": nil, "td": {"colspan": true, "rowspan": true, "align": true},
+	"tfoot": nil, "th": {"colspan": true, "rowspan": true, "align": true, "scope": true},
+	"thead": nil, "tr": nil, "u": nil, "ul": nil, "var": nil, "wbr": nil,
+	"audio": {"src": true, "controls": true, "loop": true}, "source": {"src": true, "type": true},
+
+	"svg":     {"xmlns": true, "viewBox": true, "width": true, "height": true, "preserveAspectRatio": true},
+	"g":       {"fill": true, "stroke": true, "stroke-width": true},
+	"image":   {"href": true, "x": true, "y": true, "width": true, "height": true},
+	"rect":    {"x": true, "y": true, "width": true, "height": true, "rx": true, "ry": true, "fill": true, "stroke": true, "stroke-width": true},
+	"circle":  {"cx": true, "cy": true, "r": true, "fill": true, "stroke": true, "stroke-width": true},
+	"ellipse": {"cx": true, "cy": true, "rx": true, "ry": true, "fill": true, "stroke": true, "stroke-width": true},
+	"polygon": {"points": true, "fill": true, "stroke": true, "stroke-width": true},
+}
+
+// voidElements never have content or an end tag
+var voidElements = map[string]bool{"br": true, "col": true, "hr": true, "img": true, "source": true, "wbr": true}
+
+// droppedElements are removed together with their content
+var droppedElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true, "noscript": true,
+	"template": true, "textarea": true, "select": true, "title": true, "xmp": true, "noembed": true,
+	"noframes": true, "plaintext": true, "frameset": true, "math": true, "foreignobject": true,
+}
+
+// rawTextElements are read as raw text up to their end tag, even when written as
+// self-closing: <script/> still makes what follows a script
+var rawTextElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "noscript": true, "textarea": true, "title": true,
+	"xmp": true, "noembed": true, "noframes": true, "plaintext": true,
+}
+
+// urlAttributes hold URLs, which must be relative or use an allowed scheme
+var urlAttributes = map[string]bool{"href": true, "src": true}
+
+// allowedSchemes are the URL schemes links and sources may use. media: references an
+// uploaded media file.
+var allowedSchemes = map[string]bool{"http": true, "https": true, "mailto": true, "media": true}
+
+// svgAttributeNames restores the case of SVG attributes, which the tokenizer lowercases
+var svgAttributeNames = map[string]string{
+	"viewbox":             "viewBox",
+	"preserveaspectratio": "preserveAspectRatio",
+}
+
+// Sanitize keeps only allowlisted elements and attributes of an HTML fragment, so that it
+// cannot run scripts, load styles or embed other pages. Disallowed elements are removed
+// but keep their text, except for scripts and the like, whose content goes too. Links
+// and sources are limited to http, https, mailto and media URLs. The result is balanced:
+// every element it opens is closed.
+func Sanitize(fragment string) string {
+	tokenizer := nethtml.NewTokenizer(strings.NewReader(fragment))
+	var b strings.Builder
+	var open []string // Allowed elements opened and not closed yet
+	dropping := ""    // Element whose content is being removed
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == nethtml.ErrorToken {
+			// io.EOF, or a fragment too malformed to go on with
+			break
+		}
+
+		switch tokenType {
+		case nethtml.TextToken:
+			if dropping == "" {
+				b.WriteString(html.EscapeString(string(tokenizer.Text())))
+			}
+
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			tag := string(name)
+			if dropping != "" {
+				continue
+			}
+			if droppedElements[tag] {
+				if tokenType == nethtml.StartTagToken || rawTextElements[tag] {
+					dropping = tag
+				}
+				continue
+			}
+			attributes, allowed := allowedElements[tag]
+			if !allowed {
+				continue
+			}
+
+			b.WriteString("<" + tag)
+			for hasAttr {
+				var key, value []byte
+				key, value, hasAttr = tokenizer.TagAttr()
+				writeAttribute(&b, string(key), string(value), attributes)
+			}
+			if tag == "a" {
+				b.WriteString(` rel="nofollow noopener noreferrer"`)
+			}
+
+			switch {
+			case voidElements[tag]:
+				b.WriteString(">")
+			case tokenType == nethtml.SelfClosingTagToken:
+				// HTML ignores the slash of other elements, so <div/> would stay open
+				b.WriteString("></" + tag + ">")
+			default:
+				b.WriteString(">")
+				open = append(open, tag)
+			}
+
+		case nethtml.EndTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			if dropping != "" {
+				if tag == dropping {
+					dropping = ""
+				}
+				continue
+			}
+			// Close the element along with those opened inside it and left open
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == tag {
+					for j := len(open) - 1; j >= i; j-- {
+						b.WriteString("</" + open[j] + ">")
+					}
+					open = open[:i]
+					break
+				}
+			}
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+// writeAttribute writes an attribute of an allowed element if it is allowed too
+func writeAttribute(b *strings.Builder, key, value string, attributes map[string]bool) {
+	if name, ok := svgAttributeNames[key]; ok {
+		key = name
+	}
+	if !globalAttributes[key] && !attributes[key] {
+		return
+	}
+	if urlAttributes[key] && !safeURL(value) {
+		return
+	}
+	b.WriteString(" " + key + `="` + html.EscapeString(value) + `"`)
+}
+
+// safeURL reports whether a URL is relative or uses an allowed scheme
+func safeURL(rawURL string) bool {
+	// Browsers ignore whitespace and control characters in schemes, so java\tscript: is javascript:
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, rawURL)
+
+	colon := strings.IndexByte(cleaned, ':')
+	if colon < 0 {
+		return true
+	}
+	// A colon after a path, query or fragment does not end a scheme
+	if end := strings.IndexAny(cleaned, "/?#"); end >= 0 && end < colon {
+		return true
+	}
+	return allowedSchemes[strings.ToLower(cleaned[:colon])]
+}
@@ -0,0 +1,88 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "allowed markup is kept",
+			html: `<p class="note">A <strong>bold</strong> <a href="https://example.com" title="x">link</a><br/></p>`,
+			want: `<p class="note">A <strong>bold</strong> <a href="https://example.com" title="x" rel="nofollow noopener noreferrer">link</a><br></p>`,
+		},
+		{
+			name: "scripts and styles are removed with their content",
+			html: `before<script>alert(1)</script><style>body{display:none}</style><iframe src="https://evil.example">x</iframe>after`,
+			want: `beforeafter`,
+		},
+		{
+			name: "event handlers and styles are removed",
+			html: `<img src="media:abc" onerror="alert(1)" style="position:fixed"><b onclick="alert(1)">hi</b>`,
+			want: `<img src="media:abc"><b>hi</b>`,
+		},
+		{
+			name: "unsafe urls are removed",
+			html: `<a href="javascript:alert(1)">a</a><a href=" JaVa&#x09;Script:alert(1)">b</a><img src="data:image/png;base64,xx">`,
+			want: `<a rel="nofollow noopener noreferrer">a</a><a rel="nofollow noopener noreferrer">b</a><img>`,
+		},
+		{
+			name: "relative urls are kept",
+			html: `<a href="/decks?page=2:3">next</a>`,
+			want: `<a href="/decks?page=2:3" rel="nofollow noopener noreferrer">next</a>`,
+		},
+		{
+			name: "unknown elements keep their text",
+			html: `<form action="/x"><input value="y">Name: <custom-tag>Ada</custom-tag></form>`,
+			want: `Name: Ada`,
+		},
+		{
+			name: "text is escaped",
+			html: `1 &lt; 2 &amp; "quotes" <!-- comment -->`,
+			want: `1 &lt; 2 &amp; &#34;quotes&#34; `,
+		},
+		{
+			name: "unbalanced elements are closed",
+			html: `<ul><li><em>one</li></ul></div><p>two`,
+			want: `<ul><li><em>one</em></li></ul><p>two</p>`,
+		},
+		{
+			name: "image occlusion svg is kept",
+			html: `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 4 2" onload="alert(1)"><image href="media:abc" width="4" height="2"/>` +
+				`<polygon class="occlusion" points="0,0 1,0 1,1" fill="#ffeba2" stroke="#212121"/><script>alert(1)</script></svg>`,
+			want: `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 4 2"><image href="media:abc" width="4" height="2"></image>` +
+				`<polygon class="occlusion" points="0,0 1,0 1,1" fill="#ffeba2" stroke="#212121"></polygon></svg>`,
+		},
+		{
+			name: "self-closing elements are closed",
+			html: `<div/><a href="https://example.com"/>text<span/>`,
+			want: `<div></div><a href="https://example.com" rel="nofollow noopener noreferrer"></a>text<span></span>`,
+		},
+		{
+			name: "self-closing scripts and styles are removed with their content",
+			html: `before<script/>alert(1)</script><style/>body{display:none}</style><math/>after`,
+			want: `beforeafter`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Sanitize(tt.html))
+		})
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	for _, url := range []string{"https://example.com", "http://x", "mailto:a@b.c", "media:abc", "/path", "page#a:b", "image.png"} {
+		assert.True(t, safeURL(url), url)
+	}
+	for _, url := range []string{"javascript:alert(1)", "JAVASCRIPT:x", "java\nscript:x", "vbscript:x", "data:text/html,x", "file:///etc/passwd"} {
+		assert.False(t, safeURL(url), url)
+	}
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
@@ -14,6 +15,30 @@ type DeckRepository struct {
 	Logger *logrus.Logger
 }
 
+// deckColumns lists the selected deck columns in the order expected by scanDeck
+const deckColumns = `id, user_id, name, description, scheduler, preset_id, desired_retention, filter, created_at, updated_at`
+
+// scanDeck scans a row selected with deckColumns
+func scanDeck(row rowScanner) (*models.Deck, error) {
+	var deck models.Deck
+	err := row.Scan(
+		&deck.ID,
+		&deck.UserID,
+		&deck.Name,
+		&deck.Description,
+		&deck.Scheduler,
+		&deck.PresetID,
+		&deck.DesiredRetention,
+		&deck.Filter,
+		&deck.CreatedAt,
+		&deck.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &deck, nil
+}
+
 func NewDeckRepository(db *sql.DB, logger *logrus.Logger) *DeckRepository {
 	return &DeckRepository{
 		DB:     db,
@@ -24,52 +49,39 @@ func NewDeckRepository(db *sql.DB, logger *logrus.Logger) *DeckRepository {
 // Create creates a new deck
 func (r *DeckRepository) Create(deck *models.Deck) (*models.Deck, error) {
 	query := `
-		INSERT INTO decks (id, user_id, name, description)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, user_id, name, description, created_at, updated_at
-	`
+		INSERT INTO decks (id, user_id, name, description, scheduler, preset_id, desired_retention, filter)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ` + deckColumns
 
-	err := r.DB.QueryRow(
+	created, err := scanDeck(r.DB.QueryRow(
 		query,
 		deck.ID,
 		deck.UserID,
 		deck.Name,
 		deck.Description,
-	).Scan(
-		&deck.ID,
-		&deck.UserID,
-		&deck.Name,
-		&deck.Description,
-		&deck.CreatedAt,
-		&deck.UpdatedAt,
-	)
+		deck.Scheduler,
+		deck.PresetID,
+		deck.DesiredRetention,
+		deck.Filter,
+	))
 
 	if err != nil {
 		r.Logger.WithError(err).Error("Failed to create deck in database")
 		return nil, fmt.Errorf("failed to create deck: %w", err)
 	}
 
-	r.Logger.WithField("deck_id", deck.ID).Info("Deck created successfully")
-	return deck, nil
+	r.Logger.WithField("deck_id", created.ID).Info("Deck created successfully")
+	return created, nil
 }
 
 // GetByID retrieves a deck by ID
 func (r *DeckRepository) GetByID(id uuid.UUID) (*models.Deck, error) {
-	query := `
-		SELECT id, user_id, name, description, created_at, updated_at
+	query := `SELECT ` + deckColumns + `
 		FROM decks
 		WHERE id = $1
 	`
 
-	deck := &models.Deck{}
-	err := r.DB.QueryRow(query, id).Scan(
-		&deck.ID,
-		&deck.UserID,
-		&deck.Name,
-		&deck.Description,
-		&deck.CreatedAt,
-		&deck.UpdatedAt,
-	)
+	deck, err := scanDeck(r.DB.QueryRow(query, id))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -84,8 +96,7 @@ func (r *DeckRepository) GetByID(id uuid.UUID) (*models.Deck, error) {
 
 // GetAll retrieves all decks
 func (r *DeckRepository) GetAll() ([]*models.Deck, error) {
-	query := `
-		SELECT id, user_id, name, description, created_at, updated_at
+	query := `SELECT ` + deckColumns + `
 		FROM decks
 		ORDER BY created_at DESC
 	`
@@ -99,15 +110,7 @@ func (r *DeckRepository) GetAll() ([]*models.Deck, error) {
 
 	var decks []*models.Deck
 	for rows.Next() {
-		deck := &models.Deck{}
-		err := rows.Scan(
-			&deck.ID,
-			&deck.UserID,
-			&deck.Name,
-			&deck.Description,
-			&deck.CreatedAt,
-			&deck.UpdatedAt,
-		)
+		deck, err := scanDeck(rows)
 		if err != nil {
 			r.Logger.WithError(err).Error("Failed to scan deck row")
 			return nil, fmt.Errorf("failed to scan deck: %w", err)
@@ -123,7 +126,9 @@ func (r *DeckRepository) GetAll() ([]*models.Deck, error) {
 	return decks, nil
 }
 
-// Update updates a deck
+// Update updates a deck. A new scheduler also resets the memory state of the deck's
+// cards: SM-2 keeps the ease factor in difficulty and ignores stability, which FSRS
+// would misread, so FSRS starts the state over at the next answer instead.
 func (r *DeckRepository) Update(id uuid.UUID, updates map[string]interface{}) (*models.Deck, error) {
 	// Build dynamic UPDATE query
 	setParts := []string{}
@@ -142,30 +147,53 @@ func (r *DeckRepository) Update(id uuid.UUID, updates map[string]interface{}) (*
 		argIndex++
 	}
 
+	if scheduler, ok := updates["scheduler"].(string); ok {
+		setParts = append(setParts, fmt.Sprintf("scheduler = $%d", argIndex))
+		args = append(args, scheduler)
+		argIndex++
+	}
+
+	// A nil preset ID detaches the preset
+	if presetID, ok := updates["preset_id"]; ok {
+		setParts = append(setParts, fmt.Sprintf("preset_id = $%d", argIndex))
+		args = append(args, presetID)
+		argIndex++
+	}
+
+	if desiredRetention, ok := updates["desired_retention"].(float64); ok {
+		setParts = append(setParts, fmt.Sprintf("desired_retention = $%d", argIndex))
+		args = append(args, desiredRetention)
+		argIndex++
+	}
+
+	if filter, ok := updates["filter"].(models.DeckFilter); ok {
+		setParts = append(setParts, fmt.Sprintf("filter = $%d", argIndex))
+		args = append(args, filter)
+		argIndex++
+	}
+
 	if len(setParts) == 0 {
 		return nil, fmt.Errorf("no fields to update")
 	}
 
 	// Add updated_at and id
-	setParts = append(setParts, fmt.Sprintf("updated_at = NOW()"))
+	setParts = append(setParts, "updated_at = NOW()")
 	args = append(args, id)
 
 	query := fmt.Sprintf(`
 		UPDATE decks
 		SET %s
 		WHERE id = $%d
-		RETURNING id, user_id, name, description, created_at, updated_at
-	`, fmt.Sprintf("%s", setParts), argIndex)
+		RETURNING %s
+	`, strings.Join(setParts, ", "), argIndex, deckColumns)
 
-	deck := &models.Deck{}
-	err := r.DB.QueryRow(query, args...).Scan(
-		&deck.ID,
-		&deck.UserID,
-		&deck.Name,
-		&deck.Description,
-		&deck.CreatedAt,
-		&deck.UpdatedAt,
-	)
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	deck, err := scanDeck(tx.QueryRow(query, args...))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -175,15 +203,43 @@ func (r *DeckRepository) Update(id uuid.UUID, updates map[string]interface{}) (*
 		return nil, fmt.Errorf("failed to update deck: %w", err)
 	}
 
+	if _, ok := updates["scheduler"].(string); ok {
+		_, err := tx.Exec(`
+            UPDATE flashcards
+            SET stability = 0, difficulty = ease_factor, retrievability = 0, updated_at = NOW()
+            WHERE COALESCE(home_deck_id, deck_id) = $1`,
+			id,
+		)
+		if err != nil {
+			r.Logger.WithError(err).WithField("deck_id", id).Error("Failed to reset flashcard memory state")
+			return nil, fmt.Errorf("failed to update deck: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit deck update: %w", err)
+	}
+
 	r.Logger.WithField("deck_id", deck.ID).Info("Deck updated successfully")
 	return deck, nil
 }
 
-// Delete deletes a deck by ID
+// Delete deletes a deck by ID. Cards a filtered deck holds go back to their home
+// deck instead of being deleted with it.
 func (r *DeckRepository) Delete(id uuid.UUID) error {
-	query := `DELETE FROM decks WHERE id = $1`
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	result, err := r.DB.Exec(query, id)
+	_, err = tx.Exec(`UPDATE flashcards SET deck_id = home_deck_id, home_deck_id = NULL WHERE deck_id = $1 AND home_deck_id IS NOT NULL`, id)
+	if err != nil {
+		r.Logger.WithError(err).WithField("deck_id", id).Error("Failed to return flashcards of deleted deck")
+		return fmt.Errorf("failed to delete deck: %w", err)
+	}
+
+	result, err := tx.Exec(`DELETE FROM decks WHERE id = $1`, id)
 	if err != nil {
 		r.Logger.WithError(err).WithField("deck_id", id).Error("Failed to delete deck")
 		return fmt.Errorf("failed to delete deck: %w", err)
@@ -198,14 +254,17 @@ func (r *DeckRepository) Delete(id uuid.UUID) error {
 		return fmt.Errorf("deck not found")
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit deck deletion: %w", err)
+	}
+
 	r.Logger.WithField("deck_id", id).Info("Deck deleted successfully")
 	return nil
 }
 
 // GetByUser retrieves all decks for a user
 func (r *DeckRepository) GetByUser(userID uuid.UUID) ([]*models.Deck, error) {
-	query := `
-		SELECT id, user_id, name, description, created_at, updated_at
+	query := `SELECT ` + deckColumns + `
 		FROM decks
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -220,15 +279,7 @@ func (r *DeckRepository) GetByUser(userID uuid.UUID) ([]*models.Deck, error) {
 
 	var decks []*models.Deck
 	for rows.Next() {
-		deck := &models.Deck{}
-		err := rows.Scan(
-			&deck.ID,
-			&deck.UserID,
-			&deck.Name,
-			&deck.Description,
-			&deck.CreatedAt,
-			&deck.UpdatedAt,
-		)
+		deck, err := scanDeck(rows)
 		if err != nil {
 			r.Logger.WithError(err).Error("Failed to scan deck row")
 			return nil, fmt.Errorf("failed to scan deck: %w", err)
@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+)
+
+type DeckPresetRepository struct {
+	DB     *sql.DB
+	Logger *logrus.Logger
+}
+
+func NewDeckPresetRepository(db *sql.DB, logger *logrus.Logger) *DeckPresetRepository {
+	return &DeckPresetRepository{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// deckPresetColumns lists the selected preset columns in the order expected by scanDeckPreset
+const deckPresetColumns = `id, user_id, name, starting_ease, minimum_ease, interval_modifier, maximum_interval,
+               learning_steps, relearning_steps, new_per_day, reviews_per_day, leech_threshold, leech_action,
+               bury_siblings, typed_answer_ignore_case, typed_answer_ignore_accents, typed_answer_ignore_punctuation,
+               created_at, updated_at`
+
+// scanDeckPreset scans a row selected with deckPresetColumns
+func scanDeckPreset(row rowScanner) (*models.DeckPreset, error) {
+	var preset models.DeckPreset
+	err := row.Scan(
+		&preset.ID, &preset.UserID, &preset.Name,
+		&preset.StartingEase, &preset.MinimumEase, &preset.IntervalModifier, &preset.MaximumInterval,
+		pq.Array(&preset.LearningSteps), pq.Array(&preset.RelearningSteps),
+		&preset.NewPerDay, &preset.ReviewsPerDay, &preset.LeechThreshold, &preset.LeechAction,
+		&preset.BurySiblings, &preset.TypedAnswerIgnoreCase, &preset.TypedAnswerIgnoreAccents, &preset.TypedAnswerIgnorePunctuation,
+		&preset.CreatedAt, &preset.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &preset, nil
+}
+
+// Create inserts a new deck preset
+func (r *DeckPresetRepository) Create(preset *models.DeckPreset) (*models.DeckPreset, error) {
+	query := `
+        INSERT INTO deck_presets (id, user_id, name, starting_ease, minimum_ease, interval_modifier, maximum_interval,
+                                  learning_steps, relearning_steps, new_per_day, reviews_per_day,
+                                  leech_threshold, leech_action, bury_siblings, typed_answer_ignore_case,
+                                  typed_answer_ignore_accents, typed_answer_ignore_punctuation, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, NOW(), NOW())
+        RETURNING ` + deckPresetColumns
+
+	created, err := scanDeckPreset(r.DB.QueryRow(
+		query,
+		preset.ID, preset.UserID, preset.Name,
+		preset.StartingEase, preset.MinimumEase, preset.IntervalModifier, preset.MaximumInterval,
+		pq.Array(preset.LearningSteps), pq.Array(preset.RelearningSteps),
+		preset.NewPerDay, preset.ReviewsPerDay, preset.LeechThreshold, preset.LeechAction,
+		preset.BurySiblings, preset.TypedAnswerIgnoreCase, preset.TypedAnswerIgnoreAccents, preset.TypedAnswerIgnorePunctuation,
+	))
+
+	if err != nil {
+		r.Logger.WithError(err).Error("Failed to create deck preset")
+		return nil, fmt.Errorf("failed to create deck preset: %w", err)
+	}
+
+	r.Logger.WithField("preset_id", created.ID).Info("Deck preset created successfully")
+	return created, nil
+}
+
+// GetByID retrieves a deck preset by ID
+func (r *DeckPresetRepository) GetByID(id uuid.UUID) (*models.DeckPreset, error) {
+	query := `SELECT ` + deckPresetColumns + `
+        FROM deck_presets
+        WHERE id = $1
+    `
+
+	preset, err := scanDeckPreset(r.DB.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("deck preset not found")
+		}
+		r.Logger.WithError(err).WithField("preset_id", id).Error("Failed to get deck preset")
+		return nil, fmt.Errorf("failed to get deck preset: %w", err)
+	}
+
+	return preset, nil
+}
+
+// GetByUser retrieves all deck presets of a user, ordered by name
+func (r *DeckPresetRepository) GetByUser(userID uuid.UUID) ([]*models.DeckPreset, error) {
+	query := `SELECT ` + deckPresetColumns + `
+        FROM deck_presets
+        WHERE user_id = $1
+        ORDER BY name
+    `
+
+	rows, err := r.DB.Query(query, userID)
+	if err != nil {
+		r.Logger.WithError(err).WithField("user_id", userID).Error("Failed to get deck presets for user")
+		return nil, fmt.Errorf("failed to get deck presets: %w", err)
+	}
+	defer rows.Close()
+
+	var presets []*models.DeckPreset
+	for rows.Next() {
+		preset, err := scanDeckPreset(rows)
+		if err != nil {
+			r.Logger.WithError(err).Error("Failed to scan deck preset")
+			return nil, fmt.Errorf("failed to scan deck preset: %w", err)
+		}
+		presets = append(presets, preset)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.Logger.WithError(err).Error("Error iterating deck preset rows")
+		return nil, fmt.Errorf("failed to iterate deck presets: %w", err)
+	}
+
+	return presets, nil
+}
+
+// Update overwrites every option of a deck preset
+func (r *DeckPresetRepository) Update(preset *models.DeckPreset) (*models.DeckPreset, error) {
+	query := `
+        UPDATE deck_presets
+        SET name = $2, starting_ease = $3, minimum_ease = $4, interval_modifier = $5, maximum_interval = $6,
+            learning_steps = $7, relearning_steps = $8, new_per_day = $9, reviews_per_day = $10,
+            leech_threshold = $11, leech_action = $12, bury_siblings = $13, typed_answer_ignore_case = $14,
+            typed_answer_ignore_accents = $15, typed_answer_ignore_punctuation = $16, updated_at = NOW()
+        WHERE id = $1
+        RETURNING ` + deckPresetColumns
+
+	updated, err := scanDeckPreset(r.DB.QueryRow(
+		query,
+		preset.ID, preset.Name,
+		preset.StartingEase, preset.MinimumEase, preset.IntervalModifier, preset.MaximumInterval,
+		pq.Array(preset.LearningSteps), pq.Array(preset.RelearningSteps),
+		preset.NewPerDay, preset.ReviewsPerDay, preset.LeechThreshold, preset.LeechAction,
+		preset.BurySiblings, preset.TypedAnswerIgnoreCase, preset.TypedAnswerIgnoreAccents, preset.TypedAnswerIgnorePunctuation,
+	))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("deck preset not found")
+		}
+		r.Logger.WithError(err).WithField("preset_id", preset.ID).Error("Failed to update deck preset")
+		return nil, fmt.Errorf("failed to update deck preset: %w", err)
+	}
+
+	r.Logger.WithField("preset_id", updated.ID).Info("Deck preset updated successfully")
+	return updated, nil
+}
+
+// Delete removes a deck preset; decks using it fall back to the defaults
+func (r *DeckPresetRepository) Delete(id uuid.UUID) error {
+	query := `DELETE FROM deck_presets WHERE id = $1`
+
+	result, err := r.DB.Exec(query, id)
+	if err != nil {
+		r.Logger.WithError(err).WithField("preset_id", id).Error("Failed to delete deck preset")
+		return fmt.Errorf("failed to delete deck preset: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("deck preset not found")
+	}
+
+	r.Logger.WithField("preset_id", id).Info("Deck preset deleted successfully")
+	return nil
+}
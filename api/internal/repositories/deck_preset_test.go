@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/pkg/testutils"
+)
+
+func TestDeckPresetRepository_CRUD(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	user := testutils.CreateTestUser()
+	user.PasswordHash = "test_hash"
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	repo := NewDeckPresetRepository(td.DB.DB, td.Logger)
+
+	preset := testutils.CreateTestDeckPreset(createdUser.ID)
+	preset.LearningSteps = []int64{5, 30, 120}
+	preset.BurySiblings = true
+	createdPreset, err := repo.Create(preset)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5, 30, 120}, createdPreset.LearningSteps)
+	assert.True(t, createdPreset.BurySiblings)
+	assert.Equal(t, []int64{10}, createdPreset.RelearningSteps)
+
+	createdPreset.Name = "Languages"
+	createdPreset.RelearningSteps = []int64{}
+	createdPreset.ReviewsPerDay = 50
+	createdPreset.TypedAnswerIgnoreAccents = true
+	updatedPreset, err := repo.Update(createdPreset)
+	require.NoError(t, err)
+	assert.Equal(t, "Languages", updatedPreset.Name)
+	assert.Empty(t, updatedPreset.RelearningSteps)
+	assert.Equal(t, 50, updatedPreset.ReviewsPerDay)
+	assert.True(t, updatedPreset.TypedAnswerIgnoreAccents)
+
+	// Decks using a deleted preset fall back to the defaults
+	deckRepo := NewDeckRepository(td.DB.DB, td.Logger)
+	deck := testutils.CreateTestDeck(createdUser.ID)
+	deck.PresetID = &createdPreset.ID
+	createdDeck, err := deckRepo.Create(deck)
+	require.NoError(t, err)
+	require.NotNil(t, createdDeck.PresetID)
+
+	presets, err := repo.GetByUser(createdUser.ID)
+	require.NoError(t, err)
+	assert.Len(t, presets, 1)
+
+	require.NoError(t, repo.Delete(createdPreset.ID))
+
+	retrievedDeck, err := deckRepo.GetByID(createdDeck.ID)
+	require.NoError(t, err)
+	assert.Nil(t, retrievedDeck.PresetID)
+
+	_, err = repo.GetByID(createdPreset.ID)
+	assert.EqualError(t, err, "deck preset not found")
+}
@@ -310,6 +310,77 @@ func TestDeckRepository_Update_BothFields(t *testing.T) {
 	assert.True(t, updatedDeck.UpdatedAt.After(createdDeck.UpdatedAt))
 }
 
+func TestDeckRepository_Update_Scheduler(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	// Create user first
+	user := testutils.CreateTestUser()
+	user.PasswordHash = "test_hash"
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	repo := NewDeckRepository(td.DB.DB, td.Logger)
+
+	// Create a deck
+	deck := testutils.CreateTestDeck(createdUser.ID)
+	createdDeck, err := repo.Create(deck)
+	require.NoError(t, err)
+	assert.Equal(t, "sm2", createdDeck.Scheduler)
+
+	flashcardRepo := NewFlashcardRepository(td.DB.DB, td.Logger)
+	card := testutils.CreateTestFlashcard(createdUser.ID, createdDeck.ID)
+	card.EaseFactor, card.Difficulty, card.Stability = 2.2, 2.2, 40
+	_, err = flashcardRepo.Create(card)
+	require.NoError(t, err)
+
+	// Switch the deck to FSRS
+	updates := map[string]interface{}{
+		"scheduler": "fsrs",
+	}
+
+	updatedDeck, err := repo.Update(createdDeck.ID, updates)
+	require.NoError(t, err)
+	require.NotNil(t, updatedDeck)
+
+	assert.Equal(t, "fsrs", updatedDeck.Scheduler)
+	assert.Equal(t, createdDeck.Name, updatedDeck.Name)
+
+	// FSRS starts the memory state of the deck's cards over
+	reset, err := flashcardRepo.GetByID(card.ID)
+	require.NoError(t, err)
+	assert.Zero(t, reset.Stability)
+	assert.Equal(t, 2.2, reset.EaseFactor)
+}
+
+func TestDeckRepository_Update_DesiredRetention(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	user := testutils.CreateTestUser()
+	user.PasswordHash = "test_hash"
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	repo := NewDeckRepository(td.DB.DB, td.Logger)
+
+	createdDeck, err := repo.Create(testutils.CreateTestDeck(createdUser.ID))
+	require.NoError(t, err)
+	assert.Equal(t, 0.9, createdDeck.DesiredRetention)
+
+	updatedDeck, err := repo.Update(createdDeck.ID, map[string]interface{}{"desired_retention": 0.85})
+	require.NoError(t, err)
+	assert.Equal(t, 0.85, updatedDeck.DesiredRetention)
+
+	// Retention outside 85-97% is rejected
+	_, err = repo.Update(createdDeck.ID, map[string]interface{}{"desired_retention": 0.5})
+	assert.Error(t, err)
+}
+
 func TestDeckRepository_Update_NotFound(t *testing.T) {
 	td := testutils.SetupTestDatabase(t)
 	defer td.Close()
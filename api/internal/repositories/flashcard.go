@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 
 	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/render"
 )
 
 type FlashcardRepository struct {
@@ -16,6 +18,46 @@ type FlashcardRepository struct {
 	Logger *logrus.Logger
 }
 
+// flashcardColumns lists the selected flashcard columns in the order expected by scanFlashcard
+const flashcardColumns = `id, user_id, deck_id, home_deck_id, note_id, ord, front, back, format, front_html, back_html, difficulty, interval, ease_factor, review_count,
+               stability, retrievability, state, step, last_review, next_review, suspended, buried_until,
+               lapses, leech, created_at, updated_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanFlashcard scans a row selected with flashcardColumns. Content not rendered yet is
+// rendered as it is read.
+func scanFlashcard(row rowScanner) (*models.Flashcard, error) {
+	var card models.Flashcard
+	var frontHTML, backHTML sql.NullString
+	err := row.Scan(
+		&card.ID, &card.UserID, &card.DeckID, &card.HomeDeckID, &card.NoteID, &card.Ord, &card.Front, &card.Back,
+		&card.Format, &frontHTML, &backHTML,
+		&card.Difficulty, &card.Interval, &card.EaseFactor, &card.ReviewCount,
+		&card.Stability, &card.Retrievability, &card.State, &card.Step,
+		&card.LastReview, &card.NextReview, &card.Suspended, &card.BuriedUntil,
+		&card.Lapses, &card.Leech, &card.CreatedAt, &card.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	card.FrontHTML, card.BackHTML = frontHTML.String, backHTML.String
+	if !frontHTML.Valid || !backHTML.Valid {
+		renderFlashcard(&card)
+	}
+	return &card, nil
+}
+
+// renderFlashcard renders a card's front and back to sanitized HTML in its format
+func renderFlashcard(card *models.Flashcard) {
+	card.FrontHTML = render.HTML(card.Format, card.Front)
+	card.BackHTML = render.HTML(card.Format, card.Back)
+}
+
 func NewFlashcardRepository(db *sql.DB, logger *logrus.Logger) *FlashcardRepository {
 	return &FlashcardRepository{
 		DB:     db,
@@ -25,54 +67,77 @@ func NewFlashcardRepository(db *sql.DB, logger *logrus.Logger) *FlashcardReposit
 
 // Create inserts a new flashcard
 func (r *FlashcardRepository) Create(card *models.Flashcard) (*models.Flashcard, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	created, err := insertFlashcard(tx, card)
+	if err != nil {
+		r.Logger.WithError(err).Error("Failed to create flashcard")
+		return nil, fmt.Errorf("failed to create flashcard: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit flashcard creation: %w", err)
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"flashcard_id": created.ID,
+		"user_id":      created.UserID,
+	}).Info("Flashcard created successfully")
+
+	return created, nil
+}
+
+// insertFlashcard inserts a card in tx and links the media it references
+func insertFlashcard(tx *sql.Tx, card *models.Flashcard) (*models.Flashcard, error) {
 	query := `
-        INSERT INTO flashcards (id, user_id, deck_id, front, back, difficulty, interval, ease_factor, review_count, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
-        RETURNING id, user_id, deck_id, front, back, difficulty, interval, ease_factor, review_count, last_review, next_review, created_at, updated_at
-    `
+        INSERT INTO flashcards (id, user_id, deck_id, note_id, ord, front, back, format, front_html, back_html,
+                                difficulty, interval, ease_factor, review_count, stability, retrievability, state, step,
+                                created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, NOW(), NOW())
+        RETURNING ` + flashcardColumns
+
+	if card.State == "" {
+		card.State = models.CardStateNew
+	}
+	if card.Format == "" {
+		card.Format = models.ContentFormatPlain
+	}
+	renderFlashcard(card)
 
 	now := time.Now()
 	card.CreatedAt = now
 	card.UpdatedAt = now
 
-	err := r.DB.QueryRow(
+	created, err := scanFlashcard(tx.QueryRow(
 		query,
-		card.ID, card.UserID, card.DeckID, card.Front, card.Back,
+		card.ID, card.UserID, card.DeckID, card.NoteID, card.Ord, card.Front, card.Back,
+		card.Format, card.FrontHTML, card.BackHTML,
 		card.Difficulty, card.Interval, card.EaseFactor, card.ReviewCount,
-	).Scan(
-		&card.ID, &card.UserID, &card.DeckID, &card.Front, &card.Back,
-		&card.Difficulty, &card.Interval, &card.EaseFactor, &card.ReviewCount,
-		&card.LastReview, &card.NextReview, &card.CreatedAt, &card.UpdatedAt,
-	)
-
+		card.Stability, card.Retrievability, card.State, card.Step,
+	))
 	if err != nil {
-		r.Logger.WithError(err).Error("Failed to create flashcard")
-		return nil, fmt.Errorf("failed to create flashcard: %w", err)
+		return nil, err
 	}
 
-	r.Logger.WithFields(logrus.Fields{
-		"flashcard_id": card.ID,
-		"user_id":      card.UserID,
-	}).Info("Flashcard created successfully")
+	if err := linkFlashcardMedia(tx, created); err != nil {
+		return nil, err
+	}
 
-	return card, nil
+	return created, nil
 }
 
 // GetByID retrieves a flashcard by ID
 func (r *FlashcardRepository) GetByID(id uuid.UUID) (*models.Flashcard, error) {
-	query := `
-        SELECT id, user_id, deck_id, front, back, difficulty, interval, ease_factor, review_count,
-               last_review, next_review, created_at, updated_at
+	query := `SELECT ` + flashcardColumns + `
         FROM flashcards
         WHERE id = $1
     `
 
-	var card models.Flashcard
-	err := r.DB.QueryRow(query, id).Scan(
-		&card.ID, &card.UserID, &card.DeckID, &card.Front, &card.Back,
-		&card.Difficulty, &card.Interval, &card.EaseFactor, &card.ReviewCount,
-		&card.LastReview, &card.NextReview, &card.CreatedAt, &card.UpdatedAt,
-	)
+	card, err := scanFlashcard(r.DB.QueryRow(query, id))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -82,14 +147,12 @@ func (r *FlashcardRepository) GetByID(id uuid.UUID) (*models.Flashcard, error) {
 		return nil, fmt.Errorf("failed to get flashcard: %w", err)
 	}
 
-	return &card, nil
+	return card, nil
 }
 
 // GetByUser retrieves all flashcards for a user
 func (r *FlashcardRepository) GetByUser(userID uuid.UUID) ([]*models.Flashcard, error) {
-	query := `
-        SELECT id, user_id, deck_id, front, back, difficulty, interval, ease_factor, review_count,
-               last_review, next_review, created_at, updated_at
+	query := `SELECT ` + flashcardColumns + `
         FROM flashcards
         WHERE user_id = $1
         ORDER BY created_at DESC
@@ -104,17 +167,12 @@ func (r *FlashcardRepository) GetByUser(userID uuid.UUID) ([]*models.Flashcard,
 
 	var flashcards []*models.Flashcard
 	for rows.Next() {
-		var card models.Flashcard
-		err := rows.Scan(
-			&card.ID, &card.UserID, &card.DeckID, &card.Front, &card.Back,
-			&card.Difficulty, &card.Interval, &card.EaseFactor, &card.ReviewCount,
-			&card.LastReview, &card.NextReview, &card.CreatedAt, &card.UpdatedAt,
-		)
+		card, err := scanFlashcard(rows)
 		if err != nil {
 			r.Logger.WithError(err).Error("Failed to scan flashcard")
 			return nil, fmt.Errorf("failed to scan flashcard: %w", err)
 		}
-		flashcards = append(flashcards, &card)
+		flashcards = append(flashcards, card)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -130,6 +188,110 @@ func (r *FlashcardRepository) GetByUser(userID uuid.UUID) ([]*models.Flashcard,
 	return flashcards, nil
 }
 
+// GetByIDs retrieves the flashcards with the given IDs. IDs that do not exist are skipped.
+func (r *FlashcardRepository) GetByIDs(ids []uuid.UUID) ([]*models.Flashcard, error) {
+	query := `SELECT ` + flashcardColumns + `
+        FROM flashcards
+        WHERE id = ANY($1::uuid[])
+        ORDER BY created_at DESC
+    `
+
+	rows, err := r.DB.Query(query, pq.Array(ids))
+	if err != nil {
+		r.Logger.WithError(err).Error("Failed to get flashcards by IDs")
+		return nil, fmt.Errorf("failed to get flashcards: %w", err)
+	}
+
+	return r.scanFlashcards(rows)
+}
+
+// GetByDeck retrieves the flashcards belonging to a deck, including those moved from it
+// into a filtered deck
+func (r *FlashcardRepository) GetByDeck(deckID uuid.UUID) ([]*models.Flashcard, error) {
+	query := `SELECT ` + flashcardColumns + `
+        FROM flashcards
+        WHERE COALESCE(home_deck_id, deck_id) = $1
+        ORDER BY created_at
+    `
+
+	rows, err := r.DB.Query(query, deckID)
+	if err != nil {
+		r.Logger.WithError(err).WithField("deck_id", deckID).Error("Failed to get flashcards of deck")
+		return nil, fmt.Errorf("failed to get flashcards: %w", err)
+	}
+
+	return r.scanFlashcards(rows)
+}
+
+// GetByNote retrieves the flashcards generated from a note, in template order
+func (r *FlashcardRepository) GetByNote(noteID uuid.UUID) ([]*models.Flashcard, error) {
+	query := `SELECT ` + flashcardColumns + `
+        FROM flashcards
+        WHERE note_id = $1
+        ORDER BY ord
+    `
+
+	rows, err := r.DB.Query(query, noteID)
+	if err != nil {
+		r.Logger.WithError(err).WithField("note_id", noteID).Error("Failed to get flashcards of note")
+		return nil, fmt.Errorf("failed to get flashcards: %w", err)
+	}
+
+	return r.scanFlashcards(rows)
+}
+
+// GetLeeches retrieves the leech flashcards of a user, most lapsed first
+func (r *FlashcardRepository) GetLeeches(userID uuid.UUID) ([]*models.Flashcard, error) {
+	query := `SELECT ` + flashcardColumns + `
+        FROM flashcards
+        WHERE user_id = $1 AND leech
+        ORDER BY lapses DESC, created_at DESC
+    `
+
+	rows, err := r.DB.Query(query, userID)
+	if err != nil {
+		r.Logger.WithError(err).WithField("user_id", userID).Error("Failed to get leech flashcards")
+		return nil, fmt.Errorf("failed to get flashcards: %w", err)
+	}
+
+	return r.scanFlashcards(rows)
+}
+
+// GetReviewDueDates returns the next review time of every review card of a user
+// falling due in [from, to), used to spread new due dates over quiet days
+func (r *FlashcardRepository) GetReviewDueDates(userID uuid.UUID, from, to time.Time) ([]time.Time, error) {
+	query := `
+        SELECT next_review
+        FROM flashcards
+        WHERE user_id = $1 AND state = 'review' AND NOT suspended
+          AND next_review >= $2 AND next_review < $3
+    `
+
+	rows, err := r.DB.Query(query, userID, from, to)
+	if err != nil {
+		r.Logger.WithError(err).WithField("user_id", userID).Error("Failed to get review due dates")
+		return nil, fmt.Errorf("failed to get review due dates: %w", err)
+	}
+	defer rows.Close()
+
+	var dueDates []time.Time
+	for rows.Next() {
+		var due time.Time
+		if err := rows.Scan(&due); err != nil {
+			r.Logger.WithError(err).Error("Failed to scan review due date")
+			return nil, fmt.Errorf("failed to scan review due date: %w", err)
+		}
+		dueDates = append(dueDates, due)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.Logger.WithError(err).Error("Error iterating review due dates")
+		return nil, fmt.Errorf("failed to iterate review due dates: %w", err)
+	}
+
+	return dueDates, nil
+}
+
 // Update with safer named parameter approach
 func (r *FlashcardRepository) Update(id uuid.UUID, updates *models.UpdateFlashcardRequest) (*models.Flashcard, error) {
 	// Start with existing card
@@ -145,6 +307,12 @@ func (r *FlashcardRepository) Update(id uuid.UUID, updates *models.UpdateFlashca
 	if updates.Back != nil {
 		card.Back = *updates.Back
 	}
+	if updates.Format != nil {
+		card.Format = *updates.Format
+	}
+	if updates.Front != nil || updates.Back != nil || updates.Format != nil {
+		renderFlashcard(card)
+	}
 	if updates.Difficulty != nil {
 		card.Difficulty = *updates.Difficulty
 	}
@@ -157,6 +325,12 @@ func (r *FlashcardRepository) Update(id uuid.UUID, updates *models.UpdateFlashca
 	if updates.ReviewCount != nil {
 		card.ReviewCount = *updates.ReviewCount
 	}
+	if updates.Stability != nil {
+		card.Stability = *updates.Stability
+	}
+	if updates.Retrievability != nil {
+		card.Retrievability = *updates.Retrievability
+	}
 	if updates.LastReview != nil {
 		card.LastReview = updates.LastReview
 	}
@@ -169,30 +343,187 @@ func (r *FlashcardRepository) Update(id uuid.UUID, updates *models.UpdateFlashca
         UPDATE flashcards
         SET front = $2, back = $3, difficulty = $4, interval = $5, 
             ease_factor = $6, review_count = $7, last_review = $8, 
-            next_review = $9, updated_at = NOW()
+            next_review = $9, stability = $10, retrievability = $11,
+            format = $12, front_html = $13, back_html = $14, updated_at = NOW()
         WHERE id = $1
-        RETURNING id, user_id, deck_id, front, back, difficulty, interval, ease_factor, review_count,
-                  last_review, next_review, created_at, updated_at
-    `
+        RETURNING ` + flashcardColumns
 
-	err = r.DB.QueryRow(
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	card, err = scanFlashcard(tx.QueryRow(
 		query,
 		id, card.Front, card.Back, card.Difficulty, card.Interval,
 		card.EaseFactor, card.ReviewCount, card.LastReview, card.NextReview,
-	).Scan(
-		&card.ID, &card.UserID, &card.DeckID, &card.Front, &card.Back,
-		&card.Difficulty, &card.Interval, &card.EaseFactor, &card.ReviewCount,
-		&card.LastReview, &card.NextReview, &card.CreatedAt, &card.UpdatedAt,
-	)
+		card.Stability, card.Retrievability, card.Format, card.FrontHTML, card.BackHTML,
+	))
 
 	if err != nil {
 		r.Logger.WithError(err).WithField("flashcard_id", id).Error("Failed to update flashcard")
 		return nil, fmt.Errorf("failed to update flashcard: %w", err)
 	}
 
+	// Only a new front or back can change which media the card references
+	if updates.Front != nil || updates.Back != nil {
+		if err := linkFlashcardMedia(tx, card); err != nil {
+			r.Logger.WithError(err).WithField("flashcard_id", id).Error("Failed to link flashcard media")
+			return nil, fmt.Errorf("failed to update flashcard: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit flashcard update: %w", err)
+	}
+
 	return card, nil
 }
 
+// UpdateSchedulingState overwrites every scheduling column, including clearing
+// last_review and next_review when the state has none (e.g. undoing a first review)
+func (r *FlashcardRepository) UpdateSchedulingState(id uuid.UUID, state models.SchedulingState) (*models.Flashcard, error) {
+	query := `
+        UPDATE flashcards
+        SET difficulty = $2, interval = $3, ease_factor = $4, review_count = $5,
+            stability = $6, retrievability = $7, state = $8, step = $9,
+            last_review = $10, next_review = $11, lapses = $12, leech = $13, updated_at = NOW()
+        WHERE id = $1
+        RETURNING ` + flashcardColumns
+
+	card, err := scanFlashcard(r.DB.QueryRow(
+		query,
+		id, state.Difficulty, state.Interval, state.EaseFactor, state.ReviewCount,
+		state.Stability, state.Retrievability, state.CardState(), state.Step, state.LastReview, state.NextReview,
+		state.Lapses, state.Leech,
+	))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("flashcard not found")
+		}
+		r.Logger.WithError(err).WithField("flashcard_id", id).Error("Failed to update flashcard scheduling state")
+		return nil, fmt.Errorf("failed to update flashcard: %w", err)
+	}
+
+	return card, nil
+}
+
+// SetSuspended suspends or unsuspends flashcards and returns them
+func (r *FlashcardRepository) SetSuspended(ids []uuid.UUID, suspended bool) ([]*models.Flashcard, error) {
+	query := `
+        UPDATE flashcards
+        SET suspended = $2, updated_at = NOW()
+        WHERE id = ANY($1::uuid[])
+        RETURNING ` + flashcardColumns
+
+	rows, err := r.DB.Query(query, pq.Array(ids), suspended)
+	if err != nil {
+		r.Logger.WithError(err).Error("Failed to update flashcard suspension")
+		return nil, fmt.Errorf("failed to update flashcards: %w", err)
+	}
+
+	return r.scanFlashcards(rows)
+}
+
+// SetBuriedUntil buries flashcards until the given time, or unburies them when until is nil
+func (r *FlashcardRepository) SetBuriedUntil(ids []uuid.UUID, until *time.Time) ([]*models.Flashcard, error) {
+	query := `
+        UPDATE flashcards
+        SET buried_until = $2, updated_at = NOW()
+        WHERE id = ANY($1::uuid[])
+        RETURNING ` + flashcardColumns
+
+	rows, err := r.DB.Query(query, pq.Array(ids), until)
+	if err != nil {
+		r.Logger.WithError(err).Error("Failed to bury flashcards")
+		return nil, fmt.Errorf("failed to update flashcards: %w", err)
+	}
+
+	return r.scanFlashcards(rows)
+}
+
+// MoveToFilteredDeck moves flashcards into a filtered deck, remembering the deck each
+// came from as its home deck. Cards already in a filtered deck are left where they are.
+func (r *FlashcardRepository) MoveToFilteredDeck(ids []uuid.UUID, deckID uuid.UUID) ([]*models.Flashcard, error) {
+	query := `
+        UPDATE flashcards
+        SET home_deck_id = deck_id, deck_id = $2, updated_at = NOW()
+        WHERE id = ANY($1::uuid[]) AND home_deck_id IS NULL
+        RETURNING ` + flashcardColumns
+
+	rows, err := r.DB.Query(query, pq.Array(ids), deckID)
+	if err != nil {
+		r.Logger.WithError(err).WithField("deck_id", deckID).Error("Failed to move flashcards into filtered deck")
+		return nil, fmt.Errorf("failed to update flashcards: %w", err)
+	}
+
+	return r.scanFlashcards(rows)
+}
+
+// ReturnToHomeDeck moves flashcards out of their filtered deck back to their home deck
+func (r *FlashcardRepository) ReturnToHomeDeck(ids []uuid.UUID) ([]*models.Flashcard, error) {
+	query := `
+        UPDATE flashcards
+        SET deck_id = home_deck_id, home_deck_id = NULL, updated_at = NOW()
+        WHERE id = ANY($1::uuid[]) AND home_deck_id IS NOT NULL
+        RETURNING ` + flashcardColumns
+
+	rows, err := r.DB.Query(query, pq.Array(ids))
+	if err != nil {
+		r.Logger.WithError(err).Error("Failed to return flashcards to their home deck")
+		return nil, fmt.Errorf("failed to update flashcards: %w", err)
+	}
+
+	return r.scanFlashcards(rows)
+}
+
+// EmptyFilteredDeck returns every flashcard in a filtered deck to its home deck and
+// returns how many were moved
+func (r *FlashcardRepository) EmptyFilteredDeck(deckID uuid.UUID) (int, error) {
+	query := `
+        UPDATE flashcards
+        SET deck_id = home_deck_id, home_deck_id = NULL, updated_at = NOW()
+        WHERE deck_id = $1 AND home_deck_id IS NOT NULL
+    `
+
+	result, err := r.DB.Exec(query, deckID)
+	if err != nil {
+		r.Logger.WithError(err).WithField("deck_id", deckID).Error("Failed to empty filtered deck")
+		return 0, fmt.Errorf("failed to empty filtered deck: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(count), nil
+}
+
+// scanFlashcards scans and closes rows selected with flashcardColumns
+func (r *FlashcardRepository) scanFlashcards(rows *sql.Rows) ([]*models.Flashcard, error) {
+	defer rows.Close()
+
+	var flashcards []*models.Flashcard
+	for rows.Next() {
+		card, err := scanFlashcard(rows)
+		if err != nil {
+			r.Logger.WithError(err).Error("Failed to scan flashcard")
+			return nil, fmt.Errorf("failed to scan flashcard: %w", err)
+		}
+		flashcards = append(flashcards, card)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.Logger.WithError(err).Error("Error iterating flashcard rows")
+		return nil, fmt.Errorf("failed to iterate flashcards: %w", err)
+	}
+
+	return flashcards, nil
+}
+
 // Delete removes a flashcard
 func (r *FlashcardRepository) Delete(id uuid.UUID) error {
 	query := `DELETE FROM flashcards WHERE id = $1`
@@ -217,6 +217,45 @@ func TestFlashcardRepository_Update_AllFields(t *testing.T) {
 	assert.True(t, updatedFlashcard.UpdatedAt.After(createdFlashcard.UpdatedAt))
 }
 
+func TestFlashcardRepository_RendersContent(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	user := testutils.CreateTestUser()
+	user.PasswordHash = "test_hash"
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	deckRepo := NewDeckRepository(td.DB.DB, td.Logger)
+	createdDeck, err := deckRepo.Create(testutils.CreateTestDeck(createdUser.ID))
+	require.NoError(t, err)
+
+	repo := NewFlashcardRepository(td.DB.DB, td.Logger)
+	flashcard := testutils.CreateTestFlashcard(createdUser.ID, createdDeck.ID)
+	flashcard.Front = "**Bold** <script>alert(1)</script>"
+	flashcard.Format = models.ContentFormatMarkdown
+	createdFlashcard, err := repo.Create(flashcard)
+	require.NoError(t, err)
+	assert.Equal(t, models.ContentFormatMarkdown, createdFlashcard.Format)
+	assert.Equal(t, "<p><strong>Bold</strong> </p>\n", createdFlashcard.FrontHTML)
+
+	// Changing the format renders the content again
+	plain := models.ContentFormatPlain
+	updatedFlashcard, err := repo.Update(createdFlashcard.ID, &models.UpdateFlashcardRequest{Format: &plain})
+	require.NoError(t, err)
+	assert.Equal(t, "**Bold** &lt;script&gt;alert(1)&lt;/script&gt;", updatedFlashcard.FrontHTML)
+
+	// Content not rendered yet is rendered when read
+	_, err = td.DB.Exec(`UPDATE flashcards SET front_html = NULL, back_html = NULL WHERE id = $1`, createdFlashcard.ID)
+	require.NoError(t, err)
+	card, err := repo.GetByID(createdFlashcard.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updatedFlashcard.FrontHTML, card.FrontHTML)
+	assert.Equal(t, updatedFlashcard.BackHTML, card.BackHTML)
+}
+
 func TestFlashcardRepository_Update_PartialFields(t *testing.T) {
 	td := testutils.SetupTestDatabase(t)
 	defer td.Close()
@@ -256,6 +295,245 @@ func TestFlashcardRepository_Update_PartialFields(t *testing.T) {
 	assert.Equal(t, createdFlashcard.Difficulty, updatedFlashcard.Difficulty)
 }
 
+func TestFlashcardRepository_UpdateSchedulingState_ClearsReviewDates(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	// Create user, deck, and flashcard
+	user := testutils.CreateTestUser()
+	user.PasswordHash = "test_hash"
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	deckRepo := NewDeckRepository(td.DB.DB, td.Logger)
+	deck := testutils.CreateTestDeck(createdUser.ID)
+	createdDeck, err := deckRepo.Create(deck)
+	require.NoError(t, err)
+
+	repo := NewFlashcardRepository(td.DB.DB, td.Logger)
+	flashcard := testutils.CreateTestFlashcard(createdUser.ID, createdDeck.ID)
+	createdFlashcard, err := repo.Create(flashcard)
+	require.NoError(t, err)
+
+	// Review the card, then restore its original (never reviewed) state
+	lastReview := time.Now()
+	nextReview := lastReview.Add(24 * time.Hour)
+	reviewed, err := repo.UpdateSchedulingState(createdFlashcard.ID, models.SchedulingState{
+		Difficulty:  2.6,
+		Interval:    1,
+		EaseFactor:  2.6,
+		ReviewCount: 1,
+		State:       models.CardStateLearning,
+		Step:        1,
+		LastReview:  &lastReview,
+		NextReview:  &nextReview,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, reviewed.ReviewCount)
+	assert.Equal(t, models.CardStateLearning, reviewed.State)
+	assert.Equal(t, 1, reviewed.Step)
+	assert.NotNil(t, reviewed.NextReview)
+
+	restored, err := repo.UpdateSchedulingState(createdFlashcard.ID, createdFlashcard.SchedulingState())
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, restored.ReviewCount)
+	assert.Equal(t, models.CardStateNew, restored.State)
+	assert.Equal(t, 2.5, restored.EaseFactor)
+	assert.Nil(t, restored.LastReview)
+	assert.Nil(t, restored.NextReview)
+	assert.Equal(t, createdFlashcard.Front, restored.Front)
+}
+
+func TestFlashcardRepository_GetReviewDueDates(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	user := testutils.CreateTestUser()
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	deckRepo := NewDeckRepository(td.DB.DB, td.Logger)
+	createdDeck, err := deckRepo.Create(testutils.CreateTestDeck(createdUser.ID))
+	require.NoError(t, err)
+
+	repo := NewFlashcardRepository(td.DB.DB, td.Logger)
+	now := time.Now()
+	schedule := func(state string, due time.Time) {
+		card, err := repo.Create(testutils.CreateTestFlashcard(createdUser.ID, createdDeck.ID))
+		require.NoError(t, err)
+		_, err = repo.UpdateSchedulingState(card.ID, models.SchedulingState{
+			Interval: 5, EaseFactor: 2.5, ReviewCount: 2, State: state, LastReview: &now, NextReview: &due,
+		})
+		require.NoError(t, err)
+	}
+
+	schedule(models.CardStateReview, now.AddDate(0, 0, 5))
+	schedule(models.CardStateReview, now.AddDate(0, 0, 6))
+	schedule(models.CardStateReview, now.AddDate(0, 0, 30))  // outside the window
+	schedule(models.CardStateLearning, now.AddDate(0, 0, 5)) // not a review
+
+	dueDates, err := repo.GetReviewDueDates(createdUser.ID, now.AddDate(0, 0, 4), now.AddDate(0, 0, 10))
+	require.NoError(t, err)
+	assert.Len(t, dueDates, 2)
+}
+
+func TestFlashcardRepository_SetSuspendedAndBuried(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	user := testutils.CreateTestUser()
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	deckRepo := NewDeckRepository(td.DB.DB, td.Logger)
+	createdDeck, err := deckRepo.Create(testutils.CreateTestDeck(createdUser.ID))
+	require.NoError(t, err)
+
+	repo := NewFlashcardRepository(td.DB.DB, td.Logger)
+	first, err := repo.Create(testutils.CreateTestFlashcard(createdUser.ID, createdDeck.ID))
+	require.NoError(t, err)
+	second, err := repo.Create(testutils.CreateTestFlashcard(createdUser.ID, createdDeck.ID))
+	require.NoError(t, err)
+	ids := []uuid.UUID{first.ID, second.ID}
+
+	cards, err := repo.GetByIDs(ids)
+	require.NoError(t, err)
+	assert.Len(t, cards, 2)
+
+	suspended, err := repo.SetSuspended(ids, true)
+	require.NoError(t, err)
+	require.Len(t, suspended, 2)
+	assert.True(t, suspended[0].Suspended)
+	assert.True(t, suspended[1].Suspended)
+
+	until := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	buried, err := repo.SetBuriedUntil([]uuid.UUID{first.ID}, &until)
+	require.NoError(t, err)
+	require.Len(t, buried, 1)
+	require.NotNil(t, buried[0].BuriedUntil)
+	assert.True(t, buried[0].BuriedUntil.Equal(until))
+
+	unburied, err := repo.SetBuriedUntil([]uuid.UUID{first.ID}, nil)
+	require.NoError(t, err)
+	require.Len(t, unburied, 1)
+	assert.Nil(t, unburied[0].BuriedUntil)
+}
+
+func TestFlashcardRepository_GetLeeches(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	user := testutils.CreateTestUser()
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	deckRepo := NewDeckRepository(td.DB.DB, td.Logger)
+	createdDeck, err := deckRepo.Create(testutils.CreateTestDeck(createdUser.ID))
+	require.NoError(t, err)
+
+	repo := NewFlashcardRepository(td.DB.DB, td.Logger)
+	now := time.Now()
+	lapse := func(lapses int, leech bool) *models.Flashcard {
+		card, err := repo.Create(testutils.CreateTestFlashcard(createdUser.ID, createdDeck.ID))
+		require.NoError(t, err)
+		card, err = repo.UpdateSchedulingState(card.ID, models.SchedulingState{
+			Interval: 1, EaseFactor: 2.5, State: models.CardStateRelearning, LastReview: &now, NextReview: &now,
+			Lapses: lapses, Leech: leech,
+		})
+		require.NoError(t, err)
+		return card
+	}
+
+	lapse(3, false)
+	eight := lapse(8, true)
+	twelve := lapse(12, true)
+
+	leeches, err := repo.GetLeeches(createdUser.ID)
+	require.NoError(t, err)
+	require.Len(t, leeches, 2)
+	assert.Equal(t, twelve.ID, leeches[0].ID)
+	assert.Equal(t, 12, leeches[0].Lapses)
+	assert.Equal(t, eight.ID, leeches[1].ID)
+}
+
+func TestFlashcardRepository_FilteredDeck(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	user := testutils.CreateTestUser()
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	deckRepo := NewDeckRepository(td.DB.DB, td.Logger)
+	home, err := deckRepo.Create(testutils.CreateTestDeck(createdUser.ID))
+	require.NoError(t, err)
+	filteredDeck := testutils.CreateTestDeck(createdUser.ID)
+	filteredDeck.Filter = &models.DeckFilter{Search: "is:due", Order: models.FilterOrderDue, Limit: 100, Reschedule: true}
+	filtered, err := deckRepo.Create(filteredDeck)
+	require.NoError(t, err)
+	require.NotNil(t, filtered.Filter)
+	assert.Equal(t, "is:due", filtered.Filter.Search)
+
+	repo := NewFlashcardRepository(td.DB.DB, td.Logger)
+	first, err := repo.Create(testutils.CreateTestFlashcard(createdUser.ID, home.ID))
+	require.NoError(t, err)
+	second, err := repo.Create(testutils.CreateTestFlashcard(createdUser.ID, home.ID))
+	require.NoError(t, err)
+	third, err := repo.Create(testutils.CreateTestFlashcard(createdUser.ID, home.ID))
+	require.NoError(t, err)
+
+	moved, err := repo.MoveToFilteredDeck([]uuid.UUID{first.ID, second.ID, third.ID}, filtered.ID)
+	require.NoError(t, err)
+	require.Len(t, moved, 3)
+	assert.Equal(t, filtered.ID, moved[0].DeckID)
+	require.NotNil(t, moved[0].HomeDeckID)
+	assert.Equal(t, home.ID, *moved[0].HomeDeckID)
+
+	// Moved cards still belong to their home deck
+	homeCards, err := repo.GetByDeck(home.ID)
+	require.NoError(t, err)
+	assert.Len(t, homeCards, 3)
+	filteredCards, err := repo.GetByDeck(filtered.ID)
+	require.NoError(t, err)
+	assert.Empty(t, filteredCards)
+
+	// Cards already in a filtered deck are not moved again
+	moved, err = repo.MoveToFilteredDeck([]uuid.UUID{first.ID}, filtered.ID)
+	require.NoError(t, err)
+	assert.Len(t, moved, 0)
+
+	returned, err := repo.ReturnToHomeDeck([]uuid.UUID{first.ID})
+	require.NoError(t, err)
+	require.Len(t, returned, 1)
+	assert.Equal(t, home.ID, returned[0].DeckID)
+	assert.Nil(t, returned[0].HomeDeckID)
+
+	emptied, err := repo.EmptyFilteredDeck(filtered.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, emptied)
+
+	_, err = repo.MoveToFilteredDeck([]uuid.UUID{second.ID}, filtered.ID)
+	require.NoError(t, err)
+
+	// Deleting a filtered deck returns its cards instead of deleting them
+	require.NoError(t, deckRepo.Delete(filtered.ID))
+	card, err := repo.GetByID(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, home.ID, card.DeckID)
+	assert.Nil(t, card.HomeDeckID)
+}
+
 func TestFlashcardRepository_Delete_Success(t *testing.T) {
 	td := testutils.SetupTestDatabase(t)
 	defer td.Close()
@@ -28,13 +28,90 @@ type DeckRepositoryInterface interface {
 	GetDeckFlashcardCount(deckID uuid.UUID) (int, error)
 }
 
+// DeckPresetRepositoryInterface defines the interface for deck preset repository operations
+type DeckPresetRepositoryInterface interface {
+	Create(preset *models.DeckPreset) (*models.DeckPreset, error)
+	GetByID(id uuid.UUID) (*models.DeckPreset, error)
+	GetByUser(userID uuid.UUID) ([]*models.DeckPreset, error)
+	Update(preset *models.DeckPreset) (*models.DeckPreset, error)
+	Delete(id uuid.UUID) error
+}
+
 // FlashcardRepositoryInterface defines the interface for flashcard repository operations
 type FlashcardRepositoryInterface interface {
 	Create(card *models.Flashcard) (*models.Flashcard, error)
 	GetByID(id uuid.UUID) (*models.Flashcard, error)
 	GetByUser(userID uuid.UUID) ([]*models.Flashcard, error)
+	GetByIDs(ids []uuid.UUID) ([]*models.Flashcard, error)
+	GetByDeck(deckID uuid.UUID) ([]*models.Flashcard, error)
+	GetByNote(noteID uuid.UUID) ([]*models.Flashcard, error)
+	GetLeeches(userID uuid.UUID) ([]*models.Flashcard, error)
+	GetReviewDueDates(userID uuid.UUID, from, to time.Time) ([]time.Time, error)
 	Update(id uuid.UUID, updates *models.UpdateFlashcardRequest) (*models.Flashcard, error)
+	UpdateSchedulingState(id uuid.UUID, state models.SchedulingState) (*models.Flashcard, error)
+	SetSuspended(ids []uuid.UUID, suspended bool) ([]*models.Flashcard, error)
+	SetBuriedUntil(ids []uuid.UUID, until *time.Time) ([]*models.Flashcard, error)
+	MoveToFilteredDeck(ids []uuid.UUID, deckID uuid.UUID) ([]*models.Flashcard, error)
+	ReturnToHomeDeck(ids []uuid.UUID) ([]*models.Flashcard, error)
+	EmptyFilteredDeck(deckID uuid.UUID) (int, error)
+	Delete(id uuid.UUID) error
+}
+
+// NoteTypeRepositoryInterface defines the interface for note type repository operations
+type NoteTypeRepositoryInterface interface {
+	Create(noteType *models.NoteType) (*models.NoteType, error)
+	GetByID(id uuid.UUID) (*models.NoteType, error)
+	GetByUser(userID uuid.UUID) ([]*models.NoteType, error)
+	Delete(id uuid.UUID) error
+}
+
+// NoteRepositoryInterface defines the interface for note repository operations
+type NoteRepositoryInterface interface {
+	Create(note *models.Note, cards []*models.Flashcard) (*models.Note, error)
+	GetByID(id uuid.UUID) (*models.Note, error)
+	UpdateContent(id uuid.UUID, fields models.NoteFields, occlusions models.Occlusions, cards models.NoteCardChanges) (*models.Note, error)
 	Delete(id uuid.UUID) error
+	CountByNoteType(noteTypeID uuid.UUID) (int, error)
+}
+
+// MediaRepositoryInterface defines the interface for media repository operations
+type MediaRepositoryInterface interface {
+	Create(media *models.Media, quota int64, store func() error) (*models.Media, error)
+	GetByUserAndHash(userID uuid.UUID, hash string) (*models.Media, error)
+	SumSizeByUser(userID uuid.UUID) (int64, error)
+	GetUnreferenced(before time.Time) ([]*models.Media, error)
+	DeleteUnreferenced(media *models.Media, removeFile func() error) (bool, error)
+}
+
+// ReviewLogRepositoryInterface defines the interface for review log repository operations
+type ReviewLogRepositoryInterface interface {
+	Create(log *models.ReviewLog) (*models.ReviewLog, error)
+	GetByFlashcard(flashcardID uuid.UUID) ([]*models.ReviewLog, error)
+	GetByUser(userID uuid.UUID) ([]*models.ReviewLog, error)
+	GetByUserSince(userID uuid.UUID, since time.Time) ([]*models.ReviewLog, error)
+	GetLatestByFlashcard(flashcardID uuid.UUID) (*models.ReviewLog, error)
+	CountUndoneBySession(sessionID uuid.UUID) (int, error)
+	MarkUndone(id uuid.UUID) error
+	CountStudiedSince(userID uuid.UUID, since time.Time) ([]*models.StudiedCount, error)
+	CountReviewOutcomes(userID uuid.UUID, since time.Time) ([]*models.ReviewOutcomeCount, error)
+}
+
+// StudySessionRepositoryInterface defines the interface for study session repository operations
+type StudySessionRepositoryInterface interface {
+	Create(session *models.StudySession) (*models.StudySession, error)
+	GetByID(id uuid.UUID) (*models.StudySession, error)
+	Update(session *models.StudySession) (*models.StudySession, error)
+	Modify(id uuid.UUID, change func(*models.StudySession) error) (*models.StudySession, error)
+}
+
+// OptimizerRunRepositoryInterface defines the interface for optimizer run repository operations
+type OptimizerRunRepositoryInterface interface {
+	Create(run *models.OptimizerRun) (*models.OptimizerRun, error)
+	GetByID(id uuid.UUID) (*models.OptimizerRun, error)
+	GetActiveByUser(userID uuid.UUID) (*models.OptimizerRun, error)
+	Update(run *models.OptimizerRun) (*models.OptimizerRun, error)
+	Heartbeat(ids []uuid.UUID) error
+	FailStale(staleAfter time.Duration, reason string) (int, error)
 }
 
 // RefreshTokenRepositoryInterface defines the interface for refresh token repository operations
@@ -0,0 +1,277 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+)
+
+type MediaRepository struct {
+	DB     *sql.DB
+	Logger *logrus.Logger
+}
+
+func NewMediaRepository(db *sql.DB, logger *logrus.Logger) *MediaRepository {
+	return &MediaRepository{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// mediaColumns lists the selected media columns in the order expected by scanMedia
+const mediaColumns = `id, user_id, hash, content_type, size, width, height, created_at`
+
+// scanMedia scans a row selected with mediaColumns
+func scanMedia(row rowScanner) (*models.Media, error) {
+	var media models.Media
+	err := row.Scan(
+		&media.ID, &media.UserID, &media.Hash, &media.ContentType, &media.Size,
+		&media.Width, &media.Height, &media.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+// Create records an uploaded media file and calls store to save the file, under locks
+// on the user and the hash: the media is only created if it fits in the user's quota,
+// and the garbage collector cannot delete the file meanwhile. Nothing is recorded when
+// store fails. Cards of the user that already reference its hash are linked to it.
+func (r *MediaRepository) Create(media *models.Media, quota int64, store func() error) (*models.Media, error) {
+	query := `
+        INSERT INTO media (id, user_id, hash, content_type, size, width, height, created_at)
+        SELECT $1, $2, $3, $4, $5, $6, $7, NOW()
+        WHERE (SELECT COALESCE(SUM(size), 0) FROM media WHERE user_id = $2) + $5 <= $8
+        RETURNING ` + mediaColumns
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Uploads of the user wait for each other so they cannot go over the quota together
+	if err := lockMedia(tx, mediaUserLock(media.UserID)); err != nil {
+		return nil, err
+	}
+	if err := lockMedia(tx, mediaHashLock(media.Hash)); err != nil {
+		return nil, err
+	}
+
+	created, err := scanMedia(tx.QueryRow(
+		query,
+		media.ID, media.UserID, media.Hash, media.ContentType, media.Size, media.Width, media.Height, quota,
+	))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("media quota exceeded")
+		}
+		r.Logger.WithError(err).WithField("user_id", media.UserID).Error("Failed to create media")
+		return nil, fmt.Errorf("failed to create media: %w", err)
+	}
+
+	_, err = tx.Exec(`
+        INSERT INTO flashcard_media (flashcard_id, media_id)
+        SELECT id, $2 FROM flashcards
+        WHERE user_id = $1 AND (front LIKE $3 OR back LIKE $3)
+        ON CONFLICT DO NOTHING`,
+		created.UserID, created.ID, "%"+models.MediaReferencePrefix+created.Hash+"%",
+	)
+	if err != nil {
+		r.Logger.WithError(err).WithField("media_id", created.ID).Error("Failed to link media to flashcards")
+		return nil, fmt.Errorf("failed to create media: %w", err)
+	}
+
+	if err := store(); err != nil {
+		return nil, fmt.Errorf("failed to store media file: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit media creation: %w", err)
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"media_id": created.ID,
+		"hash":     created.Hash,
+	}).Info("Media created successfully")
+	return created, nil
+}
+
+// mediaUserLock names the advisory lock taken on the media of a user
+func mediaUserLock(userID uuid.UUID) string {
+	return "media-user:" + userID.String()
+}
+
+// mediaHashLock names the advisory lock taken on the file stored under a hash
+func mediaHashLock(hash string) string {
+	return "media-hash:" + hash
+}
+
+// lockMedia takes an advisory lock until the end of the transaction
+func lockMedia(tx *sql.Tx, name string) error {
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, name); err != nil {
+		return fmt.Errorf("failed to lock media: %w", err)
+	}
+	return nil
+}
+
+// GetByUserAndHash retrieves the media a user uploaded with the given content hash
+func (r *MediaRepository) GetByUserAndHash(userID uuid.UUID, hash string) (*models.Media, error) {
+	query := `SELECT ` + mediaColumns + `
+        FROM media
+        WHERE user_id = $1 AND hash = $2
+    `
+
+	media, err := scanMedia(r.DB.QueryRow(query, userID, hash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("media not found")
+		}
+		r.Logger.WithError(err).WithField("hash", hash).Error("Failed to get media")
+		return nil, fmt.Errorf("failed to get media: %w", err)
+	}
+
+	return media, nil
+}
+
+// SumSizeByUser returns the total size in bytes of the media a user uploaded
+func (r *MediaRepository) SumSizeByUser(userID uuid.UUID) (int64, error) {
+	query := `SELECT COALESCE(SUM(size), 0) FROM media WHERE user_id = $1`
+
+	var total int64
+	if err := r.DB.QueryRow(query, userID).Scan(&total); err != nil {
+		r.Logger.WithError(err).WithField("user_id", userID).Error("Failed to sum media size")
+		return 0, fmt.Errorf("failed to sum media size: %w", err)
+	}
+
+	return total, nil
+}
+
+// GetUnreferenced retrieves media uploaded before the given time that no flashcard references
+func (r *MediaRepository) GetUnreferenced(before time.Time) ([]*models.Media, error) {
+	query := `SELECT ` + mediaColumns + `
+        FROM media m
+        WHERE created_at < $1
+          AND NOT EXISTS (SELECT 1 FROM flashcard_media fm WHERE fm.media_id = m.id)
+        ORDER BY created_at
+    `
+
+	rows, err := r.DB.Query(query, before)
+	if err != nil {
+		r.Logger.WithError(err).Error("Failed to get unreferenced media")
+		return nil, fmt.Errorf("failed to get unreferenced media: %w", err)
+	}
+	defer rows.Close()
+
+	var media []*models.Media
+	for rows.Next() {
+		m, err := scanMedia(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan media: %w", err)
+		}
+		media = append(media, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get unreferenced media: %w", err)
+	}
+
+	return media, nil
+}
+
+// DeleteUnreferenced removes a media record unless a flashcard references it by now,
+// and reports whether it was removed. When no other media points at its file anymore,
+// removeFile is called to delete it, under the lock uploads of the same hash take; the
+// record is kept if removeFile fails.
+func (r *MediaRepository) DeleteUnreferenced(media *models.Media, removeFile func() error) (bool, error) {
+	query := `
+        DELETE FROM media m
+        WHERE id = $1
+          AND NOT EXISTS (SELECT 1 FROM flashcard_media fm WHERE fm.media_id = m.id)
+    `
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockMedia(tx, mediaHashLock(media.Hash)); err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec(query, media.ID)
+	if err != nil {
+		r.Logger.WithError(err).WithField("media_id", media.ID).Error("Failed to delete media")
+		return false, fmt.Errorf("failed to delete media: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	var remaining int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM media WHERE hash = $1`, media.Hash).Scan(&remaining); err != nil {
+		r.Logger.WithError(err).WithField("hash", media.Hash).Error("Failed to count media")
+		return false, fmt.Errorf("failed to count media: %w", err)
+	}
+	if remaining == 0 {
+		if err := removeFile(); err != nil {
+			return false, fmt.Errorf("failed to delete orphaned media file: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit media deletion: %w", err)
+	}
+
+	r.Logger.WithField("media_id", media.ID).Info("Media deleted successfully")
+	return true, nil
+}
+
+// linkFlashcardMedia records which of the user's media a card references in its front
+// or back, replacing what was recorded before. References to media the user has not
+// uploaded are linked once it is uploaded.
+func linkFlashcardMedia(tx *sql.Tx, card *models.Flashcard) error {
+	if _, err := tx.Exec(`DELETE FROM flashcard_media WHERE flashcard_id = $1`, card.ID); err != nil {
+		return fmt.Errorf("failed to unlink media: %w", err)
+	}
+
+	hashes := models.MediaReferences(card.Front + "\n" + card.Back)
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	// Media being deleted holds the lock of its hash, so the link either sees it gone
+	// or keeps it. Locks are taken in order so that cards sharing media do not deadlock.
+	slices.Sort(hashes)
+	for _, hash := range hashes {
+		if err := lockMedia(tx, mediaHashLock(hash)); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec(`
+        INSERT INTO flashcard_media (flashcard_id, media_id)
+        SELECT $1, id FROM media
+        WHERE user_id = $2 AND hash = ANY($3)
+        ON CONFLICT DO NOTHING`,
+		card.ID, card.UserID, pq.Array(hashes),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to link media: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+// testMediaQuota lets tests create media without running into the quota
+const testMediaQuota = 1 << 30
+
+// storeNothing stands in for storing media files
+func storeNothing() error { return nil }
+
+func TestMediaRepository_CreateAndGet(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	user := testutils.CreateTestUser()
+	user.PasswordHash = "test_hash"
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	repo := NewMediaRepository(td.DB.DB, td.Logger)
+	hash := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	width, height := 640, 480
+
+	created, err := repo.Create(&models.Media{
+		ID:          uuid.New(),
+		UserID:      createdUser.ID,
+		Hash:        hash,
+		ContentType: "image/png",
+		Size:        2048,
+		Width:       &width,
+		Height:      &height,
+	}, testMediaQuota, storeNothing)
+	require.NoError(t, err)
+	assert.Equal(t, hash, created.Hash)
+
+	media, err := repo.GetByUserAndHash(createdUser.ID, hash)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, media.ID)
+	assert.Equal(t, int64(2048), media.Size)
+	require.NotNil(t, media.Width)
+	assert.Equal(t, 640, *media.Width)
+
+	// Media is only found for the user who uploaded it
+	_, err = repo.GetByUserAndHash(uuid.New(), hash)
+	assert.EqualError(t, err, "media not found")
+}
+
+func TestMediaRepository_SumSizeByUser(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	repo := NewMediaRepository(td.DB.DB, td.Logger)
+	hash := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	var userIDs []uuid.UUID
+	for _, size := range []int64{1000, 500} {
+		user := testutils.CreateTestUser()
+		user.PasswordHash = "test_hash"
+		createdUser, err := userRepo.Create(user)
+		require.NoError(t, err)
+		userIDs = append(userIDs, createdUser.ID)
+
+		_, err = repo.Create(&models.Media{ID: uuid.New(), UserID: createdUser.ID, Hash: hash, ContentType: "audio/mpeg", Size: size}, testMediaQuota, storeNothing)
+		require.NoError(t, err)
+	}
+
+	total, err := repo.SumSizeByUser(userIDs[0])
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), total)
+
+	total, err = repo.SumSizeByUser(uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+}
+
+func TestMediaRepository_Create_Quota(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	user := testutils.CreateTestUser()
+	user.PasswordHash = "test_hash"
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	repo := NewMediaRepository(td.DB.DB, td.Logger)
+	_, err = repo.Create(&models.Media{ID: uuid.New(), UserID: createdUser.ID, Hash: fmt.Sprintf("%064x", 1), ContentType: "audio/mpeg", Size: 600}, 1000, storeNothing)
+	require.NoError(t, err)
+
+	stored := false
+	_, err = repo.Create(&models.Media{ID: uuid.New(), UserID: createdUser.ID, Hash: fmt.Sprintf("%064x", 2), ContentType: "audio/mpeg", Size: 600}, 1000, func() error {
+		stored = true
+		return nil
+	})
+	assert.EqualError(t, err, "media quota exceeded")
+	assert.False(t, stored)
+
+	// Nothing is recorded when the file cannot be stored
+	_, err = repo.Create(&models.Media{ID: uuid.New(), UserID: createdUser.ID, Hash: fmt.Sprintf("%064x", 3), ContentType: "audio/mpeg", Size: 100}, 1000, func() error {
+		return fmt.Errorf("disk full")
+	})
+	assert.EqualError(t, err, "failed to store media file: disk full")
+
+	total, err := repo.SumSizeByUser(createdUser.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(600), total)
+}
+
+func TestMediaRepository_FlashcardReferences(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	user := testutils.CreateTestUser()
+	user.PasswordHash = "test_hash"
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	deckRepo := NewDeckRepository(td.DB.DB, td.Logger)
+	createdDeck, err := deckRepo.Create(testutils.CreateTestDeck(createdUser.ID))
+	require.NoError(t, err)
+
+	repo := NewMediaRepository(td.DB.DB, td.Logger)
+	flashcardRepo := NewFlashcardRepository(td.DB.DB, td.Logger)
+	hash := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	later := time.Now().Add(time.Hour)
+
+	// A card referencing media before it is uploaded is linked on upload
+	card := testutils.CreateTestFlashcard(createdUser.ID, createdDeck.ID)
+	card.Back = `<img src="media:` + hash + `">`
+	createdCard, err := flashcardRepo.Create(card)
+	require.NoError(t, err)
+
+	media, err := repo.Create(&models.Media{ID: uuid.New(), UserID: createdUser.ID, Hash: hash, ContentType: "image/png", Size: 10}, testMediaQuota, storeNothing)
+	require.NoError(t, err)
+
+	unreferenced, err := repo.GetUnreferenced(later)
+	require.NoError(t, err)
+	assert.Empty(t, unreferenced)
+
+	deleted, err := repo.DeleteUnreferenced(media, storeNothing)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	// Removing the reference leaves the media to the garbage collector
+	newBack := "no media"
+	_, err = flashcardRepo.Update(createdCard.ID, &models.UpdateFlashcardRequest{Back: &newBack})
+	require.NoError(t, err)
+
+	unreferenced, err = repo.GetUnreferenced(later)
+	require.NoError(t, err)
+	require.Len(t, unreferenced, 1)
+	assert.Equal(t, media.ID, unreferenced[0].ID)
+
+	// Media younger than the cutoff is kept
+	unreferenced, err = repo.GetUnreferenced(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, unreferenced)
+
+	fileRemoved := false
+	deleted, err = repo.DeleteUnreferenced(media, func() error {
+		fileRemoved = true
+		return nil
+	})
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	assert.True(t, fileRemoved)
+
+	_, err = repo.GetByUserAndHash(createdUser.ID, hash)
+	assert.EqualError(t, err, "media not found")
+}
@@ -0,0 +1,244 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+)
+
+type NoteRepository struct {
+	DB     *sql.DB
+	Logger *logrus.Logger
+}
+
+func NewNoteRepository(db *sql.DB, logger *logrus.Logger) *NoteRepository {
+	return &NoteRepository{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// noteColumns lists the selected note columns in the order expected by scanNote
+const noteColumns = `id, user_id, deck_id, note_type_id, fields, occlusions, created_at, updated_at`
+
+// scanNote scans a row selected with noteColumns
+func scanNote(row rowScanner) (*models.Note, error) {
+	var note models.Note
+	err := row.Scan(
+		&note.ID, &note.UserID, &note.DeckID, &note.NoteTypeID, &note.Fields, &note.Occlusions,
+		&note.CreatedAt, &note.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// Create inserts a new note together with its cards, so that a note is never saved
+// without them
+func (r *NoteRepository) Create(note *models.Note, cards []*models.Flashcard) (*models.Note, error) {
+	query := `
+        INSERT INTO notes (id, user_id, deck_id, note_type_id, fields, occlusions, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+        RETURNING ` + noteColumns
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	created, err := scanNote(tx.QueryRow(
+		query,
+		note.ID, note.UserID, note.DeckID, note.NoteTypeID, note.Fields, note.Occlusions,
+	))
+
+	if err != nil {
+		r.Logger.WithError(err).Error("Failed to create note")
+		return nil, fmt.Errorf("failed to create note: %w", err)
+	}
+
+	for _, card := range cards {
+		savedCard, err := insertFlashcard(tx, card)
+		if err != nil {
+			r.Logger.WithError(err).WithField("note_id", created.ID).Error("Failed to create note card")
+			return nil, fmt.Errorf("failed to create flashcard: %w", err)
+		}
+		created.Cards = append(created.Cards, savedCard)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit note creation: %w", err)
+	}
+
+	r.Logger.WithField("note_id", created.ID).Info("Note created successfully")
+	return created, nil
+}
+
+// GetByID retrieves a note by ID
+func (r *NoteRepository) GetByID(id uuid.UUID) (*models.Note, error) {
+	query := `SELECT ` + noteColumns + `
+        FROM notes
+        WHERE id = $1
+    `
+
+	note, err := scanNote(r.DB.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("note not found")
+		}
+		r.Logger.WithError(err).WithField("note_id", id).Error("Failed to get note")
+		return nil, fmt.Errorf("failed to get note: %w", err)
+	}
+
+	return note, nil
+}
+
+// UpdateContent replaces the field values and occlusions of a note and writes the
+// changes to its cards that go with them, all or none of them. The note is returned
+// with all of its cards.
+func (r *NoteRepository) UpdateContent(id uuid.UUID, fields models.NoteFields, occlusions models.Occlusions, cards models.NoteCardChanges) (*models.Note, error) {
+	query := `
+        UPDATE notes
+        SET fields = $2, occlusions = $3, updated_at = NOW()
+        WHERE id = $1
+        RETURNING ` + noteColumns
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	updated, err := scanNote(tx.QueryRow(query, id, fields, occlusions))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("note not found")
+		}
+		r.Logger.WithError(err).WithField("note_id", id).Error("Failed to update note")
+		return nil, fmt.Errorf("failed to update note: %w", err)
+	}
+
+	for _, card := range cards.Create {
+		if _, err := insertFlashcard(tx, card); err != nil {
+			r.Logger.WithError(err).WithField("note_id", id).Error("Failed to create note card")
+			return nil, fmt.Errorf("failed to create flashcard: %w", err)
+		}
+	}
+
+	for _, card := range cards.Update {
+		if err := updateNoteCard(tx, id, card); err != nil {
+			r.Logger.WithError(err).WithField("note_id", id).Error("Failed to update note card")
+			return nil, fmt.Errorf("failed to update flashcard: %w", err)
+		}
+	}
+
+	if len(cards.Delete) > 0 {
+		_, err := tx.Exec(`DELETE FROM flashcards WHERE note_id = $1 AND id = ANY($2::uuid[])`, id, pq.Array(cards.Delete))
+		if err != nil {
+			r.Logger.WithError(err).WithField("note_id", id).Error("Failed to delete note cards")
+			return nil, fmt.Errorf("failed to delete flashcards: %w", err)
+		}
+	}
+
+	updated.Cards, err = noteCards(tx, id)
+	if err != nil {
+		r.Logger.WithError(err).WithField("note_id", id).Error("Failed to get note cards")
+		return nil, fmt.Errorf("failed to get flashcards: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit note update: %w", err)
+	}
+
+	r.Logger.WithField("note_id", id).Info("Note updated successfully")
+	return updated, nil
+}
+
+// updateNoteCard saves the new front and back of a card of a note and links the media
+// they reference
+func updateNoteCard(tx *sql.Tx, noteID uuid.UUID, card *models.Flashcard) error {
+	query := `
+        UPDATE flashcards
+        SET front = $3, back = $4, front_html = $5, back_html = $6, updated_at = NOW()
+        WHERE id = $1 AND note_id = $2
+        RETURNING ` + flashcardColumns
+
+	renderFlashcard(card)
+	updated, err := scanFlashcard(tx.QueryRow(query, card.ID, noteID, card.Front, card.Back, card.FrontHTML, card.BackHTML))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("flashcard not found")
+		}
+		return err
+	}
+
+	return linkFlashcardMedia(tx, updated)
+}
+
+// noteCards retrieves the cards of a note in tx, ordered by ord
+func noteCards(tx *sql.Tx, noteID uuid.UUID) ([]*models.Flashcard, error) {
+	query := `SELECT ` + flashcardColumns + `
+        FROM flashcards
+        WHERE note_id = $1
+        ORDER BY ord
+    `
+
+	rows, err := tx.Query(query, noteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cards []*models.Flashcard
+	for rows.Next() {
+		card, err := scanFlashcard(rows)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+	return cards, rows.Err()
+}
+
+// Delete removes a note together with its cards
+func (r *NoteRepository) Delete(id uuid.UUID) error {
+	query := `DELETE FROM notes WHERE id = $1`
+
+	result, err := r.DB.Exec(query, id)
+	if err != nil {
+		r.Logger.WithError(err).WithField("note_id", id).Error("Failed to delete note")
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("note not found")
+	}
+
+	r.Logger.WithField("note_id", id).Info("Note deleted successfully")
+	return nil
+}
+
+// CountByNoteType returns how many notes use a note type
+func (r *NoteRepository) CountByNoteType(noteTypeID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM notes WHERE note_type_id = $1`
+
+	var count int
+	err := r.DB.QueryRow(query, noteTypeID).Scan(&count)
+	if err != nil {
+		r.Logger.WithError(err).WithField("note_type_id", noteTypeID).Error("Failed to count notes of note type")
+		return 0, fmt.Errorf("failed to count notes: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+func TestNoteRepository_CRUD(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	user := testutils.CreateTestUser()
+	user.PasswordHash = "test_hash"
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	deckRepo := NewDeckRepository(td.DB.DB, td.Logger)
+	createdDeck, err := deckRepo.Create(testutils.CreateTestDeck(createdUser.ID))
+	require.NoError(t, err)
+
+	noteTypeRepo := NewNoteTypeRepository(td.DB.DB, td.Logger)
+	repo := NewNoteRepository(td.DB.DB, td.Logger)
+	flashcardRepo := NewFlashcardRepository(td.DB.DB, td.Logger)
+
+	// Built-in note types come first and are shared by every user
+	noteTypes, err := noteTypeRepo.GetByUser(createdUser.ID)
+	require.NoError(t, err)
+	require.Len(t, noteTypes, 4)
+	assert.Equal(t, models.BasicNoteTypeID, noteTypes[0].ID)
+	assert.Nil(t, noteTypes[0].UserID)
+	assert.Len(t, noteTypes[1].Templates, 2)
+	assert.Equal(t, models.ClozeNoteTypeID, noteTypes[2].ID)
+	assert.Equal(t, models.NoteTypeKindCloze, noteTypes[2].Kind)
+	assert.Equal(t, models.NoteTypeKindImageOcclusion, noteTypes[3].Kind)
+
+	noteType, err := noteTypeRepo.Create(&models.NoteType{
+		ID:        uuid.New(),
+		UserID:    &createdUser.ID,
+		Name:      "Vocabulary",
+		Kind:      models.NoteTypeKindStandard,
+		Fields:    models.NoteFieldList{"Word", "Meaning"},
+		Templates: models.CardTemplates{{Name: "Card 1", Front: "{{Word}}", Back: "{{Meaning}}"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.NoteFieldList{"Word", "Meaning"}, noteType.Fields)
+
+	noteID := uuid.New()
+	var newCards []*models.Flashcard
+	for ord := range 3 {
+		card := testutils.CreateTestFlashcard(createdUser.ID, createdDeck.ID)
+		card.NoteID = &noteID
+		card.Ord = &ord
+		newCards = append(newCards, card)
+	}
+	note, err := repo.Create(&models.Note{
+		ID:         noteID,
+		UserID:     createdUser.ID,
+		DeckID:     createdDeck.ID,
+		NoteTypeID: noteType.ID,
+		Fields:     models.NoteFields{"Word": "Hund", "Meaning": "dgo"},
+	}, newCards[:2])
+	require.NoError(t, err)
+	assert.Len(t, note.Cards, 2)
+
+	cards, err := flashcardRepo.GetByNote(note.ID)
+	require.NoError(t, err)
+	require.Len(t, cards, 2)
+	assert.Equal(t, 0, *cards[0].Ord)
+	assert.Equal(t, note.ID, *cards[1].NoteID)
+
+	assert.Empty(t, note.Occlusions)
+
+	occlusions := models.Occlusions{{Index: 1, Shape: models.OcclusionShapeRect, Left: 0.1, Top: 0.2, Width: 0.3, Height: 0.4}}
+	rendered := *cards[1]
+	rendered.Back = "dog"
+	updated, err := repo.UpdateContent(note.ID, models.NoteFields{"Word": "Hund", "Meaning": "dog"}, occlusions, models.NoteCardChanges{
+		Create: newCards[2:],
+		Update: []*models.Flashcard{&rendered},
+		Delete: []uuid.UUID{cards[0].ID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "dog", updated.Fields["Meaning"])
+	assert.Equal(t, occlusions, updated.Occlusions)
+	require.Len(t, updated.Cards, 2)
+	assert.Equal(t, cards[1].ID, updated.Cards[0].ID)
+	assert.Equal(t, "dog", updated.Cards[0].Back)
+	assert.Equal(t, 2, *updated.Cards[1].Ord)
+
+	// A failing card write leaves the note as it was
+	_, err = repo.UpdateContent(note.ID, models.NoteFields{"Word": "Katze"}, nil, models.NoteCardChanges{Create: newCards[2:]})
+	require.Error(t, err)
+	unchanged, err := repo.GetByID(note.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Hund", unchanged.Fields["Word"])
+
+	count, err := repo.CountByNoteType(noteType.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// Deleting a note deletes its cards
+	require.NoError(t, repo.Delete(note.ID))
+	cards, err = flashcardRepo.GetByNote(note.ID)
+	require.NoError(t, err)
+	assert.Empty(t, cards)
+
+	_, err = repo.GetByID(note.ID)
+	assert.EqualError(t, err, "note not found")
+
+	require.NoError(t, noteTypeRepo.Delete(noteType.ID))
+	_, err = noteTypeRepo.GetByID(noteType.ID)
+	assert.EqualError(t, err, "note type not found")
+}
@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+)
+
+type NoteTypeRepository struct {
+	DB     *sql.DB
+	Logger *logrus.Logger
+}
+
+func NewNoteTypeRepository(db *sql.DB, logger *logrus.Logger) *NoteTypeRepository {
+	return &NoteTypeRepository{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// noteTypeColumns lists the selected note type columns in the order expected by scanNoteType
+const noteTypeColumns = `id, user_id, name, kind, fields, templates, created_at, updated_at`
+
+// scanNoteType scans a row selected with noteTypeColumns
+func scanNoteType(row rowScanner) (*models.NoteType, error) {
+	var noteType models.NoteType
+	err := row.Scan(
+		&noteType.ID, &noteType.UserID, &noteType.Name, &noteType.Kind, &noteType.Fields, &noteType.Templates,
+		&noteType.CreatedAt, &noteType.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &noteType, nil
+}
+
+// Create inserts a new note type
+func (r *NoteTypeRepository) Create(noteType *models.NoteType) (*models.NoteType, error) {
+	query := `
+        INSERT INTO note_types (id, user_id, name, kind, fields, templates, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+        RETURNING ` + noteTypeColumns
+
+	created, err := scanNoteType(r.DB.QueryRow(
+		query,
+		noteType.ID, noteType.UserID, noteType.Name, noteType.Kind, noteType.Fields, noteType.Templates,
+	))
+
+	if err != nil {
+		r.Logger.WithError(err).Error("Failed to create note type")
+		return nil, fmt.Errorf("failed to create note type: %w", err)
+	}
+
+	r.Logger.WithField("note_type_id", created.ID).Info("Note type created successfully")
+	return created, nil
+}
+
+// GetByID retrieves a note type by ID
+func (r *NoteTypeRepository) GetByID(id uuid.UUID) (*models.NoteType, error) {
+	query := `SELECT ` + noteTypeColumns + `
+        FROM note_types
+        WHERE id = $1
+    `
+
+	noteType, err := scanNoteType(r.DB.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("note type not found")
+		}
+		r.Logger.WithError(err).WithField("note_type_id", id).Error("Failed to get note type")
+		return nil, fmt.Errorf("failed to get note type: %w", err)
+	}
+
+	return noteType, nil
+}
+
+// GetByUser retrieves the built-in note types followed by those of a user, ordered by name
+func (r *NoteTypeRepository) GetByUser(userID uuid.UUID) ([]*models.NoteType, error) {
+	query := `SELECT ` + noteTypeColumns + `
+        FROM note_types
+        WHERE user_id = $1 OR user_id IS NULL
+        ORDER BY user_id NULLS FIRST, name
+    `
+
+	rows, err := r.DB.Query(query, userID)
+	if err != nil {
+		r.Logger.WithError(err).WithField("user_id", userID).Error("Failed to get note types for user")
+		return nil, fmt.Errorf("failed to get note types: %w", err)
+	}
+	defer rows.Close()
+
+	var noteTypes []*models.NoteType
+	for rows.Next() {
+		noteType, err := scanNoteType(rows)
+		if err != nil {
+			r.Logger.WithError(err).Error("Failed to scan note type")
+			return nil, fmt.Errorf("failed to scan note type: %w", err)
+		}
+		noteTypes = append(noteTypes, noteType)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.Logger.WithError(err).Error("Error iterating note type rows")
+		return nil, fmt.Errorf("failed to iterate note types: %w", err)
+	}
+
+	return noteTypes, nil
+}
+
+// Delete removes a note type. Note types still used by notes cannot be deleted.
+func (r *NoteTypeRepository) Delete(id uuid.UUID) error {
+	query := `DELETE FROM note_types WHERE id = $1`
+
+	result, err := r.DB.Exec(query, id)
+	if err != nil {
+		r.Logger.WithError(err).WithField("note_type_id", id).Error("Failed to delete note type")
+		return fmt.Errorf("failed to delete note type: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("note type not found")
+	}
+
+	r.Logger.WithField("note_type_id", id).Info("Note type deleted successfully")
+	return nil
+}
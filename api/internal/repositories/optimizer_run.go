@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+)
+
+type OptimizerRunRepository struct {
+	DB     *sql.DB
+	Logger *logrus.Logger
+}
+
+func NewOptimizerRunRepository(db *sql.DB, logger *logrus.Logger) *OptimizerRunRepository {
+	return &OptimizerRunRepository{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// optimizerRunColumns lists the selected run columns in the order expected by scanOptimizerRun
+const optimizerRunColumns = `id, user_id, status, review_count, log_loss_before, log_loss_after, rmse_before, rmse_after,
+               weights, error, created_at, started_at, finished_at, heartbeat_at`
+
+// scanOptimizerRun scans a row selected with optimizerRunColumns
+func scanOptimizerRun(row rowScanner) (*models.OptimizerRun, error) {
+	var run models.OptimizerRun
+	err := row.Scan(
+		&run.ID, &run.UserID, &run.Status, &run.ReviewCount,
+		&run.LogLossBefore, &run.LogLossAfter, &run.RMSEBefore, &run.RMSEAfter,
+		pq.Array(&run.Weights), &run.Error, &run.CreatedAt, &run.StartedAt, &run.FinishedAt, &run.HeartbeatAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// Create inserts a new optimizer run
+func (r *OptimizerRunRepository) Create(run *models.OptimizerRun) (*models.OptimizerRun, error) {
+	query := `
+        INSERT INTO optimizer_runs (id, user_id, status, created_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING ` + optimizerRunColumns
+
+	created, err := scanOptimizerRun(r.DB.QueryRow(query, run.ID, run.UserID, run.Status, run.CreatedAt))
+	if err != nil {
+		// The partial unique index allows one unfinished run per user, so a concurrent
+		// start loses here even if it passed the active run check
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" && pqErr.Constraint == "idx_optimizer_runs_active" {
+			return nil, fmt.Errorf("optimizer run already in progress")
+		}
+		r.Logger.WithError(err).WithField("user_id", run.UserID).Error("Failed to create optimizer run")
+		return nil, fmt.Errorf("failed to create optimizer run: %w", err)
+	}
+
+	r.Logger.WithField("run_id", created.ID).Info("Optimizer run created successfully")
+	return created, nil
+}
+
+// GetByID retrieves an optimizer run by ID
+func (r *OptimizerRunRepository) GetByID(id uuid.UUID) (*models.OptimizerRun, error) {
+	query := `SELECT ` + optimizerRunColumns + `
+        FROM optimizer_runs
+        WHERE id = $1
+    `
+
+	run, err := scanOptimizerRun(r.DB.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("optimizer run not found")
+		}
+		r.Logger.WithError(err).WithField("run_id", id).Error("Failed to get optimizer run")
+		return nil, fmt.Errorf("failed to get optimizer run: %w", err)
+	}
+
+	return run, nil
+}
+
+// GetActiveByUser retrieves the pending or running optimizer run of a user
+func (r *OptimizerRunRepository) GetActiveByUser(userID uuid.UUID) (*models.OptimizerRun, error) {
+	query := `SELECT ` + optimizerRunColumns + `
+        FROM optimizer_runs
+        WHERE user_id = $1 AND status IN ('pending', 'running')
+    `
+
+	run, err := scanOptimizerRun(r.DB.QueryRow(query, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("optimizer run not found")
+		}
+		r.Logger.WithError(err).WithField("user_id", userID).Error("Failed to get active optimizer run")
+		return nil, fmt.Errorf("failed to get optimizer run: %w", err)
+	}
+
+	return run, nil
+}
+
+// Update saves the status and results of an optimizer run
+func (r *OptimizerRunRepository) Update(run *models.OptimizerRun) (*models.OptimizerRun, error) {
+	query := `
+        UPDATE optimizer_runs
+        SET status = $2, review_count = $3, log_loss_before = $4, log_loss_after = $5,
+            rmse_before = $6, rmse_after = $7, weights = $8, error = $9, started_at = $10, finished_at = $11
+        WHERE id = $1
+        RETURNING ` + optimizerRunColumns
+
+	updated, err := scanOptimizerRun(r.DB.QueryRow(
+		query,
+		run.ID, run.Status, run.ReviewCount, run.LogLossBefore, run.LogLossAfter,
+		run.RMSEBefore, run.RMSEAfter, pq.Array(run.Weights), run.Error, run.StartedAt, run.FinishedAt,
+	))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("optimizer run not found")
+		}
+		r.Logger.WithError(err).WithField("run_id", run.ID).Error("Failed to update optimizer run")
+		return nil, fmt.Errorf("failed to update optimizer run: %w", err)
+	}
+
+	return updated, nil
+}
+
+// Heartbeat marks the given unfinished optimizer runs as still alive
+func (r *OptimizerRunRepository) Heartbeat(ids []uuid.UUID) error {
+	query := `
+        UPDATE optimizer_runs
+        SET heartbeat_at = NOW()
+        WHERE id = ANY($1) AND status IN ('pending', 'running')
+    `
+
+	if _, err := r.DB.Exec(query, pq.Array(ids)); err != nil {
+		r.Logger.WithError(err).WithField("run_count", len(ids)).Error("Failed to record optimizer run heartbeat")
+		return fmt.Errorf("failed to update optimizer runs: %w", err)
+	}
+
+	return nil
+}
+
+// FailStale marks every pending or running optimizer run without a heartbeat for
+// longer than staleAfter as failed and returns how many there were
+func (r *OptimizerRunRepository) FailStale(staleAfter time.Duration, reason string) (int, error) {
+	query := `
+        UPDATE optimizer_runs
+        SET status = 'failed', error = $1, finished_at = NOW()
+        WHERE status IN ('pending', 'running') AND heartbeat_at < NOW() - make_interval(secs => $2)
+    `
+
+	result, err := r.DB.Exec(query, reason, staleAfter.Seconds())
+	if err != nil {
+		r.Logger.WithError(err).Error("Failed to fail stale optimizer runs")
+		return 0, fmt.Errorf("failed to update optimizer runs: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
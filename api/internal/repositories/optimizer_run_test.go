@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+func TestOptimizerRunRepository_Lifecycle(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	user := testutils.CreateTestUser()
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	repo := NewOptimizerRunRepository(td.DB.DB, td.Logger)
+
+	_, err = repo.GetActiveByUser(createdUser.ID)
+	assert.EqualError(t, err, "optimizer run not found")
+
+	created, err := repo.Create(&models.OptimizerRun{
+		ID:        uuid.New(),
+		UserID:    createdUser.ID,
+		Status:    models.OptimizerRunPending,
+		CreatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	assert.Nil(t, created.Weights)
+
+	// Only one unfinished run per user
+	_, err = repo.Create(&models.OptimizerRun{ID: uuid.New(), UserID: createdUser.ID, Status: models.OptimizerRunPending, CreatedAt: time.Now()})
+	assert.EqualError(t, err, "optimizer run already in progress")
+
+	active, err := repo.GetActiveByUser(createdUser.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, active.ID)
+
+	logLoss, rmse := 0.35, 0.28
+	finishedAt := time.Now()
+	created.Status = models.OptimizerRunSucceeded
+	created.ReviewCount = 1200
+	created.LogLossAfter = &logLoss
+	created.RMSEAfter = &rmse
+	created.Weights = []float64{0.4, 1.4, 3.7, 13.8}
+	created.FinishedAt = &finishedAt
+
+	updated, err := repo.Update(created)
+	require.NoError(t, err)
+	assert.Equal(t, models.OptimizerRunSucceeded, updated.Status)
+	assert.Equal(t, 1200, updated.ReviewCount)
+	assert.Equal(t, created.Weights, updated.Weights)
+	assert.InDelta(t, 0.35, *updated.LogLossAfter, 1e-9)
+	assert.Nil(t, updated.LogLossBefore)
+
+	_, err = repo.GetActiveByUser(createdUser.ID)
+	assert.EqualError(t, err, "optimizer run not found")
+}
+
+func TestOptimizerRunRepository_FailStale(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	user := testutils.CreateTestUser()
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	otherUser := testutils.CreateTestUser()
+	otherUser.Email = "other@example.com"
+	createdOther, err := userRepo.Create(otherUser)
+	require.NoError(t, err)
+
+	repo := NewOptimizerRunRepository(td.DB.DB, td.Logger)
+	stale, err := repo.Create(&models.OptimizerRun{ID: uuid.New(), UserID: createdUser.ID, Status: models.OptimizerRunPending, CreatedAt: time.Now()})
+	require.NoError(t, err)
+	alive, err := repo.Create(&models.OptimizerRun{ID: uuid.New(), UserID: createdOther.ID, Status: models.OptimizerRunPending, CreatedAt: time.Now()})
+	require.NoError(t, err)
+
+	_, err = td.DB.Exec(`UPDATE optimizer_runs SET heartbeat_at = NOW() - INTERVAL '10 minutes' WHERE id = $1`, stale.ID)
+	require.NoError(t, err)
+	require.NoError(t, repo.Heartbeat([]uuid.UUID{alive.ID}))
+
+	count, err := repo.FailStale(2*time.Minute, "interrupted: server running it stopped")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	failed, err := repo.GetByID(stale.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OptimizerRunFailed, failed.Status)
+	require.NotNil(t, failed.Error)
+	assert.Equal(t, "interrupted: server running it stopped", *failed.Error)
+	assert.NotNil(t, failed.FinishedAt)
+
+	running, err := repo.GetByID(alive.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OptimizerRunPending, running.Status)
+}
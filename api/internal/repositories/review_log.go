@@ -0,0 +1,300 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+)
+
+type ReviewLogRepository struct {
+	DB     *sql.DB
+	Logger *logrus.Logger
+}
+
+func NewReviewLogRepository(db *sql.DB, logger *logrus.Logger) *ReviewLogRepository {
+	return &ReviewLogRepository{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// reviewLogColumns lists the selected review log columns in the order expected by scanReviewLog
+const reviewLogColumns = `id, flashcard_id, user_id, deck_id, scheduler, quality, time_taken_ms,
+               state_before, state_after, session_id, reviewed_at, undone_at, cram, buried_sibling_ids,
+               returned_from_deck_id`
+
+// scanReviewLog scans a row selected with reviewLogColumns
+func scanReviewLog(row rowScanner) (*models.ReviewLog, error) {
+	var log models.ReviewLog
+	err := row.Scan(
+		&log.ID, &log.FlashcardID, &log.UserID, &log.DeckID, &log.Scheduler, &log.Quality, &log.TimeTakenMs,
+		&log.StateBefore, &log.StateAfter, &log.SessionID, &log.ReviewedAt, &log.UndoneAt, &log.Cram,
+		pq.Array(&log.BuriedSiblingIDs), &log.ReturnedFromDeckID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+// Create inserts a new review log entry
+func (r *ReviewLogRepository) Create(log *models.ReviewLog) (*models.ReviewLog, error) {
+	query := `
+        INSERT INTO review_logs (id, flashcard_id, user_id, deck_id, scheduler, quality, time_taken_ms,
+                                 state_before, state_after, session_id, reviewed_at, cram, buried_sibling_ids,
+                                 returned_from_deck_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, COALESCE($13::uuid[], '{}'), $14)
+        RETURNING ` + reviewLogColumns
+
+	created, err := scanReviewLog(r.DB.QueryRow(
+		query,
+		log.ID, log.FlashcardID, log.UserID, log.DeckID, log.Scheduler, log.Quality, log.TimeTakenMs,
+		log.StateBefore, log.StateAfter, log.SessionID, log.ReviewedAt, log.Cram, pq.Array(log.BuriedSiblingIDs),
+		log.ReturnedFromDeckID,
+	))
+
+	if err != nil {
+		r.Logger.WithError(err).WithField("flashcard_id", log.FlashcardID).Error("Failed to create review log")
+		return nil, fmt.Errorf("failed to create review log: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetByFlashcard retrieves the review history of a flashcard, newest first
+func (r *ReviewLogRepository) GetByFlashcard(flashcardID uuid.UUID) ([]*models.ReviewLog, error) {
+	query := `SELECT ` + reviewLogColumns + `
+        FROM review_logs
+        WHERE flashcard_id = $1
+        ORDER BY reviewed_at DESC
+    `
+
+	rows, err := r.DB.Query(query, flashcardID)
+	if err != nil {
+		r.Logger.WithError(err).WithField("flashcard_id", flashcardID).Error("Failed to get review logs for flashcard")
+		return nil, fmt.Errorf("failed to get review logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []*models.ReviewLog
+	for rows.Next() {
+		log, err := scanReviewLog(rows)
+		if err != nil {
+			r.Logger.WithError(err).Error("Failed to scan review log")
+			return nil, fmt.Errorf("failed to scan review log: %w", err)
+		}
+		logs = append(logs, log)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.Logger.WithError(err).Error("Error iterating review log rows")
+		return nil, fmt.Errorf("failed to iterate review logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// GetByUser retrieves every review of a user that was not undone, card by card and
+// oldest first within each card, so a card's history can be replayed in order. Cram
+// answers did not change the schedule and are left out.
+func (r *ReviewLogRepository) GetByUser(userID uuid.UUID) ([]*models.ReviewLog, error) {
+	query := `SELECT ` + reviewLogColumns + `
+        FROM review_logs
+        WHERE user_id = $1 AND undone_at IS NULL AND NOT cram
+        ORDER BY flashcard_id, reviewed_at
+    `
+
+	rows, err := r.DB.Query(query, userID)
+	if err != nil {
+		r.Logger.WithError(err).WithField("user_id", userID).Error("Failed to get review logs for user")
+		return nil, fmt.Errorf("failed to get review logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []*models.ReviewLog
+	for rows.Next() {
+		log, err := scanReviewLog(rows)
+		if err != nil {
+			r.Logger.WithError(err).Error("Failed to scan review log")
+			return nil, fmt.Errorf("failed to scan review log: %w", err)
+		}
+		logs = append(logs, log)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.Logger.WithError(err).Error("Error iterating review log rows")
+		return nil, fmt.Errorf("failed to iterate review logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// GetByUserSince retrieves the reviews a user gave since the given time that were not
+// undone. Cram answers did not rate the card and are left out.
+func (r *ReviewLogRepository) GetByUserSince(userID uuid.UUID, since time.Time) ([]*models.ReviewLog, error) {
+	query := `SELECT ` + reviewLogColumns + `
+        FROM review_logs
+        WHERE user_id = $1 AND reviewed_at >= $2 AND undone_at IS NULL AND NOT cram
+        ORDER BY reviewed_at
+    `
+
+	rows, err := r.DB.Query(query, userID, since)
+	if err != nil {
+		r.Logger.WithError(err).WithField("user_id", userID).Error("Failed to get recent review logs for user")
+		return nil, fmt.Errorf("failed to get review logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []*models.ReviewLog
+	for rows.Next() {
+		log, err := scanReviewLog(rows)
+		if err != nil {
+			r.Logger.WithError(err).Error("Failed to scan review log")
+			return nil, fmt.Errorf("failed to scan review log: %w", err)
+		}
+		logs = append(logs, log)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.Logger.WithError(err).Error("Error iterating review log rows")
+		return nil, fmt.Errorf("failed to iterate review logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// GetLatestByFlashcard retrieves the most recent review of a flashcard that has not been undone
+func (r *ReviewLogRepository) GetLatestByFlashcard(flashcardID uuid.UUID) (*models.ReviewLog, error) {
+	query := `SELECT ` + reviewLogColumns + `
+        FROM review_logs
+        WHERE flashcard_id = $1 AND undone_at IS NULL
+        ORDER BY reviewed_at DESC
+        LIMIT 1
+    `
+
+	log, err := scanReviewLog(r.DB.QueryRow(query, flashcardID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("review log not found")
+		}
+		r.Logger.WithError(err).WithField("flashcard_id", flashcardID).Error("Failed to get latest review log")
+		return nil, fmt.Errorf("failed to get review log: %w", err)
+	}
+
+	return log, nil
+}
+
+// CountUndoneBySession returns how many reviews have been undone in a study session
+func (r *ReviewLogRepository) CountUndoneBySession(sessionID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM review_logs WHERE session_id = $1 AND undone_at IS NOT NULL`
+
+	var count int
+	err := r.DB.QueryRow(query, sessionID).Scan(&count)
+	if err != nil {
+		r.Logger.WithError(err).WithField("session_id", sessionID).Error("Failed to count undone reviews")
+		return 0, fmt.Errorf("failed to count undone reviews: %w", err)
+	}
+
+	return count, nil
+}
+
+// MarkUndone flags a review as reverted
+func (r *ReviewLogRepository) MarkUndone(id uuid.UUID) error {
+	query := `UPDATE review_logs SET undone_at = NOW() WHERE id = $1 AND undone_at IS NULL`
+
+	result, err := r.DB.Exec(query, id)
+	if err != nil {
+		r.Logger.WithError(err).WithField("review_log_id", id).Error("Failed to mark review as undone")
+		return fmt.Errorf("failed to mark review as undone: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("review log not found")
+	}
+
+	return nil
+}
+
+// CountStudiedSince counts, per deck, the new cards and reviews a user answered since the given time.
+// Answers on learning and relearning steps are not counted, and neither are undone or cram reviews.
+func (r *ReviewLogRepository) CountStudiedSince(userID uuid.UUID, since time.Time) ([]*models.StudiedCount, error) {
+	query := `
+        SELECT deck_id,
+               COUNT(*) FILTER (WHERE state_before->>'state' = 'new') AS new,
+               COUNT(*) FILTER (WHERE state_before->>'state' = 'review') AS reviews
+        FROM review_logs
+        WHERE user_id = $1 AND reviewed_at >= $2 AND undone_at IS NULL AND NOT cram
+        GROUP BY deck_id
+    `
+
+	rows, err := r.DB.Query(query, userID, since)
+	if err != nil {
+		r.Logger.WithError(err).WithField("user_id", userID).Error("Failed to count studied cards")
+		return nil, fmt.Errorf("failed to count studied cards: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []*models.StudiedCount
+	for rows.Next() {
+		var count models.StudiedCount
+		if err := rows.Scan(&count.DeckID, &count.New, &count.Reviews); err != nil {
+			r.Logger.WithError(err).Error("Failed to scan studied count")
+			return nil, fmt.Errorf("failed to scan studied count: %w", err)
+		}
+		counts = append(counts, &count)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.Logger.WithError(err).Error("Error iterating studied count rows")
+		return nil, fmt.Errorf("failed to iterate studied counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// CountReviewOutcomes counts, per deck, the answers a user gave since the given time to cards
+// in review and how many of them were recalled. Undone and cram reviews are not counted.
+func (r *ReviewLogRepository) CountReviewOutcomes(userID uuid.UUID, since time.Time) ([]*models.ReviewOutcomeCount, error) {
+	query := `
+        SELECT deck_id, COUNT(*) AS reviews, COUNT(*) FILTER (WHERE quality >= 3) AS recalled
+        FROM review_logs
+        WHERE user_id = $1 AND reviewed_at >= $2 AND undone_at IS NULL AND NOT cram
+          AND state_before->>'state' = 'review'
+        GROUP BY deck_id
+    `
+
+	rows, err := r.DB.Query(query, userID, since)
+	if err != nil {
+		r.Logger.WithError(err).WithField("user_id", userID).Error("Failed to count review outcomes")
+		return nil, fmt.Errorf("failed to count review outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []*models.ReviewOutcomeCount
+	for rows.Next() {
+		var count models.ReviewOutcomeCount
+		if err := rows.Scan(&count.DeckID, &count.Reviews, &count.Recalled); err != nil {
+			r.Logger.WithError(err).Error("Failed to scan review outcome count")
+			return nil, fmt.Errorf("failed to scan review outcome count: %w", err)
+		}
+		counts = append(counts, &count)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.Logger.WithError(err).Error("Error iterating review outcome rows")
+		return nil, fmt.Errorf("failed to iterate review outcomes: %w", err)
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+func TestReviewLogRepository_CreateAndGetByFlashcard(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	// Create user, deck, and flashcard
+	user := testutils.CreateTestUser()
+	user.PasswordHash = "test_hash"
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	deckRepo := NewDeckRepository(td.DB.DB, td.Logger)
+	createdDeck, err := deckRepo.Create(testutils.CreateTestDeck(createdUser.ID))
+	require.NoError(t, err)
+
+	flashcardRepo := NewFlashcardRepository(td.DB.DB, td.Logger)
+	createdFlashcard, err := flashcardRepo.Create(testutils.CreateTestFlashcard(createdUser.ID, createdDeck.ID))
+	require.NoError(t, err)
+
+	repo := NewReviewLogRepository(td.DB.DB, td.Logger)
+
+	nextReview := time.Now().Add(24 * time.Hour)
+	older := &models.ReviewLog{
+		ID:          uuid.New(),
+		FlashcardID: createdFlashcard.ID,
+		UserID:      createdUser.ID,
+		DeckID:      createdDeck.ID,
+		Scheduler:   models.SchedulerSM2,
+		Quality:     4,
+		TimeTakenMs: 2500,
+		StateBefore: createdFlashcard.SchedulingState(),
+		StateAfter:  models.SchedulingState{Interval: 1, EaseFactor: 2.5, ReviewCount: 1, NextReview: &nextReview},
+		ReviewedAt:  time.Now().Add(-time.Minute),
+	}
+	newer := *older
+	newer.ID = uuid.New()
+	newer.Quality = 1
+	newer.ReviewedAt = time.Now()
+
+	createdLog, err := repo.Create(older)
+	require.NoError(t, err)
+	assert.Equal(t, older.ID, createdLog.ID)
+	assert.Equal(t, 1, createdLog.StateAfter.ReviewCount)
+	require.NotNil(t, createdLog.StateAfter.NextReview)
+
+	_, err = repo.Create(&newer)
+	require.NoError(t, err)
+
+	logs, err := repo.GetByFlashcard(createdFlashcard.ID)
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+
+	// Newest review first
+	assert.Equal(t, newer.ID, logs[0].ID)
+	assert.Equal(t, older.ID, logs[1].ID)
+	assert.Equal(t, 2500, logs[1].TimeTakenMs)
+	assert.Equal(t, createdFlashcard.EaseFactor, logs[1].StateBefore.EaseFactor)
+}
+
+func TestReviewLogRepository_CountReviewOutcomes(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	user := testutils.CreateTestUser()
+	user.PasswordHash = "test_hash"
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	deckRepo := NewDeckRepository(td.DB.DB, td.Logger)
+	createdDeck, err := deckRepo.Create(testutils.CreateTestDeck(createdUser.ID))
+	require.NoError(t, err)
+
+	flashcardRepo := NewFlashcardRepository(td.DB.DB, td.Logger)
+	createdFlashcard, err := flashcardRepo.Create(testutils.CreateTestFlashcard(createdUser.ID, createdDeck.ID))
+	require.NoError(t, err)
+
+	repo := NewReviewLogRepository(td.DB.DB, td.Logger)
+
+	review := createdFlashcard.SchedulingState()
+	review.State = models.CardStateReview
+	learning := createdFlashcard.SchedulingState()
+	learning.State = models.CardStateLearning
+
+	logs := []struct {
+		stateBefore models.SchedulingState
+		quality     int
+		reviewedAt  time.Time
+		cram        bool
+	}{
+		{review, 4, time.Now(), false},
+		{review, 3, time.Now(), false},
+		{review, 1, time.Now(), false},
+		{learning, 4, time.Now(), false},                  // Not a review
+		{review, 4, time.Now().AddDate(0, 0, -40), false}, // Too old
+		{review, 1, time.Now(), true},                     // Crammed
+	}
+	for _, l := range logs {
+		_, err := repo.Create(&models.ReviewLog{
+			ID:          uuid.New(),
+			FlashcardID: createdFlashcard.ID,
+			UserID:      createdUser.ID,
+			DeckID:      createdDeck.ID,
+			Scheduler:   models.SchedulerSM2,
+			Quality:     l.quality,
+			StateBefore: l.stateBefore,
+			StateAfter:  l.stateBefore,
+			ReviewedAt:  l.reviewedAt,
+			Cram:        l.cram,
+		})
+		require.NoError(t, err)
+	}
+
+	// Cram answers did not change the schedule, so they are not replayed or counted
+	history, err := repo.GetByUser(createdUser.ID)
+	require.NoError(t, err)
+	assert.Len(t, history, 5)
+
+	recent, err := repo.GetByUserSince(createdUser.ID, time.Now().AddDate(0, 0, -1))
+	require.NoError(t, err)
+	assert.Len(t, recent, 4)
+
+	studied, err := repo.CountStudiedSince(createdUser.ID, time.Now().AddDate(0, 0, -1))
+	require.NoError(t, err)
+	require.Len(t, studied, 1)
+	assert.Equal(t, 3, studied[0].Reviews)
+
+	counts, err := repo.CountReviewOutcomes(createdUser.ID, time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+	require.Len(t, counts, 1)
+	assert.Equal(t, createdDeck.ID, counts[0].DeckID)
+	assert.Equal(t, 3, counts[0].Reviews)
+	assert.Equal(t, 2, counts[0].Recalled)
+}
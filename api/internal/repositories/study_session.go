@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+)
+
+type StudySessionRepository struct {
+	DB     *sql.DB
+	Logger *logrus.Logger
+}
+
+func NewStudySessionRepository(db *sql.DB, logger *logrus.Logger) *StudySessionRepository {
+	return &StudySessionRepository{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// studySessionColumns lists the selected session columns in the order expected by scanStudySession
+const studySessionColumns = `id, user_id, deck_id, status, mode, cram_order, queue, new_count, learning_count, review_count,
+               again_count, total_time_ms, started_at, finished_at, updated_at`
+
+// scanStudySession scans a row selected with studySessionColumns
+func scanStudySession(row rowScanner) (*models.StudySession, error) {
+	var session models.StudySession
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.DeckID, &session.Status, &session.Mode, &session.CramOrder, &session.Queue,
+		&session.NewCount, &session.LearningCount, &session.ReviewCount,
+		&session.AgainCount, &session.TotalTimeMs,
+		&session.StartedAt, &session.FinishedAt, &session.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Create inserts a new study session
+func (r *StudySessionRepository) Create(session *models.StudySession) (*models.StudySession, error) {
+	query := `
+        INSERT INTO study_sessions (id, user_id, deck_id, status, mode, cram_order, queue, started_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+        RETURNING ` + studySessionColumns
+
+	created, err := scanStudySession(r.DB.QueryRow(
+		query,
+		session.ID, session.UserID, session.DeckID, session.Status, session.Mode, session.CramOrder, session.Queue, session.StartedAt,
+	))
+
+	if err != nil {
+		r.Logger.WithError(err).Error("Failed to create study session")
+		return nil, fmt.Errorf("failed to create study session: %w", err)
+	}
+
+	r.Logger.WithField("session_id", created.ID).Info("Study session created successfully")
+	return created, nil
+}
+
+// GetByID retrieves a study session by ID
+func (r *StudySessionRepository) GetByID(id uuid.UUID) (*models.StudySession, error) {
+	query := `SELECT ` + studySessionColumns + `
+        FROM study_sessions
+        WHERE id = $1
+    `
+
+	session, err := scanStudySession(r.DB.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("study session not found")
+		}
+		r.Logger.WithError(err).WithField("session_id", id).Error("Failed to get study session")
+		return nil, fmt.Errorf("failed to get study session: %w", err)
+	}
+
+	return session, nil
+}
+
+// studySessionUpdateQuery saves the progress of a study session
+const studySessionUpdateQuery = `
+        UPDATE study_sessions
+        SET status = $2, queue = $3, new_count = $4, learning_count = $5, review_count = $6,
+            again_count = $7, total_time_ms = $8, finished_at = $9, updated_at = NOW()
+        WHERE id = $1
+        RETURNING ` + studySessionColumns
+
+// studySessionUpdateArgs returns the arguments of studySessionUpdateQuery
+func studySessionUpdateArgs(session *models.StudySession) []any {
+	return []any{
+		session.ID, session.Status, session.Queue,
+		session.NewCount, session.LearningCount, session.ReviewCount,
+		session.AgainCount, session.TotalTimeMs, session.FinishedAt,
+	}
+}
+
+// Update saves the progress of a study session
+func (r *StudySessionRepository) Update(session *models.StudySession) (*models.StudySession, error) {
+	updated, err := scanStudySession(r.DB.QueryRow(studySessionUpdateQuery, studySessionUpdateArgs(session)...))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("study session not found")
+		}
+		r.Logger.WithError(err).WithField("session_id", session.ID).Error("Failed to update study session")
+		return nil, fmt.Errorf("failed to update study session: %w", err)
+	}
+
+	return updated, nil
+}
+
+// Modify locks a study session, applies change to it and saves the result, so that
+// concurrent changes to the same session apply one after the other instead of
+// overwriting each other. The session is left untouched when change fails, and its
+// error is returned as is.
+func (r *StudySessionRepository) Modify(id uuid.UUID, change func(*models.StudySession) error) (*models.StudySession, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + studySessionColumns + `
+        FROM study_sessions
+        WHERE id = $1
+        FOR UPDATE
+    `
+
+	session, err := scanStudySession(tx.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("study session not found")
+		}
+		r.Logger.WithError(err).WithField("session_id", id).Error("Failed to lock study session")
+		return nil, fmt.Errorf("failed to get study session: %w", err)
+	}
+
+	if err := change(session); err != nil {
+		return nil, err
+	}
+
+	updated, err := scanStudySession(tx.QueryRow(studySessionUpdateQuery, studySessionUpdateArgs(session)...))
+	if err != nil {
+		r.Logger.WithError(err).WithField("session_id", id).Error("Failed to update study session")
+		return nil, fmt.Errorf("failed to update study session: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit study session update: %w", err)
+	}
+
+	return updated, nil
+}
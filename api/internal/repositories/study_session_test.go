@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+func TestStudySessionRepository_CreateAndUpdate(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	user := testutils.CreateTestUser()
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(user)
+	require.NoError(t, err)
+
+	repo := NewStudySessionRepository(td.DB.DB, td.Logger)
+
+	firstCard, secondCard := uuid.New(), uuid.New()
+	session := &models.StudySession{
+		ID:     uuid.New(),
+		UserID: createdUser.ID,
+		Status: models.StudySessionActive,
+		Mode:   models.StudySessionModeNormal,
+		Queue: models.StudyQueue{
+			{FlashcardID: firstCard, State: models.CardStateReview},
+			{FlashcardID: secondCard, State: models.CardStateNew},
+		},
+		StartedAt: time.Now(),
+	}
+
+	created, err := repo.Create(session)
+	require.NoError(t, err)
+	assert.Nil(t, created.DeckID)
+	assert.Equal(t, session.Queue, created.Queue)
+
+	dueAt := time.Now().Add(10 * time.Minute).UTC().Truncate(time.Millisecond)
+	finishedAt := time.Now()
+	created.Queue = models.StudyQueue{{FlashcardID: secondCard, State: models.CardStateLearning, DueAt: &dueAt}}
+	created.ReviewCount = 1
+	created.AgainCount = 1
+	created.TotalTimeMs = 4200
+	created.Status = models.StudySessionFinished
+	created.FinishedAt = &finishedAt
+
+	updated, err := repo.Update(created)
+	require.NoError(t, err)
+	assert.Equal(t, models.StudySessionFinished, updated.Status)
+	assert.Equal(t, 1, updated.ReviewCount)
+	assert.Equal(t, 4200, updated.TotalTimeMs)
+	require.Len(t, updated.Queue, 1)
+	assert.True(t, dueAt.Equal(*updated.Queue[0].DueAt))
+	assert.NotNil(t, updated.FinishedAt)
+
+	retrieved, err := repo.GetByID(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updated.Queue, retrieved.Queue)
+
+	_, err = repo.GetByID(uuid.New())
+	assert.EqualError(t, err, "study session not found")
+
+	order := models.CramOrderHardest
+	cram, err := repo.Create(&models.StudySession{
+		ID:        uuid.New(),
+		UserID:    createdUser.ID,
+		Status:    models.StudySessionActive,
+		Mode:      models.StudySessionModeCram,
+		CramOrder: &order,
+		StartedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.StudySessionModeCram, cram.Mode)
+	require.NotNil(t, cram.CramOrder)
+	assert.Equal(t, models.CramOrderHardest, *cram.CramOrder)
+}
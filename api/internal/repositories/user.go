@@ -3,8 +3,10 @@ package repositories
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 	"swipelearn-api/internal/models"
 )
@@ -14,6 +16,31 @@ type UserRepository struct {
 	Logger *logrus.Logger
 }
 
+// userColumns lists the selected user columns in the order expected by scanUser
+const userColumns = `id, email, name, password_hash, new_per_day, reviews_per_day, timezone, day_start_hour, fsrs_weights, created_at, updated_at`
+
+// scanUser scans a row selected with userColumns
+func scanUser(row rowScanner) (*models.User, error) {
+	var user models.User
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.Name,
+		&user.PasswordHash,
+		&user.NewPerDay,
+		&user.ReviewsPerDay,
+		&user.Timezone,
+		&user.DayStartHour,
+		pq.Array(&user.FSRSWeights),
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func NewUserRepository(db *sql.DB, logger *logrus.Logger) *UserRepository {
 	return &UserRepository{
 		DB:     db,
@@ -26,50 +53,33 @@ func (r *UserRepository) Create(user *models.User) (*models.User, error) {
 	query := `
 		INSERT INTO users (id, email, name, password_hash)
 		VALUES ($1, $2, $3, $4)
-		RETURNING id, email, name, password_hash, created_at, updated_at
-	`
+		RETURNING ` + userColumns
 
-	err := r.DB.QueryRow(
+	created, err := scanUser(r.DB.QueryRow(
 		query,
 		user.ID,
 		user.Email,
 		user.Name,
 		user.PasswordHash,
-	).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Name,
-		&user.PasswordHash,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	))
 
 	if err != nil {
 		r.Logger.WithError(err).Error("Failed to create user in database")
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	r.Logger.WithField("user_id", user.ID).Info("User created successfully")
-	return user, nil
+	r.Logger.WithField("user_id", created.ID).Info("User created successfully")
+	return created, nil
 }
 
 // GetByID retrieves a user by ID
 func (r *UserRepository) GetByID(id uuid.UUID) (*models.User, error) {
-	query := `
-		SELECT id, email, name, password_hash, created_at, updated_at
+	query := `SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1
 	`
 
-	user := &models.User{}
-	err := r.DB.QueryRow(query, id).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Name,
-		&user.PasswordHash,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	user, err := scanUser(r.DB.QueryRow(query, id))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -84,21 +94,12 @@ func (r *UserRepository) GetByID(id uuid.UUID) (*models.User, error) {
 
 // GetByEmail retrieves a user by email
 func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
-	query := `
-		SELECT id, email, name, password_hash, created_at, updated_at
+	query := `SELECT ` + userColumns + `
 		FROM users
 		WHERE email = $1
 	`
 
-	user := &models.User{}
-	err := r.DB.QueryRow(query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Name,
-		&user.PasswordHash,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	user, err := scanUser(r.DB.QueryRow(query, email))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -113,8 +114,7 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 
 // GetAll retrieves all users
 func (r *UserRepository) GetAll() ([]*models.User, error) {
-	query := `
-		SELECT id, email, name, password_hash, created_at, updated_at
+	query := `SELECT ` + userColumns + `
 		FROM users
 		ORDER BY created_at DESC
 	`
@@ -128,15 +128,7 @@ func (r *UserRepository) GetAll() ([]*models.User, error) {
 
 	var users []*models.User
 	for rows.Next() {
-		user := &models.User{}
-		err := rows.Scan(
-			&user.ID,
-			&user.Email,
-			&user.Name,
-			&user.PasswordHash,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-		)
+		user, err := scanUser(rows)
 		if err != nil {
 			r.Logger.WithError(err).Error("Failed to scan user row")
 			return nil, fmt.Errorf("failed to scan user: %w", err)
@@ -171,30 +163,52 @@ func (r *UserRepository) Update(id uuid.UUID, updates map[string]interface{}) (*
 		argIndex++
 	}
 
+	if newPerDay, ok := updates["new_per_day"].(int); ok {
+		setParts = append(setParts, fmt.Sprintf("new_per_day = $%d", argIndex))
+		args = append(args, newPerDay)
+		argIndex++
+	}
+
+	if reviewsPerDay, ok := updates["reviews_per_day"].(int); ok {
+		setParts = append(setParts, fmt.Sprintf("reviews_per_day = $%d", argIndex))
+		args = append(args, reviewsPerDay)
+		argIndex++
+	}
+
+	if timezone, ok := updates["timezone"].(string); ok {
+		setParts = append(setParts, fmt.Sprintf("timezone = $%d", argIndex))
+		args = append(args, timezone)
+		argIndex++
+	}
+
+	if dayStartHour, ok := updates["day_start_hour"].(int); ok {
+		setParts = append(setParts, fmt.Sprintf("day_start_hour = $%d", argIndex))
+		args = append(args, dayStartHour)
+		argIndex++
+	}
+
+	if fsrsWeights, ok := updates["fsrs_weights"].([]float64); ok {
+		setParts = append(setParts, fmt.Sprintf("fsrs_weights = $%d", argIndex))
+		args = append(args, pq.Array(fsrsWeights))
+		argIndex++
+	}
+
 	if len(setParts) == 0 {
 		return nil, fmt.Errorf("no fields to update")
 	}
 
 	// Add updated_at and id
-	setParts = append(setParts, fmt.Sprintf("updated_at = NOW()"))
+	setParts = append(setParts, "updated_at = NOW()")
 	args = append(args, id)
 
 	query := fmt.Sprintf(`
 		UPDATE users
 		SET %s
 		WHERE id = $%d
-		RETURNING id, email, name, password_hash, created_at, updated_at
-	`, fmt.Sprintf("%s", setParts), argIndex)
+		RETURNING %s
+	`, strings.Join(setParts, ", "), argIndex, userColumns)
 
-	user := &models.User{}
-	err := r.DB.QueryRow(query, args...).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Name,
-		&user.PasswordHash,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	user, err := scanUser(r.DB.QueryRow(query, args...))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
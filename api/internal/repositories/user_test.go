@@ -275,6 +275,29 @@ func TestUserRepository_Update_BothFields(t *testing.T) {
 	assert.True(t, updatedUser.UpdatedAt.After(createdUser.UpdatedAt))
 }
 
+func TestUserRepository_Update_StudyDay(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	repo := NewUserRepository(td.DB.DB, td.Logger)
+
+	user := testutils.CreateTestUser()
+	createdUser, err := repo.Create(user)
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", createdUser.Timezone)
+	assert.Equal(t, 4, createdUser.DayStartHour)
+
+	updatedUser, err := repo.Update(createdUser.ID, map[string]interface{}{
+		"timezone":       "America/Los_Angeles",
+		"day_start_hour": 6,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "America/Los_Angeles", updatedUser.Timezone)
+	assert.Equal(t, 6, updatedUser.DayStartHour)
+}
+
 func TestUserRepository_Update_NotFound(t *testing.T) {
 	td := testutils.SetupTestDatabase(t)
 	defer td.Close()
@@ -349,3 +372,19 @@ func TestUserRepository_Delete_NotFound(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "user not found")
 }
+
+func TestUserRepository_Update_FSRSWeights(t *testing.T) {
+	td := testutils.SetupTestDatabase(t)
+	defer td.Close()
+	td.RunMigrations(t)
+
+	userRepo := NewUserRepository(td.DB.DB, td.Logger)
+	createdUser, err := userRepo.Create(testutils.CreateTestUser())
+	require.NoError(t, err)
+	assert.Nil(t, createdUser.FSRSWeights)
+
+	weights := []float64{0.5, 1.2, 3.1, 15.2, 5.0, 1.1, 0.9, 0.02, 1.5, 0.12, 1.0, 2.0, 0.08, 0.3, 1.6, 0.25, 2.9}
+	updated, err := userRepo.Update(createdUser.ID, map[string]interface{}{"fsrs_weights": weights})
+	require.NoError(t, err)
+	assert.Equal(t, weights, updated.FSRSWeights)
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"swipelearn-api/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupDeckPresetRoutes(apiGroup *gin.RouterGroup, presetHandler *handlers.DeckPresetHandler) {
+	// Deck preset routes under /api/v1/deck-presets
+	presets := apiGroup.Group("/deck-presets")
+	{
+		presets.POST("", presetHandler.CreateDeckPreset)       // POST /api/v1/deck-presets
+		presets.GET("", presetHandler.GetDeckPresets)          // GET /api/v1/deck-presets
+		presets.GET("/:id", presetHandler.GetDeckPreset)       // GET /api/v1/deck-presets/:id
+		presets.PUT("/:id", presetHandler.UpdateDeckPreset)    // PUT /api/v1/deck-presets/:id
+		presets.DELETE("/:id", presetHandler.DeleteDeckPreset) // DELETE /api/v1/deck-presets/:id
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"swipelearn-api/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupFilteredDeckRoutes(apiGroup *gin.RouterGroup, filteredDeckHandler *handlers.FilteredDeckHandler) {
+	// Filtered deck routes under /api/v1/filtered-decks
+	filteredDecks := apiGroup.Group("/filtered-decks")
+	{
+		filteredDecks.POST("", filteredDeckHandler.CreateFilteredDeck)              // POST /api/v1/filtered-decks
+		filteredDecks.PUT("/:id", filteredDeckHandler.UpdateFilteredDeck)           // PUT /api/v1/filtered-decks/:id
+		filteredDecks.POST("/:id/rebuild", filteredDeckHandler.RebuildFilteredDeck) // POST /api/v1/filtered-decks/:id/rebuild
+		filteredDecks.POST("/:id/empty", filteredDeckHandler.EmptyFilteredDeck)     // POST /api/v1/filtered-decks/:id/empty
+	}
+}
@@ -10,11 +10,23 @@ func SetupFlashcardRoutes(apiGroup *gin.RouterGroup, flashcardHandler *handlers.
 	// Flashcard routes under /api/v1/flashcards
 	flashcards := apiGroup.Group("/flashcards")
 	{
-		flashcards.GET("", flashcardHandler.GetFlashcards)               // GET /api/v1/flashcards
-		flashcards.POST("", flashcardHandler.CreateFlashcard)            // POST /api/v1/flashcards
-		flashcards.PUT("/:id", flashcardHandler.UpdateFlashcard)         // PUT /api/v1/flashcards/:id
-		flashcards.DELETE("/:id", flashcardHandler.DeleteFlashcard)      // DELETE /api/v1/flashcards/:id
-		flashcards.POST("/:id/review", flashcardHandler.ReviewFlashcard) // POST /api/v1/flashcards/:id/review
-		flashcards.GET("/due", flashcardHandler.GetDueFlashcards)        // GET /api/v1/flashcards/due
+		flashcards.GET("", flashcardHandler.GetFlashcards)                   // GET /api/v1/flashcards
+		flashcards.POST("", flashcardHandler.CreateFlashcard)                // POST /api/v1/flashcards
+		flashcards.PUT("/:id", flashcardHandler.UpdateFlashcard)             // PUT /api/v1/flashcards/:id
+		flashcards.DELETE("/:id", flashcardHandler.DeleteFlashcard)          // DELETE /api/v1/flashcards/:id
+		flashcards.POST("/:id/review", flashcardHandler.ReviewFlashcard)     // POST /api/v1/flashcards/:id/review
+		flashcards.POST("/:id/review/undo", flashcardHandler.UndoReview)     // POST /api/v1/flashcards/:id/review/undo
+		flashcards.GET("/:id/reviews", flashcardHandler.GetFlashcardReviews) // GET /api/v1/flashcards/:id/reviews
+		flashcards.GET("/:id/preview", flashcardHandler.GetFlashcardPreview) // GET /api/v1/flashcards/:id/preview
+		flashcards.POST("/:id/answer", flashcardHandler.CheckAnswer)         // POST /api/v1/flashcards/:id/answer
+		flashcards.GET("/:id/choices", flashcardHandler.GetMultipleChoice)   // GET /api/v1/flashcards/:id/choices
+		flashcards.POST("/:id/choices", flashcardHandler.GradeChoice)        // POST /api/v1/flashcards/:id/choices
+		flashcards.GET("/due", flashcardHandler.GetDueFlashcards)            // GET /api/v1/flashcards/due
+		flashcards.GET("/leeches", flashcardHandler.GetLeeches)              // GET /api/v1/flashcards/leeches
+		flashcards.POST("/suspend", flashcardHandler.SuspendFlashcards)      // POST /api/v1/flashcards/suspend
+		flashcards.POST("/unsuspend", flashcardHandler.UnsuspendFlashcards)  // POST /api/v1/flashcards/unsuspend
+		flashcards.POST("/bury", flashcardHandler.BuryFlashcards)            // POST /api/v1/flashcards/bury
+		flashcards.POST("/forget", flashcardHandler.ForgetFlashcards)        // POST /api/v1/flashcards/forget
+		flashcards.POST("/set-due", flashcardHandler.SetFlashcardsDue)       // POST /api/v1/flashcards/set-due
 	}
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"swipelearn-api/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupMediaRoutes(apiGroup *gin.RouterGroup, mediaHandler *handlers.MediaHandler) {
+	// Media routes under /api/v1/media
+	media := apiGroup.Group("/media")
+	{
+		media.POST("", mediaHandler.UploadMedia)   // POST /api/v1/media
+		media.GET("/:hash", mediaHandler.GetMedia) // GET /api/v1/media/:hash
+	}
+}
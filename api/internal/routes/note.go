@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"swipelearn-api/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupNoteRoutes(apiGroup *gin.RouterGroup, noteHandler *handlers.NoteHandler) {
+	// Note routes under /api/v1/notes
+	notes := apiGroup.Group("/notes")
+	{
+		notes.POST("", noteHandler.CreateNote)       // POST /api/v1/notes
+		notes.GET("/:id", noteHandler.GetNote)       // GET /api/v1/notes/:id
+		notes.PUT("/:id", noteHandler.UpdateNote)    // PUT /api/v1/notes/:id
+		notes.DELETE("/:id", noteHandler.DeleteNote) // DELETE /api/v1/notes/:id
+	}
+}
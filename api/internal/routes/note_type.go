@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"swipelearn-api/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupNoteTypeRoutes(apiGroup *gin.RouterGroup, noteTypeHandler *handlers.NoteTypeHandler) {
+	// Note type routes under /api/v1/note-types
+	noteTypes := apiGroup.Group("/note-types")
+	{
+		noteTypes.POST("", noteTypeHandler.CreateNoteType)       // POST /api/v1/note-types
+		noteTypes.GET("", noteTypeHandler.GetNoteTypes)          // GET /api/v1/note-types
+		noteTypes.GET("/:id", noteTypeHandler.GetNoteType)       // GET /api/v1/note-types/:id
+		noteTypes.DELETE("/:id", noteTypeHandler.DeleteNoteType) // DELETE /api/v1/note-types/:id
+	}
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"swipelearn-api/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupOptimizerRoutes(apiGroup *gin.RouterGroup, optimizerHandler *handlers.OptimizerHandler) {
+	// Scheduler optimizer routes under /api/v1/users/me/scheduler
+	scheduler := apiGroup.Group("/users/me/scheduler")
+	{
+		scheduler.POST("/optimize", optimizerHandler.OptimizeScheduler)  // POST /api/v1/users/me/scheduler/optimize
+		scheduler.GET("/optimize/:id", optimizerHandler.GetOptimizerRun) // GET /api/v1/users/me/scheduler/optimize/:id
+	}
+}
@@ -11,6 +11,14 @@ import (
 func SetupRouter(
 	flashcardHandler *handlers.FlashcardHandler,
 	deckHandler *handlers.DeckHandler,
+	filteredDeckHandler *handlers.FilteredDeckHandler,
+	deckPresetHandler *handlers.DeckPresetHandler,
+	noteTypeHandler *handlers.NoteTypeHandler,
+	noteHandler *handlers.NoteHandler,
+	mediaHandler *handlers.MediaHandler,
+	studySessionHandler *handlers.StudySessionHandler,
+	optimizerHandler *handlers.OptimizerHandler,
+	statsHandler *handlers.StatsHandler,
 	userHandler *handlers.UserHandler,
 	authHandler *handlers.AuthHandler,
 	jwtService *services.JWTService,
@@ -30,7 +38,15 @@ func SetupRouter(
 	// Setup route groups
 	SetupFlashcardRoutes(apiGroup, flashcardHandler)
 	SetupDeckRoutes(apiGroup, deckHandler)
+	SetupFilteredDeckRoutes(apiGroup, filteredDeckHandler)
+	SetupDeckPresetRoutes(apiGroup, deckPresetHandler)
+	SetupNoteTypeRoutes(apiGroup, noteTypeHandler)
+	SetupNoteRoutes(apiGroup, noteHandler)
+	SetupMediaRoutes(apiGroup, mediaHandler)
+	SetupStudySessionRoutes(apiGroup, studySessionHandler)
 	SetupUserRoutes(apiGroup, userHandler)
+	SetupOptimizerRoutes(apiGroup, optimizerHandler)
+	SetupStatsRoutes(apiGroup, statsHandler)
 
 	// Protected auth routes
 	authGroup := apiGroup.Group("/auth")
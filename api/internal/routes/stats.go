@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"swipelearn-api/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupStatsRoutes(apiGroup *gin.RouterGroup, statsHandler *handlers.StatsHandler) {
+	// Stats routes under /api/v1/stats
+	stats := apiGroup.Group("/stats")
+	{
+		stats.GET("/forecast", statsHandler.GetForecast)   // GET /api/v1/stats/forecast
+		stats.GET("/retention", statsHandler.GetRetention) // GET /api/v1/stats/retention
+	}
+}
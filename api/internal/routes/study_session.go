@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"swipelearn-api/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupStudySessionRoutes(apiGroup *gin.RouterGroup, sessionHandler *handlers.StudySessionHandler) {
+	// Study session routes under /api/v1/study-sessions
+	sessions := apiGroup.Group("/study-sessions")
+	{
+		sessions.POST("", sessionHandler.CreateStudySession)            // POST /api/v1/study-sessions
+		sessions.GET("/:id/next", sessionHandler.GetNextCard)           // GET /api/v1/study-sessions/:id/next
+		sessions.POST("/:id/answer", sessionHandler.AnswerCard)         // POST /api/v1/study-sessions/:id/answer
+		sessions.POST("/:id/finish", sessionHandler.FinishStudySession) // POST /api/v1/study-sessions/:id/finish
+	}
+}
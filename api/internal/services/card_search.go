@@ -0,0 +1,309 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"swipelearn-api/internal/models"
+)
+
+// maxRatedDays is how far back a rated: search can look
+const maxRatedDays = 365
+
+// cardSearch is a parsed card search. Every term must match; a term starting with "-"
+// must not. Supported terms:
+//
+//	text, "quoted text"   front or back contains the text (case-insensitive)
+//	deck:NAME             home deck name, * matches anything
+//	is:STATE              new, learning, review, relearning, due, suspended, buried or leech
+//	prop:FIELDopN         due (days from today), ivl, lapses, reps or ease compared with
+//	                      =, !=, <, <=, > or >=
+//	rated:N[:ANSWER]      answered in the last N study days, optionally only again,
+//	                      hard, good or easy answers
+type cardSearch struct {
+	terms     []searchTerm
+	ratedDays int // Longest rated: window, 0 when the search does not need review history
+}
+
+type searchTerm struct {
+	negate bool
+	match  func(card *models.Flashcard, ctx *cardSearchContext) bool
+}
+
+// cardSearchContext holds what matching a card needs besides the card itself
+type cardSearchContext struct {
+	now     time.Time
+	clock   studyClock
+	decks   map[uuid.UUID]*models.Deck
+	reviews map[uuid.UUID][]*models.ReviewLog // Reviews since ratedDays ago, by card
+}
+
+// matches reports whether a card matches every term of the search
+func (s *cardSearch) matches(card *models.Flashcard, ctx *cardSearchContext) bool {
+	for _, term := range s.terms {
+		if term.match(card, ctx) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// parseCardSearch parses a search query. An empty query matches every card.
+func parseCardSearch(query string) (*cardSearch, error) {
+	tokens, err := searchTokens(query)
+	if err != nil {
+		return nil, err
+	}
+
+	search := &cardSearch{}
+	for _, token := range tokens {
+		term := searchTerm{negate: token.negate}
+
+		key, value, hasKey := strings.Cut(token.text, ":")
+		switch {
+		case token.quoted || !hasKey:
+			term.match = textTerm(token.text)
+		case key == "deck":
+			term.match, err = deckTerm(value)
+		case key == "is":
+			term.match, err = isTerm(value)
+		case key == "prop":
+			term.match, err = propTerm(value)
+		case key == "rated":
+			var days int
+			term.match, days, err = ratedTerm(value)
+			search.ratedDays = max(search.ratedDays, days)
+		default:
+			err = fmt.Errorf("invalid search: unknown term %q", token.text)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		search.terms = append(search.terms, term)
+	}
+
+	return search, nil
+}
+
+type searchToken struct {
+	text   string
+	negate bool
+	quoted bool // Quoted tokens are always plain text
+}
+
+// searchTokens splits a query on whitespace outside double quotes
+func searchTokens(query string) ([]searchToken, error) {
+	var tokens []searchToken
+	var current strings.Builder
+	var token searchToken
+	inToken, inQuotes := false, false
+
+	flush := func() {
+		if inToken {
+			token.text = current.String()
+			tokens = append(tokens, token)
+		}
+		current.Reset()
+		token = searchToken{}
+		inToken = false
+	}
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			if current.Len() == 0 {
+				token.quoted = true
+			}
+			inToken = true
+			inQuotes = !inQuotes
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+		case r == '-' && !inToken:
+			token.negate = true
+			inToken = true
+		default:
+			inToken = true
+			current.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("invalid search: unterminated quote")
+	}
+	flush()
+
+	for _, token := range tokens {
+		if token.text == "" {
+			return nil, fmt.Errorf("invalid search: empty term")
+		}
+	}
+	return tokens, nil
+}
+
+func textTerm(text string) func(*models.Flashcard, *cardSearchContext) bool {
+	text = strings.ToLower(text)
+	return func(card *models.Flashcard, _ *cardSearchContext) bool {
+		return strings.Contains(strings.ToLower(card.Front), text) || strings.Contains(strings.ToLower(card.Back), text)
+	}
+}
+
+func deckTerm(name string) (func(*models.Flashcard, *cardSearchContext) bool, error) {
+	if name == "" {
+		return nil, fmt.Errorf("invalid search: deck: needs a deck name")
+	}
+
+	pattern := regexp.MustCompile("(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(name), `\*`, ".*") + "$")
+	return func(card *models.Flashcard, ctx *cardSearchContext) bool {
+		deck, ok := ctx.decks[card.HomeDeck()]
+		return ok && pattern.MatchString(deck.Name)
+	}, nil
+}
+
+func isTerm(value string) (func(*models.Flashcard, *cardSearchContext) bool, error) {
+	switch value {
+	case models.CardStateNew, models.CardStateLearning, models.CardStateReview, models.CardStateRelearning:
+		return func(card *models.Flashcard, _ *cardSearchContext) bool {
+			return card.SchedulingState().CardState() == value
+		}, nil
+	case "due":
+		return func(card *models.Flashcard, ctx *cardSearchContext) bool {
+			if card.NextReview == nil || card.SchedulingState().CardState() == models.CardStateNew {
+				return false
+			}
+			if card.SchedulingState().CardState() == models.CardStateReview {
+				return card.NextReview.Before(ctx.clock.nextDayStart(ctx.now))
+			}
+			return card.NextReview.Before(ctx.now)
+		}, nil
+	case "suspended":
+		return func(card *models.Flashcard, _ *cardSearchContext) bool {
+			return card.Suspended
+		}, nil
+	case "buried":
+		return func(card *models.Flashcard, ctx *cardSearchContext) bool {
+			return card.BuriedUntil != nil && card.BuriedUntil.After(ctx.now)
+		}, nil
+	case "leech":
+		return func(card *models.Flashcard, _ *cardSearchContext) bool {
+			return card.Leech
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid search: unknown state %q in is:", value)
+	}
+}
+
+// propOperators lists the comparison operators of prop: terms, longest first
+var propOperators = []string{"<=", ">=", "!=", "=", "<", ">"}
+
+func propTerm(value string) (func(*models.Flashcard, *cardSearchContext) bool, error) {
+	field, op, operand := "", "", ""
+	for _, candidate := range propOperators {
+		if i := strings.Index(value, candidate); i > 0 {
+			field, op, operand = value[:i], candidate, value[i+len(candidate):]
+			break
+		}
+	}
+	if op == "" {
+		return nil, fmt.Errorf("invalid search: prop: needs a comparison such as prop:ivl>=10")
+	}
+
+	n, err := strconv.ParseFloat(operand, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search: %q is not a number", operand)
+	}
+
+	var property func(card *models.Flashcard, ctx *cardSearchContext) (float64, bool)
+	switch field {
+	case "due":
+		property = func(card *models.Flashcard, ctx *cardSearchContext) (float64, bool) {
+			if card.NextReview == nil {
+				return 0, false
+			}
+			today := ctx.clock.dayStart(ctx.now)
+			return math.Round(ctx.clock.dayStart(*card.NextReview).Sub(today).Hours() / 24), true
+		}
+	case "ivl":
+		property = func(card *models.Flashcard, _ *cardSearchContext) (float64, bool) {
+			return float64(card.Interval), card.SchedulingState().CardState() != models.CardStateNew
+		}
+	case "lapses":
+		property = func(card *models.Flashcard, _ *cardSearchContext) (float64, bool) {
+			return float64(card.Lapses), true
+		}
+	case "reps":
+		property = func(card *models.Flashcard, _ *cardSearchContext) (float64, bool) {
+			return float64(card.ReviewCount), true
+		}
+	case "ease":
+		property = func(card *models.Flashcard, _ *cardSearchContext) (float64, bool) {
+			return card.EaseFactor, true
+		}
+	default:
+		return nil, fmt.Errorf("invalid search: unknown property %q in prop:", field)
+	}
+
+	return func(card *models.Flashcard, ctx *cardSearchContext) bool {
+		v, ok := property(card, ctx)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "<=":
+			return v <= n
+		case ">=":
+			return v >= n
+		case "!=":
+			return v != n
+		case "<":
+			return v < n
+		case ">":
+			return v > n
+		default:
+			return v == n
+		}
+	}, nil
+}
+
+// ratedAnswers maps the answers of rated: terms onto ratings
+var ratedAnswers = map[string]int{
+	"again": ratingAgain,
+	"hard":  ratingHard,
+	"good":  ratingGood,
+	"easy":  ratingEasy,
+}
+
+func ratedTerm(value string) (func(*models.Flashcard, *cardSearchContext) bool, int, error) {
+	daysValue, answer, hasAnswer := strings.Cut(value, ":")
+	days, err := strconv.Atoi(daysValue)
+	if err != nil || days < 1 || days > maxRatedDays {
+		return nil, 0, fmt.Errorf("invalid search: rated: needs a number of days between 1 and %d", maxRatedDays)
+	}
+
+	rating := 0
+	if hasAnswer {
+		var ok bool
+		if rating, ok = ratedAnswers[answer]; !ok {
+			return nil, 0, fmt.Errorf("invalid search: unknown answer %q in rated:", answer)
+		}
+	}
+
+	return func(card *models.Flashcard, ctx *cardSearchContext) bool {
+		// rated:1 is today, rated:2 today and yesterday, and so on
+		since := ctx.clock.dayStart(ctx.now).AddDate(0, 0, 1-days)
+		for _, review := range ctx.reviews[card.ID] {
+			if review.ReviewedAt.Before(since) {
+				continue
+			}
+			if rating == 0 || ratingFromQuality(review.Quality) == rating {
+				return true
+			}
+		}
+		return false
+	}, days, nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+// searchMatches parses query and returns which of the cards it matches
+func searchMatches(t *testing.T, query string, ctx *cardSearchContext, cards ...*models.Flashcard) []bool {
+	t.Helper()
+
+	search, err := parseCardSearch(query)
+	require.NoError(t, err, query)
+
+	matches := make([]bool, len(cards))
+	for i, card := range cards {
+		matches[i] = search.matches(card, ctx)
+	}
+	return matches
+}
+
+func newTestSearchContext(decks ...*models.Deck) *cardSearchContext {
+	ctx := &cardSearchContext{
+		now:   time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC),
+		clock: studyClock{location: time.UTC, dayStartHour: 4},
+		decks: map[uuid.UUID]*models.Deck{},
+	}
+	for _, deck := range decks {
+		ctx.decks[deck.ID] = deck
+	}
+	return ctx
+}
+
+func TestParseCardSearch_Errors(t *testing.T) {
+	for _, query := range []string{
+		`"unterminated`,
+		`-`,
+		`tag:verbs`,
+		`deck:`,
+		`is:sleepy`,
+		`prop:ivl`,
+		`prop:ivl>=ten`,
+		`prop:color=1`,
+		`rated:0`,
+		`rated:400`,
+		`rated:2:maybe`,
+	} {
+		_, err := parseCardSearch(query)
+		require.Error(t, err, query)
+		assert.Contains(t, err.Error(), "invalid search: ", query)
+	}
+}
+
+func TestCardSearch_Text(t *testing.T) {
+	ctx := newTestSearchContext()
+	card := &models.Flashcard{Front: "Der Hund", Back: "The dog barks"}
+
+	assert.Equal(t, []bool{true}, searchMatches(t, "hund", ctx, card))
+	assert.Equal(t, []bool{true}, searchMatches(t, "DOG hund", ctx, card))
+	assert.Equal(t, []bool{false}, searchMatches(t, "dog cat", ctx, card))
+	assert.Equal(t, []bool{true}, searchMatches(t, `"dog barks"`, ctx, card))
+	assert.Equal(t, []bool{false}, searchMatches(t, `"barks dog"`, ctx, card))
+	assert.Equal(t, []bool{false}, searchMatches(t, "-hund", ctx, card))
+	assert.Equal(t, []bool{true}, searchMatches(t, `-"the cat"`, ctx, card))
+	// Quoted text is never a term with a key
+	assert.Equal(t, []bool{false}, searchMatches(t, `"is:new"`, ctx, card))
+	assert.Equal(t, []bool{true}, searchMatches(t, "", ctx, card))
+}
+
+func TestCardSearch_Deck(t *testing.T) {
+	userID := uuid.New()
+	german := testutils.CreateTestDeck(userID)
+	german.Name = "German::Verbs"
+	french := testutils.CreateTestDeck(userID)
+	french.Name = "French"
+	filtered := testutils.CreateTestDeck(userID)
+	filtered.Name = "Cram"
+	ctx := newTestSearchContext(german, french, filtered)
+
+	inGerman := testutils.CreateTestFlashcard(userID, german.ID)
+	inFrench := testutils.CreateTestFlashcard(userID, french.ID)
+	// Cards in a filtered deck are searched by their home deck
+	borrowed := testutils.CreateTestFlashcard(userID, filtered.ID)
+	borrowed.HomeDeckID = &german.ID
+
+	assert.Equal(t, []bool{true, false, true}, searchMatches(t, "deck:german*", ctx, inGerman, inFrench, borrowed))
+	assert.Equal(t, []bool{false, true, false}, searchMatches(t, "deck:French", ctx, inGerman, inFrench, borrowed))
+	assert.Equal(t, []bool{false, false, false}, searchMatches(t, "deck:Cram", ctx, inGerman, inFrench, borrowed))
+	assert.Equal(t, []bool{false, true, false}, searchMatches(t, "-deck:german::*", ctx, inGerman, inFrench, borrowed))
+}
+
+func TestCardSearch_Is(t *testing.T) {
+	ctx := newTestSearchContext()
+	tonight := time.Date(2025, 3, 10, 23, 0, 0, 0, time.UTC)
+	tomorrow := time.Date(2025, 3, 11, 12, 0, 0, 0, time.UTC)
+	later := ctx.now.Add(time.Hour)
+	buriedUntil := ctx.now.Add(12 * time.Hour)
+
+	fresh := &models.Flashcard{State: models.CardStateNew}
+	dueTonight := &models.Flashcard{State: models.CardStateReview, NextReview: &tonight}
+	dueTomorrow := &models.Flashcard{State: models.CardStateReview, NextReview: &tomorrow}
+	learningLater := &models.Flashcard{State: models.CardStateLearning, NextReview: &later}
+	suspended := &models.Flashcard{State: models.CardStateReview, NextReview: &tomorrow, Suspended: true}
+	buried := &models.Flashcard{State: models.CardStateNew, BuriedUntil: &buriedUntil}
+	leech := &models.Flashcard{State: models.CardStateRelearning, NextReview: &later, Leech: true}
+	cards := []*models.Flashcard{fresh, dueTonight, dueTomorrow, learningLater, suspended, buried, leech}
+
+	assert.Equal(t, []bool{true, false, false, false, false, true, false}, searchMatches(t, "is:new", ctx, cards...))
+	assert.Equal(t, []bool{false, true, true, false, true, false, false}, searchMatches(t, "is:review", ctx, cards...))
+	assert.Equal(t, []bool{false, false, false, true, false, false, false}, searchMatches(t, "is:learning", ctx, cards...))
+	// Reviews are due for the whole study day, learning cards once their step has passed
+	assert.Equal(t, []bool{false, true, false, false, false, false, false}, searchMatches(t, "is:due", ctx, cards...))
+	assert.Equal(t, []bool{false, false, false, false, true, false, false}, searchMatches(t, "is:suspended", ctx, cards...))
+	assert.Equal(t, []bool{false, false, false, false, false, true, false}, searchMatches(t, "is:buried", ctx, cards...))
+	assert.Equal(t, []bool{false, false, false, false, false, false, true}, searchMatches(t, "is:leech", ctx, cards...))
+	assert.Equal(t, []bool{false, true, true, false, false, false, false}, searchMatches(t, "is:review -is:suspended", ctx, cards...))
+}
+
+func TestCardSearch_Prop(t *testing.T) {
+	ctx := newTestSearchContext()
+	yesterday := time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC)
+	inThreeDays := time.Date(2025, 3, 13, 12, 0, 0, 0, time.UTC)
+
+	overdue := &models.Flashcard{State: models.CardStateReview, Interval: 5, Lapses: 0, ReviewCount: 4, EaseFactor: 2.5, NextReview: &yesterday}
+	upcoming := &models.Flashcard{State: models.CardStateReview, Interval: 30, Lapses: 3, ReviewCount: 12, EaseFactor: 1.9, NextReview: &inThreeDays}
+	fresh := &models.Flashcard{State: models.CardStateNew, Interval: 1, EaseFactor: 2.5}
+
+	assert.Equal(t, []bool{true, false, false}, searchMatches(t, "prop:due<0", ctx, overdue, upcoming, fresh))
+	assert.Equal(t, []bool{false, true, false}, searchMatches(t, "prop:due=3", ctx, overdue, upcoming, fresh))
+	assert.Equal(t, []bool{false, true, false}, searchMatches(t, "prop:ivl>=10", ctx, overdue, upcoming, fresh))
+	// New cards have no interval yet
+	assert.Equal(t, []bool{true, false, false}, searchMatches(t, "prop:ivl<10", ctx, overdue, upcoming, fresh))
+	assert.Equal(t, []bool{false, true, false}, searchMatches(t, "prop:lapses>2", ctx, overdue, upcoming, fresh))
+	assert.Equal(t, []bool{true, true, false}, searchMatches(t, "prop:reps!=0", ctx, overdue, upcoming, fresh))
+	assert.Equal(t, []bool{false, true, false}, searchMatches(t, "prop:ease<2", ctx, overdue, upcoming, fresh))
+}
+
+func TestCardSearch_Rated(t *testing.T) {
+	ctx := newTestSearchContext()
+	today := &models.Flashcard{ID: uuid.New()}
+	yesterday := &models.Flashcard{ID: uuid.New()}
+	lastWeek := &models.Flashcard{ID: uuid.New()}
+	never := &models.Flashcard{ID: uuid.New()}
+	ctx.reviews = map[uuid.UUID][]*models.ReviewLog{
+		today.ID:     {{Quality: 1, ReviewedAt: time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)}},
+		yesterday.ID: {{Quality: 4, ReviewedAt: time.Date(2025, 3, 9, 20, 0, 0, 0, time.UTC)}},
+		lastWeek.ID:  {{Quality: 5, ReviewedAt: time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC)}},
+	}
+	cards := []*models.Flashcard{today, yesterday, lastWeek, never}
+
+	search, err := parseCardSearch("rated:7 rated:2")
+	require.NoError(t, err)
+	assert.Equal(t, 7, search.ratedDays)
+
+	assert.Equal(t, []bool{true, false, false, false}, searchMatches(t, "rated:1", ctx, cards...))
+	assert.Equal(t, []bool{true, true, false, false}, searchMatches(t, "rated:2", ctx, cards...))
+	assert.Equal(t, []bool{true, true, true, false}, searchMatches(t, "rated:7", ctx, cards...))
+	assert.Equal(t, []bool{true, false, false, false}, searchMatches(t, "rated:7:again", ctx, cards...))
+	assert.Equal(t, []bool{false, true, false, false}, searchMatches(t, "rated:7:good", ctx, cards...))
+	assert.Equal(t, []bool{false, false, true, true}, searchMatches(t, "-rated:2", ctx, cards...))
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// clozeDeletion matches a {{c1::text}} or {{c1::text::hint}} cloze deletion
+var clozeDeletion = regexp.MustCompile(`(?s)\{\{c(\d+)::(.*?)(?:::(.*?))?\}\}`)
+
+// clozeMask replaces a masked deletion without a hint
+const clozeMask = "[...]"
+
+// maxClozeIndices bounds the deletion indices of a cloze note, each of which is a card,
+// like the occlusions of an image occlusion note
+const maxClozeIndices = 200
+
+// clozeIndices returns the distinct deletion indices used in a text, in ascending order.
+// c0 is not a valid index and is ignored.
+func clozeIndices(text string) []int {
+	seen := make(map[int]bool)
+	var indices []int
+	for _, match := range clozeDeletion.FindAllStringSubmatch(text, -1) {
+		index, err := strconv.Atoi(match[1])
+		if err != nil || index < 1 || seen[index] {
+			continue
+		}
+		seen[index] = true
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// renderCloze renders a text for the card of one deletion index. The card's deletions
+// are masked, showing their hint if they have one, unless reveal is set; every other
+// deletion shows its text. Index 0 reveals all deletions.
+func renderCloze(text string, index int, reveal bool) string {
+	return clozeDeletion.ReplaceAllStringFunc(text, func(deletion string) string {
+		match := clozeDeletion.FindStringSubmatch(deletion)
+		if n, _ := strconv.Atoi(match[1]); reveal || n != index {
+			return match[2]
+		}
+		if match[3] != "" {
+			return "[" + match[3] + "]"
+		}
+		return clozeMask
+	})
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClozeIndices(t *testing.T) {
+	text := "{{c2::Paris}} is the capital of {{c1::France::country}}, and {{c2::Berlin}} of {{c0::Germany}}"
+
+	assert.Equal(t, []int{1, 2}, clozeIndices(text))
+	assert.Empty(t, clozeIndices("no deletions {{Front}}"))
+}
+
+func TestRenderCloze(t *testing.T) {
+	text := "{{c1::Paris}} is the capital of {{c2::France::country}}"
+
+	tests := []struct {
+		name   string
+		index  int
+		reveal bool
+		want   string
+	}{
+		{name: "front of c1", index: 1, want: "[...] is the capital of France"},
+		{name: "front of c2 shows hint", index: 2, want: "Paris is the capital of [country]"},
+		{name: "back of c2", index: 2, reveal: true, want: "Paris is the capital of France"},
+		{name: "no card", index: 0, want: "Paris is the capital of France"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, renderCloze(text, tt.index, tt.reveal))
+		})
+	}
+}
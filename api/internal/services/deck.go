@@ -11,24 +11,49 @@ import (
 )
 
 type DeckService struct {
-	deckRepo repositories.DeckRepositoryInterface
-	Logger   *logrus.Logger
+	deckRepo   repositories.DeckRepositoryInterface
+	presetRepo repositories.DeckPresetRepositoryInterface
+	Logger     *logrus.Logger
 }
 
-func NewDeckService(repo repositories.DeckRepositoryInterface, logger *logrus.Logger) *DeckService {
+func NewDeckService(
+	repo repositories.DeckRepositoryInterface,
+	presetRepo repositories.DeckPresetRepositoryInterface,
+	logger *logrus.Logger,
+) *DeckService {
 	return &DeckService{
-		deckRepo: repo,
-		Logger:   logger,
+		deckRepo:   repo,
+		presetRepo: presetRepo,
+		Logger:     logger,
 	}
 }
 
 // Create creates a new deck with business logic validation
 func (s *DeckService) Create(req *models.CreateDeckRequest, userID uuid.UUID) (*models.Deck, error) {
+	scheduler := req.Scheduler
+	if scheduler == "" {
+		scheduler = models.SchedulerSM2
+	}
+
+	if req.PresetID != nil {
+		if err := s.checkPresetOwnership(*req.PresetID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	desiredRetention := models.DefaultDesiredRetention
+	if req.DesiredRetention != nil {
+		desiredRetention = *req.DesiredRetention
+	}
+
 	deck := &models.Deck{
-		ID:          uuid.New(),
-		UserID:      userID,
-		Name:        req.Name,
-		Description: req.Description,
+		ID:               uuid.New(),
+		UserID:           userID,
+		Name:             req.Name,
+		Description:      req.Description,
+		Scheduler:        scheduler,
+		PresetID:         req.PresetID,
+		DesiredRetention: desiredRetention,
 	}
 
 	savedDeck, err := s.deckRepo.Create(deck)
@@ -123,6 +148,28 @@ func (s *DeckService) Update(id uuid.UUID, req *models.UpdateDeckRequest) (*mode
 		updates["description"] = *req.Description
 	}
 
+	if req.Scheduler != nil && *req.Scheduler != existingDeck.Scheduler {
+		if _, err := NewScheduler(*req.Scheduler, DefaultSchedulerConfig()); err != nil {
+			return nil, err
+		}
+		updates["scheduler"] = *req.Scheduler
+	}
+
+	if req.PresetID != nil && (existingDeck.PresetID == nil || *req.PresetID != *existingDeck.PresetID) {
+		if err := s.checkPresetOwnership(*req.PresetID, existingDeck.UserID); err != nil {
+			return nil, err
+		}
+		updates["preset_id"] = *req.PresetID
+	}
+
+	if req.ClearPreset && existingDeck.PresetID != nil {
+		updates["preset_id"] = nil
+	}
+
+	if req.DesiredRetention != nil && *req.DesiredRetention != existingDeck.DesiredRetention {
+		updates["desired_retention"] = *req.DesiredRetention
+	}
+
 	if len(updates) == 0 {
 		return existingDeck, nil // No changes needed
 	}
@@ -220,3 +267,17 @@ func (s *DeckService) DeleteWithOwnership(id uuid.UUID, userID uuid.UUID) error
 	// Call regular delete method
 	return s.Delete(id)
 }
+
+// checkPresetOwnership ensures a deck is only attached to a preset of the same user
+func (s *DeckService) checkPresetOwnership(presetID uuid.UUID, userID uuid.UUID) error {
+	preset, err := s.presetRepo.GetByID(presetID)
+	if err != nil {
+		return fmt.Errorf("failed to get deck preset: %w", err)
+	}
+
+	if preset.UserID != userID {
+		return fmt.Errorf("unauthorized: deck preset does not belong to user")
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/repositories"
+)
+
+type DeckPresetService struct {
+	presetRepo repositories.DeckPresetRepositoryInterface
+	Logger     *logrus.Logger
+}
+
+func NewDeckPresetService(repo repositories.DeckPresetRepositoryInterface, logger *logrus.Logger) *DeckPresetService {
+	return &DeckPresetService{
+		presetRepo: repo,
+		Logger:     logger,
+	}
+}
+
+// DefaultDeckPreset returns the options used by decks without a preset
+func DefaultDeckPreset() models.DeckPreset {
+	return models.DeckPreset{
+		StartingEase:                 2.5,
+		MinimumEase:                  SM2MinEaseFactor,
+		IntervalModifier:             1.0,
+		MaximumInterval:              36500,
+		LearningSteps:                []int64{1, 10},
+		RelearningSteps:              []int64{10},
+		NewPerDay:                    20,
+		ReviewsPerDay:                200,
+		LeechThreshold:               8,
+		LeechAction:                  models.LeechActionTag,
+		TypedAnswerIgnoreCase:        true,
+		TypedAnswerIgnorePunctuation: true,
+	}
+}
+
+// Create creates a deck preset, filling unset options with the defaults
+func (s *DeckPresetService) Create(req *models.CreateDeckPresetRequest, userID uuid.UUID) (*models.DeckPreset, error) {
+	preset := DefaultDeckPreset()
+	preset.ID = uuid.New()
+	preset.UserID = userID
+	preset.Name = req.Name
+
+	if err := applyDeckPresetOptions(&preset, &req.DeckPresetOptions); err != nil {
+		return nil, err
+	}
+
+	savedPreset, err := s.presetRepo.Create(&preset)
+	if err != nil {
+		s.Logger.WithError(err).Error("Service failed to create deck preset")
+		return nil, fmt.Errorf("failed to create deck preset: %w", err)
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"preset_id": savedPreset.ID,
+		"name":      savedPreset.Name,
+	}).Info("Deck preset created successfully")
+
+	return savedPreset, nil
+}
+
+// GetByUser retrieves all deck presets of a user
+func (s *DeckPresetService) GetByUser(userID uuid.UUID) ([]*models.DeckPreset, error) {
+	presets, err := s.presetRepo.GetByUser(userID)
+	if err != nil {
+		s.Logger.WithError(err).WithField("user_id", userID).Error("Service failed to get deck presets for user")
+		return nil, fmt.Errorf("failed to get deck presets: %w", err)
+	}
+
+	return presets, nil
+}
+
+// GetByIDWithOwnership retrieves a deck preset with user ownership validation
+func (s *DeckPresetService) GetByIDWithOwnership(id uuid.UUID, userID uuid.UUID) (*models.DeckPreset, error) {
+	preset, err := s.presetRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deck preset: %w", err)
+	}
+
+	if preset.UserID != userID {
+		s.Logger.WithFields(logrus.Fields{
+			"preset_id": id,
+			"user_id":   userID,
+			"owner_id":  preset.UserID,
+		}).Warn("Unauthorized attempt to access deck preset")
+		return nil, fmt.Errorf("unauthorized: deck preset does not belong to user")
+	}
+
+	return preset, nil
+}
+
+// UpdateWithOwnership updates a deck preset with user ownership validation
+func (s *DeckPresetService) UpdateWithOwnership(id uuid.UUID, userID uuid.UUID, req *models.UpdateDeckPresetRequest) (*models.DeckPreset, error) {
+	preset, err := s.GetByIDWithOwnership(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		preset.Name = *req.Name
+	}
+	if err := applyDeckPresetOptions(preset, &req.DeckPresetOptions); err != nil {
+		return nil, err
+	}
+
+	updatedPreset, err := s.presetRepo.Update(preset)
+	if err != nil {
+		s.Logger.WithError(err).WithField("preset_id", id).Error("Service failed to update deck preset")
+		return nil, fmt.Errorf("failed to update deck preset: %w", err)
+	}
+
+	s.Logger.WithField("preset_id", id).Info("Deck preset updated successfully")
+	return updatedPreset, nil
+}
+
+// DeleteWithOwnership removes a deck preset with user ownership validation.
+// Decks using the preset fall back to the default options.
+func (s *DeckPresetService) DeleteWithOwnership(id uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.GetByIDWithOwnership(id, userID); err != nil {
+		return err
+	}
+
+	if err := s.presetRepo.Delete(id); err != nil {
+		s.Logger.WithError(err).WithField("preset_id", id).Error("Service failed to delete deck preset")
+		return fmt.Errorf("failed to delete deck preset: %w", err)
+	}
+
+	s.Logger.WithField("preset_id", id).Info("Deck preset deleted successfully")
+	return nil
+}
+
+// applyDeckPresetOptions copies the set options onto the preset and validates the result
+func applyDeckPresetOptions(preset *models.DeckPreset, opts *models.DeckPresetOptions) error {
+	if opts.StartingEase != nil {
+		preset.StartingEase = *opts.StartingEase
+	}
+	if opts.MinimumEase != nil {
+		preset.MinimumEase = *opts.MinimumEase
+	}
+	if opts.IntervalModifier != nil {
+		preset.IntervalModifier = *opts.IntervalModifier
+	}
+	if opts.MaximumInterval != nil {
+		preset.MaximumInterval = *opts.MaximumInterval
+	}
+	if opts.LearningSteps != nil {
+		preset.LearningSteps = opts.LearningSteps
+	}
+	if opts.RelearningSteps != nil {
+		preset.RelearningSteps = opts.RelearningSteps
+	}
+	if opts.NewPerDay != nil {
+		preset.NewPerDay = *opts.NewPerDay
+	}
+	if opts.ReviewsPerDay != nil {
+		preset.ReviewsPerDay = *opts.ReviewsPerDay
+	}
+	if opts.LeechThreshold != nil {
+		preset.LeechThreshold = *opts.LeechThreshold
+	}
+	if opts.LeechAction != nil {
+		preset.LeechAction = *opts.LeechAction
+	}
+	if opts.BurySiblings != nil {
+		preset.BurySiblings = *opts.BurySiblings
+	}
+	if opts.TypedAnswerIgnoreCase != nil {
+		preset.TypedAnswerIgnoreCase = *opts.TypedAnswerIgnoreCase
+	}
+	if opts.TypedAnswerIgnoreAccents != nil {
+		preset.TypedAnswerIgnoreAccents = *opts.TypedAnswerIgnoreAccents
+	}
+	if opts.TypedAnswerIgnorePunctuation != nil {
+		preset.TypedAnswerIgnorePunctuation = *opts.TypedAnswerIgnorePunctuation
+	}
+
+	if preset.MinimumEase > preset.StartingEase {
+		return fmt.Errorf("invalid deck preset: minimum ease cannot exceed starting ease")
+	}
+
+	return nil
+}
+
+// presetSchedulerConfig returns base with a deck preset's options applied
+func presetSchedulerConfig(base SchedulerConfig, preset *models.DeckPreset) SchedulerConfig {
+	if preset == nil {
+		return base
+	}
+
+	base.LearningSteps = minuteSteps(preset.LearningSteps)
+	base.RelearningSteps = minuteSteps(preset.RelearningSteps)
+	base.MinimumEase = preset.MinimumEase
+	base.IntervalModifier = preset.IntervalModifier
+	base.MaximumInterval = preset.MaximumInterval
+	return base
+}
+
+// minuteSteps converts steps stored in minutes into durations
+func minuteSteps(minutes []int64) []time.Duration {
+	steps := make([]time.Duration, len(minutes))
+	for i, m := range minutes {
+		steps[i] = time.Duration(m) * time.Minute
+	}
+	return steps
+}
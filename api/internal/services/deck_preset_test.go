@@ -0,0 +1,265 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+// MockDeckPresetRepository is a mock implementation of DeckPresetRepository for testing
+type MockDeckPresetRepository struct {
+	mock.Mock
+}
+
+func (m *MockDeckPresetRepository) Create(preset *models.DeckPreset) (*models.DeckPreset, error) {
+	args := m.Called(preset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DeckPreset), args.Error(1)
+}
+
+func (m *MockDeckPresetRepository) GetByID(id uuid.UUID) (*models.DeckPreset, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DeckPreset), args.Error(1)
+}
+
+func (m *MockDeckPresetRepository) GetByUser(userID uuid.UUID) ([]*models.DeckPreset, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DeckPreset), args.Error(1)
+}
+
+func (m *MockDeckPresetRepository) Update(preset *models.DeckPreset) (*models.DeckPreset, error) {
+	args := m.Called(preset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DeckPreset), args.Error(1)
+}
+
+func (m *MockDeckPresetRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestDeckPresetService_Create_FillsDefaults(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockDeckPresetRepository{}
+	service := NewDeckPresetService(mockRepo, logger)
+
+	userID := uuid.New()
+	startingEase := 2.3
+	req := &models.CreateDeckPresetRequest{
+		Name:              "Languages",
+		DeckPresetOptions: models.DeckPresetOptions{StartingEase: &startingEase, LearningSteps: []int64{5, 30, 120}},
+	}
+
+	mockRepo.On("Create", mock.MatchedBy(func(preset *models.DeckPreset) bool {
+		return preset.UserID == userID &&
+			preset.Name == "Languages" &&
+			preset.StartingEase == 2.3 &&
+			preset.MinimumEase == SM2MinEaseFactor &&
+			preset.IntervalModifier == 1.0 &&
+			assert.ObjectsAreEqual([]int64{5, 30, 120}, preset.LearningSteps) &&
+			assert.ObjectsAreEqual([]int64{10}, preset.RelearningSteps) &&
+			preset.NewPerDay == 20 &&
+			preset.ReviewsPerDay == 200
+	})).Return(&models.DeckPreset{ID: uuid.New(), Name: "Languages"}, nil)
+
+	result, err := service.Create(req, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Languages", result.Name)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeckPresetService_Create_MinimumEaseAboveStartingEase(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockDeckPresetRepository{}
+	service := NewDeckPresetService(mockRepo, logger)
+
+	minimumEase := 2.8
+	req := &models.CreateDeckPresetRequest{
+		Name:              "Broken",
+		DeckPresetOptions: models.DeckPresetOptions{MinimumEase: &minimumEase},
+	}
+
+	result, err := service.Create(req, uuid.New())
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "invalid deck preset: minimum ease cannot exceed starting ease", err.Error())
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
+func TestDeckPresetService_UpdateWithOwnership_Success(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockDeckPresetRepository{}
+	service := NewDeckPresetService(mockRepo, logger)
+
+	userID := uuid.New()
+	existing := DefaultDeckPreset()
+	existing.ID = uuid.New()
+	existing.UserID = userID
+	existing.Name = "Default"
+
+	modifier := 0.8
+	newPerDay := 5
+	req := &models.UpdateDeckPresetRequest{
+		DeckPresetOptions: models.DeckPresetOptions{IntervalModifier: &modifier, NewPerDay: &newPerDay},
+	}
+
+	mockRepo.On("GetByID", existing.ID).Return(&existing, nil)
+	mockRepo.On("Update", mock.MatchedBy(func(preset *models.DeckPreset) bool {
+		return preset.Name == "Default" && preset.IntervalModifier == 0.8 && preset.NewPerDay == 5 && preset.ReviewsPerDay == 200
+	})).Return(&existing, nil)
+
+	_, err := service.UpdateWithOwnership(existing.ID, userID, req)
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeckPresetService_UpdateWithOwnership_Unauthorized(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockDeckPresetRepository{}
+	service := NewDeckPresetService(mockRepo, logger)
+
+	presetID := uuid.New()
+	mockRepo.On("GetByID", presetID).Return(&models.DeckPreset{ID: presetID, UserID: uuid.New()}, nil)
+
+	result, err := service.UpdateWithOwnership(presetID, uuid.New(), &models.UpdateDeckPresetRequest{})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "unauthorized: deck preset does not belong to user", err.Error())
+	mockRepo.AssertNotCalled(t, "Update")
+}
+
+func TestDeckService_Create_RejectsOtherUsersPreset(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	service := NewDeckService(mockRepo, mockPresetRepo, logger)
+
+	presetID := uuid.New()
+	mockPresetRepo.On("GetByID", presetID).Return(&models.DeckPreset{ID: presetID, UserID: uuid.New()}, nil)
+
+	result, err := service.Create(&models.CreateDeckRequest{Name: "Deck", PresetID: &presetID}, uuid.New())
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "unauthorized: deck preset does not belong to user", err.Error())
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
+func TestPresetSchedulerConfig(t *testing.T) {
+	preset := DefaultDeckPreset()
+	preset.LearningSteps = []int64{1, 60}
+	preset.RelearningSteps = []int64{}
+	preset.MinimumEase = 1.5
+	preset.IntervalModifier = 0.9
+	preset.MaximumInterval = 180
+
+	config := presetSchedulerConfig(DefaultSchedulerConfig(), &preset)
+
+	assert.Equal(t, []time.Duration{time.Minute, time.Hour}, config.LearningSteps)
+	assert.Empty(t, config.RelearningSteps)
+	assert.Equal(t, 1.5, config.MinimumEase)
+	assert.Equal(t, 0.9, config.IntervalModifier)
+	assert.Equal(t, 180, config.MaximumInterval)
+	assert.Equal(t, DefaultSchedulerConfig().GraduatingInterval, config.GraduatingInterval)
+
+	assert.Equal(t, DefaultSchedulerConfig(), presetSchedulerConfig(DefaultSchedulerConfig(), nil))
+}
+
+func TestSM2Scheduler_Schedule_PresetLimits(t *testing.T) {
+	config := DefaultSchedulerConfig()
+	config.MinimumEase = 2.0
+	config.IntervalModifier = 0.5
+	config.MaximumInterval = 5
+	scheduler := NewSM2Scheduler(config)
+	now := time.Now()
+
+	failed := scheduler.Schedule(models.SchedulingState{Interval: 10, EaseFactor: 2.1, ReviewCount: 3, State: models.CardStateReview}, 0, now)
+	assert.Equal(t, 2.0, failed.EaseFactor)
+
+	// round(20 * 2.5 * 0.5) = 25, capped at 5
+	passed := scheduler.Schedule(models.SchedulingState{Interval: 20, EaseFactor: 2.5, ReviewCount: 3, State: models.CardStateReview}, 4, now)
+	assert.Equal(t, 5, passed.Interval)
+}
+
+func TestFlashcardService_Create_UsesPresetStartingEase(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	deckID := uuid.New()
+	presetID := uuid.New()
+	preset := DefaultDeckPreset()
+	preset.ID = presetID
+	preset.StartingEase = 2.1
+
+	mockDeckRepo.On("GetByID", deckID).Return(&models.Deck{ID: deckID, UserID: userID, PresetID: &presetID}, nil)
+	mockPresetRepo.On("GetByID", presetID).Return(&preset, nil)
+	mockRepo.On("Create", mock.MatchedBy(func(card *models.Flashcard) bool {
+		return card.EaseFactor == 2.1 && card.Difficulty == 2.1 && card.State == models.CardStateNew
+	})).Return(&models.Flashcard{ID: uuid.New()}, nil)
+
+	_, err := service.Create(&models.CreateFlashcardRequest{Front: "Q", Back: "A", UserID: userID, DeckID: deckID})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockPresetRepo.AssertExpectations(t)
+}
+
+func TestFlashcardService_ReviewFlashcard_UsesPresetSteps(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	cardID := uuid.New()
+	deckID := uuid.New()
+	presetID := uuid.New()
+	preset := DefaultDeckPreset()
+	preset.ID = presetID
+	preset.LearningSteps = []int64{30}
+	card := &models.Flashcard{ID: cardID, DeckID: deckID, EaseFactor: 2.5, Interval: 1, State: models.CardStateNew}
+
+	mockRepo.On("GetByID", cardID).Return(card, nil)
+	mockDeckRepo.On("GetByID", deckID).Return(&models.Deck{ID: deckID, Scheduler: models.SchedulerSM2, PresetID: &presetID}, nil)
+	mockUserRepo.On("GetByID", card.UserID).Return(&models.User{ID: card.UserID}, nil)
+	mockPresetRepo.On("GetByID", presetID).Return(&preset, nil)
+	mockRepo.On("UpdateSchedulingState", cardID, mock.MatchedBy(func(state models.SchedulingState) bool {
+		// Hard on the only step is 1.5x the step
+		return state.State == models.CardStateLearning && state.NextReview.Sub(*state.LastReview) == 45*time.Minute
+	})).Return(card, nil)
+	mockReviewLogRepo.On("Create", mock.AnythingOfType("*models.ReviewLog")).Return(&models.ReviewLog{}, nil)
+
+	_, err := service.ReviewFlashcard(cardID, &models.ReviewFlashcardRequest{Quality: 3})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -71,7 +71,7 @@ func (m *MockDeckRepository) GetDeckFlashcardCount(deckID uuid.UUID) (int, error
 func TestDeckService_Create_Success(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	userID := uuid.New()
 	req := &models.CreateDeckRequest{
@@ -103,7 +103,7 @@ func TestDeckService_Create_Success(t *testing.T) {
 func TestDeckService_Create_RepositoryError(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	userID := uuid.New()
 	req := &models.CreateDeckRequest{
@@ -125,7 +125,7 @@ func TestDeckService_Create_RepositoryError(t *testing.T) {
 func TestDeckService_GetByID_Success(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	deckID := uuid.New()
 	expectedDeck := &models.Deck{
@@ -150,7 +150,7 @@ func TestDeckService_GetByID_Success(t *testing.T) {
 func TestDeckService_GetByID_NotFound(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	deckID := uuid.New()
 	mockRepo.On("GetByID", deckID).Return(nil, sql.ErrNoRows)
@@ -167,7 +167,7 @@ func TestDeckService_GetByID_NotFound(t *testing.T) {
 func TestDeckService_GetByIDWithOwnership_Success(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	deckID := uuid.New()
 	userID := uuid.New()
@@ -193,7 +193,7 @@ func TestDeckService_GetByIDWithOwnership_Success(t *testing.T) {
 func TestDeckService_GetByIDWithOwnership_Unauthorized(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	deckID := uuid.New()
 	userID := uuid.New()
@@ -221,7 +221,7 @@ func TestDeckService_GetByIDWithOwnership_Unauthorized(t *testing.T) {
 func TestDeckService_GetAll_Success(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	expectedDecks := []*models.Deck{
 		{
@@ -253,7 +253,7 @@ func TestDeckService_GetAll_Success(t *testing.T) {
 func TestDeckService_GetAll_RepositoryError(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	mockRepo.On("GetAll").Return(nil, assert.AnError)
 
@@ -269,7 +269,7 @@ func TestDeckService_GetAll_RepositoryError(t *testing.T) {
 func TestDeckService_GetByUser_Success(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	userID := uuid.New()
 	expectedDecks := []*models.Deck{
@@ -295,7 +295,7 @@ func TestDeckService_GetByUser_Success(t *testing.T) {
 func TestDeckService_Update_Name(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	deckID := uuid.New()
 	newName := "Updated Deck Name"
@@ -333,10 +333,66 @@ func TestDeckService_Update_Name(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestDeckService_Create_DesiredRetention(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockDeckRepository{}
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
+
+	var created []*models.Deck
+	mockRepo.On("Create", mock.AnythingOfType("*models.Deck")).
+		Run(func(args mock.Arguments) { created = append(created, args.Get(0).(*models.Deck)) }).
+		Return(&models.Deck{}, nil)
+
+	retention := 0.95
+	_, err := service.Create(&models.CreateDeckRequest{Name: "Default"}, uuid.New())
+	require.NoError(t, err)
+	_, err = service.Create(&models.CreateDeckRequest{Name: "Strict", DesiredRetention: &retention}, uuid.New())
+	require.NoError(t, err)
+
+	require.Len(t, created, 2)
+	assert.Equal(t, models.DefaultDesiredRetention, created[0].DesiredRetention)
+	assert.Equal(t, 0.95, created[1].DesiredRetention)
+}
+
+func TestDeckService_Update_DesiredRetention(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockDeckRepository{}
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
+
+	existingDeck := testutils.CreateTestDeck(uuid.New())
+	retention := 0.87
+
+	mockRepo.On("GetByID", existingDeck.ID).Return(existingDeck, nil)
+	mockRepo.On("Update", existingDeck.ID, map[string]interface{}{"desired_retention": 0.87}).Return(existingDeck, nil)
+
+	_, err := service.Update(existingDeck.ID, &models.UpdateDeckRequest{DesiredRetention: &retention})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeckService_Update_ClearPreset(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockDeckRepository{}
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
+
+	existingDeck := testutils.CreateTestDeck(uuid.New())
+	presetID := uuid.New()
+	existingDeck.PresetID = &presetID
+
+	mockRepo.On("GetByID", existingDeck.ID).Return(existingDeck, nil)
+	mockRepo.On("Update", existingDeck.ID, map[string]interface{}{"preset_id": nil}).Return(existingDeck, nil)
+
+	_, err := service.Update(existingDeck.ID, &models.UpdateDeckRequest{ClearPreset: true})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestDeckService_Update_Description(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	deckID := uuid.New()
 	newDescription := "Updated Description"
@@ -377,7 +433,7 @@ func TestDeckService_Update_Description(t *testing.T) {
 func TestDeckService_Update_NoChanges(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	deckID := uuid.New()
 	originalName := "Original Name"
@@ -411,7 +467,7 @@ func TestDeckService_Update_NoChanges(t *testing.T) {
 func TestDeckService_UpdateWithOwnership_Success(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	deckID := uuid.New()
 	userID := uuid.New()
@@ -453,7 +509,7 @@ func TestDeckService_UpdateWithOwnership_Success(t *testing.T) {
 func TestDeckService_UpdateWithOwnership_Unauthorized(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	deckID := uuid.New()
 	userID := uuid.New()
@@ -486,7 +542,7 @@ func TestDeckService_UpdateWithOwnership_Unauthorized(t *testing.T) {
 func TestDeckService_Delete_Success(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	deckID := uuid.New()
 	existingDeck := &models.Deck{
@@ -512,7 +568,7 @@ func TestDeckService_Delete_Success(t *testing.T) {
 func TestDeckService_Delete_WithFlashcards(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	deckID := uuid.New()
 	existingDeck := &models.Deck{
@@ -538,7 +594,7 @@ func TestDeckService_Delete_WithFlashcards(t *testing.T) {
 func TestDeckService_Delete_NotFound(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	deckID := uuid.New()
 
@@ -556,7 +612,7 @@ func TestDeckService_Delete_NotFound(t *testing.T) {
 func TestDeckService_Delete_RepositoryError(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockDeckRepository{}
-	service := NewDeckService(mockRepo, logger)
+	service := NewDeckService(mockRepo, &MockDeckPresetRepository{}, logger)
 
 	deckID := uuid.New()
 	existingDeck := &models.Deck{
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/repositories"
+)
+
+// defaultFilteredDeckLimit is how many cards a filtered deck holds unless set otherwise
+const defaultFilteredDeckLimit = 100
+
+// FilteredDeckService builds filtered decks: temporary decks holding the cards that
+// match a search, pulled out of their home decks until the deck is emptied
+type FilteredDeckService struct {
+	deckRepo      repositories.DeckRepositoryInterface
+	flashcardRepo repositories.FlashcardRepositoryInterface
+	reviewLogRepo repositories.ReviewLogRepositoryInterface
+	userRepo      repositories.UserRepositoryInterface
+	Logger        *logrus.Logger
+}
+
+func NewFilteredDeckService(
+	deckRepo repositories.DeckRepositoryInterface,
+	flashcardRepo repositories.FlashcardRepositoryInterface,
+	reviewLogRepo repositories.ReviewLogRepositoryInterface,
+	userRepo repositories.UserRepositoryInterface,
+	logger *logrus.Logger,
+) *FilteredDeckService {
+	return &FilteredDeckService{
+		deckRepo:      deckRepo,
+		flashcardRepo: flashcardRepo,
+		reviewLogRepo: reviewLogRepo,
+		userRepo:      userRepo,
+		Logger:        logger,
+	}
+}
+
+// Create creates a filtered deck for a user and fills it with the matching cards
+func (s *FilteredDeckService) Create(req *models.CreateFilteredDeckRequest, userID uuid.UUID) (*models.FilteredDeckBuild, error) {
+	filter := models.DeckFilter{
+		Search:     req.Search,
+		Order:      req.Order,
+		Limit:      req.Limit,
+		Reschedule: true,
+	}
+	if filter.Order == "" {
+		filter.Order = models.FilterOrderDue
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultFilteredDeckLimit
+	}
+	if req.Reschedule != nil {
+		filter.Reschedule = *req.Reschedule
+	}
+
+	if _, err := parseCardSearch(filter.Search); err != nil {
+		return nil, err
+	}
+
+	deck, err := s.deckRepo.Create(&models.Deck{
+		ID:               uuid.New(),
+		UserID:           userID,
+		Name:             req.Name,
+		Description:      req.Description,
+		Scheduler:        models.SchedulerSM2,
+		DesiredRetention: models.DefaultDesiredRetention,
+		Filter:           &filter,
+	})
+	if err != nil {
+		s.Logger.WithError(err).Error("Service failed to create filtered deck")
+		return nil, fmt.Errorf("failed to create deck: %w", err)
+	}
+
+	return s.build(deck)
+}
+
+// UpdateWithOwnership changes the definition of a filtered deck of the user and rebuilds it
+func (s *FilteredDeckService) UpdateWithOwnership(id uuid.UUID, userID uuid.UUID, req *models.UpdateFilteredDeckRequest) (*models.FilteredDeckBuild, error) {
+	deck, err := s.filteredDeck(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := *deck.Filter
+	if req.Search != nil {
+		filter.Search = *req.Search
+	}
+	if req.Order != nil {
+		filter.Order = *req.Order
+	}
+	if req.Limit != nil {
+		filter.Limit = *req.Limit
+	}
+	if req.Reschedule != nil {
+		filter.Reschedule = *req.Reschedule
+	}
+
+	if _, err := parseCardSearch(filter.Search); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.deckRepo.Update(id, map[string]interface{}{"filter": filter})
+	if err != nil {
+		s.Logger.WithError(err).WithField("deck_id", id).Error("Service failed to update filtered deck")
+		return nil, fmt.Errorf("failed to update deck: %w", err)
+	}
+
+	return s.build(updated)
+}
+
+// RebuildWithOwnership returns the cards of a filtered deck of the user to their home
+// decks and pulls in the cards matching its search again
+func (s *FilteredDeckService) RebuildWithOwnership(id uuid.UUID, userID uuid.UUID) (*models.FilteredDeckBuild, error) {
+	deck, err := s.filteredDeck(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.build(deck)
+}
+
+// EmptyWithOwnership returns every card of a filtered deck of the user to its home deck
+func (s *FilteredDeckService) EmptyWithOwnership(id uuid.UUID, userID uuid.UUID) (*models.FilteredDeckBuild, error) {
+	deck, err := s.filteredDeck(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	returned, err := s.flashcardRepo.EmptyFilteredDeck(deck.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"deck_id":         deck.ID,
+		"flashcard_count": returned,
+	}).Info("Filtered deck emptied")
+
+	return &models.FilteredDeckBuild{Deck: deck}, nil
+}
+
+// filteredDeck returns a filtered deck after checking that it belongs to the user
+func (s *FilteredDeckService) filteredDeck(id uuid.UUID, userID uuid.UUID) (*models.Deck, error) {
+	deck, err := s.deckRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("deck not found")
+	}
+
+	if deck.UserID != userID {
+		s.Logger.WithFields(logrus.Fields{
+			"deck_id":  id,
+			"user_id":  userID,
+			"owner_id": deck.UserID,
+		}).Warn("Unauthorized attempt to access filtered deck")
+		return nil, fmt.Errorf("unauthorized: deck does not belong to user")
+	}
+
+	if deck.Filter == nil {
+		return nil, fmt.Errorf("deck is not a filtered deck")
+	}
+
+	return deck, nil
+}
+
+// build empties a filtered deck and moves the cards matching its search into it.
+// Suspended and buried cards, and cards already in another filtered deck, are left out.
+func (s *FilteredDeckService) build(deck *models.Deck) (*models.FilteredDeckBuild, error) {
+	search, err := parseCardSearch(deck.Filter.Search)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.flashcardRepo.EmptyFilteredDeck(deck.ID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(deck.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	flashcards, err := s.flashcardRepo.GetByUser(deck.UserID)
+	if err != nil {
+		s.Logger.WithError(err).WithField("user_id", deck.UserID).Error("Service failed to get flashcards for filtered deck")
+		return nil, fmt.Errorf("failed to get flashcards: %w", err)
+	}
+
+	ctx, err := s.searchContext(user, search)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*models.Flashcard
+	for _, card := range flashcards {
+		if card.HomeDeckID != nil || card.Suspended || (card.BuriedUntil != nil && card.BuriedUntil.After(ctx.now)) {
+			continue
+		}
+		if search.matches(card, ctx) {
+			matched = append(matched, card)
+		}
+	}
+
+	sortFilteredCards(matched, deck.Filter.Order)
+	if len(matched) > deck.Filter.Limit {
+		matched = matched[:deck.Filter.Limit]
+	}
+
+	build := &models.FilteredDeckBuild{Deck: deck}
+	if len(matched) > 0 {
+		ids := make([]uuid.UUID, len(matched))
+		for i, card := range matched {
+			ids[i] = card.ID
+		}
+
+		moved, err := s.flashcardRepo.MoveToFilteredDeck(ids, deck.ID)
+		if err != nil {
+			return nil, err
+		}
+		build.CardCount = len(moved)
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"deck_id":         deck.ID,
+		"search":          deck.Filter.Search,
+		"matched_count":   len(matched),
+		"flashcard_count": build.CardCount,
+	}).Info("Filtered deck built")
+
+	return build, nil
+}
+
+// searchContext loads what a search needs to match the cards of a user
+func (s *FilteredDeckService) searchContext(user *models.User, search *cardSearch) (*cardSearchContext, error) {
+	decks, err := s.deckRepo.GetByUser(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decks: %w", err)
+	}
+
+	ctx := &cardSearchContext{
+		now:   time.Now(),
+		clock: studyClockForUser(user),
+		decks: make(map[uuid.UUID]*models.Deck, len(decks)),
+	}
+	for _, deck := range decks {
+		ctx.decks[deck.ID] = deck
+	}
+
+	if search.ratedDays > 0 {
+		since := ctx.clock.dayStart(ctx.now).AddDate(0, 0, 1-search.ratedDays)
+		logs, err := s.reviewLogRepo.GetByUserSince(user.ID, since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get review history: %w", err)
+		}
+
+		ctx.reviews = make(map[uuid.UUID][]*models.ReviewLog)
+		for _, log := range logs {
+			ctx.reviews[log.FlashcardID] = append(ctx.reviews[log.FlashcardID], log)
+		}
+	}
+
+	return ctx, nil
+}
+
+// sortFilteredCards orders the cards matching a filtered deck's search
+func sortFilteredCards(cards []*models.Flashcard, order string) {
+	if order == models.FilterOrderRandom {
+		rand.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+		return
+	}
+
+	sort.SliceStable(cards, func(i, j int) bool {
+		a, b := cards[i], cards[j]
+		switch order {
+		case models.FilterOrderAdded:
+			return a.CreatedAt.Before(b.CreatedAt)
+		case models.FilterOrderLapses:
+			return a.Lapses > b.Lapses
+		case models.FilterOrderInterval:
+			return a.Interval < b.Interval
+		case models.FilterOrderIntervalDesc:
+			return a.Interval > b.Interval
+		default:
+			// Cards never scheduled (new cards) come last
+			if a.NextReview == nil || b.NextReview == nil {
+				return a.NextReview != nil && b.NextReview == nil
+			}
+			return a.NextReview.Before(*b.NextReview)
+		}
+	})
+}
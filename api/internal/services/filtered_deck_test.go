@@ -0,0 +1,193 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+func newTestFilteredDeckService() (*FilteredDeckService, *MockDeckRepository, *MockFlashcardRepository, *MockReviewLogRepository, *MockUserRepository) {
+	mockDeckRepo := &MockDeckRepository{}
+	mockFlashcardRepo := &MockFlashcardRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFilteredDeckService(mockDeckRepo, mockFlashcardRepo, mockReviewLogRepo, mockUserRepo, testutils.TestLogger())
+	return service, mockDeckRepo, mockFlashcardRepo, mockReviewLogRepo, mockUserRepo
+}
+
+func newTestFilteredDeck(userID uuid.UUID, filter models.DeckFilter) *models.Deck {
+	deck := testutils.CreateTestDeck(userID)
+	deck.Name = "Cram"
+	deck.Filter = &filter
+	return deck
+}
+
+func TestFilteredDeckService_Create(t *testing.T) {
+	service, mockDeckRepo, mockFlashcardRepo, _, mockUserRepo := newTestFilteredDeckService()
+
+	user := testutils.CreateTestUser()
+	home := testutils.CreateTestDeck(user.ID)
+	now := time.Now()
+	dueSoon, dueLater := now.Add(-48*time.Hour), now.Add(-24*time.Hour)
+
+	later := testutils.CreateTestFlashcard(user.ID, home.ID)
+	later.Front, later.NextReview = "review later", &dueLater
+	soon := testutils.CreateTestFlashcard(user.ID, home.ID)
+	soon.Front, soon.NextReview = "review soon", &dueSoon
+	fresh := testutils.CreateTestFlashcard(user.ID, home.ID)
+	fresh.Front = "review new"
+	suspended := testutils.CreateTestFlashcard(user.ID, home.ID)
+	suspended.Front, suspended.Suspended = "review suspended", true
+	unrelated := testutils.CreateTestFlashcard(user.ID, home.ID)
+	unrelated.Front = "something else"
+
+	created := &models.Deck{}
+	mockDeckRepo.On("Create", mock.AnythingOfType("*models.Deck")).
+		Run(func(args mock.Arguments) { *created = *args.Get(0).(*models.Deck) }).
+		Return(created, nil)
+	mockDeckRepo.On("GetByUser", user.ID).Return([]*models.Deck{home}, nil)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockFlashcardRepo.On("EmptyFilteredDeck", mock.AnythingOfType("uuid.UUID")).Return(0, nil)
+	mockFlashcardRepo.On("GetByUser", user.ID).Return([]*models.Flashcard{later, soon, fresh, suspended, unrelated}, nil)
+	mockFlashcardRepo.On("MoveToFilteredDeck", []uuid.UUID{soon.ID, later.ID}, mock.AnythingOfType("uuid.UUID")).
+		Return([]*models.Flashcard{soon, later}, nil)
+
+	build, err := service.Create(&models.CreateFilteredDeckRequest{
+		Name:   "Cram",
+		Search: "review",
+		Limit:  2,
+	}, user.ID)
+
+	require.NoError(t, err)
+	require.NotNil(t, created.Filter)
+	assert.Equal(t, models.DeckFilter{Search: "review", Order: models.FilterOrderDue, Limit: 2, Reschedule: true}, *created.Filter)
+	assert.Equal(t, 2, build.CardCount)
+	mockFlashcardRepo.AssertExpectations(t)
+}
+
+func TestFilteredDeckService_Create_InvalidSearch(t *testing.T) {
+	service, mockDeckRepo, _, _, _ := newTestFilteredDeckService()
+
+	_, err := service.Create(&models.CreateFilteredDeckRequest{Name: "Cram", Search: "is:sleepy"}, uuid.New())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid search")
+	mockDeckRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestFilteredDeckService_Rebuild(t *testing.T) {
+	service, mockDeckRepo, mockFlashcardRepo, mockReviewLogRepo, mockUserRepo := newTestFilteredDeckService()
+
+	user := testutils.CreateTestUser()
+	home := testutils.CreateTestDeck(user.ID)
+	deck := newTestFilteredDeck(user.ID, models.DeckFilter{Search: "rated:1:again", Order: models.FilterOrderLapses, Limit: 10})
+
+	fewLapses := testutils.CreateTestFlashcard(user.ID, home.ID)
+	fewLapses.Lapses = 1
+	manyLapses := testutils.CreateTestFlashcard(user.ID, home.ID)
+	manyLapses.Lapses = 4
+	recalled := testutils.CreateTestFlashcard(user.ID, home.ID)
+	// Cards already in another filtered deck stay where they are
+	elsewhere := testutils.CreateTestFlashcard(user.ID, uuid.New())
+	elsewhere.HomeDeckID = &home.ID
+
+	failed := []*models.ReviewLog{
+		{FlashcardID: fewLapses.ID, Quality: 1, ReviewedAt: time.Now()},
+		{FlashcardID: manyLapses.ID, Quality: 0, ReviewedAt: time.Now()},
+		{FlashcardID: recalled.ID, Quality: 4, ReviewedAt: time.Now()},
+		{FlashcardID: elsewhere.ID, Quality: 1, ReviewedAt: time.Now()},
+	}
+
+	mockDeckRepo.On("GetByID", deck.ID).Return(deck, nil)
+	mockDeckRepo.On("GetByUser", user.ID).Return([]*models.Deck{home, deck}, nil)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockReviewLogRepo.On("GetByUserSince", user.ID, mock.AnythingOfType("time.Time")).Return(failed, nil)
+	mockFlashcardRepo.On("EmptyFilteredDeck", deck.ID).Return(3, nil)
+	mockFlashcardRepo.On("GetByUser", user.ID).Return([]*models.Flashcard{fewLapses, manyLapses, recalled, elsewhere}, nil)
+	mockFlashcardRepo.On("MoveToFilteredDeck", []uuid.UUID{manyLapses.ID, fewLapses.ID}, deck.ID).
+		Return([]*models.Flashcard{manyLapses, fewLapses}, nil)
+
+	build, err := service.RebuildWithOwnership(deck.ID, user.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, deck, build.Deck)
+	assert.Equal(t, 2, build.CardCount)
+	mockFlashcardRepo.AssertExpectations(t)
+	mockReviewLogRepo.AssertExpectations(t)
+}
+
+func TestFilteredDeckService_Update(t *testing.T) {
+	service, mockDeckRepo, mockFlashcardRepo, _, mockUserRepo := newTestFilteredDeckService()
+
+	user := testutils.CreateTestUser()
+	deck := newTestFilteredDeck(user.ID, models.DeckFilter{Search: "is:due", Order: models.FilterOrderDue, Limit: 100, Reschedule: true})
+	search := "is:new"
+	reschedule := false
+	updated := newTestFilteredDeck(user.ID, models.DeckFilter{Search: search, Order: models.FilterOrderDue, Limit: 100})
+	updated.ID = deck.ID
+
+	mockDeckRepo.On("GetByID", deck.ID).Return(deck, nil)
+	mockDeckRepo.On("Update", deck.ID, map[string]interface{}{"filter": *updated.Filter}).Return(updated, nil)
+	mockDeckRepo.On("GetByUser", user.ID).Return([]*models.Deck{updated}, nil)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockFlashcardRepo.On("EmptyFilteredDeck", deck.ID).Return(0, nil)
+	mockFlashcardRepo.On("GetByUser", user.ID).Return([]*models.Flashcard{}, nil)
+
+	build, err := service.UpdateWithOwnership(deck.ID, user.ID, &models.UpdateFilteredDeckRequest{Search: &search, Reschedule: &reschedule})
+
+	require.NoError(t, err)
+	assert.Equal(t, updated, build.Deck)
+	assert.Equal(t, 0, build.CardCount)
+	mockDeckRepo.AssertExpectations(t)
+	mockFlashcardRepo.AssertNotCalled(t, "MoveToFilteredDeck", mock.Anything, mock.Anything)
+}
+
+func TestFilteredDeckService_Empty(t *testing.T) {
+	service, mockDeckRepo, mockFlashcardRepo, _, _ := newTestFilteredDeckService()
+
+	userID := uuid.New()
+	deck := newTestFilteredDeck(userID, models.DeckFilter{Search: "is:due", Order: models.FilterOrderDue, Limit: 100})
+
+	mockDeckRepo.On("GetByID", deck.ID).Return(deck, nil)
+	mockFlashcardRepo.On("EmptyFilteredDeck", deck.ID).Return(5, nil)
+
+	build, err := service.EmptyWithOwnership(deck.ID, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, build.CardCount)
+	mockFlashcardRepo.AssertExpectations(t)
+}
+
+func TestFilteredDeckService_NotFilteredDeck(t *testing.T) {
+	service, mockDeckRepo, mockFlashcardRepo, _, _ := newTestFilteredDeckService()
+
+	userID := uuid.New()
+	deck := testutils.CreateTestDeck(userID)
+	mockDeckRepo.On("GetByID", deck.ID).Return(deck, nil)
+
+	_, err := service.RebuildWithOwnership(deck.ID, userID)
+
+	require.Error(t, err)
+	assert.Equal(t, "deck is not a filtered deck", err.Error())
+	mockFlashcardRepo.AssertNotCalled(t, "EmptyFilteredDeck", mock.Anything)
+}
+
+func TestFilteredDeckService_Unauthorized(t *testing.T) {
+	service, mockDeckRepo, mockFlashcardRepo, _, _ := newTestFilteredDeckService()
+
+	deck := newTestFilteredDeck(uuid.New(), models.DeckFilter{Search: "is:due", Order: models.FilterOrderDue, Limit: 100})
+	mockDeckRepo.On("GetByID", deck.ID).Return(deck, nil)
+
+	_, err := service.EmptyWithOwnership(deck.ID, uuid.New())
+
+	require.Error(t, err)
+	assert.Equal(t, "unauthorized: deck does not belong to user", err.Error())
+	mockFlashcardRepo.AssertNotCalled(t, "EmptyFilteredDeck", mock.Anything)
+}
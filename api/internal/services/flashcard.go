@@ -3,6 +3,8 @@ package services
 import (
 	"fmt"
 	"math"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,16 +12,36 @@ import (
 
 	"swipelearn-api/internal/models"
 	"swipelearn-api/internal/repositories"
+	"swipelearn-api/internal/utils"
 )
 
 type FlashcardService struct {
 	flashcardRepo repositories.FlashcardRepositoryInterface
+	deckRepo      repositories.DeckRepositoryInterface
+	presetRepo    repositories.DeckPresetRepositoryInterface
+	reviewLogRepo repositories.ReviewLogRepositoryInterface
+	userRepo      repositories.UserRepositoryInterface
+	config        SchedulerConfig
+	undoLimit     int // Maximum number of reviews that can be undone per study session
 	Logger        *logrus.Logger
 }
 
-func NewFlashcardService(repo repositories.FlashcardRepositoryInterface, logger *logrus.Logger) *FlashcardService {
+func NewFlashcardService(
+	repo repositories.FlashcardRepositoryInterface,
+	deckRepo repositories.DeckRepositoryInterface,
+	presetRepo repositories.DeckPresetRepositoryInterface,
+	reviewLogRepo repositories.ReviewLogRepositoryInterface,
+	userRepo repositories.UserRepositoryInterface,
+	logger *logrus.Logger,
+) *FlashcardService {
 	return &FlashcardService{
 		flashcardRepo: repo,
+		deckRepo:      deckRepo,
+		presetRepo:    presetRepo,
+		reviewLogRepo: reviewLogRepo,
+		userRepo:      userRepo,
+		config:        SchedulerConfigFromEnv(logger),
+		undoLimit:     utils.GetEnvAsInt("REVIEW_UNDO_LIMIT", 10),
 		Logger:        logger,
 	}
 }
@@ -34,16 +56,17 @@ func (s *FlashcardService) Create(req *models.CreateFlashcardRequest) (*models.F
 		return nil, fmt.Errorf("user ID is required")
 	}
 
-	card := &models.Flashcard{
-		ID:          uuid.New(),
-		UserID:      req.UserID,
-		Front:       req.Front,
-		Back:        req.Back,
-		DeckID:      req.DeckID,
-		Difficulty:  2.5, // Initial difficulty for new cards
-		Interval:    1,   // Start with 1 day interval
-		EaseFactor:  2.5, // SM-2 default ease factor
-		ReviewCount: 0,
+	deck, err := s.deckRepo.GetByID(req.DeckID)
+	if err != nil {
+		return nil, fmt.Errorf("deck not found: %w", err)
+	}
+
+	card, err := s.newFlashcard(deck, req.UserID, req.Front, req.Back)
+	if err != nil {
+		return nil, err
+	}
+	if req.Format != "" {
+		card.Format = req.Format
 	}
 
 	savedCard, err := s.flashcardRepo.Create(card)
@@ -61,6 +84,32 @@ func (s *FlashcardService) Create(req *models.CreateFlashcardRequest) (*models.F
 	return savedCard, nil
 }
 
+// newFlashcard builds a new, unsaved card for a deck
+func (s *FlashcardService) newFlashcard(deck *models.Deck, userID uuid.UUID, front, back string) (*models.Flashcard, error) {
+	if deck.Filter != nil {
+		return nil, fmt.Errorf("cannot add flashcards to a filtered deck")
+	}
+
+	startingEase, err := s.deckStartingEase(deck)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Flashcard{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Front:       front,
+		Back:        back,
+		Format:      models.ContentFormatPlain,
+		DeckID:      deck.ID,
+		Difficulty:  startingEase, // Initial difficulty for new cards
+		Interval:    1,            // Start with 1 day interval
+		EaseFactor:  startingEase, // SM-2 starting ease factor
+		ReviewCount: 0,
+		State:       models.CardStateNew,
+	}, nil
+}
+
 // GetByUser retrieves flashcards for a user with optional filters
 func (s *FlashcardService) GetByUser(userID uuid.UUID, filters map[string]any) ([]*models.Flashcard, error) {
 	flashcards, err := s.flashcardRepo.GetByUser(userID)
@@ -139,6 +188,11 @@ func (s *FlashcardService) UpdateWithOwnership(id uuid.UUID, userID uuid.UUID, r
 		return nil, fmt.Errorf("unauthorized: flashcard does not belong to user")
 	}
 
+	// Note cards are rendered from the note's fields
+	if existingCard.NoteID != nil && (req.Front != nil || req.Back != nil) {
+		return nil, fmt.Errorf("flashcard belongs to a note; edit the note instead")
+	}
+
 	// Call the regular update method
 	return s.Update(id, req)
 }
@@ -175,12 +229,19 @@ func (s *FlashcardService) DeleteWithOwnership(id uuid.UUID, userID uuid.UUID) e
 		return fmt.Errorf("unauthorized: flashcard does not belong to user")
 	}
 
+	// Editing the note would generate the card again
+	if existingCard.NoteID != nil {
+		return fmt.Errorf("flashcard belongs to a note; delete the note instead")
+	}
+
 	// Call the regular delete method
 	return s.Delete(id)
 }
 
-// ReviewFlashcard handles the spaced repetition review logic using correct SM-2 algorithm
-func (s *FlashcardService) ReviewFlashcard(id uuid.UUID, quality int) (*models.Flashcard, error) {
+// ReviewFlashcard handles the spaced repetition review logic using the deck's scheduler
+func (s *FlashcardService) ReviewFlashcard(id uuid.UUID, req *models.ReviewFlashcardRequest) (*models.Flashcard, error) {
+	quality := req.Quality
+
 	// Validate quality range (0-5)
 	if quality < 0 || quality > 5 {
 		return nil, fmt.Errorf("quality must be between 0 and 5, got %d", quality)
@@ -191,70 +252,396 @@ func (s *FlashcardService) ReviewFlashcard(id uuid.UUID, quality int) (*models.F
 		return nil, fmt.Errorf("flashcard not found: %w", err)
 	}
 
-	// SM-2 Algorithm - Correct Formula
-	q := float64(quality)
-
-	// Correct SM-2 ease factor formula:
-	// EF' = EF + (0.1 - (5-q) * (0.08 + (5-q) * 0.02))
-	newEaseFactor := card.EaseFactor + (0.1 - (5.0-q)*(0.08+(5.0-q)*0.02))
+	// A filtered deck that does not reschedule only previews its cards
+	if card.HomeDeckID != nil {
+		filtered, err := s.deckRepo.GetByID(card.DeckID)
+		if err != nil {
+			return nil, fmt.Errorf("deck not found: %w", err)
+		}
+		if filtered.Filter != nil && !filtered.Filter.Reschedule {
+			return s.previewAnswer(card, req)
+		}
+	}
 
-	// Enforce minimum ease factor of 1.3
-	newEaseFactor = math.Max(1.3, newEaseFactor)
+	planner, err := s.reviewPlanner(card)
+	if err != nil {
+		return nil, err
+	}
 
-	var newInterval int
-	var newRepetitions int
-	var nextReview time.Time
+	now := time.Now()
+	before := card.SchedulingState()
+	next, err := planner.schedule(card, quality, now)
+	if err != nil {
+		return nil, err
+	}
 
-	if q < 3 {
-		// Incorrect response (quality 0, 1, or 2), reset interval and repetitions
-		newInterval = 1
-		newRepetitions = 0
-		nextReview = time.Now().Add(time.Hour * 24)
-	} else {
-		// Correct response (quality 3, 4, or 5)
-		newRepetitions = card.ReviewCount + 1
+	// Cards leave a filtered deck once answered, unless they are still on a learning step
+	var returnedFrom *uuid.UUID
+	if card.HomeDeckID != nil && next.CardState() == models.CardStateReview {
+		returnedFrom = &card.DeckID
+	}
 
-		// Calculate new interval based on repetitions
-		switch newRepetitions {
-		case 1:
-			newInterval = 1
-		case 2:
-			newInterval = 6
-		default:
-			newInterval = int(math.Round(float64(card.Interval) * newEaseFactor))
+	// The review records the siblings it buries, so that undoing it unburies them
+	var buried []uuid.UUID
+	buryUntil := planner.clock.nextDayStart(now)
+	if planner.burySiblings && card.NoteID != nil {
+		if buried, err = s.siblingsToBury(card, buryUntil); err != nil {
+			return nil, err
 		}
-		nextReview = time.Now().Add(time.Hour * 24 * time.Duration(newInterval))
 	}
 
-	// Update the card with all SM-2 fields
-	updateReq := &models.UpdateFlashcardRequest{
-		Difficulty:  &newEaseFactor,
-		Interval:    &newInterval,
-		EaseFactor:  &newEaseFactor,
-		ReviewCount: &newRepetitions,
-		LastReview:  &[]time.Time{time.Now()}[0],
-		NextReview:  &nextReview,
+	updatedCard, err := s.flashcardRepo.UpdateSchedulingState(id, next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update flashcard review: %w", err)
 	}
 
-	updatedCard, err := s.flashcardRepo.Update(id, updateReq)
+	// Keep the raw review so history, undo and tuning can replay it
+	_, err = s.reviewLogRepo.Create(&models.ReviewLog{
+		ID:                 uuid.New(),
+		FlashcardID:        card.ID,
+		UserID:             card.UserID,
+		DeckID:             card.HomeDeck(),
+		Scheduler:          planner.scheduler.Name(),
+		Quality:            quality,
+		TimeTakenMs:        req.TimeTakenMs,
+		StateBefore:        before,
+		StateAfter:         next,
+		SessionID:          req.SessionID,
+		ReviewedAt:         now,
+		BuriedSiblingIDs:   buried,
+		ReturnedFromDeckID: returnedFrom,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update flashcard review: %w", err)
+		s.Logger.WithError(err).WithField("flashcard_id", id).Error("Service failed to record review")
+		return nil, fmt.Errorf("failed to record review: %w", err)
+	}
+
+	if returnedFrom != nil {
+		returned, err := s.flashcardRepo.ReturnToHomeDeck([]uuid.UUID{id})
+		if err != nil {
+			s.Logger.WithError(err).WithField("flashcard_id", id).Error("Service failed to return flashcard to its home deck")
+			return nil, fmt.Errorf("failed to return flashcard to its home deck: %w", err)
+		}
+		if len(returned) == 1 {
+			updatedCard = returned[0]
+		}
+	}
+
+	if next.Leech && !before.Leech {
+		s.Logger.WithFields(logrus.Fields{
+			"flashcard_id": id,
+			"lapses":       next.Lapses,
+			"action":       planner.leechAction,
+		}).Warn("Flashcard became a leech")
+
+		if planner.leechAction == models.LeechActionSuspend {
+			suspended, err := s.flashcardRepo.SetSuspended([]uuid.UUID{id}, true)
+			if err != nil {
+				s.Logger.WithError(err).WithField("flashcard_id", id).Error("Service failed to suspend leech")
+				return nil, fmt.Errorf("failed to suspend leech: %w", err)
+			}
+			if len(suspended) == 1 {
+				updatedCard = suspended[0]
+			}
+		}
+	}
+
+	if len(buried) > 0 {
+		if err := s.burySiblings(card, buried, buryUntil); err != nil {
+			return nil, err
+		}
 	}
 
 	s.Logger.WithFields(logrus.Fields{
 		"flashcard_id":    id,
+		"scheduler":       planner.scheduler.Name(),
 		"quality":         quality,
-		"new_interval":    newInterval,
-		"new_ease_factor": newEaseFactor,
-		"repetitions":     newRepetitions,
-		"next_review":     nextReview,
-	}).Info("Flashcard reviewed successfully with SM-2 algorithm")
+		"new_interval":    next.Interval,
+		"new_ease_factor": next.EaseFactor,
+		"new_stability":   next.Stability,
+		"repetitions":     next.ReviewCount,
+		"next_review":     next.NextReview,
+	}).Info("Flashcard reviewed successfully")
 
 	return updatedCard, nil
 }
 
+// siblingsToBury returns the new and review cards of an answered card's note that are
+// not buried until the given time yet. Siblings on a learning step are left alone so
+// their steps keep running.
+func (s *FlashcardService) siblingsToBury(card *models.Flashcard, until time.Time) ([]uuid.UUID, error) {
+	siblings, err := s.flashcardRepo.GetByNote(*card.NoteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sibling flashcards: %w", err)
+	}
+
+	var ids []uuid.UUID
+	for _, sibling := range siblings {
+		if sibling.ID == card.ID || sibling.Suspended || (sibling.BuriedUntil != nil && !sibling.BuriedUntil.Before(until)) {
+			continue
+		}
+		switch sibling.SchedulingState().CardState() {
+		case models.CardStateNew, models.CardStateReview:
+			ids = append(ids, sibling.ID)
+		}
+	}
+	return ids, nil
+}
+
+// burySiblings buries the given siblings of an answered card until the given time
+func (s *FlashcardService) burySiblings(card *models.Flashcard, ids []uuid.UUID, until time.Time) error {
+	if _, err := s.flashcardRepo.SetBuriedUntil(ids, &until); err != nil {
+		s.Logger.WithError(err).WithField("flashcard_id", card.ID).Error("Service failed to bury sibling flashcards")
+		return fmt.Errorf("failed to bury sibling flashcards: %w", err)
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"flashcard_id":    card.ID,
+		"note_id":         *card.NoteID,
+		"flashcard_count": len(ids),
+		"buried_until":    until,
+	}).Info("Sibling flashcards buried")
+
+	return nil
+}
+
+// previewAnswer handles an answer to a card in a filtered deck that does not reschedule.
+// Its schedule is left alone and the answer is recorded like a cram answer; once the
+// card is recalled it goes back to its home deck.
+func (s *FlashcardService) previewAnswer(card *models.Flashcard, req *models.ReviewFlashcardRequest) (*models.Flashcard, error) {
+	home, err := s.deckRepo.GetByID(card.HomeDeck())
+	if err != nil {
+		return nil, fmt.Errorf("deck not found: %w", err)
+	}
+
+	var returnedFrom *uuid.UUID
+	if req.Quality >= 3 {
+		returnedFrom = &card.DeckID
+	}
+
+	state := card.SchedulingState()
+	_, err = s.reviewLogRepo.Create(&models.ReviewLog{
+		ID:                 uuid.New(),
+		FlashcardID:        card.ID,
+		UserID:             card.UserID,
+		DeckID:             home.ID,
+		Scheduler:          home.Scheduler,
+		Quality:            req.Quality,
+		TimeTakenMs:        req.TimeTakenMs,
+		StateBefore:        state,
+		StateAfter:         state,
+		SessionID:          req.SessionID,
+		ReviewedAt:         time.Now(),
+		Cram:               true,
+		ReturnedFromDeckID: returnedFrom,
+	})
+	if err != nil {
+		s.Logger.WithError(err).WithField("flashcard_id", card.ID).Error("Service failed to record preview answer")
+		return nil, fmt.Errorf("failed to record review: %w", err)
+	}
+
+	if returnedFrom == nil {
+		return card, nil
+	}
+
+	returned, err := s.flashcardRepo.ReturnToHomeDeck([]uuid.UUID{card.ID})
+	if err != nil {
+		s.Logger.WithError(err).WithField("flashcard_id", card.ID).Error("Service failed to return flashcard to its home deck")
+		return nil, fmt.Errorf("failed to return flashcard to its home deck: %w", err)
+	}
+	if len(returned) != 1 {
+		return card, nil
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"flashcard_id": card.ID,
+		"deck_id":      card.DeckID,
+		"quality":      req.Quality,
+	}).Info("Previewed flashcard returned to its home deck")
+
+	return returned[0], nil
+}
+
+// PreviewWithOwnership computes the scheduling state a flashcard would get for every
+// quality without saving anything, after checking that the card belongs to the user
+func (s *FlashcardService) PreviewWithOwnership(id uuid.UUID, userID uuid.UUID) ([]*models.ReviewPreview, error) {
+	card, err := s.flashcardRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("flashcard not found: %w", err)
+	}
+
+	if card.UserID != userID {
+		s.Logger.WithFields(logrus.Fields{
+			"flashcard_id": id,
+			"user_id":      userID,
+			"owner_id":     card.UserID,
+		}).Warn("Unauthorized attempt to preview flashcard")
+		return nil, fmt.Errorf("unauthorized: flashcard does not belong to user")
+	}
+
+	planner, err := s.reviewPlanner(card)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	previews := make([]*models.ReviewPreview, 0, 6)
+	for quality := 0; quality <= 5; quality++ {
+		next, err := planner.schedule(card, quality, now)
+		if err != nil {
+			return nil, err
+		}
+		previews = append(previews, &models.ReviewPreview{
+			Quality:    quality,
+			State:      next.State,
+			Interval:   next.Interval,
+			EaseFactor: next.EaseFactor,
+			NextReview: next.NextReview,
+		})
+	}
+
+	return previews, nil
+}
+
+// reviewPlanner schedules answers to one card: it runs the deck's scheduler, fuzzes
+// and balances day-based intervals, moves them onto the owner's study days and
+// counts lapses against the deck's leech threshold
+type reviewPlanner struct {
+	scheduler      Scheduler
+	config         SchedulerConfig
+	clock          studyClock
+	leechThreshold int
+	leechAction    string
+	burySiblings   bool // Bury the other cards of a note once one is answered
+	flashcardRepo  repositories.FlashcardRepositoryInterface
+}
+
+// reviewPlanner returns the planner for answering a card
+func (s *FlashcardService) reviewPlanner(card *models.Flashcard) (*reviewPlanner, error) {
+	deck, err := s.deckRepo.GetByID(card.HomeDeck())
+	if err != nil {
+		return nil, fmt.Errorf("deck not found: %w", err)
+	}
+
+	user, err := s.userRepo.GetByID(card.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	return s.deckPlanner(deck, user)
+}
+
+// deckPlanner returns the planner for answering cards of a deck owned by user
+func (s *FlashcardService) deckPlanner(deck *models.Deck, user *models.User) (*reviewPlanner, error) {
+	preset, err := s.deckPreset(deck)
+	if err != nil {
+		return nil, err
+	}
+
+	config := presetSchedulerConfig(s.config, preset)
+	config.FSRSWeights = user.FSRSWeights
+	config.DesiredRetention = deck.DesiredRetention
+	scheduler, err := NewScheduler(deck.Scheduler, config)
+	if err != nil {
+		s.Logger.WithError(err).WithField("deck_id", deck.ID).Error("Deck has an invalid scheduler")
+		return nil, err
+	}
+
+	options := DefaultDeckPreset()
+	if preset != nil {
+		options = *preset
+	}
+
+	return &reviewPlanner{
+		scheduler:      scheduler,
+		config:         config,
+		clock:          studyClockForUser(user),
+		leechThreshold: options.LeechThreshold,
+		leechAction:    options.LeechAction,
+		burySiblings:   options.BurySiblings,
+		flashcardRepo:  s.flashcardRepo,
+	}, nil
+}
+
+// schedule computes the scheduling state after answering a card, without saving it
+func (p *reviewPlanner) schedule(card *models.Flashcard, quality int, now time.Time) (models.SchedulingState, error) {
+	before := card.SchedulingState()
+	next := p.scheduler.Schedule(before, quality, now)
+
+	if next.CardState() == models.CardStateReview && next.Interval > 0 {
+		interval, err := p.spreadInterval(card, before.ReviewCount, next.Interval, now)
+		if err != nil {
+			return models.SchedulingState{}, err
+		}
+		next.Interval = interval
+	}
+
+	p.clock.alignToStudyDay(&next, now)
+
+	// Failing a card in review is a lapse; enough of them make the card a leech
+	next.Lapses, next.Leech = before.Lapses, before.Leech
+	if before.CardState() == models.CardStateReview && quality < 3 {
+		next.Lapses++
+		if p.leechThreshold > 0 && next.Lapses >= p.leechThreshold {
+			next.Leech = true
+		}
+	}
+
+	return next, nil
+}
+
+// spreadInterval fuzzes a review interval within its fuzz range and, with load
+// balancing on, moves it to the day of that range with the fewest reviews due
+func (p *reviewPlanner) spreadInterval(card *models.Flashcard, reviewCount int, interval int, now time.Time) (int, error) {
+	if !p.config.IntervalFuzz && !p.config.LoadBalancing {
+		return interval, nil
+	}
+
+	low, high := fuzzRange(interval, p.config.MaximumInterval)
+	if low == high {
+		return interval, nil
+	}
+
+	target := interval
+	if p.config.IntervalFuzz {
+		target = low + fuzzSeed(card.ID, reviewCount).IntN(high-low+1)
+	}
+	if !p.config.LoadBalancing {
+		return target, nil
+	}
+
+	dayStart := p.clock.dayStart(now)
+	dueDates, err := p.flashcardRepo.GetReviewDueDates(card.UserID, dayStart.AddDate(0, 0, low), dayStart.AddDate(0, 0, high+1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get review load: %w", err)
+	}
+
+	load := make(map[int]int, high-low+1)
+	for _, due := range dueDates {
+		day := p.clock.dayStart(due)
+		load[int(math.Round(day.Sub(dayStart).Hours()/24))]++
+	}
+
+	return leastLoadedInterval(low, high, target, load), nil
+}
+
+// deckPreset returns the option preset of a deck, or nil when it uses the defaults
+func (s *FlashcardService) deckPreset(deck *models.Deck) (*models.DeckPreset, error) {
+	if deck.PresetID == nil {
+		return nil, nil
+	}
+
+	preset, err := s.presetRepo.GetByID(*deck.PresetID)
+	if err != nil {
+		s.Logger.WithError(err).WithField("deck_id", deck.ID).Error("Service failed to get deck preset")
+		return nil, fmt.Errorf("failed to get deck preset: %w", err)
+	}
+
+	return preset, nil
+}
+
 // ReviewFlashcardWithOwnership handles the spaced repetition review logic with user ownership validation
-func (s *FlashcardService) ReviewFlashcardWithOwnership(id uuid.UUID, userID uuid.UUID, quality int) (*models.Flashcard, error) {
+func (s *FlashcardService) ReviewFlashcardWithOwnership(id uuid.UUID, userID uuid.UUID, req *models.ReviewFlashcardRequest) (*models.Flashcard, error) {
 	// Get the flashcard first
 	card, err := s.flashcardRepo.GetByID(id)
 	if err != nil {
@@ -272,31 +659,442 @@ func (s *FlashcardService) ReviewFlashcardWithOwnership(id uuid.UUID, userID uui
 	}
 
 	// Call the regular review method
-	return s.ReviewFlashcard(id, quality)
+	return s.ReviewFlashcard(id, req)
 }
 
-// GetDueCards retrieves flashcards that are due for review
-func (s *FlashcardService) GetDueCards(userID uuid.UUID) ([]*models.Flashcard, error) {
+// CramFlashcardWithOwnership records an answer given in a cram session, after checking
+// that the card belongs to the user. The answer is kept in the review history, flagged
+// as cram, but the card's schedule is left exactly as it was.
+func (s *FlashcardService) CramFlashcardWithOwnership(id uuid.UUID, userID uuid.UUID, req *models.ReviewFlashcardRequest) (*models.Flashcard, error) {
+	if req.Quality < 0 || req.Quality > 5 {
+		return nil, fmt.Errorf("quality must be between 0 and 5, got %d", req.Quality)
+	}
+
+	card, err := s.flashcardRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("flashcard not found: %w", err)
+	}
+
+	if card.UserID != userID {
+		s.Logger.WithFields(logrus.Fields{
+			"flashcard_id": id,
+			"user_id":      userID,
+			"owner_id":     card.UserID,
+		}).Warn("Unauthorized attempt to cram flashcard")
+		return nil, fmt.Errorf("unauthorized: flashcard does not belong to user")
+	}
+
+	deck, err := s.deckRepo.GetByID(card.HomeDeck())
+	if err != nil {
+		return nil, fmt.Errorf("deck not found: %w", err)
+	}
+
+	state := card.SchedulingState()
+	_, err = s.reviewLogRepo.Create(&models.ReviewLog{
+		ID:          uuid.New(),
+		FlashcardID: card.ID,
+		UserID:      card.UserID,
+		DeckID:      deck.ID,
+		Scheduler:   deck.Scheduler,
+		Quality:     req.Quality,
+		TimeTakenMs: req.TimeTakenMs,
+		StateBefore: state,
+		StateAfter:  state,
+		SessionID:   req.SessionID,
+		ReviewedAt:  time.Now(),
+		Cram:        true,
+	})
+	if err != nil {
+		s.Logger.WithError(err).WithField("flashcard_id", id).Error("Service failed to record cram answer")
+		return nil, fmt.Errorf("failed to record review: %w", err)
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"flashcard_id": id,
+		"quality":      req.Quality,
+	}).Info("Cram answer recorded")
+
+	return card, nil
+}
+
+// UndoLastReviewWithOwnership reverts the most recent review of a flashcard, restoring the
+// scheduling state recorded before it. Only reviews answered in the given study session can be
+// undone, and at most undoLimit reviews per session.
+func (s *FlashcardService) UndoLastReviewWithOwnership(id uuid.UUID, userID uuid.UUID, sessionID uuid.UUID) (*models.Flashcard, error) {
+	card, _, err := s.undoLastReview(id, userID, sessionID)
+	return card, err
+}
+
+// undoLastReview undoes the last review of a flashcard like UndoLastReviewWithOwnership
+// and also returns the review that was undone
+func (s *FlashcardService) undoLastReview(id uuid.UUID, userID uuid.UUID, sessionID uuid.UUID) (*models.Flashcard, *models.ReviewLog, error) {
+	card, err := s.flashcardRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("flashcard not found: %w", err)
+	}
+
+	if card.UserID != userID {
+		s.Logger.WithFields(logrus.Fields{
+			"flashcard_id": id,
+			"user_id":      userID,
+			"owner_id":     card.UserID,
+		}).Warn("Unauthorized attempt to undo flashcard review")
+		return nil, nil, fmt.Errorf("unauthorized: flashcard does not belong to user")
+	}
+
+	lastReview, err := s.reviewLogRepo.GetLatestByFlashcard(id)
+	if err != nil {
+		if err.Error() == "review log not found" {
+			return nil, nil, fmt.Errorf("no review to undo")
+		}
+		return nil, nil, fmt.Errorf("failed to get last review: %w", err)
+	}
+
+	// The card may have been reviewed again elsewhere; never rewind past that
+	if lastReview.SessionID == nil || *lastReview.SessionID != sessionID {
+		return nil, nil, fmt.Errorf("last review does not belong to this study session")
+	}
+
+	undone, err := s.reviewLogRepo.CountUndoneBySession(sessionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check undo history: %w", err)
+	}
+	if undone >= s.undoLimit {
+		return nil, nil, fmt.Errorf("undo limit reached for this study session")
+	}
+
+	restoredCard, err := s.flashcardRepo.UpdateSchedulingState(id, lastReview.StateBefore)
+	if err != nil {
+		s.Logger.WithError(err).WithField("flashcard_id", id).Error("Service failed to restore flashcard state")
+		return nil, nil, fmt.Errorf("failed to restore flashcard: %w", err)
+	}
+
+	// Undoing the lapse that made the card a leech also lifts the suspension it caused
+	if lastReview.StateAfter.Leech && !lastReview.StateBefore.Leech && restoredCard.Suspended {
+		unsuspended, err := s.flashcardRepo.SetSuspended([]uuid.UUID{id}, false)
+		if err != nil {
+			s.Logger.WithError(err).WithField("flashcard_id", id).Error("Service failed to unsuspend leech")
+			return nil, nil, fmt.Errorf("failed to restore flashcard: %w", err)
+		}
+		if len(unsuspended) == 1 {
+			restoredCard = unsuspended[0]
+		}
+	}
+
+	// The card goes back to the filtered deck the review returned it from
+	if lastReview.ReturnedFromDeckID != nil {
+		moved, err := s.flashcardRepo.MoveToFilteredDeck([]uuid.UUID{id}, *lastReview.ReturnedFromDeckID)
+		if err != nil {
+			s.Logger.WithError(err).WithField("flashcard_id", id).Error("Service failed to move flashcard back to its filtered deck")
+			return nil, nil, fmt.Errorf("failed to restore flashcard: %w", err)
+		}
+		if len(moved) == 1 {
+			restoredCard = moved[0]
+		}
+	}
+
+	if len(lastReview.BuriedSiblingIDs) > 0 {
+		if _, err := s.flashcardRepo.SetBuriedUntil(lastReview.BuriedSiblingIDs, nil); err != nil {
+			s.Logger.WithError(err).WithField("flashcard_id", id).Error("Service failed to unbury sibling flashcards")
+			return nil, nil, fmt.Errorf("failed to restore flashcard: %w", err)
+		}
+	}
+
+	if err := s.reviewLogRepo.MarkUndone(lastReview.ID); err != nil {
+		s.Logger.WithError(err).WithField("review_log_id", lastReview.ID).Error("Service failed to mark review as undone")
+		return nil, nil, fmt.Errorf("failed to undo review: %w", err)
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"flashcard_id":  id,
+		"review_log_id": lastReview.ID,
+		"session_id":    sessionID,
+		"undo_count":    undone + 1,
+	}).Info("Flashcard review undone successfully")
+
+	return restoredCard, lastReview, nil
+}
+
+// GetReviewHistoryWithOwnership retrieves the review log of a flashcard with user ownership validation
+func (s *FlashcardService) GetReviewHistoryWithOwnership(id uuid.UUID, userID uuid.UUID) ([]*models.ReviewLog, error) {
+	card, err := s.flashcardRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("flashcard not found: %w", err)
+	}
+
+	if card.UserID != userID {
+		s.Logger.WithFields(logrus.Fields{
+			"flashcard_id": id,
+			"user_id":      userID,
+			"owner_id":     card.UserID,
+		}).Warn("Unauthorized attempt to read flashcard reviews")
+		return nil, fmt.Errorf("unauthorized: flashcard does not belong to user")
+	}
+
+	logs, err := s.reviewLogRepo.GetByFlashcard(id)
+	if err != nil {
+		s.Logger.WithError(err).WithField("flashcard_id", id).Error("Service failed to get review history")
+		return nil, fmt.Errorf("failed to get review history: %w", err)
+	}
+
+	return logs, nil
+}
+
+// GetLeeches retrieves the flashcards of a user flagged as leeches, most lapsed first
+func (s *FlashcardService) GetLeeches(userID uuid.UUID) ([]*models.Flashcard, error) {
+	leeches, err := s.flashcardRepo.GetLeeches(userID)
+	if err != nil {
+		s.Logger.WithError(err).WithField("user_id", userID).Error("Service failed to get leeches")
+		return nil, fmt.Errorf("failed to get leeches: %w", err)
+	}
+
+	return leeches, nil
+}
+
+// GetDueCards retrieves flashcards that are due for review. New cards and reviews are
+// limited by what is left of today's budget for their deck and for the user; cards on
+// learning steps are always included.
+func (s *FlashcardService) GetDueCards(userID uuid.UUID) (*models.DueQueue, error) {
+	return s.dueQueue(userID, nil)
+}
+
+// dueQueue builds the due queue of a user, limited to one deck when deckID is set.
+// The daily budget still counts what was studied in every deck.
+func (s *FlashcardService) dueQueue(userID uuid.UUID, deckID *uuid.UUID) (*models.DueQueue, error) {
 	flashcards, err := s.flashcardRepo.GetByUser(userID)
 	if err != nil {
 		s.Logger.WithError(err).WithField("user_id", userID).Error("Service failed to get flashcards for due cards")
 		return nil, fmt.Errorf("failed to get flashcards: %w", err)
 	}
 
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
 	var dueCards []*models.Flashcard
 	now := time.Now()
+	clock := studyClockForUser(user)
+	dayEnd := clock.nextDayStart(now)
 
 	for _, card := range flashcards {
-		// If next_review is nil or is in the past, card is due
-		if card.NextReview == nil || card.NextReview.Before(now) {
+		if deckID != nil && card.DeckID != *deckID {
+			continue
+		}
+
+		// Suspended cards stay out until unsuspended, buried ones until their time has passed
+		if card.Suspended || (card.BuriedUntil != nil && card.BuriedUntil.After(now)) {
+			continue
+		}
+
+		// If next_review is nil or is in the past, card is due. Reviews are due for the
+		// whole study day they fall on, while learning cards are scheduled minutes
+		// ahead and only become due once their step has passed. Cards pulled into a
+		// filtered deck are due right away unless they are on a step.
+		state := card.SchedulingState().CardState()
+		switch {
+		case card.NextReview == nil:
+			dueCards = append(dueCards, card)
+		case card.HomeDeckID != nil && (state == models.CardStateNew || state == models.CardStateReview):
 			dueCards = append(dueCards, card)
+		case state == models.CardStateReview:
+			if card.NextReview.Before(dayEnd) {
+				dueCards = append(dueCards, card)
+			}
+		case card.NextReview.Before(now):
+			dueCards = append(dueCards, card)
+		}
+	}
+
+	// Learning steps come first so they are not pushed past their due time,
+	// then reviews, then new cards
+	sort.SliceStable(dueCards, func(i, j int) bool {
+		a, b := dueCards[i], dueCards[j]
+		if rankA, rankB := dueQueueRank(a), dueQueueRank(b); rankA != rankB {
+			return rankA < rankB
+		}
+		if a.NextReview != nil && b.NextReview != nil {
+			return a.NextReview.Before(*b.NextReview)
+		}
+		return false
+	})
+
+	remaining, err := s.remainingBudget(user, clock.dayStart(now))
+	if err != nil {
+		return nil, err
+	}
+
+	buryingDecks, err := s.siblingBuryingDecks(userID, dueCards)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hand out the budget in queue order
+	userLeft := remaining.StudyBudget
+	deckLeft := make(map[uuid.UUID]models.StudyBudget, len(remaining.Decks))
+	for deckID, budget := range remaining.Decks {
+		deckLeft[deckID] = budget
+	}
+
+	queue := &models.DueQueue{Cards: []*models.Flashcard{}, Remaining: *remaining}
+	queuedNotes := make(map[uuid.UUID]bool)
+	for _, card := range dueCards {
+		// With sibling burying, a note only gets one new or review card a day
+		if card.NoteID != nil && buryingDecks[card.HomeDeck()] && queuedNotes[*card.NoteID] {
+			if state := card.SchedulingState().CardState(); state == models.CardStateNew || state == models.CardStateReview {
+				continue
+			}
+		}
+
+		// Filtered decks are studied on demand, outside the daily limits
+		if card.HomeDeckID != nil {
+			queue.Cards = append(queue.Cards, card)
+			if card.NoteID != nil {
+				queuedNotes[*card.NoteID] = true
+			}
+			continue
+		}
+
+		deckBudget := deckLeft[card.DeckID]
+		switch card.SchedulingState().CardState() {
+		case models.CardStateNew:
+			if userLeft.New == 0 || deckBudget.New == 0 {
+				continue
+			}
+			userLeft.New--
+			deckBudget.New--
+		case models.CardStateReview:
+			if userLeft.Reviews == 0 || deckBudget.Reviews == 0 {
+				continue
+			}
+			userLeft.Reviews--
+			deckBudget.Reviews--
+		}
+		deckLeft[card.DeckID] = deckBudget
+		queue.Cards = append(queue.Cards, card)
+		if card.NoteID != nil {
+			queuedNotes[*card.NoteID] = true
 		}
 	}
 
 	s.Logger.WithFields(logrus.Fields{
-		"user_id":        userID,
-		"due_card_count": len(dueCards),
+		"user_id":           userID,
+		"due_card_count":    len(dueCards),
+		"queued_card_count": len(queue.Cards),
+		"remaining_new":     remaining.New,
+		"remaining_reviews": remaining.Reviews,
 	}).Info("Retrieved due flashcards for user")
 
-	return dueCards, nil
+	return queue, nil
+}
+
+// siblingBuryingDecks returns the decks of a user whose preset buries siblings. The
+// decks are only looked up when one of the cards belongs to a note.
+func (s *FlashcardService) siblingBuryingDecks(userID uuid.UUID, cards []*models.Flashcard) (map[uuid.UUID]bool, error) {
+	burying := make(map[uuid.UUID]bool)
+	if !slices.ContainsFunc(cards, func(card *models.Flashcard) bool { return card.NoteID != nil }) {
+		return burying, nil
+	}
+
+	decks, err := s.deckRepo.GetByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decks: %w", err)
+	}
+
+	presets, err := s.presetRepo.GetByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deck presets: %w", err)
+	}
+	presetsByID := make(map[uuid.UUID]*models.DeckPreset, len(presets))
+	for _, preset := range presets {
+		presetsByID[preset.ID] = preset
+	}
+
+	for _, deck := range decks {
+		if deck.PresetID == nil {
+			continue
+		}
+		if preset, ok := presetsByID[*deck.PresetID]; ok && preset.BurySiblings {
+			burying[deck.ID] = true
+		}
+	}
+
+	return burying, nil
+}
+
+// remainingBudget returns how many new cards and reviews the user can still study today,
+// per deck (from the deck's preset) and overall (capped by the user's own limits).
+// Cards studied since dayStart count against the budget.
+func (s *FlashcardService) remainingBudget(user *models.User, dayStart time.Time) (*models.RemainingBudget, error) {
+	decks, err := s.deckRepo.GetByUser(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decks: %w", err)
+	}
+
+	presets, err := s.presetRepo.GetByUser(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deck presets: %w", err)
+	}
+	presetsByID := make(map[uuid.UUID]*models.DeckPreset, len(presets))
+	for _, preset := range presets {
+		presetsByID[preset.ID] = preset
+	}
+
+	studied, err := s.reviewLogRepo.CountStudiedSince(user.ID, dayStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count studied cards: %w", err)
+	}
+	studiedByDeck := make(map[uuid.UUID]*models.StudiedCount, len(studied))
+	var studiedTotal models.StudyBudget
+	for _, count := range studied {
+		studiedByDeck[count.DeckID] = count
+		studiedTotal.New += count.New
+		studiedTotal.Reviews += count.Reviews
+	}
+
+	defaults := DefaultDeckPreset()
+	remaining := &models.RemainingBudget{Decks: make(map[uuid.UUID]models.StudyBudget, len(decks))}
+	for _, deck := range decks {
+		if deck.Filter != nil {
+			continue
+		}
+
+		limits := models.StudyBudget{New: defaults.NewPerDay, Reviews: defaults.ReviewsPerDay}
+		if deck.PresetID != nil {
+			if preset, ok := presetsByID[*deck.PresetID]; ok {
+				limits = models.StudyBudget{New: preset.NewPerDay, Reviews: preset.ReviewsPerDay}
+			}
+		}
+
+		budget := limits
+		if count, ok := studiedByDeck[deck.ID]; ok {
+			budget.New = max(0, limits.New-count.New)
+			budget.Reviews = max(0, limits.Reviews-count.Reviews)
+		}
+
+		remaining.Decks[deck.ID] = budget
+		remaining.New += budget.New
+		remaining.Reviews += budget.Reviews
+	}
+
+	if user.NewPerDay != nil {
+		remaining.New = min(remaining.New, max(0, *user.NewPerDay-studiedTotal.New))
+	}
+	if user.ReviewsPerDay != nil {
+		remaining.Reviews = min(remaining.Reviews, max(0, *user.ReviewsPerDay-studiedTotal.Reviews))
+	}
+
+	return remaining, nil
+}
+
+// dueQueueRank orders due cards by state: learning, review, then new
+func dueQueueRank(card *models.Flashcard) int {
+	switch card.SchedulingState().CardState() {
+	case models.CardStateLearning, models.CardStateRelearning:
+		return 0
+	case models.CardStateReview:
+		return 1
+	default:
+		return 2
+	}
 }
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+)
+
+// SuspendWithOwnership suspends or unsuspends flashcards of the user. Suspended cards
+// keep their scheduling state but never appear in the due queue.
+func (s *FlashcardService) SuspendWithOwnership(ids []uuid.UUID, userID uuid.UUID, suspended bool) ([]*models.Flashcard, error) {
+	if _, err := s.ownedFlashcards(ids, userID); err != nil {
+		return nil, err
+	}
+
+	cards, err := s.flashcardRepo.SetSuspended(ids, suspended)
+	if err != nil {
+		s.Logger.WithError(err).WithField("user_id", userID).Error("Service failed to suspend flashcards")
+		return nil, fmt.Errorf("failed to update flashcards: %w", err)
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"user_id":         userID,
+		"flashcard_count": len(cards),
+		"suspended":       suspended,
+	}).Info("Flashcard suspension updated")
+
+	return cards, nil
+}
+
+// BuryWithOwnership hides flashcards of the user from the due queue until a given time,
+// by default the start of the user's next study day
+func (s *FlashcardService) BuryWithOwnership(ids []uuid.UUID, userID uuid.UUID, until *time.Time) ([]*models.Flashcard, error) {
+	if _, err := s.ownedFlashcards(ids, userID); err != nil {
+		return nil, err
+	}
+
+	if until == nil {
+		user, err := s.userRepo.GetByID(userID)
+		if err != nil {
+			return nil, fmt.Errorf("user not found: %w", err)
+		}
+		nextDay := studyClockForUser(user).nextDayStart(time.Now())
+		until = &nextDay
+	}
+
+	cards, err := s.flashcardRepo.SetBuriedUntil(ids, until)
+	if err != nil {
+		s.Logger.WithError(err).WithField("user_id", userID).Error("Service failed to bury flashcards")
+		return nil, fmt.Errorf("failed to update flashcards: %w", err)
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"user_id":         userID,
+		"flashcard_count": len(cards),
+		"buried_until":    until,
+	}).Info("Flashcards buried")
+
+	return cards, nil
+}
+
+// ForgetWithOwnership resets flashcards of the user to new cards, clearing their lapses
+// and leech flag. Their review history is kept.
+func (s *FlashcardService) ForgetWithOwnership(ids []uuid.UUID, userID uuid.UUID) ([]*models.Flashcard, error) {
+	cards, err := s.ownedFlashcards(ids, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	startingEase := make(map[uuid.UUID]float64)
+	updated := make([]*models.Flashcard, 0, len(cards))
+	for _, card := range cards {
+		ease, ok := startingEase[card.HomeDeck()]
+		if !ok {
+			ease, err = s.startingEase(card.HomeDeck())
+			if err != nil {
+				return nil, err
+			}
+			startingEase[card.HomeDeck()] = ease
+		}
+
+		reset, err := s.flashcardRepo.UpdateSchedulingState(card.ID, models.SchedulingState{
+			Difficulty: ease,
+			Interval:   1,
+			EaseFactor: ease,
+			State:      models.CardStateNew,
+		})
+		if err != nil {
+			s.Logger.WithError(err).WithField("flashcard_id", card.ID).Error("Service failed to forget flashcard")
+			return nil, fmt.Errorf("failed to update flashcard: %w", err)
+		}
+		updated = append(updated, reset)
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"user_id":         userID,
+		"flashcard_count": len(updated),
+	}).Info("Flashcards reset to new")
+
+	return updated, nil
+}
+
+// SetDueWithOwnership makes flashcards of the user due on a given study day, counted from
+// today. With daysMax set, each card gets a random day between days and daysMax so a
+// large batch does not land on the same day. Cards that are not in review yet become
+// review cards with the chosen number of days as their interval.
+func (s *FlashcardService) SetDueWithOwnership(ids []uuid.UUID, userID uuid.UUID, days int, daysMax *int) ([]*models.Flashcard, error) {
+	if daysMax != nil && *daysMax < days {
+		return nil, fmt.Errorf("invalid due range: days_max cannot be less than days")
+	}
+
+	cards, err := s.ownedFlashcards(ids, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	today := studyClockForUser(user).dayStart(time.Now())
+
+	updated := make([]*models.Flashcard, 0, len(cards))
+	for _, card := range cards {
+		due := days
+		if daysMax != nil {
+			due += rand.IntN(*daysMax - days + 1)
+		}
+		dueAt := today.AddDate(0, 0, due)
+
+		state := card.SchedulingState()
+		if state.CardState() != models.CardStateReview {
+			state.State = models.CardStateReview
+			state.Step = 0
+			state.Interval = max(due, 1)
+		}
+		state.NextReview = &dueAt
+
+		saved, err := s.flashcardRepo.UpdateSchedulingState(card.ID, state)
+		if err != nil {
+			s.Logger.WithError(err).WithField("flashcard_id", card.ID).Error("Service failed to set flashcard due date")
+			return nil, fmt.Errorf("failed to update flashcard: %w", err)
+		}
+		updated = append(updated, saved)
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"user_id":         userID,
+		"flashcard_count": len(updated),
+		"days":            days,
+		"days_max":        daysMax,
+	}).Info("Flashcard due dates set")
+
+	return updated, nil
+}
+
+// ownedFlashcards retrieves flashcards by ID, checking that every one exists and belongs to the user
+func (s *FlashcardService) ownedFlashcards(ids []uuid.UUID, userID uuid.UUID) ([]*models.Flashcard, error) {
+	cards, err := s.flashcardRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flashcards: %w", err)
+	}
+
+	found := make(map[uuid.UUID]bool, len(cards))
+	for _, card := range cards {
+		if card.UserID != userID {
+			s.Logger.WithFields(logrus.Fields{
+				"flashcard_id": card.ID,
+				"user_id":      userID,
+				"owner_id":     card.UserID,
+			}).Warn("Unauthorized attempt to update flashcard")
+			return nil, fmt.Errorf("unauthorized: flashcard does not belong to user")
+		}
+		found[card.ID] = true
+	}
+
+	for _, id := range ids {
+		if !found[id] {
+			return nil, fmt.Errorf("flashcard not found")
+		}
+	}
+
+	return cards, nil
+}
+
+// startingEase returns the starting ease of new cards in a deck, from its preset or the defaults
+func (s *FlashcardService) startingEase(deckID uuid.UUID) (float64, error) {
+	deck, err := s.deckRepo.GetByID(deckID)
+	if err != nil {
+		return 0, fmt.Errorf("deck not found: %w", err)
+	}
+
+	return s.deckStartingEase(deck)
+}
+
+// deckStartingEase returns the starting ease of new cards in a deck
+func (s *FlashcardService) deckStartingEase(deck *models.Deck) (float64, error) {
+	preset, err := s.deckPreset(deck)
+	if err != nil {
+		return 0, err
+	}
+
+	if preset == nil {
+		return DefaultDeckPreset().StartingEase, nil
+	}
+	return preset.StartingEase, nil
+}
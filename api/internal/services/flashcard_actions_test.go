@@ -0,0 +1,191 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+func newTestFlashcardService() (*FlashcardService, *MockFlashcardRepository, *MockDeckRepository, *MockUserRepository) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, &MockDeckPresetRepository{}, &MockReviewLogRepository{}, mockUserRepo, logger)
+	return service, mockRepo, mockDeckRepo, mockUserRepo
+}
+
+func TestFlashcardService_SuspendWithOwnership(t *testing.T) {
+	service, mockRepo, _, _ := newTestFlashcardService()
+
+	userID := uuid.New()
+	cards := []*models.Flashcard{
+		testutils.CreateTestFlashcard(userID, uuid.New()),
+		testutils.CreateTestFlashcard(userID, uuid.New()),
+	}
+	ids := []uuid.UUID{cards[0].ID, cards[1].ID}
+
+	mockRepo.On("GetByIDs", ids).Return(cards, nil)
+	mockRepo.On("SetSuspended", ids, true).Return(cards, nil)
+
+	result, err := service.SuspendWithOwnership(ids, userID, true)
+
+	require.NoError(t, err)
+	assert.Len(t, result, 2)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFlashcardService_SuspendWithOwnership_Unauthorized(t *testing.T) {
+	service, mockRepo, _, _ := newTestFlashcardService()
+
+	userID := uuid.New()
+	own := testutils.CreateTestFlashcard(userID, uuid.New())
+	other := testutils.CreateTestFlashcard(uuid.New(), uuid.New())
+	ids := []uuid.UUID{own.ID, other.ID}
+
+	mockRepo.On("GetByIDs", ids).Return([]*models.Flashcard{own, other}, nil)
+
+	result, err := service.SuspendWithOwnership(ids, userID, true)
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "unauthorized: flashcard does not belong to user")
+	mockRepo.AssertNotCalled(t, "SetSuspended", mock.Anything, mock.Anything)
+}
+
+func TestFlashcardService_BuryWithOwnership_NotFound(t *testing.T) {
+	service, mockRepo, _, _ := newTestFlashcardService()
+
+	userID := uuid.New()
+	card := testutils.CreateTestFlashcard(userID, uuid.New())
+	ids := []uuid.UUID{card.ID, uuid.New()}
+
+	mockRepo.On("GetByIDs", ids).Return([]*models.Flashcard{card}, nil)
+
+	_, err := service.BuryWithOwnership(ids, userID, nil)
+
+	assert.EqualError(t, err, "flashcard not found")
+	mockRepo.AssertNotCalled(t, "SetBuriedUntil", mock.Anything, mock.Anything)
+}
+
+func TestFlashcardService_BuryWithOwnership_UntilNextStudyDay(t *testing.T) {
+	service, mockRepo, _, mockUserRepo := newTestFlashcardService()
+
+	user := &models.User{ID: uuid.New(), Timezone: "Asia/Tokyo", DayStartHour: 4}
+	card := testutils.CreateTestFlashcard(user.ID, uuid.New())
+	ids := []uuid.UUID{card.ID}
+	nextDay := studyClockForUser(user).nextDayStart(time.Now())
+
+	mockRepo.On("GetByIDs", ids).Return([]*models.Flashcard{card}, nil)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockRepo.On("SetBuriedUntil", ids, mock.MatchedBy(func(until *time.Time) bool {
+		return until != nil && until.Equal(nextDay)
+	})).Return([]*models.Flashcard{card}, nil)
+
+	_, err := service.BuryWithOwnership(ids, user.ID, nil)
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFlashcardService_ForgetWithOwnership(t *testing.T) {
+	service, mockRepo, mockDeckRepo, _ := newTestFlashcardService()
+
+	userID := uuid.New()
+	deckID := uuid.New()
+	lastReview := time.Now().AddDate(0, 0, -3)
+	nextReview := time.Now().AddDate(0, 0, 20)
+	card := &models.Flashcard{
+		ID:          uuid.New(),
+		UserID:      userID,
+		DeckID:      deckID,
+		Interval:    20,
+		EaseFactor:  2.1,
+		ReviewCount: 7,
+		State:       models.CardStateReview,
+		LastReview:  &lastReview,
+		NextReview:  &nextReview,
+	}
+
+	mockRepo.On("GetByIDs", []uuid.UUID{card.ID}).Return([]*models.Flashcard{card}, nil)
+	mockDeckRepo.On("GetByID", deckID).Return(&models.Deck{ID: deckID, UserID: userID}, nil)
+	mockRepo.On("UpdateSchedulingState", card.ID, models.SchedulingState{
+		Difficulty: 2.5,
+		Interval:   1,
+		EaseFactor: 2.5,
+		State:      models.CardStateNew,
+	}).Return(card, nil)
+
+	_, err := service.ForgetWithOwnership([]uuid.UUID{card.ID}, userID)
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFlashcardService_SetDueWithOwnership_Range(t *testing.T) {
+	service, mockRepo, _, mockUserRepo := newTestFlashcardService()
+
+	user := &models.User{ID: uuid.New()}
+	newCard := testutils.CreateTestFlashcard(user.ID, uuid.New())
+	today := studyClockForUser(user).dayStart(time.Now())
+	daysMax := 7
+
+	mockRepo.On("GetByIDs", []uuid.UUID{newCard.ID}).Return([]*models.Flashcard{newCard}, nil)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockRepo.On("UpdateSchedulingState", newCard.ID, mock.MatchedBy(func(state models.SchedulingState) bool {
+		days := int(state.NextReview.Sub(today).Hours() / 24)
+		return state.State == models.CardStateReview &&
+			days >= 3 && days <= 7 &&
+			state.Interval == days
+	})).Return(newCard, nil)
+
+	_, err := service.SetDueWithOwnership([]uuid.UUID{newCard.ID}, user.ID, 3, &daysMax)
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFlashcardService_SetDueWithOwnership_InvalidRange(t *testing.T) {
+	service, mockRepo, _, _ := newTestFlashcardService()
+
+	daysMax := 2
+	_, err := service.SetDueWithOwnership([]uuid.UUID{uuid.New()}, uuid.New(), 5, &daysMax)
+
+	assert.EqualError(t, err, "invalid due range: days_max cannot be less than days")
+	mockRepo.AssertNotCalled(t, "GetByIDs", mock.Anything)
+}
+
+func TestFlashcardService_GetDueCards_SkipsSuspendedAndBuried(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	deckID := uuid.New()
+	mockStudyDay(mockUserRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, &models.User{ID: userID}, []*models.Deck{{ID: deckID, UserID: userID}}, nil)
+
+	buriedUntil := time.Now().Add(time.Hour)
+	buryEnded := time.Now().Add(-time.Hour)
+	cards := []*models.Flashcard{
+		{ID: uuid.New(), UserID: userID, DeckID: deckID, Front: "Suspended", State: models.CardStateNew, Suspended: true},
+		{ID: uuid.New(), UserID: userID, DeckID: deckID, Front: "Buried", State: models.CardStateNew, BuriedUntil: &buriedUntil},
+		{ID: uuid.New(), UserID: userID, DeckID: deckID, Front: "Unburied", State: models.CardStateNew, BuriedUntil: &buryEnded},
+	}
+	mockRepo.On("GetByUser", userID).Return(cards, nil)
+
+	result, err := service.GetDueCards(userID)
+
+	require.NoError(t, err)
+	require.Len(t, result.Cards, 1)
+	assert.Equal(t, "Unburied", result.Cards[0].Front)
+}
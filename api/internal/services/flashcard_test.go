@@ -43,6 +43,46 @@ func (m *MockFlashcardRepository) GetByUser(userID uuid.UUID) ([]*models.Flashca
 	return args.Get(0).([]*models.Flashcard), args.Error(1)
 }
 
+func (m *MockFlashcardRepository) GetByIDs(ids []uuid.UUID) ([]*models.Flashcard, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Flashcard), args.Error(1)
+}
+
+func (m *MockFlashcardRepository) GetByDeck(deckID uuid.UUID) ([]*models.Flashcard, error) {
+	args := m.Called(deckID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Flashcard), args.Error(1)
+}
+
+func (m *MockFlashcardRepository) GetByNote(noteID uuid.UUID) ([]*models.Flashcard, error) {
+	args := m.Called(noteID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Flashcard), args.Error(1)
+}
+
+func (m *MockFlashcardRepository) GetLeeches(userID uuid.UUID) ([]*models.Flashcard, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Flashcard), args.Error(1)
+}
+
+func (m *MockFlashcardRepository) GetReviewDueDates(userID uuid.UUID, from, to time.Time) ([]time.Time, error) {
+	args := m.Called(userID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
 func (m *MockFlashcardRepository) Update(id uuid.UUID, updates *models.UpdateFlashcardRequest) (*models.Flashcard, error) {
 	args := m.Called(id, updates)
 	if args.Get(0) == nil {
@@ -51,15 +91,80 @@ func (m *MockFlashcardRepository) Update(id uuid.UUID, updates *models.UpdateFla
 	return args.Get(0).(*models.Flashcard), args.Error(1)
 }
 
+func (m *MockFlashcardRepository) UpdateSchedulingState(id uuid.UUID, state models.SchedulingState) (*models.Flashcard, error) {
+	args := m.Called(id, state)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Flashcard), args.Error(1)
+}
+
+func (m *MockFlashcardRepository) SetSuspended(ids []uuid.UUID, suspended bool) ([]*models.Flashcard, error) {
+	args := m.Called(ids, suspended)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Flashcard), args.Error(1)
+}
+
+func (m *MockFlashcardRepository) SetBuriedUntil(ids []uuid.UUID, until *time.Time) ([]*models.Flashcard, error) {
+	args := m.Called(ids, until)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Flashcard), args.Error(1)
+}
+
+func (m *MockFlashcardRepository) MoveToFilteredDeck(ids []uuid.UUID, deckID uuid.UUID) ([]*models.Flashcard, error) {
+	args := m.Called(ids, deckID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Flashcard), args.Error(1)
+}
+
+func (m *MockFlashcardRepository) ReturnToHomeDeck(ids []uuid.UUID) ([]*models.Flashcard, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Flashcard), args.Error(1)
+}
+
+func (m *MockFlashcardRepository) EmptyFilteredDeck(deckID uuid.UUID) (int, error) {
+	args := m.Called(deckID)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockFlashcardRepository) Delete(id uuid.UUID) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
+// mockStudyDay sets up the user, decks and today's review history read when building the due queue
+func mockStudyDay(
+	userRepo *MockUserRepository,
+	deckRepo *MockDeckRepository,
+	presetRepo *MockDeckPresetRepository,
+	reviewLogRepo *MockReviewLogRepository,
+	user *models.User,
+	decks []*models.Deck,
+	studied []*models.StudiedCount,
+) {
+	userRepo.On("GetByID", user.ID).Return(user, nil)
+	deckRepo.On("GetByUser", user.ID).Return(decks, nil)
+	presetRepo.On("GetByUser", user.ID).Return([]*models.DeckPreset{}, nil)
+	reviewLogRepo.On("CountStudiedSince", user.ID, mock.AnythingOfType("time.Time")).Return(studied, nil)
+}
+
 func TestFlashcardService_Create_Success(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockFlashcardRepository{}
-	service := NewFlashcardService(mockRepo, logger)
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
 
 	userID := uuid.New()
 	deckID := uuid.New()
@@ -82,6 +187,7 @@ func TestFlashcardService_Create_Success(t *testing.T) {
 		ReviewCount: 0,
 	}
 
+	mockDeckRepo.On("GetByID", deckID).Return(&models.Deck{ID: deckID, UserID: userID}, nil)
 	mockRepo.On("Create", mock.AnythingOfType("*models.Flashcard")).Return(expectedCard, nil)
 
 	result, err := service.Create(req)
@@ -101,10 +207,46 @@ func TestFlashcardService_Create_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestFlashcardService_Create_Format(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{name: "defaults to plain", format: "", want: models.ContentFormatPlain},
+		{name: "markdown", format: models.ContentFormatMarkdown, want: models.ContentFormatMarkdown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockFlashcardRepository{}
+			mockDeckRepo := &MockDeckRepository{}
+			service := NewFlashcardService(mockRepo, mockDeckRepo, &MockDeckPresetRepository{}, &MockReviewLogRepository{}, &MockUserRepository{}, testutils.TestLogger())
+
+			userID, deckID := uuid.New(), uuid.New()
+			mockDeckRepo.On("GetByID", deckID).Return(&models.Deck{ID: deckID, UserID: userID}, nil)
+			mockRepo.On("Create", mock.MatchedBy(func(card *models.Flashcard) bool {
+				return card.Format == tt.want
+			})).Return(&models.Flashcard{}, nil)
+
+			_, err := service.Create(&models.CreateFlashcardRequest{
+				Front: "**Question**", Back: "Answer", Format: tt.format, UserID: userID, DeckID: deckID,
+			})
+
+			require.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestFlashcardService_Create_InvalidUserID(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockFlashcardRepository{}
-	service := NewFlashcardService(mockRepo, logger)
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
 
 	deckID := uuid.New()
 	req := &models.CreateFlashcardRequest{
@@ -126,7 +268,11 @@ func TestFlashcardService_Create_InvalidUserID(t *testing.T) {
 func TestFlashcardService_Create_InvalidDeckID(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockFlashcardRepository{}
-	service := NewFlashcardService(mockRepo, logger)
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
 
 	userID := uuid.New()
 	req := &models.CreateFlashcardRequest{
@@ -148,7 +294,11 @@ func TestFlashcardService_Create_InvalidDeckID(t *testing.T) {
 func TestFlashcardService_GetByUser_Success(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockFlashcardRepository{}
-	service := NewFlashcardService(mockRepo, logger)
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
 
 	userID := uuid.New()
 	expectedCards := []*models.Flashcard{
@@ -179,7 +329,11 @@ func TestFlashcardService_GetByUser_Success(t *testing.T) {
 func TestFlashcardService_ReviewFlashcard_PerfectResponse(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockFlashcardRepository{}
-	service := NewFlashcardService(mockRepo, logger)
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
 
 	cardID := uuid.New()
 	quality := 5 // Perfect response
@@ -216,10 +370,15 @@ func TestFlashcardService_ReviewFlashcard_PerfectResponse(t *testing.T) {
 
 	// Mock GetByID returns existing card
 	mockRepo.On("GetByID", cardID).Return(existingCard, nil)
-	// Mock Update returns updated card
-	mockRepo.On("Update", cardID, mock.AnythingOfType("*models.UpdateFlashcardRequest")).Return(expectedCard, nil)
+	// Mock deck lookup selects the SM-2 scheduler
+	mockDeckRepo.On("GetByID", existingCard.DeckID).Return(&models.Deck{ID: existingCard.DeckID, Scheduler: models.SchedulerSM2}, nil)
+	mockUserRepo.On("GetByID", existingCard.UserID).Return(&models.User{ID: existingCard.UserID}, nil)
+	// Mock UpdateSchedulingState returns updated card
+	mockRepo.On("UpdateSchedulingState", cardID, mock.AnythingOfType("models.SchedulingState")).Return(expectedCard, nil)
+	// Mock review log insert
+	mockReviewLogRepo.On("Create", mock.AnythingOfType("*models.ReviewLog")).Return(&models.ReviewLog{}, nil)
 
-	result, err := service.ReviewFlashcard(cardID, quality)
+	result, err := service.ReviewFlashcard(cardID, &models.ReviewFlashcardRequest{Quality: quality})
 
 	require.NoError(t, err)
 	require.NotNil(t, result)
@@ -237,7 +396,11 @@ func TestFlashcardService_ReviewFlashcard_PerfectResponse(t *testing.T) {
 func TestFlashcardService_ReviewFlashcard_PoorResponse(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockFlashcardRepository{}
-	service := NewFlashcardService(mockRepo, logger)
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
 
 	cardID := uuid.New()
 	quality := 2 // Poor response (below threshold)
@@ -272,10 +435,15 @@ func TestFlashcardService_ReviewFlashcard_PoorResponse(t *testing.T) {
 
 	// Mock GetByID returns existing card
 	mockRepo.On("GetByID", cardID).Return(existingCard, nil)
-	// Mock Update returns updated card
-	mockRepo.On("Update", cardID, mock.AnythingOfType("*models.UpdateFlashcardRequest")).Return(expectedCard, nil)
+	// Mock deck lookup selects the SM-2 scheduler
+	mockDeckRepo.On("GetByID", existingCard.DeckID).Return(&models.Deck{ID: existingCard.DeckID, Scheduler: models.SchedulerSM2}, nil)
+	mockUserRepo.On("GetByID", existingCard.UserID).Return(&models.User{ID: existingCard.UserID}, nil)
+	// Mock UpdateSchedulingState returns updated card
+	mockRepo.On("UpdateSchedulingState", cardID, mock.AnythingOfType("models.SchedulingState")).Return(expectedCard, nil)
+	// Mock review log insert
+	mockReviewLogRepo.On("Create", mock.AnythingOfType("*models.ReviewLog")).Return(&models.ReviewLog{}, nil)
 
-	result, err := service.ReviewFlashcard(cardID, quality)
+	result, err := service.ReviewFlashcard(cardID, &models.ReviewFlashcardRequest{Quality: quality})
 
 	require.NoError(t, err)
 	require.NotNil(t, result)
@@ -290,12 +458,16 @@ func TestFlashcardService_ReviewFlashcard_PoorResponse(t *testing.T) {
 func TestFlashcardService_ReviewFlashcard_InvalidQuality(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockFlashcardRepository{}
-	service := NewFlashcardService(mockRepo, logger)
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
 
 	cardID := uuid.New()
 	quality := 6 // Invalid (must be 0-5)
 
-	result, err := service.ReviewFlashcard(cardID, quality)
+	result, err := service.ReviewFlashcard(cardID, &models.ReviewFlashcardRequest{Quality: quality})
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -308,14 +480,18 @@ func TestFlashcardService_ReviewFlashcard_InvalidQuality(t *testing.T) {
 func TestFlashcardService_ReviewFlashcard_CardNotFound(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockFlashcardRepository{}
-	service := NewFlashcardService(mockRepo, logger)
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
 
 	cardID := uuid.New()
 	quality := 3
 
 	mockRepo.On("GetByID", cardID).Return(nil, sql.ErrNoRows)
 
-	result, err := service.ReviewFlashcard(cardID, quality)
+	result, err := service.ReviewFlashcard(cardID, &models.ReviewFlashcardRequest{Quality: quality})
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -327,9 +503,15 @@ func TestFlashcardService_ReviewFlashcard_CardNotFound(t *testing.T) {
 func TestFlashcardService_GetDueCards_Empty(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockFlashcardRepository{}
-	service := NewFlashcardService(mockRepo, logger)
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
 
 	userID := uuid.New()
+	deckID := uuid.New()
+	mockStudyDay(mockUserRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, &models.User{ID: userID}, []*models.Deck{{ID: deckID, UserID: userID}}, nil)
 
 	// Mock cards that are not due
 	tomorrow := time.Now().Add(24 * time.Hour)
@@ -337,6 +519,7 @@ func TestFlashcardService_GetDueCards_Empty(t *testing.T) {
 		{
 			ID:         uuid.New(),
 			UserID:     userID,
+			DeckID:     deckID,
 			NextReview: &tomorrow, // Due tomorrow
 		},
 	}
@@ -346,7 +529,7 @@ func TestFlashcardService_GetDueCards_Empty(t *testing.T) {
 	result, err := service.GetDueCards(userID)
 
 	require.NoError(t, err)
-	assert.Empty(t, result) // No cards due
+	assert.Empty(t, result.Cards) // No cards due
 
 	mockRepo.AssertExpectations(t)
 }
@@ -354,9 +537,15 @@ func TestFlashcardService_GetDueCards_Empty(t *testing.T) {
 func TestFlashcardService_GetDueCards_WithDueCards(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockFlashcardRepository{}
-	service := NewFlashcardService(mockRepo, logger)
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
 
 	userID := uuid.New()
+	deckID := uuid.New()
+	mockStudyDay(mockUserRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, &models.User{ID: userID}, []*models.Deck{{ID: deckID, UserID: userID}}, nil)
 	now := time.Now()
 
 	// Mock cards with different due dates
@@ -367,18 +556,21 @@ func TestFlashcardService_GetDueCards_WithDueCards(t *testing.T) {
 		{
 			ID:         uuid.New(),
 			UserID:     userID,
+			DeckID:     deckID,
 			Front:      "Due Card 1",
 			NextReview: &oneHourAgo, // Due 1 hour ago
 		},
 		{
 			ID:         uuid.New(),
 			UserID:     userID,
+			DeckID:     deckID,
 			Front:      "Due Card 2",
 			NextReview: nil, // Never reviewed, so it's due
 		},
 		{
 			ID:         uuid.New(),
 			UserID:     userID,
+			DeckID:     deckID,
 			Front:      "Future Card",
 			NextReview: &oneHourFromNow, // Due in 1 hour
 		},
@@ -389,10 +581,10 @@ func TestFlashcardService_GetDueCards_WithDueCards(t *testing.T) {
 	result, err := service.GetDueCards(userID)
 
 	require.NoError(t, err)
-	assert.Len(t, result, 2) // Only 2 cards are due
+	assert.Len(t, result.Cards, 2) // Only 2 cards are due
 
 	// Verify the due cards are returned
-	fronts := []string{result[0].Front, result[1].Front}
+	fronts := []string{result.Cards[0].Front, result.Cards[1].Front}
 	assert.Contains(t, fronts, "Due Card 1")
 	assert.Contains(t, fronts, "Due Card 2")
 	assert.NotContains(t, fronts, "Future Card")
@@ -400,10 +592,140 @@ func TestFlashcardService_GetDueCards_WithDueCards(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestFlashcardService_GetDueCards_OrdersByState(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	deckID := uuid.New()
+	mockStudyDay(mockUserRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, &models.User{ID: userID}, []*models.Deck{{ID: deckID, UserID: userID}}, nil)
+	now := time.Now()
+	yesterday := now.Add(-24 * time.Hour)
+	twoMinutesAgo := now.Add(-2 * time.Minute)
+	inFiveMinutes := now.Add(5 * time.Minute)
+
+	cards := []*models.Flashcard{
+		{ID: uuid.New(), UserID: userID, DeckID: deckID, Front: "New", State: models.CardStateNew},
+		{ID: uuid.New(), UserID: userID, DeckID: deckID, Front: "Review", State: models.CardStateReview, NextReview: &yesterday},
+		{ID: uuid.New(), UserID: userID, DeckID: deckID, Front: "Learning", State: models.CardStateLearning, NextReview: &twoMinutesAgo},
+		{ID: uuid.New(), UserID: userID, DeckID: deckID, Front: "Learning Later", State: models.CardStateLearning, NextReview: &inFiveMinutes},
+		{ID: uuid.New(), UserID: userID, DeckID: deckID, Front: "Relearning", State: models.CardStateRelearning, NextReview: &yesterday},
+	}
+
+	mockRepo.On("GetByUser", userID).Return(cards, nil)
+
+	result, err := service.GetDueCards(userID)
+
+	require.NoError(t, err)
+	require.Len(t, result.Cards, 4)
+	assert.Equal(t, "Relearning", result.Cards[0].Front)
+	assert.Equal(t, "Learning", result.Cards[1].Front)
+	assert.Equal(t, "Review", result.Cards[2].Front)
+	assert.Equal(t, "New", result.Cards[3].Front)
+}
+
+func TestFlashcardService_GetDueCards_DeckLimits(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	preset := testutils.CreateTestDeckPreset(userID)
+	preset.NewPerDay = 2
+	preset.ReviewsPerDay = 3
+	deck := testutils.CreateTestDeck(userID)
+	deck.PresetID = &preset.ID
+
+	mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+	mockDeckRepo.On("GetByUser", userID).Return([]*models.Deck{deck}, nil)
+	mockPresetRepo.On("GetByUser", userID).Return([]*models.DeckPreset{preset}, nil)
+	mockReviewLogRepo.On("CountStudiedSince", userID, mock.AnythingOfType("time.Time")).
+		Return([]*models.StudiedCount{{DeckID: deck.ID, New: 1, Reviews: 1}}, nil)
+
+	yesterday := time.Now().Add(-24 * time.Hour)
+	var cards []*models.Flashcard
+	for i := 0; i < 3; i++ {
+		cards = append(cards,
+			&models.Flashcard{ID: uuid.New(), UserID: userID, DeckID: deck.ID, State: models.CardStateNew},
+			&models.Flashcard{ID: uuid.New(), UserID: userID, DeckID: deck.ID, State: models.CardStateReview, NextReview: &yesterday},
+			&models.Flashcard{ID: uuid.New(), UserID: userID, DeckID: deck.ID, State: models.CardStateLearning, NextReview: &yesterday},
+		)
+	}
+	mockRepo.On("GetByUser", userID).Return(cards, nil)
+
+	result, err := service.GetDueCards(userID)
+
+	require.NoError(t, err)
+	counts := map[string]int{}
+	for _, card := range result.Cards {
+		counts[card.State]++
+	}
+	assert.Equal(t, 3, counts[models.CardStateLearning]) // learning cards are never held back
+	assert.Equal(t, 2, counts[models.CardStateReview])
+	assert.Equal(t, 1, counts[models.CardStateNew])
+	assert.Equal(t, models.StudyBudget{New: 1, Reviews: 2}, result.Remaining.StudyBudget)
+	assert.Equal(t, models.StudyBudget{New: 1, Reviews: 2}, result.Remaining.Decks[deck.ID])
+}
+
+func TestFlashcardService_GetDueCards_UserLimits(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	newPerDay, reviewsPerDay := 3, 0
+	firstDeck := testutils.CreateTestDeck(userID)
+	secondDeck := testutils.CreateTestDeck(userID)
+	mockStudyDay(mockUserRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo,
+		&models.User{ID: userID, NewPerDay: &newPerDay, ReviewsPerDay: &reviewsPerDay},
+		[]*models.Deck{firstDeck, secondDeck},
+		[]*models.StudiedCount{{DeckID: firstDeck.ID, New: 1}},
+	)
+
+	yesterday := time.Now().Add(-24 * time.Hour)
+	var cards []*models.Flashcard
+	for _, deckID := range []uuid.UUID{firstDeck.ID, secondDeck.ID} {
+		for i := 0; i < 2; i++ {
+			cards = append(cards,
+				&models.Flashcard{ID: uuid.New(), UserID: userID, DeckID: deckID, State: models.CardStateNew},
+				&models.Flashcard{ID: uuid.New(), UserID: userID, DeckID: deckID, State: models.CardStateReview, NextReview: &yesterday},
+			)
+		}
+	}
+	mockRepo.On("GetByUser", userID).Return(cards, nil)
+
+	result, err := service.GetDueCards(userID)
+
+	require.NoError(t, err)
+	require.Len(t, result.Cards, 2) // 3 new per day, 1 already studied, no reviews
+	for _, card := range result.Cards {
+		assert.Equal(t, models.CardStateNew, card.State)
+	}
+	assert.Equal(t, models.StudyBudget{New: 2, Reviews: 0}, result.Remaining.StudyBudget)
+	assert.Equal(t, models.StudyBudget{New: 19, Reviews: 200}, result.Remaining.Decks[firstDeck.ID])
+}
+
 func TestFlashcardService_ReviewFlashcardWithOwnership_Success(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockFlashcardRepository{}
-	service := NewFlashcardService(mockRepo, logger)
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
 
 	cardID := uuid.New()
 	userID := uuid.New()
@@ -433,10 +755,15 @@ func TestFlashcardService_ReviewFlashcardWithOwnership_Success(t *testing.T) {
 
 	// Mock GetByID returns existing card
 	mockRepo.On("GetByID", cardID).Return(existingCard, nil)
-	// Mock Update returns updated card
-	mockRepo.On("Update", cardID, mock.AnythingOfType("*models.UpdateFlashcardRequest")).Return(expectedCard, nil)
+	// Mock deck lookup selects the SM-2 scheduler
+	mockDeckRepo.On("GetByID", existingCard.DeckID).Return(&models.Deck{ID: existingCard.DeckID, Scheduler: models.SchedulerSM2}, nil)
+	mockUserRepo.On("GetByID", existingCard.UserID).Return(&models.User{ID: existingCard.UserID}, nil)
+	// Mock UpdateSchedulingState returns updated card
+	mockRepo.On("UpdateSchedulingState", cardID, mock.AnythingOfType("models.SchedulingState")).Return(expectedCard, nil)
+	// Mock review log insert
+	mockReviewLogRepo.On("Create", mock.AnythingOfType("*models.ReviewLog")).Return(&models.ReviewLog{}, nil)
 
-	result, err := service.ReviewFlashcardWithOwnership(cardID, userID, quality)
+	result, err := service.ReviewFlashcardWithOwnership(cardID, userID, &models.ReviewFlashcardRequest{Quality: quality})
 
 	require.NoError(t, err)
 	require.NotNil(t, result)
@@ -449,7 +776,11 @@ func TestFlashcardService_ReviewFlashcardWithOwnership_Success(t *testing.T) {
 func TestFlashcardService_ReviewFlashcardWithOwnership_Unauthorized(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockFlashcardRepository{}
-	service := NewFlashcardService(mockRepo, logger)
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
 
 	cardID := uuid.New()
 	userID := uuid.New()
@@ -466,7 +797,7 @@ func TestFlashcardService_ReviewFlashcardWithOwnership_Unauthorized(t *testing.T
 	// Mock GetByID returns existing card
 	mockRepo.On("GetByID", cardID).Return(existingCard, nil)
 
-	result, err := service.ReviewFlashcardWithOwnership(cardID, userID, quality)
+	result, err := service.ReviewFlashcardWithOwnership(cardID, userID, &models.ReviewFlashcardRequest{Quality: quality})
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -479,7 +810,11 @@ func TestFlashcardService_ReviewFlashcardWithOwnership_Unauthorized(t *testing.T
 func TestFlashcardService_UpdateWithOwnership_Unauthorized(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockFlashcardRepository{}
-	service := NewFlashcardService(mockRepo, logger)
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
 
 	cardID := uuid.New()
 	userID := uuid.New()
@@ -512,7 +847,11 @@ func TestFlashcardService_UpdateWithOwnership_Unauthorized(t *testing.T) {
 func TestFlashcardService_DeleteWithOwnership_Unauthorized(t *testing.T) {
 	logger := testutils.TestLogger()
 	mockRepo := &MockFlashcardRepository{}
-	service := NewFlashcardService(mockRepo, logger)
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
 
 	cardID := uuid.New()
 	userID := uuid.New()
@@ -536,3 +875,262 @@ func TestFlashcardService_DeleteWithOwnership_Unauthorized(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestFlashcardService_PreviewWithOwnership(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	lastReview := time.Now().AddDate(0, 0, -6)
+	card := &models.Flashcard{
+		ID:          uuid.New(),
+		UserID:      userID,
+		DeckID:      uuid.New(),
+		Interval:    6,
+		EaseFactor:  2.5,
+		ReviewCount: 2,
+		State:       models.CardStateReview,
+		LastReview:  &lastReview,
+	}
+
+	mockRepo.On("GetByID", card.ID).Return(card, nil)
+	mockDeckRepo.On("GetByID", card.DeckID).Return(&models.Deck{ID: card.DeckID, Scheduler: models.SchedulerSM2}, nil)
+	mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+
+	previews, err := service.PreviewWithOwnership(card.ID, userID)
+
+	require.NoError(t, err)
+	require.Len(t, previews, 6)
+	for quality, preview := range previews {
+		assert.Equal(t, quality, preview.Quality)
+		require.NotNil(t, preview.NextReview)
+	}
+	assert.Equal(t, models.CardStateRelearning, previews[0].State)
+	assert.Equal(t, models.CardStateReview, previews[4].State)
+	assert.InDelta(t, 15, previews[4].Interval, 2) // 15 days, fuzzed
+
+	// Nothing is saved
+	mockRepo.AssertNotCalled(t, "UpdateSchedulingState", mock.Anything, mock.Anything)
+	mockReviewLogRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestFlashcardService_PreviewWithOwnership_Unauthorized(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	card := testutils.CreateTestFlashcard(uuid.New(), uuid.New())
+	mockRepo.On("GetByID", card.ID).Return(card, nil)
+
+	previews, err := service.PreviewWithOwnership(card.ID, uuid.New())
+
+	assert.Nil(t, previews)
+	assert.EqualError(t, err, "unauthorized: flashcard does not belong to user")
+}
+
+func TestFlashcardService_Create_FilteredDeck(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	deck := testutils.CreateTestDeck(userID)
+	deck.Filter = &models.DeckFilter{Search: "is:due", Order: models.FilterOrderDue, Limit: 100}
+	mockDeckRepo.On("GetByID", deck.ID).Return(deck, nil)
+
+	_, err := service.Create(&models.CreateFlashcardRequest{Front: "Q", Back: "A", UserID: userID, DeckID: deck.ID})
+
+	require.Error(t, err)
+	assert.Equal(t, "cannot add flashcards to a filtered deck", err.Error())
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestFlashcardService_ReviewFlashcard_FilteredDeckPreview(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	home := testutils.CreateTestDeck(userID)
+	filtered := testutils.CreateTestDeck(userID)
+	filtered.Filter = &models.DeckFilter{Search: "deck:*", Order: models.FilterOrderDue, Limit: 100, Reschedule: false}
+	card := testutils.CreateTestFlashcard(userID, filtered.ID)
+	card.HomeDeckID = &home.ID
+	returned := testutils.CreateTestFlashcard(userID, home.ID)
+	returned.ID = card.ID
+
+	mockRepo.On("GetByID", card.ID).Return(card, nil)
+	mockDeckRepo.On("GetByID", filtered.ID).Return(filtered, nil)
+	mockDeckRepo.On("GetByID", home.ID).Return(home, nil)
+	mockRepo.On("ReturnToHomeDeck", []uuid.UUID{card.ID}).Return([]*models.Flashcard{returned}, nil)
+	var logs []*models.ReviewLog
+	mockReviewLogRepo.On("Create", mock.AnythingOfType("*models.ReviewLog")).
+		Run(func(args mock.Arguments) { logs = append(logs, args.Get(0).(*models.ReviewLog)) }).
+		Return(&models.ReviewLog{}, nil)
+
+	// A failed answer keeps the card in the filtered deck
+	result, err := service.ReviewFlashcard(card.ID, &models.ReviewFlashcardRequest{Quality: 1})
+	require.NoError(t, err)
+	assert.Equal(t, filtered.ID, result.DeckID)
+	mockRepo.AssertNotCalled(t, "ReturnToHomeDeck", mock.Anything)
+
+	// A recalled card goes home with its schedule untouched
+	result, err = service.ReviewFlashcard(card.ID, &models.ReviewFlashcardRequest{Quality: 4})
+	require.NoError(t, err)
+	assert.Equal(t, home.ID, result.DeckID)
+	assert.Equal(t, models.CardStateNew, result.State)
+
+	mockRepo.AssertNotCalled(t, "UpdateSchedulingState", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+
+	// Both answers are recorded like cram answers, and only the second returned the card
+	require.Len(t, logs, 2)
+	for _, log := range logs {
+		assert.True(t, log.Cram)
+		assert.Equal(t, home.ID, log.DeckID)
+		assert.Equal(t, log.StateBefore, log.StateAfter)
+	}
+	assert.Nil(t, logs[0].ReturnedFromDeckID)
+	assert.Equal(t, &filtered.ID, logs[1].ReturnedFromDeckID)
+}
+
+func TestFlashcardService_UndoLastReviewWithOwnership_ReturnsToFilteredDeck(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	service := NewFlashcardService(mockRepo, &MockDeckRepository{}, &MockDeckPresetRepository{}, mockReviewLogRepo, &MockUserRepository{}, logger)
+
+	userID := uuid.New()
+	sessionID := uuid.New()
+	homeID := uuid.New()
+	filteredID := uuid.New()
+	card := testutils.CreateTestFlashcard(userID, homeID)
+	before := card.SchedulingState()
+	lastReview := &models.ReviewLog{
+		ID:                 uuid.New(),
+		FlashcardID:        card.ID,
+		SessionID:          &sessionID,
+		StateBefore:        before,
+		StateAfter:         before,
+		Cram:               true,
+		ReturnedFromDeckID: &filteredID,
+	}
+	moved := *card
+	moved.DeckID, moved.HomeDeckID = filteredID, &homeID
+
+	mockRepo.On("GetByID", card.ID).Return(card, nil)
+	mockReviewLogRepo.On("GetLatestByFlashcard", card.ID).Return(lastReview, nil)
+	mockReviewLogRepo.On("CountUndoneBySession", sessionID).Return(0, nil)
+	mockRepo.On("UpdateSchedulingState", card.ID, before).Return(card, nil)
+	mockRepo.On("MoveToFilteredDeck", []uuid.UUID{card.ID}, filteredID).Return([]*models.Flashcard{&moved}, nil)
+	mockReviewLogRepo.On("MarkUndone", lastReview.ID).Return(nil)
+
+	result, err := service.UndoLastReviewWithOwnership(card.ID, userID, sessionID)
+
+	require.NoError(t, err)
+	assert.Equal(t, filteredID, result.DeckID)
+	assert.Equal(t, &homeID, result.HomeDeckID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFlashcardService_ReviewFlashcard_FilteredDeckReschedule(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	home := testutils.CreateTestDeck(userID)
+	filtered := testutils.CreateTestDeck(userID)
+	filtered.Scheduler = models.SchedulerFSRS // Never used: cards keep their home deck's scheduler
+	filtered.Filter = &models.DeckFilter{Search: "is:review", Order: models.FilterOrderDue, Limit: 100, Reschedule: true}
+
+	yesterday := time.Now().Add(-24 * time.Hour)
+	card := testutils.CreateTestFlashcard(userID, filtered.ID)
+	card.HomeDeckID = &home.ID
+	card.State = models.CardStateReview
+	card.Interval = 6
+	card.ReviewCount = 2
+	card.NextReview = &yesterday
+	returned := testutils.CreateTestFlashcard(userID, home.ID)
+	returned.ID = card.ID
+
+	mockRepo.On("GetByID", card.ID).Return(card, nil)
+	mockDeckRepo.On("GetByID", filtered.ID).Return(filtered, nil)
+	mockDeckRepo.On("GetByID", home.ID).Return(home, nil)
+	mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+	mockRepo.On("UpdateSchedulingState", card.ID, mock.AnythingOfType("models.SchedulingState")).Return(card, nil)
+	mockReviewLogRepo.On("Create", mock.MatchedBy(func(log *models.ReviewLog) bool {
+		return log.DeckID == home.ID && log.Scheduler == models.SchedulerSM2
+	})).Return(&models.ReviewLog{}, nil)
+	mockRepo.On("ReturnToHomeDeck", []uuid.UUID{card.ID}).Return([]*models.Flashcard{returned}, nil)
+
+	result, err := service.ReviewFlashcard(card.ID, &models.ReviewFlashcardRequest{Quality: 4})
+
+	require.NoError(t, err)
+	assert.Equal(t, home.ID, result.DeckID)
+	mockRepo.AssertExpectations(t)
+	mockReviewLogRepo.AssertExpectations(t)
+}
+
+func TestFlashcardService_GetDueCards_FilteredDeck(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	preset := testutils.CreateTestDeckPreset(userID)
+	preset.NewPerDay = 0
+	preset.ReviewsPerDay = 0
+	home := testutils.CreateTestDeck(userID)
+	home.PresetID = &preset.ID
+	filtered := testutils.CreateTestDeck(userID)
+	filtered.Filter = &models.DeckFilter{Search: "deck:*", Order: models.FilterOrderDue, Limit: 100, Reschedule: true}
+
+	mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+	mockDeckRepo.On("GetByUser", userID).Return([]*models.Deck{home, filtered}, nil)
+	mockPresetRepo.On("GetByUser", userID).Return([]*models.DeckPreset{preset}, nil)
+	mockReviewLogRepo.On("CountStudiedSince", userID, mock.AnythingOfType("time.Time")).Return([]*models.StudiedCount{}, nil)
+
+	nextMonth := time.Now().AddDate(0, 1, 0)
+	borrowedNew := &models.Flashcard{ID: uuid.New(), UserID: userID, DeckID: filtered.ID, HomeDeckID: &home.ID, State: models.CardStateNew}
+	borrowedReview := &models.Flashcard{ID: uuid.New(), UserID: userID, DeckID: filtered.ID, HomeDeckID: &home.ID, State: models.CardStateReview, NextReview: &nextMonth}
+	atHome := &models.Flashcard{ID: uuid.New(), UserID: userID, DeckID: home.ID, State: models.CardStateNew}
+	mockRepo.On("GetByUser", userID).Return([]*models.Flashcard{borrowedNew, borrowedReview, atHome}, nil)
+
+	result, err := service.GetDueCards(userID)
+
+	require.NoError(t, err)
+	// Filtered deck cards are due now and bypass the home deck's limits
+	var ids []uuid.UUID
+	for _, card := range result.Cards {
+		ids = append(ids, card.ID)
+	}
+	assert.ElementsMatch(t, []uuid.UUID{borrowedNew.ID, borrowedReview.ID}, ids)
+	_, listed := result.Remaining.Decks[filtered.ID]
+	assert.False(t, listed)
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"swipelearn-api/internal/models"
+)
+
+// forecastMaxSameDayAnswers caps how often a card is answered on one simulated day, so a
+// misconfigured step list cannot keep a card on the same day forever
+const forecastMaxSameDayAnswers = 20
+
+// forecastCard is a card followed through a workload simulation
+type forecastCard struct {
+	planner *reviewPlanner
+	state   models.SchedulingState
+}
+
+// workloadSimulation replays the coming study days of a user: every card due on a day
+// is answered under its deck's scheduler, recalled or forgotten at random, and comes
+// back on the day its new interval lands on. Intervals are not fuzzed and daily
+// review limits are ignored, so the result is the load the schedule asks for.
+type workloadSimulation struct {
+	clock        studyClock
+	today        time.Time // Start of the current study day
+	days         int
+	retention    float64 // Recall probability for cards without a memory model
+	cards        []forecastCard
+	newPerDay    int
+	newPlanner   *reviewPlanner // Scheduler of the deck hypothetical new cards go to
+	startingEase float64
+}
+
+// dayIndex returns the study day, counted from today, that t falls on. Times before
+// today are overdue and count as today.
+func (w *workloadSimulation) dayIndex(t time.Time) int {
+	day := int(math.Round(w.clock.dayStart(t).Sub(w.today).Hours() / 24))
+	return max(day, 0)
+}
+
+// scheduled counts the cards due on each day from their current next review
+func (w *workloadSimulation) scheduled() []int {
+	counts := make([]int, w.days)
+	for _, card := range w.cards {
+		if card.state.NextReview == nil {
+			continue
+		}
+		if day := w.dayIndex(*card.state.NextReview); day < w.days {
+			counts[day]++
+		}
+	}
+	return counts
+}
+
+// run simulates the coming days once and returns how many answers each day gets
+func (w *workloadSimulation) run(rng *rand.Rand) []int {
+	answers := make([]int, w.days)
+	due := make([][]forecastCard, w.days)
+	for _, card := range w.cards {
+		if card.state.NextReview == nil {
+			continue
+		}
+		if day := w.dayIndex(*card.state.NextReview); day < w.days {
+			due[day] = append(due[day], card)
+		}
+	}
+
+	for day := 0; day < w.days; day++ {
+		for range w.newPerDay {
+			due[day] = append(due[day], forecastCard{
+				planner: w.newPlanner,
+				state: models.SchedulingState{
+					Difficulty: w.startingEase,
+					Interval:   1,
+					EaseFactor: w.startingEase,
+					State:      models.CardStateNew,
+				},
+			})
+		}
+
+		dayStart := w.today.AddDate(0, 0, day)
+		dayEnd := dayStart.AddDate(0, 0, 1)
+		for _, card := range due[day] {
+			now := dayStart
+			for range forecastMaxSameDayAnswers {
+				answers[day]++
+				card.state = w.answer(card, now, rng)
+
+				next := card.state.NextReview
+				if next == nil || !next.Before(dayEnd) {
+					break
+				}
+				now = maxTime(*next, now)
+			}
+
+			if card.state.NextReview != nil {
+				if next := w.dayIndex(*card.state.NextReview); next > day && next < w.days {
+					due[next] = append(due[next], card)
+				}
+			}
+		}
+	}
+
+	return answers
+}
+
+// answer draws whether a card is recalled at now and returns its state after answering
+// good or again. Cards on learning steps are assumed to pass them.
+func (w *workloadSimulation) answer(card forecastCard, now time.Time, rng *rand.Rand) models.SchedulingState {
+	quality := 4
+	if card.state.CardState() == models.CardStateReview && rng.Float64() >= w.recallProbability(card, now) {
+		quality = 1
+	}
+
+	next := card.planner.scheduler.Schedule(card.state, quality, now)
+	card.planner.clock.alignToStudyDay(&next, now)
+	return next
+}
+
+// recallProbability is the chance a card in review is recalled at now: the FSRS
+// retrievability when the card has a memory state, otherwise the assumed retention
+func (w *workloadSimulation) recallProbability(card forecastCard, now time.Time) float64 {
+	if card.planner.scheduler.Name() != models.SchedulerFSRS || card.state.Stability <= 0 || card.state.LastReview == nil {
+		return w.retention
+	}
+	elapsed := math.Max(0, now.Sub(*card.state.LastReview).Hours()/24)
+	return fsrsRetrievability(elapsed, card.state.Stability)
+}
+
+// forecastDays summarizes the answers of every simulation run into daily forecasts
+func forecastDays(today time.Time, scheduled []int, runs [][]int, newPerDay int) []models.ForecastDay {
+	days := make([]models.ForecastDay, len(scheduled))
+	counts := make([]int, len(runs))
+
+	for day := range days {
+		total := 0
+		for i, answers := range runs {
+			counts[i] = answers[day]
+			total += answers[day]
+		}
+		sort.Ints(counts)
+
+		days[day] = models.ForecastDay{
+			Date:      today.AddDate(0, 0, day).Format(time.DateOnly),
+			Scheduled: scheduled[day],
+			NewCards:  newPerDay,
+		}
+		if len(runs) > 0 {
+			days[day].ExpectedReviews = float64(total) / float64(len(runs))
+			days[day].ReviewsLow = counts[int(0.1*float64(len(runs)-1))]
+			days[day].ReviewsHigh = counts[int(math.Ceil(0.9*float64(len(runs)-1)))]
+		}
+	}
+
+	return days
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
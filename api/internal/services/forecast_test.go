@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+// newTestStatsService returns a stats service for a user with one deck holding cards
+func newTestStatsService(deck *models.Deck, cards []*models.Flashcard, outcomes []*models.ReviewOutcomeCount) *StatsService {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	flashcardService := NewFlashcardService(mockRepo, mockDeckRepo, &MockDeckPresetRepository{}, mockReviewLogRepo, mockUserRepo, logger)
+
+	mockDeckRepo.On("GetByID", deck.ID).Return(deck, nil)
+	mockDeckRepo.On("GetByUser", deck.UserID).Return([]*models.Deck{deck}, nil)
+	mockUserRepo.On("GetByID", deck.UserID).Return(&models.User{ID: deck.UserID}, nil)
+	mockRepo.On("GetByUser", deck.UserID).Return(cards, nil)
+	mockReviewLogRepo.On("CountReviewOutcomes", deck.UserID, mock.AnythingOfType("time.Time")).Return(outcomes, nil)
+
+	service := NewStatsService(mockRepo, mockDeckRepo, mockReviewLogRepo, mockUserRepo, flashcardService, logger)
+	service.simulations = 20
+	return service
+}
+
+// overdueCards returns review cards of a deck that were due yesterday
+func overdueCards(deck *models.Deck, count int) []*models.Flashcard {
+	lastReview := time.Now().AddDate(0, 0, -11)
+	nextReview := time.Now().AddDate(0, 0, -1)
+
+	cards := make([]*models.Flashcard, count)
+	for i := range cards {
+		card := testutils.CreateTestFlashcard(deck.UserID, deck.ID)
+		card.State = models.CardStateReview
+		card.Interval = 10
+		card.ReviewCount = 4
+		card.LastReview = &lastReview
+		card.NextReview = &nextReview
+		cards[i] = card
+	}
+	return cards
+}
+
+func TestStatsService_Forecast_OverdueCardsAreDueToday(t *testing.T) {
+	deck := testutils.CreateTestDeck(uuid.New())
+	service := newTestStatsService(deck, overdueCards(deck, 10), nil)
+
+	forecast, err := service.Forecast(context.Background(), deck.UserID, &models.ForecastRequest{Days: 14})
+
+	require.NoError(t, err)
+	require.Len(t, forecast.Daily, 14)
+	assert.Equal(t, 20, forecast.Simulations)
+	assert.Equal(t, defaultForecastRetention, forecast.Retention)
+	assert.Equal(t, 10, forecast.Daily[0].Scheduled)
+	assert.GreaterOrEqual(t, forecast.Daily[0].ExpectedReviews, 10.0)
+	assert.LessOrEqual(t, float64(forecast.Daily[0].ReviewsLow), forecast.Daily[0].ExpectedReviews)
+	assert.GreaterOrEqual(t, float64(forecast.Daily[0].ReviewsHigh), forecast.Daily[0].ExpectedReviews)
+	assert.Greater(t, forecast.TotalExpectedReviews, forecast.Daily[0].ExpectedReviews, "forgotten cards come back within two weeks")
+}
+
+func TestStatsService_Forecast_DefaultsToThirtyDays(t *testing.T) {
+	deck := testutils.CreateTestDeck(uuid.New())
+	service := newTestStatsService(deck, nil, nil)
+
+	forecast, err := service.Forecast(context.Background(), deck.UserID, &models.ForecastRequest{})
+
+	require.NoError(t, err)
+	assert.Equal(t, defaultForecastDays, forecast.Days)
+	assert.Len(t, forecast.Daily, defaultForecastDays)
+	assert.Zero(t, forecast.TotalExpectedReviews)
+}
+
+func TestStatsService_Forecast_NewCardsAddLoad(t *testing.T) {
+	deck := testutils.CreateTestDeck(uuid.New())
+	service := newTestStatsService(deck, overdueCards(deck, 10), nil)
+
+	without, err := service.Forecast(context.Background(), deck.UserID, &models.ForecastRequest{Days: 14, DeckID: &deck.ID})
+	require.NoError(t, err)
+	with, err := service.Forecast(context.Background(), deck.UserID, &models.ForecastRequest{Days: 14, DeckID: &deck.ID, NewPerDay: 5})
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, with.Daily[0].NewCards)
+	assert.Zero(t, without.Daily[0].NewCards)
+	// Each new card is answered at least once per learning step on its first day
+	assert.GreaterOrEqual(t, with.Daily[0].ExpectedReviews-without.Daily[0].ExpectedReviews, 5.0)
+	assert.Greater(t, with.TotalExpectedReviews, without.TotalExpectedReviews+14*5)
+}
+
+func TestStatsService_Forecast_IsDeterministic(t *testing.T) {
+	deck := testutils.CreateTestDeck(uuid.New())
+	service := newTestStatsService(deck, overdueCards(deck, 25), nil)
+
+	first, err := service.Forecast(context.Background(), deck.UserID, &models.ForecastRequest{Days: 30})
+	require.NoError(t, err)
+	second, err := service.Forecast(context.Background(), deck.UserID, &models.ForecastRequest{Days: 30})
+	require.NoError(t, err)
+
+	assert.Equal(t, first.Daily, second.Daily)
+}
+
+func TestStatsService_Forecast_UsesRecentRetention(t *testing.T) {
+	deck := testutils.CreateTestDeck(uuid.New())
+	outcomes := []*models.ReviewOutcomeCount{
+		{DeckID: deck.ID, Reviews: 60, Recalled: 45},
+		{DeckID: uuid.New(), Reviews: 40, Recalled: 35},
+	}
+	service := newTestStatsService(deck, nil, outcomes)
+
+	forecast, err := service.Forecast(context.Background(), deck.UserID, &models.ForecastRequest{Days: 7})
+
+	require.NoError(t, err)
+	assert.InDelta(t, 0.8, forecast.Retention, 1e-9)
+}
+
+func TestStatsService_Forecast_SkipsSuspendedCards(t *testing.T) {
+	deck := testutils.CreateTestDeck(uuid.New())
+	cards := overdueCards(deck, 3)
+	cards[0].Suspended = true
+	service := newTestStatsService(deck, cards, nil)
+
+	forecast, err := service.Forecast(context.Background(), deck.UserID, &models.ForecastRequest{Days: 7})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, forecast.Daily[0].Scheduled)
+}
+
+func TestStatsService_Forecast_NewCardsRequireDeck(t *testing.T) {
+	deck := testutils.CreateTestDeck(uuid.New())
+	service := newTestStatsService(deck, nil, nil)
+
+	forecast, err := service.Forecast(context.Background(), deck.UserID, &models.ForecastRequest{NewPerDay: 10})
+
+	assert.Nil(t, forecast)
+	assert.EqualError(t, err, "deck_id is required to add new cards")
+}
+
+func TestStatsService_Forecast_UnauthorizedDeck(t *testing.T) {
+	deck := testutils.CreateTestDeck(uuid.New())
+	service := newTestStatsService(deck, nil, nil)
+
+	forecast, err := service.Forecast(context.Background(), uuid.New(), &models.ForecastRequest{DeckID: &deck.ID})
+
+	assert.Nil(t, forecast)
+	assert.EqualError(t, err, "unauthorized: deck does not belong to user")
+}
+
+func TestStatsService_ForecastSimulations(t *testing.T) {
+	service := &StatsService{simulations: 100}
+
+	assert.Equal(t, 100, service.forecastSimulations(0))
+	assert.Equal(t, 100, service.forecastSimulations(5000))
+	// A year of the most new cards a day gets fewer runs, but never below the minimum
+	assert.Equal(t, 13, service.forecastSimulations(365*200))
+	assert.Equal(t, minForecastSimulations, service.forecastSimulations(10_000_000))
+}
+
+func TestStatsService_Forecast_Canceled(t *testing.T) {
+	deck := testutils.CreateTestDeck(uuid.New())
+	service := newTestStatsService(deck, []*models.Flashcard{reviewCard(5)}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	forecast, err := service.Forecast(ctx, deck.UserID, &models.ForecastRequest{Days: 7})
+
+	assert.Nil(t, forecast)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"swipelearn-api/internal/models"
+)
+
+// FSRS forgetting curve constants (FSRS-4.5): R(t, S) = (1 + factor * t / S) ^ decay
+const (
+	fsrsDecay  = -0.5
+	fsrsFactor = 19.0 / 81.0
+)
+
+// FSRSParameters configures the FSRS scheduler
+type FSRSParameters struct {
+	Weights          []float64 // 17 model weights (w0..w16)
+	RequestRetention float64   // Desired probability of recall at the next review
+	MaximumInterval  int       // Upper bound for intervals, in days
+}
+
+// DefaultFSRSParameters returns the published FSRS-4.5 default weights
+func DefaultFSRSParameters() FSRSParameters {
+	return FSRSParameters{
+		Weights: []float64{
+			0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031,
+			1.6474, 0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+		},
+		RequestRetention: 0.9,
+		MaximumInterval:  36500,
+	}
+}
+
+// FSRSScheduler implements the Free Spaced Repetition Scheduler.
+// Stability, difficulty and retrievability are kept on the card:
+// Stability is the interval in days at which recall probability drops to 90%,
+// Difficulty (1-10) is how hard the card is to remember, and Retrievability
+// is the predicted recall probability at the moment of the last review.
+type FSRSScheduler struct {
+	params FSRSParameters
+	config SchedulerConfig
+}
+
+func NewFSRSScheduler(params FSRSParameters, config SchedulerConfig) *FSRSScheduler {
+	return &FSRSScheduler{params: params, config: config}
+}
+
+// Name returns the scheduler identifier
+func (s *FSRSScheduler) Name() string {
+	return models.SchedulerFSRS
+}
+
+// Schedule updates the card's memory state and derives the next interval from it.
+// Cards in learning or relearning stay on their same-day steps until they graduate.
+func (s *FSRSScheduler) Schedule(state models.SchedulingState, quality int, now time.Time) models.SchedulingState {
+	rating := ratingFromQuality(quality)
+	steps := s.config.stepsFor(state)
+
+	lastReview := now
+	next := s.updateMemory(state, rating, now, len(steps) > 0)
+	next.LastReview = &lastReview
+
+	if len(steps) > 0 {
+		step, delay, graduated := learningStep(steps, state.Step, rating)
+		if !graduated {
+			nextReview := now.Add(delay)
+			next.State = stepState(state)
+			next.Step = step
+			next.NextReview = &nextReview
+			return next
+		}
+	}
+
+	next.Interval = s.nextInterval(next.Stability)
+	nextReview := now.Add(days(next.Interval))
+	next.State = models.CardStateReview
+	next.Step = 0
+	if rating == ratingAgain {
+		next.ReviewCount = 0
+		// A lapse sends the card through the relearning steps first
+		if state.CardState() == models.CardStateReview && len(s.config.RelearningSteps) > 0 {
+			nextReview = now.Add(s.config.RelearningSteps[0])
+			next.State = models.CardStateRelearning
+		}
+	} else {
+		next.ReviewCount = state.ReviewCount + 1
+	}
+	next.NextReview = &nextReview
+	return next
+}
+
+// updateMemory returns the state with stability, difficulty and retrievability updated
+// for the answer. Answers on same-day steps only adjust difficulty, since FSRS
+// models memory across days.
+func (s *FSRSScheduler) updateMemory(state models.SchedulingState, rating int, now time.Time, onStep bool) models.SchedulingState {
+	next := state
+
+	if state.Stability <= 0 {
+		// First review under FSRS (new card, or a card migrated from another scheduler)
+		next.Stability = s.initStability(rating)
+		next.Difficulty = s.initDifficulty(rating)
+		next.Retrievability = 1.0
+		return next
+	}
+
+	elapsed := 0.0
+	if state.LastReview != nil {
+		elapsed = math.Max(0, now.Sub(*state.LastReview).Hours()/24)
+	}
+	next.Retrievability = fsrsRetrievability(elapsed, state.Stability)
+	next.Difficulty = s.nextDifficulty(state.Difficulty, rating)
+
+	switch {
+	case onStep:
+	case rating == ratingAgain:
+		next.Stability = s.forgetStability(state.Difficulty, state.Stability, next.Retrievability)
+	default:
+		next.Stability = s.recallStability(state.Difficulty, state.Stability, next.Retrievability, rating)
+	}
+
+	return next
+}
+
+// fsrsRetrievability returns the probability of recall after elapsed days
+func fsrsRetrievability(elapsedDays, stability float64) float64 {
+	return math.Pow(1+fsrsFactor*elapsedDays/stability, fsrsDecay)
+}
+
+func (s *FSRSScheduler) initStability(rating int) float64 {
+	return math.Max(s.params.Weights[rating-1], 0.1)
+}
+
+func (s *FSRSScheduler) initDifficulty(rating int) float64 {
+	w := s.params.Weights
+	return clampDifficulty(w[4] - float64(rating-3)*w[5])
+}
+
+func (s *FSRSScheduler) nextDifficulty(d float64, rating int) float64 {
+	w := s.params.Weights
+	next := d - w[6]*float64(rating-3)
+	// Mean reversion towards the default difficulty of a "good" first answer
+	return clampDifficulty(w[7]*s.initDifficulty(ratingGood) + (1-w[7])*next)
+}
+
+func (s *FSRSScheduler) recallStability(d, stability, r float64, rating int) float64 {
+	w := s.params.Weights
+	hardPenalty := 1.0
+	if rating == ratingHard {
+		hardPenalty = w[15]
+	}
+	easyBonus := 1.0
+	if rating == ratingEasy {
+		easyBonus = w[16]
+	}
+	return stability * (1 + math.Exp(w[8])*
+		(11-d)*
+		math.Pow(stability, -w[9])*
+		(math.Exp((1-r)*w[10])-1)*
+		hardPenalty*
+		easyBonus)
+}
+
+func (s *FSRSScheduler) forgetStability(d, stability, r float64) float64 {
+	w := s.params.Weights
+	next := w[11] *
+		math.Pow(d, -w[12]) *
+		(math.Pow(stability+1, w[13]) - 1) *
+		math.Exp((1-r)*w[14])
+	// A lapse never increases stability
+	return math.Min(next, stability)
+}
+
+// nextInterval returns the number of days until recall probability drops to the requested retention
+func (s *FSRSScheduler) nextInterval(stability float64) int {
+	interval := stability / fsrsFactor * (math.Pow(s.params.RequestRetention, 1/fsrsDecay) - 1)
+	rounded := int(math.Round(interval))
+	if rounded < 1 {
+		rounded = 1
+	}
+	if s.params.MaximumInterval > 0 && rounded > s.params.MaximumInterval {
+		rounded = s.params.MaximumInterval
+	}
+	return rounded
+}
+
+func clampDifficulty(d float64) float64 {
+	return math.Min(math.Max(d, 1), 10)
+}
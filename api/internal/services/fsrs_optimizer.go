@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"math"
+	"slices"
+
+	"swipelearn-api/internal/models"
+)
+
+// fsrsWeightBounds keeps fitted weights within the ranges the FSRS model is defined for
+var fsrsWeightBounds = [][2]float64{
+	{0.1, 100}, {0.1, 100}, {0.1, 100}, {0.1, 100}, // Initial stability per rating
+	{1, 10}, {0.1, 5}, {0.1, 5}, {0, 0.75}, // Difficulty
+	{0, 4.5}, {0, 0.8}, {0.01, 3.5}, // Stability after recall
+	{0.1, 5}, {0.01, 0.25}, {0.01, 0.9}, {0.01, 4}, // Stability after a lapse
+	{0, 1}, {1, 6}, // Hard penalty, easy bonus
+}
+
+// Adam settings used to fit FSRS weights
+const (
+	fsrsLearningRate = 0.04
+	fsrsBeta1        = 0.9
+	fsrsBeta2        = 0.999
+	fsrsEpsilon      = 1e-8
+)
+
+// fsrsReview is one answer of a card's history, reduced to what FSRS replays
+type fsrsReview struct {
+	rating      int
+	elapsedDays float64 // Days since the card's previous answer, 0 for its first one
+}
+
+// fsrsDataset holds the review histories an optimizer run fits weights to
+type fsrsDataset struct {
+	cards       [][]fsrsReview
+	predictions int // Answers given a day or more after the previous one, whose recall FSRS predicts
+}
+
+// fsrsMetrics measures how well weights predict recall: the mean log-loss and the
+// root mean squared error between predicted recall probability and actual recall
+type fsrsMetrics struct {
+	logLoss float64
+	rmse    float64
+}
+
+// newFSRSDataset groups review logs, ordered by card and then by time, into card
+// histories. Cards whose first logged answer was not to a new card are left out:
+// their earlier reviews predate the log, so their memory state cannot be replayed.
+func newFSRSDataset(logs []*models.ReviewLog) *fsrsDataset {
+	data := &fsrsDataset{}
+
+	for start := 0; start < len(logs); {
+		end := start + 1
+		for end < len(logs) && logs[end].FlashcardID == logs[start].FlashcardID {
+			end++
+		}
+
+		history := logs[start:end]
+		start = end
+		if history[0].StateBefore.CardState() != models.CardStateNew {
+			continue
+		}
+
+		card := make([]fsrsReview, len(history))
+		for i, log := range history {
+			card[i].rating = ratingFromQuality(log.Quality)
+			if i > 0 {
+				card[i].elapsedDays = log.ReviewedAt.Sub(history[i-1].ReviewedAt).Hours() / 24
+				if card[i].elapsedDays >= 1 {
+					data.predictions++
+				}
+			}
+		}
+		data.cards = append(data.cards, card)
+	}
+
+	return data
+}
+
+// evaluateFSRS replays every card history with the given weights, the same way the
+// FSRS scheduler updates a card, and scores the predicted recall of each answer given
+// a day or more after the previous one
+func evaluateFSRS(weights []float64, data *fsrsDataset) fsrsMetrics {
+	if data.predictions == 0 {
+		return fsrsMetrics{}
+	}
+
+	s := &FSRSScheduler{params: FSRSParameters{Weights: weights}}
+	var logLoss, squaredError float64
+
+	for _, card := range data.cards {
+		stability := s.initStability(card[0].rating)
+		difficulty := s.initDifficulty(card[0].rating)
+
+		for _, review := range card[1:] {
+			if review.elapsedDays < 1 {
+				// Same-day answers only move difficulty
+				difficulty = s.nextDifficulty(difficulty, review.rating)
+				continue
+			}
+
+			r := fsrsRetrievability(review.elapsedDays, stability)
+			p := math.Min(math.Max(r, 1e-6), 1-1e-6)
+			if review.rating == ratingAgain {
+				logLoss -= math.Log(1 - p)
+				squaredError += r * r
+				stability = s.forgetStability(difficulty, stability, r)
+			} else {
+				logLoss -= math.Log(p)
+				squaredError += (1 - r) * (1 - r)
+				stability = s.recallStability(difficulty, stability, r, review.rating)
+			}
+			difficulty = s.nextDifficulty(difficulty, review.rating)
+		}
+	}
+
+	n := float64(data.predictions)
+	return fsrsMetrics{logLoss: logLoss / n, rmse: math.Sqrt(squaredError / n)}
+}
+
+// fitFSRSWeights fits FSRS weights to a dataset by gradient descent (Adam) on the
+// log-loss, starting from start. Gradients are taken by central differences, so the
+// fit follows the exact formulas the scheduler uses. Returns the weights with the
+// lowest loss seen, which are never worse than start.
+func fitFSRSWeights(ctx context.Context, start []float64, data *fsrsDataset, iterations int) ([]float64, error) {
+	weights := clampFSRSWeights(slices.Clone(start))
+	best := slices.Clone(weights)
+	bestLoss := evaluateFSRS(weights, data).logLoss
+
+	m := make([]float64, len(weights))
+	v := make([]float64, len(weights))
+	gradient := make([]float64, len(weights))
+	probe := make([]float64, len(weights))
+
+	for t := 1; t <= iterations; t++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		for i := range weights {
+			h := 1e-4 * math.Max(1, math.Abs(weights[i]))
+			copy(probe, weights)
+			probe[i] = weights[i] + h
+			up := evaluateFSRS(probe, data).logLoss
+			probe[i] = weights[i] - h
+			down := evaluateFSRS(probe, data).logLoss
+			gradient[i] = (up - down) / (2 * h)
+		}
+
+		for i := range weights {
+			m[i] = fsrsBeta1*m[i] + (1-fsrsBeta1)*gradient[i]
+			v[i] = fsrsBeta2*v[i] + (1-fsrsBeta2)*gradient[i]*gradient[i]
+			mHat := m[i] / (1 - math.Pow(fsrsBeta1, float64(t)))
+			vHat := v[i] / (1 - math.Pow(fsrsBeta2, float64(t)))
+			weights[i] -= fsrsLearningRate * mHat / (math.Sqrt(vHat) + fsrsEpsilon)
+		}
+		clampFSRSWeights(weights)
+
+		if loss := evaluateFSRS(weights, data).logLoss; loss < bestLoss {
+			bestLoss = loss
+			copy(best, weights)
+		}
+	}
+
+	return best, nil
+}
+
+// clampFSRSWeights moves every weight into its bounds, in place
+func clampFSRSWeights(weights []float64) []float64 {
+	for i := range weights {
+		if i < len(fsrsWeightBounds) {
+			weights[i] = math.Min(math.Max(weights[i], fsrsWeightBounds[i][0]), fsrsWeightBounds[i][1])
+		}
+	}
+	return weights
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+)
+
+// simulatedReviewLogs generates review histories of cards whose recall follows FSRS
+// with the given weights: each card is learned, then reviewed after random gaps and
+// answered good or again depending on a draw against its predicted recall
+func simulatedReviewLogs(weights []float64, cards, reviews int) []*models.ReviewLog {
+	rng := rand.New(rand.NewPCG(1, 2))
+	s := &FSRSScheduler{params: FSRSParameters{Weights: weights}}
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	var logs []*models.ReviewLog
+	for range cards {
+		cardID := uuid.New()
+		at := start
+		stability := s.initStability(ratingGood)
+		difficulty := s.initDifficulty(ratingGood)
+		logs = append(logs, &models.ReviewLog{
+			FlashcardID: cardID,
+			Quality:     4,
+			StateBefore: models.SchedulingState{State: models.CardStateNew},
+			ReviewedAt:  at,
+		})
+
+		for range reviews {
+			elapsed := 1 + rng.IntN(30)
+			at = at.AddDate(0, 0, elapsed)
+			r := fsrsRetrievability(float64(elapsed), stability)
+
+			quality, rating := 4, ratingGood
+			if rng.Float64() > r {
+				quality, rating = 1, ratingAgain
+				stability = s.forgetStability(difficulty, stability, r)
+			} else {
+				stability = s.recallStability(difficulty, stability, r, rating)
+			}
+			difficulty = s.nextDifficulty(difficulty, rating)
+
+			logs = append(logs, &models.ReviewLog{
+				FlashcardID: cardID,
+				Quality:     quality,
+				StateBefore: models.SchedulingState{State: models.CardStateReview, ReviewCount: 1},
+				ReviewedAt:  at,
+			})
+		}
+	}
+
+	return logs
+}
+
+func TestNewFSRSDataset(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	complete, partial := uuid.New(), uuid.New()
+	logs := []*models.ReviewLog{
+		{FlashcardID: complete, Quality: 4, StateBefore: models.SchedulingState{State: models.CardStateNew}, ReviewedAt: start},
+		{FlashcardID: complete, Quality: 4, StateBefore: models.SchedulingState{State: models.CardStateLearning}, ReviewedAt: start.Add(10 * time.Minute)},
+		{FlashcardID: complete, Quality: 1, StateBefore: models.SchedulingState{State: models.CardStateReview}, ReviewedAt: start.AddDate(0, 0, 3)},
+		// Reviewed before the log existed, so its history cannot be replayed
+		{FlashcardID: partial, Quality: 4, StateBefore: models.SchedulingState{State: models.CardStateReview}, ReviewedAt: start},
+		{FlashcardID: partial, Quality: 4, StateBefore: models.SchedulingState{State: models.CardStateReview}, ReviewedAt: start.AddDate(0, 0, 5)},
+	}
+
+	data := newFSRSDataset(logs)
+
+	require.Len(t, data.cards, 1)
+	assert.Equal(t, 1, data.predictions)
+	assert.Equal(t, []fsrsReview{
+		{rating: ratingGood},
+		{rating: ratingGood, elapsedDays: 10.0 / (24 * 60)},
+		{rating: ratingAgain, elapsedDays: 3 - 10.0/(24*60)},
+	}, data.cards[0])
+}
+
+func TestEvaluateFSRS_NoPredictions(t *testing.T) {
+	metrics := evaluateFSRS(DefaultFSRSParameters().Weights, &fsrsDataset{})
+	assert.Equal(t, fsrsMetrics{}, metrics)
+}
+
+func TestFitFSRSWeights_ImprovesFit(t *testing.T) {
+	trueWeights := slices.Clone(DefaultFSRSParameters().Weights)
+	trueWeights[2] = 8    // Remembered much longer after a first "good"
+	trueWeights[8] = 1.0  // Stability grows more slowly
+	trueWeights[11] = 1.2 // Lapses cost less
+	data := newFSRSDataset(simulatedReviewLogs(trueWeights, 300, 6))
+
+	start := DefaultFSRSParameters().Weights
+	fitted, err := fitFSRSWeights(context.Background(), start, data, 40)
+	require.NoError(t, err)
+
+	before := evaluateFSRS(start, data)
+	after := evaluateFSRS(fitted, data)
+	assert.Less(t, after.logLoss, before.logLoss)
+	assert.Less(t, after.rmse, before.rmse)
+	assert.Equal(t, DefaultFSRSParameters().Weights, start, "start weights are left untouched")
+
+	for i, w := range fitted {
+		assert.GreaterOrEqual(t, w, fsrsWeightBounds[i][0])
+		assert.LessOrEqual(t, w, fsrsWeightBounds[i][1])
+	}
+}
+
+func TestFitFSRSWeights_Cancelled(t *testing.T) {
+	data := newFSRSDataset(simulatedReviewLogs(DefaultFSRSParameters().Weights, 10, 3))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := fitFSRSWeights(ctx, DefaultFSRSParameters().Weights, data, 10)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNewScheduler_FittedFSRSWeights(t *testing.T) {
+	config := DefaultSchedulerConfig()
+	config.FSRSWeights = slices.Clone(DefaultFSRSParameters().Weights)
+	config.FSRSWeights[2] = 20
+
+	scheduler, err := NewScheduler(models.SchedulerFSRS, config)
+	require.NoError(t, err)
+
+	assert.Equal(t, 20.0, scheduler.(*FSRSScheduler).initStability(ratingGood))
+}
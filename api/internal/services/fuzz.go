@@ -0,0 +1,71 @@
+package services
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand/v2"
+
+	"github.com/google/uuid"
+)
+
+// fuzzRanges widens the fuzz range by a share of each part of the interval: 15% of
+// the days between 2.5 and 7, 10% up to 20 days and 5% beyond
+var fuzzRanges = []struct {
+	start, end, factor float64
+}{
+	{2.5, 7, 0.15},
+	{7, 20, 0.10},
+	{20, math.MaxFloat64, 0.05},
+}
+
+// fuzzRange returns the lowest and highest interval, in days, a review interval may
+// be moved to. Intervals under 2.5 days are not fuzzed.
+func fuzzRange(interval int, maximumInterval int) (int, int) {
+	ivl := float64(interval)
+	if ivl < 2.5 {
+		return interval, interval
+	}
+
+	delta := 1.0
+	for _, r := range fuzzRanges {
+		delta += r.factor * max(min(ivl, r.end)-r.start, 0)
+	}
+
+	low := max(int(math.Round(ivl-delta)), 2)
+	high := int(math.Round(ivl + delta))
+	if maximumInterval > 0 {
+		high = min(high, maximumInterval)
+		low = min(low, high)
+	}
+	return low, high
+}
+
+// fuzzSeed returns the random source used to fuzz a card's next interval. It only depends
+// on the card and how often it was reviewed, so previews match the saved review.
+func fuzzSeed(cardID uuid.UUID, reviewCount int) *rand.Rand {
+	high := binary.BigEndian.Uint64(cardID[:8])
+	low := binary.BigEndian.Uint64(cardID[8:])
+	return rand.New(rand.NewPCG(high, low^uint64(reviewCount)))
+}
+
+// leastLoadedInterval returns the interval between low and high whose day has the fewest
+// reviews due. Ties go to the interval closest to target, then to the shorter one.
+func leastLoadedInterval(low, high, target int, load map[int]int) int {
+	best := target
+	for interval := low; interval <= high; interval++ {
+		switch {
+		case load[interval] < load[best]:
+			best = interval
+		case load[interval] == load[best] && distance(interval, target) < distance(best, target):
+			best = interval
+		}
+	}
+	return best
+}
+
+func distance(a, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
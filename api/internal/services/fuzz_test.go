@@ -0,0 +1,105 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+func TestFuzzRange(t *testing.T) {
+	tests := []struct {
+		interval, maximum int
+		low, high         int
+	}{
+		{1, 36500, 1, 1},
+		{2, 36500, 2, 2},
+		{3, 36500, 2, 4},
+		{7, 36500, 5, 9},
+		{15, 36500, 13, 17},
+		{100, 36500, 93, 107},
+		{100, 102, 93, 102},
+	}
+
+	for _, tt := range tests {
+		low, high := fuzzRange(tt.interval, tt.maximum)
+		assert.Equal(t, tt.low, low, "low for %d days", tt.interval)
+		assert.Equal(t, tt.high, high, "high for %d days", tt.interval)
+	}
+}
+
+func TestFuzzSeed_Deterministic(t *testing.T) {
+	cardID := uuid.New()
+
+	assert.Equal(t, fuzzSeed(cardID, 3).IntN(1000), fuzzSeed(cardID, 3).IntN(1000))
+
+	// Cards answered together spread over the range
+	seen := map[int]bool{}
+	for i := 0; i < 50; i++ {
+		seen[fuzzSeed(uuid.New(), 3).IntN(5)] = true
+	}
+	assert.Greater(t, len(seen), 1)
+}
+
+func TestLeastLoadedInterval(t *testing.T) {
+	assert.Equal(t, 14, leastLoadedInterval(13, 17, 15, map[int]int{13: 9, 14: 2, 15: 5, 16: 2, 17: 8}))
+	// Ties go to the interval closest to the target, then the shorter one
+	assert.Equal(t, 15, leastLoadedInterval(13, 17, 15, map[int]int{}))
+	assert.Equal(t, 14, leastLoadedInterval(13, 17, 15, map[int]int{13: 1, 15: 1, 17: 1}))
+}
+
+func TestFlashcardService_ReviewFlashcard_LoadBalancing(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+	service.config.LoadBalancing = true
+
+	user := &models.User{ID: uuid.New(), Timezone: "UTC"}
+	lastReview := time.Now().AddDate(0, 0, -6)
+	card := &models.Flashcard{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		DeckID:      uuid.New(),
+		Interval:    6,
+		EaseFactor:  2.5,
+		ReviewCount: 2,
+		State:       models.CardStateReview,
+		LastReview:  &lastReview,
+	}
+
+	// Every day of the 13-17 day fuzz range is busy except day 16
+	dayStart := studyClockForUser(user).dayStart(time.Now())
+	var dueDates []time.Time
+	for day := 13; day <= 17; day++ {
+		if day == 16 {
+			continue
+		}
+		for i := 0; i < 10; i++ {
+			dueDates = append(dueDates, dayStart.AddDate(0, 0, day).Add(time.Duration(i)*time.Hour))
+		}
+	}
+
+	mockRepo.On("GetByID", card.ID).Return(card, nil)
+	mockDeckRepo.On("GetByID", card.DeckID).Return(&models.Deck{ID: card.DeckID, Scheduler: models.SchedulerSM2}, nil)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockRepo.On("GetReviewDueDates", user.ID, dayStart.AddDate(0, 0, 13), dayStart.AddDate(0, 0, 18)).Return(dueDates, nil)
+	mockRepo.On("UpdateSchedulingState", card.ID, mock.MatchedBy(func(state models.SchedulingState) bool {
+		return state.Interval == 16 && state.NextReview.Equal(dayStart.AddDate(0, 0, 16))
+	})).Return(card, nil)
+	mockReviewLogRepo.On("Create", mock.AnythingOfType("*models.ReviewLog")).Return(&models.ReviewLog{}, nil)
+
+	_, err := service.ReviewFlashcard(card.ID, &models.ReviewFlashcardRequest{Quality: 4})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"swipelearn-api/internal/models"
+)
+
+// Colors of occlusion masks: the masks of the card being studied, those of the note's
+// other cards, and the outline of every mask
+const (
+	occlusionActiveFill   = "#ff8e8e"
+	occlusionInactiveFill = "#ffeba2"
+	occlusionStroke       = "#212121"
+)
+
+// occlusionIndices returns the distinct indices of a note's occlusions, in ascending order
+func occlusionIndices(occlusions models.Occlusions) []int {
+	seen := make(map[int]bool)
+	var indices []int
+	for _, occlusion := range occlusions {
+		if occlusion.Index < 1 || seen[occlusion.Index] {
+			continue
+		}
+		seen[occlusion.Index] = true
+		indices = append(indices, occlusion.Index)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// validateOcclusions checks that a note has occlusions and that each is a rectangle or
+// polygon inside the image
+func validateOcclusions(occlusions models.Occlusions) error {
+	if len(occlusions) == 0 {
+		return fmt.Errorf("invalid note: an image occlusion note needs at least one occlusion")
+	}
+
+	inside := func(v float64) bool { return v >= 0 && v <= 1 }
+	for i, occlusion := range occlusions {
+		if occlusion.Index < 1 {
+			return fmt.Errorf("invalid note: occlusion %d needs an index of 1 or more", i+1)
+		}
+		switch occlusion.Shape {
+		case models.OcclusionShapeRect:
+			if occlusion.Width <= 0 || occlusion.Height <= 0 || !inside(occlusion.Left) || !inside(occlusion.Top) ||
+				!inside(occlusion.Left+occlusion.Width) || !inside(occlusion.Top+occlusion.Height) {
+				return fmt.Errorf("invalid note: occlusion %d must be a rectangle inside the image", i+1)
+			}
+		case models.OcclusionShapePolygon:
+			if len(occlusion.Points) < 3 {
+				return fmt.Errorf("invalid note: occlusion %d needs at least 3 points", i+1)
+			}
+			for _, point := range occlusion.Points {
+				if !inside(point.X) || !inside(point.Y) {
+					return fmt.Errorf("invalid note: occlusion %d must be a polygon inside the image", i+1)
+				}
+			}
+		default:
+			return fmt.Errorf("invalid note: occlusion %d has unknown shape %q", i+1, occlusion.Shape)
+		}
+	}
+	return nil
+}
+
+// renderOcclusion draws an image with a note's occlusions on top as SVG. Every
+// occlusion is masked, so the other regions give nothing away; the masks of the card
+// of occlusion index are highlighted on the front and turn into outlines when reveal
+// is set. The image is referenced as media:HASH for clients to load.
+func renderOcclusion(image *models.Media, occlusions models.Occlusions, index int, reveal bool) string {
+	if image == nil || image.Width == nil || image.Height == nil {
+		return ""
+	}
+	width, height := float64(*image.Width), float64(*image.Height)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" class="image-occlusion" viewBox="0 0 %d %d">`, *image.Width, *image.Height)
+	fmt.Fprintf(&b, `<image href="%s%s" width="%d" height="%d"/>`, models.MediaReferencePrefix, image.Hash, *image.Width, *image.Height)
+
+	for _, occlusion := range occlusions {
+		class, fill := "occlusion", occlusionInactiveFill
+		if occlusion.Index == index {
+			class, fill = "occlusion occlusion-active", occlusionActiveFill
+			if reveal {
+				class, fill = "occlusion occlusion-revealed", "none"
+			}
+		}
+
+		switch occlusion.Shape {
+		case models.OcclusionShapeRect:
+			fmt.Fprintf(&b, `<rect class="%s" x="%s" y="%s" width="%s" height="%s" fill="%s" stroke="%s"/>`,
+				class, svgNumber(occlusion.Left*width), svgNumber(occlusion.Top*height),
+				svgNumber(occlusion.Width*width), svgNumber(occlusion.Height*height), fill, occlusionStroke)
+		case models.OcclusionShapePolygon:
+			points := make([]string, len(occlusion.Points))
+			for i, point := range occlusion.Points {
+				points[i] = svgNumber(point.X*width) + "," + svgNumber(point.Y*height)
+			}
+			fmt.Fprintf(&b, `<polygon class="%s" points="%s" fill="%s" stroke="%s"/>`,
+				class, strings.Join(points, " "), fill, occlusionStroke)
+		}
+	}
+
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// svgNumber formats a coordinate with at most two decimals
+func svgNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+)
+
+const testImageHash = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func testImage() *models.Media {
+	width, height := 400, 200
+	return &models.Media{ID: uuid.New(), Hash: testImageHash, ContentType: "image/png", Width: &width, Height: &height}
+}
+
+func rectOcclusion(index int, left, top, width, height float64) models.Occlusion {
+	return models.Occlusion{Index: index, Shape: models.OcclusionShapeRect, Left: left, Top: top, Width: width, Height: height}
+}
+
+func TestOcclusionIndices(t *testing.T) {
+	occlusions := models.Occlusions{rectOcclusion(3, 0, 0, 0.1, 0.1), rectOcclusion(1, 0.5, 0, 0.1, 0.1), rectOcclusion(3, 0, 0.5, 0.1, 0.1)}
+
+	assert.Equal(t, []int{1, 3}, occlusionIndices(occlusions))
+	assert.Empty(t, occlusionIndices(nil))
+}
+
+func TestValidateOcclusions(t *testing.T) {
+	tests := []struct {
+		name       string
+		occlusions models.Occlusions
+		want       string
+	}{
+		{name: "none", occlusions: nil, want: "invalid note: an image occlusion note needs at least one occlusion"},
+		{name: "rectangle outside the image", occlusions: models.Occlusions{rectOcclusion(1, 0.8, 0, 0.3, 0.1)}, want: "invalid note: occlusion 1 must be a rectangle inside the image"},
+		{name: "empty rectangle", occlusions: models.Occlusions{rectOcclusion(1, 0.1, 0.1, 0, 0.1)}, want: "invalid note: occlusion 1 must be a rectangle inside the image"},
+		{
+			name:       "polygon with two points",
+			occlusions: models.Occlusions{rectOcclusion(1, 0, 0, 0.1, 0.1), {Index: 2, Shape: models.OcclusionShapePolygon, Points: []models.OcclusionPoint{{X: 0, Y: 0}, {X: 1, Y: 1}}}},
+			want:       "invalid note: occlusion 2 needs at least 3 points",
+		},
+		{name: "index 0", occlusions: models.Occlusions{rectOcclusion(0, 0, 0, 0.1, 0.1)}, want: "invalid note: occlusion 1 needs an index of 1 or more"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOcclusions(tt.occlusions)
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
+
+func TestRenderOcclusion(t *testing.T) {
+	occlusions := models.Occlusions{
+		rectOcclusion(1, 0.1, 0.25, 0.2, 0.5),
+		{Index: 2, Shape: models.OcclusionShapePolygon, Points: []models.OcclusionPoint{{X: 0.5, Y: 0}, {X: 1, Y: 0}, {X: 0.75, Y: 0.333}}},
+	}
+
+	front := renderOcclusion(testImage(), occlusions, 1, false)
+	assert.True(t, strings.HasPrefix(front, `<svg xmlns="http://www.w3.org/2000/svg" class="image-occlusion" viewBox="0 0 400 200">`))
+	assert.Contains(t, front, `<image href="media:`+testImageHash+`" width="400" height="200"/>`)
+	assert.Contains(t, front, `<rect class="occlusion occlusion-active" x="40" y="50" width="80" height="100" fill="#ff8e8e" stroke="#212121"/>`)
+	assert.Contains(t, front, `<polygon class="occlusion" points="200,0 400,0 300,66.6" fill="#ffeba2" stroke="#212121"/>`)
+
+	// The back reveals the card's region and keeps every other region masked
+	back := renderOcclusion(testImage(), occlusions, 1, true)
+	assert.Contains(t, back, `<rect class="occlusion occlusion-revealed" x="40" y="50" width="80" height="100" fill="none" stroke="#212121"/>`)
+	assert.Contains(t, back, `<polygon class="occlusion" points=`)
+
+	assert.Empty(t, renderOcclusion(nil, occlusions, 1, false))
+}
+
+func TestNoteService_Create_ImageOcclusion(t *testing.T) {
+	service, mocks := newTestNoteService()
+
+	userID := uuid.New()
+	deck := &models.Deck{ID: uuid.New(), UserID: userID}
+	noteType := imageOcclusionNoteType()
+
+	mocks.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+	mocks.noteTypeRepo.On("GetByID", noteType.ID).Return(noteType, nil)
+	mocks.mediaRepo.On("GetByUserAndHash", userID, testImageHash).Return(testImage(), nil)
+	created := captureCreatedCards(mocks.noteRepo, mock.MatchedBy(func(note *models.Note) bool {
+		return len(note.Occlusions) == 3
+	}))
+
+	_, err := service.Create(&models.CreateNoteRequest{
+		DeckID:     deck.ID,
+		NoteTypeID: noteType.ID,
+		Fields:     models.NoteFields{"Image": testImageHash, "Header": "Chambers of the heart"},
+		Occlusions: models.Occlusions{
+			rectOcclusion(1, 0, 0, 0.5, 0.5),
+			rectOcclusion(2, 0.5, 0, 0.5, 0.5),
+			rectOcclusion(2, 0.5, 0.5, 0.5, 0.5), // Masked together with the other index 2 region
+		},
+	}, userID)
+
+	require.NoError(t, err)
+	require.Len(t, *created, 2, "one card per occlusion index")
+	first, second := (*created)[0], (*created)[1]
+	assert.Equal(t, 0, *first.Ord)
+	assert.Equal(t, 1, *second.Ord)
+	assert.True(t, strings.HasPrefix(first.Front, "Chambers of the heart\n\n<svg"))
+	assert.Equal(t, 1, strings.Count(first.Front, "occlusion-active"))
+	assert.Equal(t, 2, strings.Count(second.Front, "occlusion-active"))
+	assert.Equal(t, 2, strings.Count(second.Back, "occlusion-revealed"))
+}
+
+func TestNoteService_Create_ImageOcclusionRejects(t *testing.T) {
+	userID := uuid.New()
+	deck := &models.Deck{ID: uuid.New(), UserID: userID}
+	webp := &models.Media{ID: uuid.New(), Hash: testImageHash, ContentType: "image/webp"}
+	occlusions := models.Occlusions{rectOcclusion(1, 0, 0, 0.5, 0.5)}
+
+	tests := []struct {
+		name       string
+		noteTypeID uuid.UUID
+		occlusions models.Occlusions
+		media      *models.Media
+		want       string
+	}{
+		{name: "image not uploaded", noteTypeID: models.ImageOcclusionNoteTypeID, occlusions: occlusions, want: `invalid note: field "Image" must hold the hash of an image you uploaded`},
+		{name: "image without dimensions", noteTypeID: models.ImageOcclusionNoteTypeID, occlusions: occlusions, media: webp, want: `invalid note: the image in field "Image" must be a PNG, JPEG or GIF`},
+		{name: "no occlusions", noteTypeID: models.ImageOcclusionNoteTypeID, media: testImage(), want: "invalid note: an image occlusion note needs at least one occlusion"},
+		{name: "occlusions on a cloze note", noteTypeID: models.ClozeNoteTypeID, occlusions: occlusions, want: "invalid note: occlusions are only supported by image occlusion note types"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mocks := newTestNoteService()
+			mocks.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+			mocks.noteTypeRepo.On("GetByID", models.ImageOcclusionNoteTypeID).Return(imageOcclusionNoteType(), nil)
+			mocks.noteTypeRepo.On("GetByID", models.ClozeNoteTypeID).Return(clozeNoteType(), nil)
+			if tt.media != nil {
+				mocks.mediaRepo.On("GetByUserAndHash", userID, testImageHash).Return(tt.media, nil)
+			} else {
+				mocks.mediaRepo.On("GetByUserAndHash", userID, testImageHash).Return(nil, fmt.Errorf("media not found"))
+			}
+
+			fields := models.NoteFields{"Image": testImageHash}
+			if tt.noteTypeID == models.ClozeNoteTypeID {
+				fields = models.NoteFields{"Text": "{{c1::heart}}"}
+			}
+
+			note, err := service.Create(&models.CreateNoteRequest{
+				DeckID:     deck.ID,
+				NoteTypeID: tt.noteTypeID,
+				Fields:     fields,
+				Occlusions: tt.occlusions,
+			}, userID)
+
+			assert.Nil(t, note)
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+			mocks.noteRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestNoteService_UpdateWithOwnership_OcclusionsKeepSurvivingCards(t *testing.T) {
+	service, mocks := newTestNoteService()
+
+	userID := uuid.New()
+	deck := &models.Deck{ID: uuid.New(), UserID: userID}
+	noteType := imageOcclusionNoteType()
+	note := &models.Note{
+		ID:         uuid.New(),
+		UserID:     userID,
+		DeckID:     deck.ID,
+		NoteTypeID: noteType.ID,
+		Fields:     models.NoteFields{"Image": testImageHash, "Header": "", "Back Extra": ""},
+		Occlusions: models.Occlusions{rectOcclusion(1, 0, 0, 0.5, 0.5), rectOcclusion(2, 0.5, 0, 0.5, 0.5)},
+	}
+	ord0, ord1 := 0, 1
+	c1 := &models.Flashcard{ID: uuid.New(), NoteID: &note.ID, Ord: &ord0, ReviewCount: 4}
+	c2 := &models.Flashcard{ID: uuid.New(), NoteID: &note.ID, Ord: &ord1}
+	occlusions := models.Occlusions{rectOcclusion(1, 0, 0, 0.25, 0.5), rectOcclusion(3, 0.5, 0.5, 0.5, 0.5)}
+
+	mocks.noteRepo.On("GetByID", note.ID).Return(note, nil)
+	mocks.flashcardRepo.On("GetByNote", note.ID).Return([]*models.Flashcard{c1, c2}, nil)
+	mocks.noteTypeRepo.On("GetByID", noteType.ID).Return(noteType, nil)
+	mocks.mediaRepo.On("GetByUserAndHash", userID, testImageHash).Return(testImage(), nil)
+	mocks.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+	changes := captureCardChanges(mocks.noteRepo, note)
+
+	updated, err := service.UpdateWithOwnership(note.ID, userID, &models.UpdateNoteRequest{Occlusions: occlusions})
+
+	require.NoError(t, err)
+	assert.Equal(t, occlusions, updated.Occlusions)
+	// The region of c1 moved; c1 is rendered again and keeps its history
+	require.Len(t, changes.Update, 1)
+	assert.Equal(t, c1.ID, changes.Update[0].ID)
+	assert.Contains(t, changes.Update[0].Front, `class="occlusion occlusion-active" x="0" y="0" width="100" height="100"`)
+	assert.Equal(t, 4, changes.Update[0].ReviewCount)
+	require.Len(t, changes.Create, 1)
+	assert.Equal(t, 2, *changes.Create[0].Ord)
+	assert.Equal(t, []uuid.UUID{c2.ID}, changes.Delete)
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+// reviewCardWithPreset answers a card of a deck using preset and returns the scheduling
+// state that was saved. setup can add expectations to the flashcard repository.
+func reviewCardWithPreset(t *testing.T, card *models.Flashcard, preset *models.DeckPreset, quality int, setup ...func(*MockFlashcardRepository)) (models.SchedulingState, *MockFlashcardRepository) {
+	t.Helper()
+
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	mockRepo.On("GetByID", card.ID).Return(card, nil)
+	mockDeckRepo.On("GetByID", card.DeckID).Return(&models.Deck{ID: card.DeckID, Scheduler: models.SchedulerSM2, PresetID: &preset.ID}, nil)
+	mockPresetRepo.On("GetByID", preset.ID).Return(preset, nil)
+	mockUserRepo.On("GetByID", card.UserID).Return(&models.User{ID: card.UserID}, nil)
+	mockReviewLogRepo.On("Create", mock.AnythingOfType("*models.ReviewLog")).Return(&models.ReviewLog{}, nil)
+
+	var saved models.SchedulingState
+	mockRepo.On("UpdateSchedulingState", card.ID, mock.AnythingOfType("models.SchedulingState")).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(models.SchedulingState) }).
+		Return(card, nil)
+	mockRepo.On("SetSuspended", []uuid.UUID{card.ID}, true).Return([]*models.Flashcard{card}, nil).Maybe()
+	for _, fn := range setup {
+		fn(mockRepo)
+	}
+
+	_, err := service.ReviewFlashcard(card.ID, &models.ReviewFlashcardRequest{Quality: quality})
+	require.NoError(t, err)
+
+	return saved, mockRepo
+}
+
+func reviewCard(lapses int) *models.Flashcard {
+	lastReview := time.Now().AddDate(0, 0, -10)
+	return &models.Flashcard{
+		ID:          uuid.New(),
+		UserID:      uuid.New(),
+		DeckID:      uuid.New(),
+		Interval:    10,
+		EaseFactor:  2.5,
+		ReviewCount: 5,
+		State:       models.CardStateReview,
+		LastReview:  &lastReview,
+		Lapses:      lapses,
+	}
+}
+
+func TestFlashcardService_ReviewFlashcard_CountsLapse(t *testing.T) {
+	card := reviewCard(2)
+	preset := testutils.CreateTestDeckPreset(card.UserID)
+
+	saved, mockRepo := reviewCardWithPreset(t, card, preset, 1)
+
+	assert.Equal(t, 3, saved.Lapses)
+	assert.False(t, saved.Leech)
+	mockRepo.AssertNotCalled(t, "SetSuspended", mock.Anything, mock.Anything)
+}
+
+func TestFlashcardService_ReviewFlashcard_LearningFailureIsNotALapse(t *testing.T) {
+	card := reviewCard(2)
+	card.State = models.CardStateLearning
+	preset := testutils.CreateTestDeckPreset(card.UserID)
+
+	saved, _ := reviewCardWithPreset(t, card, preset, 0)
+
+	assert.Equal(t, 2, saved.Lapses)
+}
+
+func TestFlashcardService_ReviewFlashcard_LeechTagged(t *testing.T) {
+	card := reviewCard(3)
+	preset := testutils.CreateTestDeckPreset(card.UserID)
+	preset.LeechThreshold = 4
+
+	saved, mockRepo := reviewCardWithPreset(t, card, preset, 2)
+
+	assert.Equal(t, 4, saved.Lapses)
+	assert.True(t, saved.Leech)
+	mockRepo.AssertNotCalled(t, "SetSuspended", mock.Anything, mock.Anything)
+}
+
+func TestFlashcardService_ReviewFlashcard_LeechSuspended(t *testing.T) {
+	card := reviewCard(7)
+	preset := testutils.CreateTestDeckPreset(card.UserID)
+	preset.LeechAction = models.LeechActionSuspend
+
+	saved, mockRepo := reviewCardWithPreset(t, card, preset, 0)
+
+	assert.True(t, saved.Leech)
+	mockRepo.AssertCalled(t, "SetSuspended", []uuid.UUID{card.ID}, true)
+}
+
+func TestFlashcardService_ReviewFlashcard_ExistingLeechNotSuspendedAgain(t *testing.T) {
+	card := reviewCard(9)
+	card.Leech = true
+	preset := testutils.CreateTestDeckPreset(card.UserID)
+	preset.LeechAction = models.LeechActionSuspend
+
+	saved, mockRepo := reviewCardWithPreset(t, card, preset, 0)
+
+	assert.Equal(t, 10, saved.Lapses)
+	assert.True(t, saved.Leech)
+	mockRepo.AssertNotCalled(t, "SetSuspended", mock.Anything, mock.Anything)
+}
+
+func TestFlashcardService_UndoLastReviewWithOwnership_LiftsLeechSuspension(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	service := NewFlashcardService(mockRepo, &MockDeckRepository{}, &MockDeckPresetRepository{}, mockReviewLogRepo, &MockUserRepository{}, logger)
+
+	cardID := uuid.New()
+	userID := uuid.New()
+	sessionID := uuid.New()
+	before := models.SchedulingState{Interval: 10, EaseFactor: 2.5, ReviewCount: 5, State: models.CardStateReview, Lapses: 7}
+	lastReview := &models.ReviewLog{
+		ID:          uuid.New(),
+		FlashcardID: cardID,
+		SessionID:   &sessionID,
+		StateBefore: before,
+		StateAfter:  models.SchedulingState{Interval: 1, EaseFactor: 2.3, State: models.CardStateRelearning, Lapses: 8, Leech: true},
+	}
+	restored := &models.Flashcard{ID: cardID, UserID: userID, Lapses: 7, Suspended: true}
+	unsuspended := &models.Flashcard{ID: cardID, UserID: userID, Lapses: 7}
+
+	mockRepo.On("GetByID", cardID).Return(&models.Flashcard{ID: cardID, UserID: userID}, nil)
+	mockReviewLogRepo.On("GetLatestByFlashcard", cardID).Return(lastReview, nil)
+	mockReviewLogRepo.On("CountUndoneBySession", sessionID).Return(0, nil)
+	mockRepo.On("UpdateSchedulingState", cardID, before).Return(restored, nil)
+	mockRepo.On("SetSuspended", []uuid.UUID{cardID}, false).Return([]*models.Flashcard{unsuspended}, nil)
+	mockReviewLogRepo.On("MarkUndone", lastReview.ID).Return(nil)
+
+	result, err := service.UndoLastReviewWithOwnership(cardID, userID, sessionID)
+
+	require.NoError(t, err)
+	assert.False(t, result.Suspended)
+	assert.Equal(t, 7, result.Lapses)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFlashcardService_GetLeeches(t *testing.T) {
+	service, mockRepo, _, _ := newTestFlashcardService()
+
+	userID := uuid.New()
+	leeches := []*models.Flashcard{reviewCard(9), reviewCard(8)}
+	mockRepo.On("GetLeeches", userID).Return(leeches, nil)
+
+	result, err := service.GetLeeches(userID)
+
+	require.NoError(t, err)
+	assert.Len(t, result, 2)
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register GIF decoding for image dimensions
+	_ "image/jpeg" // Register JPEG decoding for image dimensions
+	_ "image/png"  // Register PNG decoding for image dimensions
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/repositories"
+	"swipelearn-api/internal/storage"
+	"swipelearn-api/internal/utils"
+)
+
+// MediaService stores the images and audio files users attach to cards. Files are
+// stored once per content however many users upload them. Media no card references
+// is removed by a garbage collector running in the background once it is older than
+// the grace period, which leaves time to reference a fresh upload.
+type MediaService struct {
+	mediaRepo  repositories.MediaRepositoryInterface
+	store      storage.Store
+	maxSize    map[string]int64 // Largest accepted upload in bytes, by media kind
+	quota      int64            // Bytes of media each user can keep
+	gcInterval time.Duration    // Time between garbage collections, 0 to never collect
+	gcGrace    time.Duration    // Age unreferenced media must reach before it is collected
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	Logger     *logrus.Logger
+}
+
+func NewMediaService(
+	mediaRepo repositories.MediaRepositoryInterface,
+	store storage.Store,
+	logger *logrus.Logger,
+) *MediaService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &MediaService{
+		mediaRepo: mediaRepo,
+		store:     store,
+		maxSize: map[string]int64{
+			mediaKindImage: int64(max(1, utils.GetEnvAsInt("MEDIA_MAX_IMAGE_BYTES", 10<<20))),
+			mediaKindAudio: int64(max(1, utils.GetEnvAsInt("MEDIA_MAX_AUDIO_BYTES", 20<<20))),
+		},
+		quota:      int64(max(1, utils.GetEnvAsInt("MEDIA_QUOTA_BYTES", 500<<20))),
+		gcInterval: utils.GetEnvAsDuration("MEDIA_GC_INTERVAL", time.Hour),
+		gcGrace:    utils.GetEnvAsDuration("MEDIA_GC_GRACE", 24*time.Hour),
+		ctx:        ctx,
+		cancel:     cancel,
+		Logger:     logger,
+	}
+}
+
+// MaxUploadSize returns the largest upload accepted for any kind of media, in bytes
+func (s *MediaService) MaxUploadSize() int64 {
+	return max(s.maxSize[mediaKindImage], s.maxSize[mediaKindAudio])
+}
+
+// Upload stores an image or audio file for a user under its content hash and reports
+// whether it was new. Uploading content the user already uploaded returns the
+// existing media without counting against the quota again.
+func (s *MediaService) Upload(userID uuid.UUID, data []byte) (*models.Media, bool, error) {
+	if len(data) == 0 {
+		return nil, false, fmt.Errorf("invalid media: file is empty")
+	}
+
+	contentType := sniffMediaType(data)
+	kind, ok := mediaKinds[contentType]
+	if !ok {
+		return nil, false, fmt.Errorf("unsupported media type %q", contentType)
+	}
+	if limit := s.maxSize[kind]; int64(len(data)) > limit {
+		return nil, false, fmt.Errorf("media too large: %s files are limited to %d bytes", kind, limit)
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	if existing, err := s.mediaRepo.GetByUserAndHash(userID, hash); err == nil {
+		return existing, false, nil
+	} else if err.Error() != "media not found" {
+		return nil, false, fmt.Errorf("failed to check media: %w", err)
+	}
+
+	// Checked again when the media is created, as other uploads may be under way
+	used, err := s.mediaRepo.SumSizeByUser(userID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to check media quota: %w", err)
+	}
+	if used+int64(len(data)) > s.quota {
+		return nil, false, fmt.Errorf("media quota exceeded: %d of %d bytes used", used, s.quota)
+	}
+
+	media := &models.Media{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Hash:        hash,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}
+	if kind == mediaKindImage {
+		if config, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+			media.Width, media.Height = &config.Width, &config.Height
+		}
+	}
+
+	savedMedia, err := s.storeMedia(media, data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"media_id":     savedMedia.ID,
+		"user_id":      userID,
+		"hash":         hash,
+		"content_type": contentType,
+		"size":         savedMedia.Size,
+	}).Info("Media uploaded successfully")
+
+	return savedMedia, true, nil
+}
+
+// storeMedia records the media and stores its file, which the garbage collector of no
+// server can delete until the media is recorded
+func (s *MediaService) storeMedia(media *models.Media, data []byte) (*models.Media, error) {
+	savedMedia, err := s.mediaRepo.Create(media, s.quota, func() error {
+		return s.store.Put(media.Hash, data, media.ContentType)
+	})
+	if err != nil {
+		if err.Error() == "media quota exceeded" {
+			return nil, fmt.Errorf("media quota exceeded: %d bytes allowed", s.quota)
+		}
+		s.Logger.WithError(err).WithField("user_id", media.UserID).Error("Service failed to create media")
+		return nil, fmt.Errorf("failed to create media: %w", err)
+	}
+
+	return savedMedia, nil
+}
+
+// Get retrieves the media a user uploaded with the given content hash. Users can only
+// retrieve media they uploaded themselves.
+func (s *MediaService) Get(userID uuid.UUID, hash string) (*models.Media, error) {
+	if !storage.ValidHash(hash) {
+		return nil, fmt.Errorf("media not found")
+	}
+	return s.mediaRepo.GetByUserAndHash(userID, hash)
+}
+
+// Open returns the content of a media file. The caller closes it.
+func (s *MediaService) Open(media *models.Media) (io.ReadCloser, error) {
+	content, err := s.store.Open(media.Hash)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			s.Logger.WithField("hash", media.Hash).Error("Media file missing from storage")
+		}
+		return nil, fmt.Errorf("failed to open media: %w", err)
+	}
+	return content, nil
+}
+
+// CollectGarbage removes media that no card references and that is older than the
+// grace period, then the stored files no media points at anymore. It returns how
+// much media was removed.
+func (s *MediaService) CollectGarbage() (int, error) {
+	candidates, err := s.mediaRepo.GetUnreferenced(time.Now().Add(-s.gcGrace))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get unreferenced media: %w", err)
+	}
+
+	removed := 0
+	for _, media := range candidates {
+		collected, err := s.collect(media)
+		if err != nil {
+			s.Logger.WithError(err).WithField("media_id", media.ID).Error("Service failed to collect media")
+			continue
+		}
+		if collected {
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		s.Logger.WithField("media_count", removed).Info("Collected unreferenced media")
+	}
+	return removed, nil
+}
+
+// collect removes one unreferenced media, and its file when no other media points at
+// it. Media a card started referencing since it was found is kept.
+func (s *MediaService) collect(media *models.Media) (bool, error) {
+	return s.mediaRepo.DeleteUnreferenced(media, func() error {
+		return s.store.Delete(media.Hash)
+	})
+}
+
+// StartGarbageCollector collects unreferenced media every gc interval until Shutdown
+func (s *MediaService) StartGarbageCollector() {
+	if s.gcInterval <= 0 {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.gcInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.CollectGarbage(); err != nil {
+					s.Logger.WithError(err).Error("Media garbage collection failed")
+				}
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown stops the garbage collector and waits, until ctx is done, for a running
+// collection to finish
+func (s *MediaService) Shutdown(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
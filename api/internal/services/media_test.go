@@ -0,0 +1,262 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/storage"
+	"swipelearn-api/pkg/testutils"
+)
+
+// MockMediaRepository is a mock implementation of MediaRepository for testing
+type MockMediaRepository struct {
+	mock.Mock
+}
+
+// Create calls store when the media would be created
+func (m *MockMediaRepository) Create(media *models.Media, quota int64, store func() error) (*models.Media, error) {
+	args := m.Called(media, quota)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	if err := store(); err != nil {
+		return nil, err
+	}
+	return args.Get(0).(*models.Media), args.Error(1)
+}
+
+func (m *MockMediaRepository) GetByUserAndHash(userID uuid.UUID, hash string) (*models.Media, error) {
+	args := m.Called(userID, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Media), args.Error(1)
+}
+
+func (m *MockMediaRepository) SumSizeByUser(userID uuid.UUID) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMediaRepository) GetUnreferenced(before time.Time) ([]*models.Media, error) {
+	args := m.Called(before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Media), args.Error(1)
+}
+
+// DeleteUnreferenced returns whether the media was deleted and calls removeFile when it
+// was the last media of its hash
+func (m *MockMediaRepository) DeleteUnreferenced(media *models.Media, removeFile func() error) (bool, error) {
+	args := m.Called(media.ID)
+	if args.Bool(0) && args.Bool(1) {
+		if err := removeFile(); err != nil {
+			return false, err
+		}
+	}
+	return args.Bool(0), args.Error(2)
+}
+
+func newTestMediaService(t *testing.T) (*MediaService, *MockMediaRepository) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	mediaRepo := &MockMediaRepository{}
+	return NewMediaService(mediaRepo, store, testutils.TestLogger()), mediaRepo
+}
+
+// testPNG encodes a blank PNG image of the given size
+func testPNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestMediaService_Upload_StoresImage(t *testing.T) {
+	service, mediaRepo := newTestMediaService(t)
+	userID := uuid.New()
+	data := testPNG(t, 64, 48)
+
+	mediaRepo.On("GetByUserAndHash", userID, mock.Anything).Return(nil, fmt.Errorf("media not found"))
+	mediaRepo.On("SumSizeByUser", userID).Return(int64(0), nil)
+	var media *models.Media
+	mediaRepo.On("Create", mock.Anything, mock.Anything).Return(&models.Media{}, nil).Run(func(args mock.Arguments) {
+		media = args.Get(0).(*models.Media)
+	})
+
+	_, created, err := service.Upload(userID, data)
+
+	require.NoError(t, err)
+	assert.True(t, created)
+	require.NotNil(t, media)
+	assert.Equal(t, userID, media.UserID)
+	assert.Equal(t, "image/png", media.ContentType)
+	assert.Equal(t, int64(len(data)), media.Size)
+	assert.True(t, storage.ValidHash(media.Hash))
+	require.NotNil(t, media.Width)
+	assert.Equal(t, 64, *media.Width)
+	assert.Equal(t, 48, *media.Height)
+
+	// The stored file can be read back through the media
+	content, err := service.Open(media)
+	require.NoError(t, err)
+	stored, err := io.ReadAll(content)
+	require.NoError(t, content.Close())
+	require.NoError(t, err)
+	assert.Equal(t, data, stored)
+}
+
+func TestMediaService_Upload_ReturnsExistingMedia(t *testing.T) {
+	service, mediaRepo := newTestMediaService(t)
+	userID := uuid.New()
+	existing := &models.Media{ID: uuid.New(), UserID: userID}
+
+	mediaRepo.On("GetByUserAndHash", userID, mock.Anything).Return(existing, nil)
+
+	media, created, err := service.Upload(userID, testPNG(t, 8, 8))
+
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, existing, media)
+	mediaRepo.AssertNotCalled(t, "SumSizeByUser", mock.Anything)
+	mediaRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestMediaService_Upload_Rejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "empty file", data: nil, want: "invalid media: file is empty"},
+		{name: "not an image", data: []byte("<svg onload=alert(1)></svg>"), want: `unsupported media type "text/plain; charset=utf-8"`},
+		{name: "unknown binary", data: make([]byte, 1024), want: `unsupported media type "application/octet-stream"`},
+		{name: "image too large", data: append(testPNG(t, 8, 8), make([]byte, 10<<20)...), want: "media too large: image files are limited to 10485760 bytes"},
+		{name: "audio too large", data: append([]byte("fLaC"), make([]byte, 20<<20)...), want: "media too large: audio files are limited to 20971520 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mediaRepo := newTestMediaService(t)
+
+			media, _, err := service.Upload(uuid.New(), tt.data)
+
+			assert.Nil(t, media)
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+			mediaRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMediaService_Upload_EnforcesQuota(t *testing.T) {
+	t.Setenv("MEDIA_QUOTA_BYTES", "1000")
+	service, mediaRepo := newTestMediaService(t)
+	userID := uuid.New()
+	data := testPNG(t, 8, 8)
+
+	mediaRepo.On("GetByUserAndHash", userID, mock.Anything).Return(nil, fmt.Errorf("media not found"))
+	mediaRepo.On("SumSizeByUser", userID).Return(int64(990), nil)
+
+	media, created, err := service.Upload(userID, data)
+
+	assert.Nil(t, media)
+	assert.False(t, created)
+	require.Error(t, err)
+	assert.Equal(t, "media quota exceeded: 990 of 1000 bytes used", err.Error())
+	mediaRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestMediaService_Upload_EnforcesQuotaOnCreate(t *testing.T) {
+	t.Setenv("MEDIA_QUOTA_BYTES", "1000")
+	service, mediaRepo := newTestMediaService(t)
+	userID := uuid.New()
+	data := testPNG(t, 8, 8)
+
+	// Another upload of the user was recorded after the quota was checked
+	mediaRepo.On("GetByUserAndHash", userID, mock.Anything).Return(nil, fmt.Errorf("media not found"))
+	mediaRepo.On("SumSizeByUser", userID).Return(int64(0), nil)
+	mediaRepo.On("Create", mock.Anything, int64(1000)).Return(nil, fmt.Errorf("media quota exceeded"))
+
+	media, created, err := service.Upload(userID, data)
+
+	assert.Nil(t, media)
+	assert.False(t, created)
+	require.Error(t, err)
+	assert.Equal(t, "media quota exceeded: 1000 bytes allowed", err.Error())
+}
+
+func TestMediaService_CollectGarbage(t *testing.T) {
+	service, mediaRepo := newTestMediaService(t)
+	shared := &models.Media{ID: uuid.New(), Hash: fmt.Sprintf("%064x", 1)}
+	sole := &models.Media{ID: uuid.New(), Hash: fmt.Sprintf("%064x", 2)}
+	linked := &models.Media{ID: uuid.New(), Hash: fmt.Sprintf("%064x", 3)}
+	for _, media := range []*models.Media{shared, sole, linked} {
+		require.NoError(t, service.store.Put(media.Hash, []byte(media.Hash), "audio/mpeg"))
+	}
+
+	mediaRepo.On("GetUnreferenced", mock.Anything).Return([]*models.Media{shared, sole, linked}, nil)
+	// Another user still has media with this content
+	mediaRepo.On("DeleteUnreferenced", shared.ID).Return(true, false, nil)
+	mediaRepo.On("DeleteUnreferenced", sole.ID).Return(true, true, nil)
+	// A card started referencing this media after it was found
+	mediaRepo.On("DeleteUnreferenced", linked.ID).Return(false, false, nil)
+
+	removed, err := service.CollectGarbage()
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	for _, media := range []*models.Media{shared, linked} {
+		content, err := service.store.Open(media.Hash)
+		require.NoError(t, err)
+		require.NoError(t, content.Close())
+	}
+	_, err = service.store.Open(sole.Hash)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestMediaService_CollectGarbage_KeepsGracePeriod(t *testing.T) {
+	t.Setenv("MEDIA_GC_GRACE", "2h")
+	service, mediaRepo := newTestMediaService(t)
+
+	mediaRepo.On("GetUnreferenced", mock.MatchedBy(func(before time.Time) bool {
+		age := time.Since(before)
+		return age >= 2*time.Hour && age < 2*time.Hour+time.Minute
+	})).Return([]*models.Media{}, nil)
+
+	removed, err := service.CollectGarbage()
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, removed)
+	mediaRepo.AssertExpectations(t)
+}
+
+func TestMediaReferences(t *testing.T) {
+	first, second := fmt.Sprintf("%064x", 1), fmt.Sprintf("%064x", 2)
+	text := `<img src="media:` + first + `"> [sound:media:` + second + `] media:` + first + ` media:ABC`
+
+	assert.Equal(t, []string{first, second}, models.MediaReferences(text))
+	assert.Empty(t, models.MediaReferences("no media here"))
+}
+
+func TestMediaService_Get_RejectsInvalidHash(t *testing.T) {
+	service, mediaRepo := newTestMediaService(t)
+
+	_, err := service.Get(uuid.New(), "../secret")
+
+	require.Error(t, err)
+	assert.Equal(t, "media not found", err.Error())
+	mediaRepo.AssertNotCalled(t, "GetByUserAndHash", mock.Anything, mock.Anything)
+}
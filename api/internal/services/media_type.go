@@ -0,0 +1,54 @@
+package services
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// Kinds of media, each with its own upload size limit
+const (
+	mediaKindImage = "image"
+	mediaKindAudio = "audio"
+)
+
+// mediaKinds lists the content types accepted for upload, with their kind
+var mediaKinds = map[string]string{
+	"image/png":  mediaKindImage,
+	"image/jpeg": mediaKindImage,
+	"image/gif":  mediaKindImage,
+	"image/webp": mediaKindImage,
+	"audio/mpeg": mediaKindAudio,
+	"audio/ogg":  mediaKindAudio,
+	"audio/wav":  mediaKindAudio,
+	"audio/mp4":  mediaKindAudio,
+	"audio/flac": mediaKindAudio,
+}
+
+// sniffMediaType determines the content type of a file from its first bytes. It
+// refines http.DetectContentType for audio formats it does not tell apart: MP3 without
+// an ID3 tag, FLAC, Ogg audio and M4A.
+func sniffMediaType(data []byte) string {
+	contentType := http.DetectContentType(data)
+	head := data[:min(len(data), 512)]
+
+	switch {
+	case contentType == "audio/wave":
+		return "audio/wav"
+	case contentType == "application/ogg" && (bytes.Contains(head, []byte("OpusHead")) || bytes.Contains(head, []byte("\x01vorbis"))):
+		return "audio/ogg"
+	case len(data) >= 12 && string(data[4:8]) == "ftyp" && (string(data[8:12]) == "M4A " || string(data[8:12]) == "M4B "):
+		return "audio/mp4"
+	case bytes.HasPrefix(data, []byte("fLaC")):
+		return "audio/flac"
+	case contentType == "application/octet-stream" && isMP3Frame(data):
+		return "audio/mpeg"
+	}
+	return contentType
+}
+
+// isMP3Frame reports whether data starts with the header of an MPEG Layer III frame:
+// eleven set sync bits followed by a valid version and layer III
+func isMP3Frame(data []byte) bool {
+	return len(data) >= 4 && data[0] == 0xFF && data[1]&0xE0 == 0xE0 &&
+		data[1]&0x18 != 0x08 && data[1]&0x06 == 0x02
+}
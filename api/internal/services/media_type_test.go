@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSniffMediaType(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "png", data: testPNG(t, 4, 4), want: "image/png"},
+		{name: "mp3 with id3 tag", data: []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), want: "audio/mpeg"},
+		{name: "mp3 frame", data: []byte{0xFF, 0xFB, 0x90, 0x64, 0x00}, want: "audio/mpeg"},
+		{name: "wav", data: []byte("RIFF\x24\x00\x00\x00WAVEfmt "), want: "audio/wav"},
+		{name: "ogg vorbis", data: []byte("OggS\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x01vorbis"), want: "audio/ogg"},
+		{name: "ogg opus", data: []byte("OggS\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00OpusHead"), want: "audio/ogg"},
+		{name: "m4a", data: []byte("\x00\x00\x00\x18ftypM4A \x00\x00\x00\x00M4A mp42"), want: "audio/mp4"},
+		{name: "mp4 video", data: []byte("\x00\x00\x00\x10ftypmp42\x00\x00\x00\x00"), want: "video/mp4"},
+		{name: "flac", data: []byte("fLaC\x00\x00\x00\x22"), want: "audio/flac"},
+		{name: "not an mp3 frame", data: []byte{0xFF, 0xFF, 0x00, 0x00, 0x00}, want: "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sniffMediaType(tt.data))
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+)
+
+// defaultDistractors is the number of wrong choices a multiple-choice question offers
+// unless asked otherwise
+const defaultDistractors = 3
+
+// Qualities a multiple-choice answer is graded with. Recognizing an answer is easier
+// than recalling it, so a right choice never earns a perfect grade, and a wrong one is
+// not a blackout since the answer was in sight.
+const (
+	multipleChoiceCorrectQuality = 4
+	multipleChoiceWrongQuality   = 1
+)
+
+// Distractors are ranked by how far their answers are from the card's: the log ratio
+// of their lengths, plus a penalty for each way they differ in type
+const (
+	answerKindPenalty    = 1.0 // One is a number, a single word or a phrase and the other is not
+	answerFormatPenalty  = 0.5 // The cards use different content formats
+	distractorPoolFactor = 2   // Distractors are drawn from this many times as many best ranked answers, so questions vary
+)
+
+// Kinds of answers, which distractors should match to be plausible
+const (
+	answerKindNumber = "number"
+	answerKindWord   = "word"
+	answerKindPhrase = "phrase"
+)
+
+// MultipleChoiceWithOwnership builds a multiple-choice question for a flashcard, after
+// checking that the card belongs to the user. The card's back is shuffled among the
+// backs of other cards of its deck that are most like it.
+func (s *FlashcardService) MultipleChoiceWithOwnership(id uuid.UUID, userID uuid.UUID, req *models.MultipleChoiceRequest) (*models.MultipleChoiceQuestion, error) {
+	card, err := s.flashcardRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("flashcard not found: %w", err)
+	}
+
+	if card.UserID != userID {
+		s.Logger.WithFields(logrus.Fields{
+			"flashcard_id": id,
+			"user_id":      userID,
+			"owner_id":     card.UserID,
+		}).Warn("Unauthorized attempt to build multiple choice question for flashcard")
+		return nil, fmt.Errorf("unauthorized: flashcard does not belong to user")
+	}
+
+	if choiceText(card) == "" {
+		return nil, fmt.Errorf("not enough answers for multiple choice: flashcard back has no text")
+	}
+
+	deckCards, err := s.flashcardRepo.GetByDeck(card.HomeDeck())
+	if err != nil {
+		return nil, err
+	}
+
+	count := req.Distractors
+	if count == 0 {
+		count = defaultDistractors
+	}
+	distractors := pickDistractors(card, deckCards, count)
+	if len(distractors) == 0 {
+		return nil, fmt.Errorf("not enough answers for multiple choice: deck has no other cards with different backs")
+	}
+
+	choices := []models.Choice{{Text: choiceText(card), HTML: card.BackHTML}}
+	for _, distractor := range distractors {
+		choices = append(choices, models.Choice{Text: choiceText(distractor), HTML: distractor.BackHTML})
+	}
+	rand.Shuffle(len(choices), func(i, j int) { choices[i], choices[j] = choices[j], choices[i] })
+
+	return &models.MultipleChoiceQuestion{
+		FlashcardID: card.ID,
+		Front:       card.Front,
+		FrontHTML:   card.FrontHTML,
+		Choices:     choices,
+	}, nil
+}
+
+// GradeChoiceWithOwnership grades the choice made in a multiple-choice question for a
+// flashcard, after checking that the card belongs to the user. Nothing is saved: the
+// suggested quality is meant to be sent as a review.
+func (s *FlashcardService) GradeChoiceWithOwnership(id uuid.UUID, userID uuid.UUID, req *models.GradeChoiceRequest) (*models.ChoiceGrade, error) {
+	card, err := s.flashcardRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("flashcard not found: %w", err)
+	}
+
+	if card.UserID != userID {
+		s.Logger.WithFields(logrus.Fields{
+			"flashcard_id": id,
+			"user_id":      userID,
+			"owner_id":     card.UserID,
+		}).Warn("Unauthorized attempt to grade multiple choice answer to flashcard")
+		return nil, fmt.Errorf("unauthorized: flashcard does not belong to user")
+	}
+
+	grade := &models.ChoiceGrade{
+		Choice:           normalizeSpace(req.Choice),
+		Expected:         choiceText(card),
+		SuggestedQuality: multipleChoiceWrongQuality,
+	}
+	if grade.Choice == grade.Expected {
+		grade.Correct = true
+		grade.SuggestedQuality = multipleChoiceCorrectQuality
+	}
+
+	return grade, nil
+}
+
+// choiceText returns the text of a card's back as it is offered as a choice
+func choiceText(card *models.Flashcard) string {
+	return normalizeSpace(expectedAnswer(card))
+}
+
+// pickDistractors picks up to count cards of a deck whose backs can pass for the back of
+// card. Other cards of the same note and backs that read the same as one already picked
+// are skipped. The best ranked candidates are preferred, chosen at random among
+// themselves.
+func pickDistractors(card *models.Flashcard, deckCards []*models.Flashcard, count int) []*models.Flashcard {
+	type candidate struct {
+		card  *models.Flashcard
+		score float64
+	}
+
+	answer := choiceText(card)
+	seen := map[string]bool{strings.ToLower(answer): true}
+	var candidates []candidate
+	for _, other := range deckCards {
+		if other.ID == card.ID || card.NoteID != nil && other.NoteID != nil && *other.NoteID == *card.NoteID {
+			continue
+		}
+		text := choiceText(other)
+		if text == "" || seen[strings.ToLower(text)] {
+			continue
+		}
+		seen[strings.ToLower(text)] = true
+
+		score := distractorScore(answer, text)
+		if other.Format != card.Format {
+			score += answerFormatPenalty
+		}
+		candidates = append(candidates, candidate{card: other, score: score})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score < candidates[j].score })
+	pool := candidates[:min(len(candidates), count*distractorPoolFactor)]
+	rand.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+
+	distractors := make([]*models.Flashcard, 0, count)
+	for _, c := range pool[:min(len(pool), count)] {
+		distractors = append(distractors, c.card)
+	}
+	return distractors
+}
+
+// distractorScore measures how unlike an answer a distractor's text is, 0 being alike.
+// Both must be non-empty.
+func distractorScore(answer, text string) float64 {
+	ratio := float64(utf8.RuneCountInString(text)) / float64(utf8.RuneCountInString(answer))
+	score := math.Abs(math.Log(ratio))
+	if answerKind(answer) != answerKind(text) {
+		score += answerKindPenalty
+	}
+	return score
+}
+
+// answerKind tells numbers such as 1,200 or 1914-1918 from single words and phrases
+func answerKind(text string) string {
+	switch {
+	case strings.ContainsFunc(text, unicode.IsDigit) && !strings.ContainsFunc(text, unicode.IsLetter):
+		return answerKindNumber
+	case strings.ContainsFunc(text, unicode.IsSpace):
+		return answerKindPhrase
+	default:
+		return answerKindWord
+	}
+}
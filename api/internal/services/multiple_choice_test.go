@@ -0,0 +1,168 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+func TestAnswerKind(t *testing.T) {
+	assert.Equal(t, answerKindNumber, answerKind("1,200"))
+	assert.Equal(t, answerKindNumber, answerKind("1914-1918"))
+	assert.Equal(t, answerKindWord, answerKind("Paris"))
+	assert.Equal(t, answerKindPhrase, answerKind("10 Downing Street"))
+}
+
+func TestPickDistractors(t *testing.T) {
+	userID, deckID := uuid.New(), uuid.New()
+	newCard := func(back string) *models.Flashcard {
+		card := testutils.CreateTestFlashcard(userID, deckID)
+		card.Format = models.ContentFormatPlain
+		card.Back = back
+		return card
+	}
+
+	card := newCard("Paris")
+	sibling := newCard("Lyon")
+	noteID := uuid.New()
+	card.NoteID, sibling.NoteID = &noteID, &noteID
+	deckCards := []*models.Flashcard{
+		card,
+		sibling,
+		newCard("Rome"),
+		newCard("Berlin"),
+		newCard("Madrid"),
+		newCard("Vienna"),
+		newCard(" paris "),
+		newCard("Rome"),
+		newCard("1871"),
+		newCard("The city on the Seine with the Eiffel Tower"),
+	}
+
+	distractors := pickDistractors(card, deckCards, 2)
+
+	// Siblings, the answer itself and repeated answers are skipped; the number and the
+	// long phrase are ranked below the other words
+	require.Len(t, distractors, 2)
+	assert.NotEqual(t, distractors[0].Back, distractors[1].Back)
+	for _, distractor := range distractors {
+		assert.Contains(t, []string{"Rome", "Berlin", "Madrid", "Vienna"}, distractor.Back)
+	}
+
+	// Fewer distractors are picked when the deck runs out of answers
+	assert.Len(t, pickDistractors(card, deckCards, 9), 6)
+}
+
+func TestFlashcardService_MultipleChoiceWithOwnership(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	homeDeckID := uuid.New()
+	var deckCards []*models.Flashcard
+	for _, back := range []string{"Paris", "Rome", "Berlin", "Madrid", "Vienna"} {
+		card := testutils.CreateTestFlashcard(userID, homeDeckID)
+		card.Format = models.ContentFormatPlain
+		card.Back = back
+		card.BackHTML = back
+		deckCards = append(deckCards, card)
+	}
+	// The card is in a filtered deck; its distractors come from its home deck
+	card := deckCards[0]
+	card.DeckID = uuid.New()
+	card.HomeDeckID = &homeDeckID
+
+	mockRepo.On("GetByID", card.ID).Return(card, nil)
+	mockRepo.On("GetByDeck", homeDeckID).Return(deckCards, nil)
+
+	question, err := service.MultipleChoiceWithOwnership(card.ID, userID, &models.MultipleChoiceRequest{})
+
+	require.NoError(t, err)
+	assert.Equal(t, card.ID, question.FlashcardID)
+	assert.Equal(t, card.Front, question.Front)
+	require.Len(t, question.Choices, defaultDistractors+1)
+	assert.Contains(t, question.Choices, models.Choice{Text: "Paris", HTML: "Paris"})
+
+	question, err = service.MultipleChoiceWithOwnership(card.ID, userID, &models.MultipleChoiceRequest{Distractors: 1})
+	require.NoError(t, err)
+	assert.Len(t, question.Choices, 2)
+}
+
+func TestFlashcardService_MultipleChoiceWithOwnership_NotEnoughAnswers(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	card := testutils.CreateTestFlashcard(userID, uuid.New())
+	card.Format = models.ContentFormatPlain
+	same := testutils.CreateTestFlashcard(userID, card.DeckID)
+	same.Format = models.ContentFormatPlain
+
+	mockRepo.On("GetByID", card.ID).Return(card, nil)
+	mockRepo.On("GetByDeck", card.DeckID).Return([]*models.Flashcard{card, same}, nil)
+
+	question, err := service.MultipleChoiceWithOwnership(card.ID, userID, &models.MultipleChoiceRequest{})
+
+	assert.Nil(t, question)
+	assert.EqualError(t, err, "not enough answers for multiple choice: deck has no other cards with different backs")
+}
+
+func TestFlashcardService_GradeChoiceWithOwnership(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	card := testutils.CreateTestFlashcard(userID, uuid.New())
+	card.Format = models.ContentFormatPlain
+	card.Back = "Paris,\n France"
+	mockRepo.On("GetByID", card.ID).Return(card, nil)
+
+	grade, err := service.GradeChoiceWithOwnership(card.ID, userID, &models.GradeChoiceRequest{Choice: "Paris, France"})
+	require.NoError(t, err)
+	assert.True(t, grade.Correct)
+	assert.Equal(t, multipleChoiceCorrectQuality, grade.SuggestedQuality)
+
+	grade, err = service.GradeChoiceWithOwnership(card.ID, userID, &models.GradeChoiceRequest{Choice: "Rome, Italy"})
+	require.NoError(t, err)
+	assert.False(t, grade.Correct)
+	assert.Equal(t, "Paris, France", grade.Expected)
+	assert.Equal(t, multipleChoiceWrongQuality, grade.SuggestedQuality)
+}
+
+func TestFlashcardService_GradeChoiceWithOwnership_Unauthorized(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	card := testutils.CreateTestFlashcard(uuid.New(), uuid.New())
+	mockRepo.On("GetByID", card.ID).Return(card, nil)
+
+	grade, err := service.GradeChoiceWithOwnership(card.ID, uuid.New(), &models.GradeChoiceRequest{Choice: "Test Back"})
+
+	assert.Nil(t, grade)
+	assert.EqualError(t, err, "unauthorized: flashcard does not belong to user")
+}
@@ -0,0 +1,417 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/repositories"
+)
+
+// maxNoteFieldLength bounds the characters of a note field, which every card of the
+// note renders
+const maxNoteFieldLength = 10000
+
+type NoteService struct {
+	noteRepo         repositories.NoteRepositoryInterface
+	flashcardRepo    repositories.FlashcardRepositoryInterface
+	deckRepo         repositories.DeckRepositoryInterface
+	mediaRepo        repositories.MediaRepositoryInterface
+	noteTypeService  *NoteTypeService
+	flashcardService *FlashcardService
+	Logger           *logrus.Logger
+}
+
+func NewNoteService(
+	noteRepo repositories.NoteRepositoryInterface,
+	flashcardRepo repositories.FlashcardRepositoryInterface,
+	deckRepo repositories.DeckRepositoryInterface,
+	mediaRepo repositories.MediaRepositoryInterface,
+	noteTypeService *NoteTypeService,
+	flashcardService *FlashcardService,
+	logger *logrus.Logger,
+) *NoteService {
+	return &NoteService{
+		noteRepo:         noteRepo,
+		flashcardRepo:    flashcardRepo,
+		deckRepo:         deckRepo,
+		mediaRepo:        mediaRepo,
+		noteTypeService:  noteTypeService,
+		flashcardService: flashcardService,
+		Logger:           logger,
+	}
+}
+
+// Create creates a note and its cards: one for every template of a standard note type
+// that the note's fields fill in, one for every deletion index of a cloze note, or one
+// for every occlusion index of an image occlusion note
+func (s *NoteService) Create(req *models.CreateNoteRequest, userID uuid.UUID) (*models.Note, error) {
+	deck, err := s.deckRepo.GetByID(req.DeckID)
+	if err != nil {
+		return nil, fmt.Errorf("deck not found")
+	}
+	if deck.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: deck does not belong to user")
+	}
+
+	noteType, err := s.noteType(req.NoteTypeID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkNoteFields(noteType, req.Fields); err != nil {
+		return nil, err
+	}
+
+	note := &models.Note{
+		ID:         uuid.New(),
+		UserID:     userID,
+		DeckID:     deck.ID,
+		NoteTypeID: noteType.ID,
+		Fields:     mergeNoteFields(noteType, nil, req.Fields),
+		Occlusions: req.Occlusions,
+	}
+
+	images, err := s.noteImages(noteType, note.Fields, note.Occlusions, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := noteCardSpecs(noteType, note.Fields, note.Occlusions)
+	if noteType.Kind == models.NoteTypeKindCloze && len(specs) > maxClozeIndices {
+		return nil, fmt.Errorf("invalid note: a cloze note can have at most %d deletion indices", maxClozeIndices)
+	}
+	if len(specs) == 0 {
+		if noteType.Kind == models.NoteTypeKindCloze {
+			return nil, fmt.Errorf("invalid note: a cloze note needs at least one {{c1::...}} deletion")
+		}
+		return nil, fmt.Errorf("invalid note: no card would be generated from these fields")
+	}
+
+	cards := make([]*models.Flashcard, 0, len(specs))
+	for _, spec := range specs {
+		card, err := s.noteCard(deck, note, spec, images)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+
+	savedNote, err := s.noteRepo.Create(note, cards)
+	if err != nil {
+		s.Logger.WithError(err).Error("Service failed to create note")
+		return nil, fmt.Errorf("failed to create note: %w", err)
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"note_id":      savedNote.ID,
+		"note_type_id": noteType.ID,
+		"deck_id":      deck.ID,
+		"card_count":   len(savedNote.Cards),
+	}).Info("Note created successfully")
+
+	return savedNote, nil
+}
+
+// GetByIDWithOwnership retrieves a note and its cards with user ownership validation
+func (s *NoteService) GetByIDWithOwnership(id uuid.UUID, userID uuid.UUID) (*models.Note, error) {
+	note, err := s.ownedNote(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	note.Cards, err = s.flashcardRepo.GetByNote(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get note cards: %w", err)
+	}
+
+	return note, nil
+}
+
+// UpdateWithOwnership updates the fields and occlusions of a note with user ownership
+// validation and renders every sibling card again. Cards keep their scheduling and
+// review history. Templates, cloze deletions or occlusion indices the update adds get a
+// new card. Cards of a standard note whose front is no longer filled in are kept; cards
+// of a cloze deletion or occlusion index that was removed are deleted.
+func (s *NoteService) UpdateWithOwnership(id uuid.UUID, userID uuid.UUID, req *models.UpdateNoteRequest) (*models.Note, error) {
+	note, err := s.GetByIDWithOwnership(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	noteType, err := s.noteType(note.NoteTypeID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkNoteFields(noteType, req.Fields); err != nil {
+		return nil, err
+	}
+
+	fields := mergeNoteFields(noteType, note.Fields, req.Fields)
+	occlusions := note.Occlusions
+	if req.Occlusions != nil {
+		occlusions = req.Occlusions
+	}
+
+	images, err := s.noteImages(noteType, fields, occlusions, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := noteCardSpecs(noteType, fields, occlusions)
+	if noteType.Kind == models.NoteTypeKindCloze && len(specs) > maxClozeIndices {
+		return nil, fmt.Errorf("invalid note: a cloze note can have at most %d deletion indices", maxClozeIndices)
+	}
+	if len(specs) == 0 && noteType.Kind == models.NoteTypeKindCloze {
+		return nil, fmt.Errorf("invalid note: a cloze note needs at least one {{c1::...}} deletion")
+	}
+
+	byOrd := make(map[int]*models.Flashcard, len(note.Cards))
+	for _, card := range note.Cards {
+		if card.Ord != nil {
+			byOrd[*card.Ord] = card
+		}
+	}
+
+	// Cards of a standard note are kept, and rendered again, once their front is empty
+	if noteType.Kind == models.NoteTypeKindStandard {
+		generated := make(map[int]bool, len(specs))
+		for _, spec := range specs {
+			generated[spec.ord] = true
+		}
+		for ord, template := range noteType.Templates {
+			if _, exists := byOrd[ord]; exists && !generated[ord] {
+				specs = append(specs, noteCardSpec{ord: ord, template: template})
+			}
+		}
+		sort.Slice(specs, func(i, j int) bool { return specs[i].ord < specs[j].ord })
+	}
+
+	content := *note
+	content.Fields = fields
+	content.Occlusions = occlusions
+
+	var changes models.NoteCardChanges
+	var deck *models.Deck
+	for _, spec := range specs {
+		card, exists := byOrd[spec.ord]
+		delete(byOrd, spec.ord)
+
+		if !exists {
+			if deck == nil {
+				if deck, err = s.deckRepo.GetByID(note.DeckID); err != nil {
+					return nil, fmt.Errorf("deck not found: %w", err)
+				}
+			}
+			if card, err = s.noteCard(deck, &content, spec, images); err != nil {
+				return nil, err
+			}
+			changes.Create = append(changes.Create, card)
+		} else {
+			front, back := renderCard(spec.template, noteCardContent(&content, spec, images))
+			if front != card.Front || back != card.Back {
+				rendered := *card
+				rendered.Front, rendered.Back = front, back
+				changes.Update = append(changes.Update, &rendered)
+			}
+		}
+	}
+
+	// What is left are cards of cloze deletions or occlusions the note no longer has
+	for _, card := range byOrd {
+		changes.Delete = append(changes.Delete, card.ID)
+	}
+
+	updatedNote, err := s.noteRepo.UpdateContent(id, fields, occlusions, changes)
+	if err != nil {
+		s.Logger.WithError(err).WithField("note_id", id).Error("Service failed to update note")
+		return nil, fmt.Errorf("failed to update note: %w", err)
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"note_id":       id,
+		"card_count":    len(updatedNote.Cards),
+		"new_cards":     len(changes.Create),
+		"removed_cards": len(changes.Delete),
+	}).Info("Note updated successfully")
+
+	return updatedNote, nil
+}
+
+// DeleteWithOwnership removes a note and all of its cards with user ownership validation
+func (s *NoteService) DeleteWithOwnership(id uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.ownedNote(id, userID); err != nil {
+		return err
+	}
+
+	if err := s.noteRepo.Delete(id); err != nil {
+		s.Logger.WithError(err).WithField("note_id", id).Error("Service failed to delete note")
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+
+	s.Logger.WithField("note_id", id).Info("Note deleted successfully")
+	return nil
+}
+
+// ownedNote retrieves a note and checks that it belongs to the user
+func (s *NoteService) ownedNote(id uuid.UUID, userID uuid.UUID) (*models.Note, error) {
+	note, err := s.noteRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if note.UserID != userID {
+		s.Logger.WithFields(logrus.Fields{
+			"note_id":  id,
+			"user_id":  userID,
+			"owner_id": note.UserID,
+		}).Warn("Unauthorized attempt to access note")
+		return nil, fmt.Errorf("unauthorized: note does not belong to user")
+	}
+
+	return note, nil
+}
+
+// noteType retrieves a note type the user can use
+func (s *NoteService) noteType(id uuid.UUID, userID uuid.UUID) (*models.NoteType, error) {
+	noteType, err := s.noteTypeService.GetByIDWithOwnership(id, userID)
+	if err != nil {
+		if err.Error() == "unauthorized: note type does not belong to user" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("note type not found")
+	}
+	return noteType, nil
+}
+
+// noteImages checks the occlusions of an image occlusion note and returns the images
+// its template draws them on, which must be images the user uploaded. Notes of other
+// kinds cannot have occlusions.
+func (s *NoteService) noteImages(noteType *models.NoteType, fields models.NoteFields, occlusions models.Occlusions, userID uuid.UUID) (map[string]*models.Media, error) {
+	if noteType.Kind != models.NoteTypeKindImageOcclusion {
+		if len(occlusions) > 0 {
+			return nil, fmt.Errorf("invalid note: occlusions are only supported by image occlusion note types")
+		}
+		return nil, nil
+	}
+
+	if err := validateOcclusions(occlusions); err != nil {
+		return nil, err
+	}
+
+	images := make(map[string]*models.Media)
+	for _, field := range templateOcclusionFields(noteType.Templates[0].Front) {
+		hash := strings.TrimSpace(fields[field])
+		media, err := s.mediaRepo.GetByUserAndHash(userID, hash)
+		if err != nil {
+			if err.Error() == "media not found" {
+				return nil, fmt.Errorf("invalid note: field %q must hold the hash of an image you uploaded", field)
+			}
+			return nil, fmt.Errorf("failed to get media: %w", err)
+		}
+		if media.Width == nil || media.Height == nil {
+			return nil, fmt.Errorf("invalid note: the image in field %q must be a PNG, JPEG or GIF", field)
+		}
+		images[hash] = media
+	}
+
+	return images, nil
+}
+
+// noteCard builds the unsaved card a note generates for a card spec
+func (s *NoteService) noteCard(deck *models.Deck, note *models.Note, spec noteCardSpec, images map[string]*models.Media) (*models.Flashcard, error) {
+	front, back := renderCard(spec.template, noteCardContent(note, spec, images))
+	card, err := s.flashcardService.newFlashcard(deck, note.UserID, front, back)
+	if err != nil {
+		return nil, err
+	}
+
+	card.NoteID = &note.ID
+	card.Ord = &spec.ord
+	card.Format = models.ContentFormatHTML // Note fields and templates hold HTML
+	return card, nil
+}
+
+// noteCardContent returns what the card of a spec is rendered from
+func noteCardContent(note *models.Note, spec noteCardSpec, images map[string]*models.Media) cardContent {
+	return cardContent{
+		fields:     note.Fields,
+		index:      spec.index,
+		occlusions: note.Occlusions,
+		images:     images,
+	}
+}
+
+// noteCardSpec describes one card a note generates
+type noteCardSpec struct {
+	ord      int // Template index, or deletion or occlusion index minus one
+	template models.CardTemplate
+	index    int // Deletion or occlusion index, 0 for cards of standard note types
+}
+
+// noteCardSpecs returns the cards a note generates, ordered by ord
+func noteCardSpecs(noteType *models.NoteType, fields models.NoteFields, occlusions models.Occlusions) []noteCardSpec {
+	var specs []noteCardSpec
+
+	switch noteType.Kind {
+	case models.NoteTypeKindCloze:
+		template := noteType.Templates[0]
+		var texts []string
+		for _, field := range templateClozeFields(template.Front) {
+			texts = append(texts, fields[field])
+		}
+		for _, index := range clozeIndices(strings.Join(texts, "\n")) {
+			specs = append(specs, noteCardSpec{ord: index - 1, template: template, index: index})
+		}
+		return specs
+	case models.NoteTypeKindImageOcclusion:
+		for _, index := range occlusionIndices(occlusions) {
+			specs = append(specs, noteCardSpec{ord: index - 1, template: noteType.Templates[0], index: index})
+		}
+		return specs
+	}
+
+	for ord, template := range noteType.Templates {
+		if cardHasContent(template, fields) {
+			specs = append(specs, noteCardSpec{ord: ord, template: template})
+		}
+	}
+	return specs
+}
+
+// checkNoteFields rejects values for fields the note type does not have and values
+// longer than maxNoteFieldLength
+func checkNoteFields(noteType *models.NoteType, fields models.NoteFields) error {
+	known := make(map[string]bool, len(noteType.Fields))
+	for _, field := range noteType.Fields {
+		known[field] = true
+	}
+	for field, value := range fields {
+		if !known[field] {
+			return fmt.Errorf("invalid note: unknown field %q for note type %q", field, noteType.Name)
+		}
+		if utf8.RuneCountInString(value) > maxNoteFieldLength {
+			return fmt.Errorf("invalid note: field %q exceeds %d characters", field, maxNoteFieldLength)
+		}
+	}
+	return nil
+}
+
+// mergeNoteFields returns a value for every field of the note type: the update's value
+// when it has one, otherwise the current one
+func mergeNoteFields(noteType *models.NoteType, current, update models.NoteFields) models.NoteFields {
+	fields := make(models.NoteFields, len(noteType.Fields))
+	for _, field := range noteType.Fields {
+		fields[field] = current[field]
+		if value, ok := update[field]; ok {
+			fields[field] = value
+		}
+	}
+	return fields
+}
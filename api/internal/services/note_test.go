@@ -0,0 +1,429 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+// MockNoteRepository is a mock implementation of NoteRepository for testing
+type MockNoteRepository struct {
+	mock.Mock
+}
+
+func (m *MockNoteRepository) Create(note *models.Note, cards []*models.Flashcard) (*models.Note, error) {
+	args := m.Called(note, cards)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	if fn, ok := args.Get(0).(func(*models.Note, []*models.Flashcard) *models.Note); ok {
+		return fn(note, cards), args.Error(1)
+	}
+	return args.Get(0).(*models.Note), args.Error(1)
+}
+
+func (m *MockNoteRepository) GetByID(id uuid.UUID) (*models.Note, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Note), args.Error(1)
+}
+
+func (m *MockNoteRepository) UpdateContent(id uuid.UUID, fields models.NoteFields, occlusions models.Occlusions, cards models.NoteCardChanges) (*models.Note, error) {
+	args := m.Called(id, fields, occlusions, cards)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	if fn, ok := args.Get(0).(func(uuid.UUID, models.NoteFields, models.Occlusions, models.NoteCardChanges) *models.Note); ok {
+		return fn(id, fields, occlusions, cards), args.Error(1)
+	}
+	return args.Get(0).(*models.Note), args.Error(1)
+}
+
+func (m *MockNoteRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockNoteRepository) CountByNoteType(noteTypeID uuid.UUID) (int, error) {
+	args := m.Called(noteTypeID)
+	return args.Int(0), args.Error(1)
+}
+
+type noteMocks struct {
+	noteRepo      *MockNoteRepository
+	noteTypeRepo  *MockNoteTypeRepository
+	flashcardRepo *MockFlashcardRepository
+	deckRepo      *MockDeckRepository
+	mediaRepo     *MockMediaRepository
+}
+
+func newTestNoteService() (*NoteService, noteMocks) {
+	logger := testutils.TestLogger()
+	mocks := noteMocks{
+		noteRepo:      &MockNoteRepository{},
+		noteTypeRepo:  &MockNoteTypeRepository{},
+		flashcardRepo: &MockFlashcardRepository{},
+		deckRepo:      &MockDeckRepository{},
+		mediaRepo:     &MockMediaRepository{},
+	}
+	flashcardService := NewFlashcardService(mocks.flashcardRepo, mocks.deckRepo, &MockDeckPresetRepository{}, &MockReviewLogRepository{}, &MockUserRepository{}, logger)
+	noteTypeService := NewNoteTypeService(mocks.noteTypeRepo, mocks.noteRepo, logger)
+	return NewNoteService(mocks.noteRepo, mocks.flashcardRepo, mocks.deckRepo, mocks.mediaRepo, noteTypeService, flashcardService, logger), mocks
+}
+
+// captureCreatedCards makes the note repository save the notes matching note, with
+// their cards, and returns every card they are saved with
+func captureCreatedCards(noteRepo *MockNoteRepository, note any) *[]*models.Flashcard {
+	var created []*models.Flashcard
+	noteRepo.On("Create", note, mock.Anything).Return(func(note *models.Note, cards []*models.Flashcard) *models.Note {
+		created = append(created, cards...)
+		saved := *note
+		saved.Cards = cards
+		return &saved
+	}, nil)
+	return &created
+}
+
+// captureCardChanges makes the note repository save any update of note and returns
+// the changes to its cards the update is saved with
+func captureCardChanges(noteRepo *MockNoteRepository, note *models.Note) *models.NoteCardChanges {
+	changes := &models.NoteCardChanges{}
+	noteRepo.On("UpdateContent", note.ID, mock.Anything, mock.Anything, mock.Anything).Return(func(id uuid.UUID, fields models.NoteFields, occlusions models.Occlusions, cards models.NoteCardChanges) *models.Note {
+		*changes = cards
+		saved := *note
+		saved.Fields, saved.Occlusions = fields, occlusions
+		return &saved
+	}, nil)
+	return changes
+}
+
+func TestNoteService_Create_GeneratesReverseCard(t *testing.T) {
+	service, mocks := newTestNoteService()
+
+	userID := uuid.New()
+	deck := &models.Deck{ID: uuid.New(), UserID: userID}
+	noteType := basicReversedNoteType()
+
+	mocks.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+	mocks.noteTypeRepo.On("GetByID", noteType.ID).Return(noteType, nil)
+	created := captureCreatedCards(mocks.noteRepo, mock.MatchedBy(func(note *models.Note) bool {
+		return note.UserID == userID && note.Fields["Front"] == "Hund" && note.Fields["Back"] == "dog"
+	}))
+
+	note, err := service.Create(&models.CreateNoteRequest{
+		DeckID:     deck.ID,
+		NoteTypeID: noteType.ID,
+		Fields:     models.NoteFields{"Front": "Hund", "Back": "dog"},
+	}, userID)
+
+	require.NoError(t, err)
+	assert.Len(t, note.Cards, 2)
+	require.Len(t, *created, 2)
+
+	forward, reverse := (*created)[0], (*created)[1]
+	assert.Equal(t, "Hund", forward.Front)
+	assert.Equal(t, "dog", forward.Back)
+	assert.Equal(t, 0, *forward.Ord)
+	assert.Equal(t, "dog", reverse.Front)
+	assert.Equal(t, "Hund", reverse.Back)
+	assert.Equal(t, 1, *reverse.Ord)
+	assert.Equal(t, forward.NoteID, reverse.NoteID)
+	assert.Equal(t, deck.ID, reverse.DeckID)
+	assert.Equal(t, models.CardStateNew, reverse.State)
+	assert.Equal(t, models.ContentFormatHTML, reverse.Format)
+}
+
+func TestNoteService_Create_SkipsEmptyFront(t *testing.T) {
+	service, mocks := newTestNoteService()
+
+	userID := uuid.New()
+	deck := &models.Deck{ID: uuid.New(), UserID: userID}
+	noteType := basicReversedNoteType()
+
+	mocks.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+	mocks.noteTypeRepo.On("GetByID", noteType.ID).Return(noteType, nil)
+	created := captureCreatedCards(mocks.noteRepo, mock.Anything)
+
+	_, err := service.Create(&models.CreateNoteRequest{
+		DeckID:     deck.ID,
+		NoteTypeID: noteType.ID,
+		Fields:     models.NoteFields{"Front": "Hund"},
+	}, userID)
+
+	require.NoError(t, err)
+	require.Len(t, *created, 1)
+	assert.Equal(t, 0, *(*created)[0].Ord)
+}
+
+func TestNoteService_Create_Invalid(t *testing.T) {
+	userID := uuid.New()
+	noteType := basicReversedNoteType()
+
+	tests := []struct {
+		name   string
+		fields models.NoteFields
+		want   string
+	}{
+		{name: "no card", fields: models.NoteFields{"Front": " "}, want: "invalid note: no card would be generated from these fields"},
+		{name: "unknown field", fields: models.NoteFields{"Front": "Hund", "Extra": "x"}, want: `invalid note: unknown field "Extra" for note type "Basic (and reversed card)"`},
+		{name: "field too long", fields: models.NoteFields{"Front": strings.Repeat("a", maxNoteFieldLength+1)}, want: `invalid note: field "Front" exceeds 10000 characters`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mocks := newTestNoteService()
+			deck := &models.Deck{ID: uuid.New(), UserID: userID}
+			mocks.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+			mocks.noteTypeRepo.On("GetByID", noteType.ID).Return(noteType, nil)
+
+			note, err := service.Create(&models.CreateNoteRequest{DeckID: deck.ID, NoteTypeID: noteType.ID, Fields: tt.fields}, userID)
+
+			assert.Nil(t, note)
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+			mocks.noteRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestNoteService_Create_DeckOfOtherUser(t *testing.T) {
+	service, mocks := newTestNoteService()
+
+	deck := &models.Deck{ID: uuid.New(), UserID: uuid.New()}
+	mocks.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+
+	note, err := service.Create(&models.CreateNoteRequest{
+		DeckID:     deck.ID,
+		NoteTypeID: models.BasicNoteTypeID,
+		Fields:     models.NoteFields{"Front": "Hund"},
+	}, uuid.New())
+
+	assert.Nil(t, note)
+	require.Error(t, err)
+	assert.Equal(t, "unauthorized: deck does not belong to user", err.Error())
+}
+
+func TestNoteService_UpdateWithOwnership_UpdatesSiblings(t *testing.T) {
+	service, mocks := newTestNoteService()
+
+	userID := uuid.New()
+	noteType := basicReversedNoteType()
+	note := &models.Note{
+		ID:         uuid.New(),
+		UserID:     userID,
+		DeckID:     uuid.New(),
+		NoteTypeID: noteType.ID,
+		Fields:     models.NoteFields{"Front": "Hund", "Back": "dgo"},
+	}
+	ord0, ord1 := 0, 1
+	forward := &models.Flashcard{ID: uuid.New(), NoteID: &note.ID, Ord: &ord0, Front: "Hund", Back: "dgo"}
+	reverse := &models.Flashcard{ID: uuid.New(), NoteID: &note.ID, Ord: &ord1, Front: "dgo", Back: "Hund"}
+	fixed := models.NoteFields{"Front": "Hund", "Back": "dog"}
+
+	mocks.noteRepo.On("GetByID", note.ID).Return(note, nil)
+	mocks.flashcardRepo.On("GetByNote", note.ID).Return([]*models.Flashcard{forward, reverse}, nil)
+	mocks.noteTypeRepo.On("GetByID", noteType.ID).Return(noteType, nil)
+	changes := captureCardChanges(mocks.noteRepo, note)
+
+	updated, err := service.UpdateWithOwnership(note.ID, userID, &models.UpdateNoteRequest{Fields: models.NoteFields{"Back": "dog"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, fixed, updated.Fields)
+	assert.Empty(t, changes.Create)
+	assert.Empty(t, changes.Delete)
+	require.Len(t, changes.Update, 2)
+	assert.Equal(t, forward.ID, changes.Update[0].ID)
+	assert.Equal(t, "Hund", changes.Update[0].Front)
+	assert.Equal(t, "dog", changes.Update[0].Back)
+	assert.Equal(t, reverse.ID, changes.Update[1].ID)
+	assert.Equal(t, "dog", changes.Update[1].Front)
+	assert.Equal(t, "Hund", changes.Update[1].Back)
+	assert.Equal(t, "dgo", forward.Back, "the loaded card is left as it was")
+}
+
+func TestNoteService_UpdateWithOwnership_GeneratesNewCard(t *testing.T) {
+	service, mocks := newTestNoteService()
+
+	userID := uuid.New()
+	deck := &models.Deck{ID: uuid.New(), UserID: userID}
+	noteType := basicReversedNoteType()
+	note := &models.Note{ID: uuid.New(), UserID: userID, DeckID: deck.ID, NoteTypeID: noteType.ID, Fields: models.NoteFields{"Front": "Hund", "Back": ""}}
+	ord0 := 0
+	forward := &models.Flashcard{ID: uuid.New(), NoteID: &note.ID, Ord: &ord0, Front: "Hund", Back: ""}
+	fields := models.NoteFields{"Front": "Hund", "Back": "dog"}
+
+	mocks.noteRepo.On("GetByID", note.ID).Return(note, nil)
+	mocks.flashcardRepo.On("GetByNote", note.ID).Return([]*models.Flashcard{forward}, nil)
+	mocks.noteTypeRepo.On("GetByID", noteType.ID).Return(noteType, nil)
+	mocks.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+	changes := captureCardChanges(mocks.noteRepo, note)
+
+	updated, err := service.UpdateWithOwnership(note.ID, userID, &models.UpdateNoteRequest{Fields: models.NoteFields{"Back": "dog"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, fields, updated.Fields)
+	require.Len(t, changes.Update, 1)
+	assert.Equal(t, forward.ID, changes.Update[0].ID)
+	require.Len(t, changes.Create, 1)
+	assert.Equal(t, "dog", changes.Create[0].Front)
+	assert.Equal(t, 1, *changes.Create[0].Ord)
+	assert.Equal(t, note.ID, *changes.Create[0].NoteID)
+}
+
+func TestNoteService_UpdateWithOwnership_Unauthorized(t *testing.T) {
+	service, mocks := newTestNoteService()
+
+	note := &models.Note{ID: uuid.New(), UserID: uuid.New()}
+	mocks.noteRepo.On("GetByID", note.ID).Return(note, nil)
+
+	updated, err := service.UpdateWithOwnership(note.ID, uuid.New(), &models.UpdateNoteRequest{Fields: models.NoteFields{"Back": "dog"}})
+
+	assert.Nil(t, updated)
+	require.Error(t, err)
+	assert.Equal(t, "unauthorized: note does not belong to user", err.Error())
+	mocks.noteRepo.AssertNotCalled(t, "UpdateContent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestFlashcardService_UpdateWithOwnership_NoteCard(t *testing.T) {
+	_, mocks := newTestNoteService()
+	service := NewFlashcardService(mocks.flashcardRepo, mocks.deckRepo, &MockDeckPresetRepository{}, &MockReviewLogRepository{}, &MockUserRepository{}, testutils.TestLogger())
+
+	userID := uuid.New()
+	noteID := uuid.New()
+	card := &models.Flashcard{ID: uuid.New(), UserID: userID, NoteID: &noteID}
+	mocks.flashcardRepo.On("GetByID", card.ID).Return(card, nil)
+
+	front := "edited"
+	result, err := service.UpdateWithOwnership(card.ID, userID, &models.UpdateFlashcardRequest{Front: &front})
+
+	assert.Nil(t, result)
+	require.Error(t, err)
+	assert.Equal(t, "flashcard belongs to a note; edit the note instead", err.Error())
+
+	err = service.DeleteWithOwnership(card.ID, userID)
+	require.Error(t, err)
+	assert.Equal(t, "flashcard belongs to a note; delete the note instead", err.Error())
+	mocks.flashcardRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestNoteService_Create_Cloze(t *testing.T) {
+	service, mocks := newTestNoteService()
+
+	userID := uuid.New()
+	deck := &models.Deck{ID: uuid.New(), UserID: userID}
+	noteType := clozeNoteType()
+
+	mocks.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+	mocks.noteTypeRepo.On("GetByID", noteType.ID).Return(noteType, nil)
+	created := captureCreatedCards(mocks.noteRepo, mock.Anything)
+
+	_, err := service.Create(&models.CreateNoteRequest{
+		DeckID:     deck.ID,
+		NoteTypeID: noteType.ID,
+		Fields:     models.NoteFields{"Text": "{{c1::Canberra}} is the capital of {{c3::Australia}}", "Back Extra": "Not Sydney"},
+	}, userID)
+
+	require.NoError(t, err)
+	require.Len(t, *created, 2)
+	assert.Equal(t, "[...] is the capital of Australia", (*created)[0].Front)
+	assert.Equal(t, "Canberra is the capital of Australia\n\nNot Sydney", (*created)[0].Back)
+	assert.Equal(t, 0, *(*created)[0].Ord)
+	assert.Equal(t, "Canberra is the capital of [...]", (*created)[1].Front)
+	assert.Equal(t, 2, *(*created)[1].Ord)
+}
+
+func TestNoteService_Create_ClozeWithoutDeletion(t *testing.T) {
+	service, mocks := newTestNoteService()
+
+	userID := uuid.New()
+	deck := &models.Deck{ID: uuid.New(), UserID: userID}
+	noteType := clozeNoteType()
+	mocks.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+	mocks.noteTypeRepo.On("GetByID", noteType.ID).Return(noteType, nil)
+
+	note, err := service.Create(&models.CreateNoteRequest{
+		DeckID:     deck.ID,
+		NoteTypeID: noteType.ID,
+		Fields:     models.NoteFields{"Text": "Canberra is the capital of Australia"},
+	}, userID)
+
+	assert.Nil(t, note)
+	require.Error(t, err)
+	assert.Equal(t, "invalid note: a cloze note needs at least one {{c1::...}} deletion", err.Error())
+}
+
+func TestNoteService_Create_ClozeTooManyDeletions(t *testing.T) {
+	service, mocks := newTestNoteService()
+
+	userID := uuid.New()
+	deck := &models.Deck{ID: uuid.New(), UserID: userID}
+	noteType := clozeNoteType()
+	mocks.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+	mocks.noteTypeRepo.On("GetByID", noteType.ID).Return(noteType, nil)
+
+	var text strings.Builder
+	for index := 1; index <= maxClozeIndices+1; index++ {
+		fmt.Fprintf(&text, "{{c%d::x}} ", index)
+	}
+	note, err := service.Create(&models.CreateNoteRequest{
+		DeckID:     deck.ID,
+		NoteTypeID: noteType.ID,
+		Fields:     models.NoteFields{"Text": text.String()},
+	}, userID)
+
+	assert.Nil(t, note)
+	require.Error(t, err)
+	assert.Equal(t, "invalid note: a cloze note can have at most 200 deletion indices", err.Error())
+	mocks.noteRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestNoteService_UpdateWithOwnership_ClozeKeepsSurvivingCards(t *testing.T) {
+	service, mocks := newTestNoteService()
+
+	userID := uuid.New()
+	deck := &models.Deck{ID: uuid.New(), UserID: userID}
+	noteType := clozeNoteType()
+	note := &models.Note{
+		ID:         uuid.New(),
+		UserID:     userID,
+		DeckID:     deck.ID,
+		NoteTypeID: noteType.ID,
+		Fields:     models.NoteFields{"Text": "{{c1::Canberra}} is in {{c2::Australia}}", "Back Extra": ""},
+	}
+	ord0, ord1 := 0, 1
+	c1 := &models.Flashcard{ID: uuid.New(), NoteID: &note.ID, Ord: &ord0, Front: "[...] is in Australia", Back: "Canberra is in Australia", ReviewCount: 7, Interval: 30}
+	c2 := &models.Flashcard{ID: uuid.New(), NoteID: &note.ID, Ord: &ord1, Front: "Canberra is in [...]", Back: "Canberra is in Australia", ReviewCount: 3}
+	fields := models.NoteFields{"Text": "{{c1::Canberra}} is in {{c3::the ACT}}", "Back Extra": ""}
+
+	mocks.noteRepo.On("GetByID", note.ID).Return(note, nil)
+	mocks.flashcardRepo.On("GetByNote", note.ID).Return([]*models.Flashcard{c1, c2}, nil)
+	mocks.noteTypeRepo.On("GetByID", noteType.ID).Return(noteType, nil)
+	mocks.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+	changes := captureCardChanges(mocks.noteRepo, note)
+
+	updated, err := service.UpdateWithOwnership(note.ID, userID, &models.UpdateNoteRequest{Fields: models.NoteFields{"Text": "{{c1::Canberra}} is in {{c3::the ACT}}"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, fields, updated.Fields)
+	// c1 survives and is only rendered again, so it keeps its scheduling and history
+	require.Len(t, changes.Update, 1)
+	assert.Equal(t, c1.ID, changes.Update[0].ID)
+	assert.Equal(t, "[...] is in the ACT", changes.Update[0].Front)
+	assert.Equal(t, "Canberra is in the ACT", changes.Update[0].Back)
+	assert.Equal(t, 30, changes.Update[0].Interval)
+	assert.Equal(t, 7, changes.Update[0].ReviewCount)
+	require.Len(t, changes.Create, 1)
+	assert.Equal(t, "Canberra is in [...]", changes.Create[0].Front)
+	assert.Equal(t, 2, *changes.Create[0].Ord)
+	assert.Equal(t, []uuid.UUID{c2.ID}, changes.Delete)
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/repositories"
+)
+
+// frontSideField is the template reference that inserts the rendered front on the back
+const frontSideField = "FrontSide"
+
+// clozeFilter is the template filter of {{cloze:Field}} references
+const clozeFilter = "cloze"
+
+// occlusionFilter is the template filter of {{occlusion:Field}} references
+const occlusionFilter = "occlusion"
+
+// templateReference matches a {{Field}} or {{filter:Field}} reference in a card template
+var templateReference = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+
+type NoteTypeService struct {
+	noteTypeRepo repositories.NoteTypeRepositoryInterface
+	noteRepo     repositories.NoteRepositoryInterface
+	Logger       *logrus.Logger
+}
+
+func NewNoteTypeService(
+	noteTypeRepo repositories.NoteTypeRepositoryInterface,
+	noteRepo repositories.NoteRepositoryInterface,
+	logger *logrus.Logger,
+) *NoteTypeService {
+	return &NoteTypeService{
+		noteTypeRepo: noteTypeRepo,
+		noteRepo:     noteRepo,
+		Logger:       logger,
+	}
+}
+
+// Create creates a note type for a user after checking its fields and templates
+func (s *NoteTypeService) Create(req *models.CreateNoteTypeRequest, userID uuid.UUID) (*models.NoteType, error) {
+	noteType := &models.NoteType{
+		ID:        uuid.New(),
+		UserID:    &userID,
+		Name:      strings.TrimSpace(req.Name),
+		Kind:      req.Kind,
+		Fields:    make(models.NoteFieldList, len(req.Fields)),
+		Templates: models.CardTemplates(req.Templates),
+	}
+	for i, field := range req.Fields {
+		noteType.Fields[i] = strings.TrimSpace(field)
+	}
+	if noteType.Kind == "" {
+		noteType.Kind = models.NoteTypeKindStandard
+	}
+
+	if err := validateNoteType(noteType); err != nil {
+		return nil, err
+	}
+
+	savedNoteType, err := s.noteTypeRepo.Create(noteType)
+	if err != nil {
+		s.Logger.WithError(err).Error("Service failed to create note type")
+		return nil, fmt.Errorf("failed to create note type: %w", err)
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"note_type_id": savedNoteType.ID,
+		"name":         savedNoteType.Name,
+	}).Info("Note type created successfully")
+
+	return savedNoteType, nil
+}
+
+// GetByUser retrieves the built-in note types and those of a user
+func (s *NoteTypeService) GetByUser(userID uuid.UUID) ([]*models.NoteType, error) {
+	noteTypes, err := s.noteTypeRepo.GetByUser(userID)
+	if err != nil {
+		s.Logger.WithError(err).WithField("user_id", userID).Error("Service failed to get note types for user")
+		return nil, fmt.Errorf("failed to get note types: %w", err)
+	}
+
+	return noteTypes, nil
+}
+
+// GetByIDWithOwnership retrieves a note type with user ownership validation. Built-in
+// note types belong to every user.
+func (s *NoteTypeService) GetByIDWithOwnership(id uuid.UUID, userID uuid.UUID) (*models.NoteType, error) {
+	noteType, err := s.noteTypeRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get note type: %w", err)
+	}
+
+	if noteType.UserID != nil && *noteType.UserID != userID {
+		s.Logger.WithFields(logrus.Fields{
+			"note_type_id": id,
+			"user_id":      userID,
+			"owner_id":     *noteType.UserID,
+		}).Warn("Unauthorized attempt to access note type")
+		return nil, fmt.Errorf("unauthorized: note type does not belong to user")
+	}
+
+	return noteType, nil
+}
+
+// DeleteWithOwnership removes a note type with user ownership validation. Built-in note
+// types and note types that still have notes cannot be deleted.
+func (s *NoteTypeService) DeleteWithOwnership(id uuid.UUID, userID uuid.UUID) error {
+	noteType, err := s.GetByIDWithOwnership(id, userID)
+	if err != nil {
+		return err
+	}
+	if noteType.UserID == nil {
+		return fmt.Errorf("cannot delete a built-in note type")
+	}
+
+	count, err := s.noteRepo.CountByNoteType(id)
+	if err != nil {
+		return fmt.Errorf("failed to count notes: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("note type is in use")
+	}
+
+	if err := s.noteTypeRepo.Delete(id); err != nil {
+		s.Logger.WithError(err).WithField("note_type_id", id).Error("Service failed to delete note type")
+		return fmt.Errorf("failed to delete note type: %w", err)
+	}
+
+	s.Logger.WithField("note_type_id", id).Info("Note type deleted successfully")
+	return nil
+}
+
+// validateNoteType checks that field names are usable in templates and that every
+// template only references fields of the note type
+func validateNoteType(noteType *models.NoteType) error {
+	if noteType.Name == "" {
+		return fmt.Errorf("invalid note type: name cannot be empty")
+	}
+
+	fields := make(map[string]bool, len(noteType.Fields))
+	for _, field := range noteType.Fields {
+		switch {
+		case field == "":
+			return fmt.Errorf("invalid note type: field names cannot be empty")
+		case strings.ContainsAny(field, "{}:"):
+			return fmt.Errorf("invalid note type: field %q cannot contain braces or colons", field)
+		case field == frontSideField:
+			return fmt.Errorf("invalid note type: %q is reserved", frontSideField)
+		case fields[field]:
+			return fmt.Errorf("invalid note type: duplicate field %q", field)
+		}
+		fields[field] = true
+	}
+
+	switch noteType.Kind {
+	case models.NoteTypeKindCloze:
+		if len(noteType.Templates) != 1 {
+			return fmt.Errorf("invalid note type: a cloze note type has exactly one template")
+		}
+	case models.NoteTypeKindImageOcclusion:
+		if len(noteType.Templates) != 1 {
+			return fmt.Errorf("invalid note type: an image occlusion note type has exactly one template")
+		}
+	}
+
+	names := make(map[string]bool, len(noteType.Templates))
+	for _, template := range noteType.Templates {
+		if names[template.Name] {
+			return fmt.Errorf("invalid note type: duplicate template %q", template.Name)
+		}
+		names[template.Name] = true
+
+		front := templateReferences(template.Front)
+		if len(front) == 0 {
+			return fmt.Errorf("invalid note type: front of template %q references no field", template.Name)
+		}
+		if noteType.Kind == models.NoteTypeKindCloze && len(templateClozeFields(template.Front)) == 0 {
+			return fmt.Errorf("invalid note type: front of template %q has no {{cloze:Field}} reference", template.Name)
+		}
+		if noteType.Kind == models.NoteTypeKindImageOcclusion {
+			images := templateOcclusionFields(template.Front + template.Back)
+			switch {
+			case len(templateOcclusionFields(template.Front)) == 0:
+				return fmt.Errorf("invalid note type: front of template %q has no {{occlusion:Field}} reference", template.Name)
+			case len(images) > 1:
+				return fmt.Errorf("invalid note type: template %q draws occlusions on more than one field", template.Name)
+			}
+		}
+
+		for _, reference := range front {
+			if err := checkTemplateRef(template.Name, reference, fields, noteType.Kind, false); err != nil {
+				return err
+			}
+		}
+		for _, reference := range templateReferences(template.Back) {
+			if err := checkTemplateRef(template.Name, reference, fields, noteType.Kind, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkTemplateRef checks that a template reference names a field of the note type
+// with a filter its kind supports. {{FrontSide}} is allowed on the back only.
+func checkTemplateRef(template string, reference templateRef, fields map[string]bool, kind string, back bool) error {
+	switch {
+	case reference.filter != "" && reference.filter != clozeFilter && reference.filter != occlusionFilter:
+		return fmt.Errorf("invalid note type: template %q uses unknown filter %q", template, reference.filter)
+	case reference.filter == clozeFilter && kind != models.NoteTypeKindCloze:
+		return fmt.Errorf("invalid note type: template %q uses cloze outside a cloze note type", template)
+	case reference.filter == occlusionFilter && kind != models.NoteTypeKindImageOcclusion:
+		return fmt.Errorf("invalid note type: template %q uses occlusion outside an image occlusion note type", template)
+	case back && reference.filter == "" && reference.field == frontSideField:
+		return nil
+	case !fields[reference.field]:
+		return fmt.Errorf("invalid note type: template %q references unknown field %q", template, reference.field)
+	}
+	return nil
+}
+
+// templateRef is a field reference in a card template, with its optional filter
+type templateRef struct {
+	filter string
+	field  string
+}
+
+// templateReferences returns the references of a template, in order
+func templateReferences(template string) []templateRef {
+	var references []templateRef
+	for _, match := range templateReference.FindAllStringSubmatch(template, -1) {
+		references = append(references, parseTemplateRef(match[1]))
+	}
+	return references
+}
+
+func parseTemplateRef(reference string) templateRef {
+	filter, field, ok := strings.Cut(reference, ":")
+	if !ok {
+		return templateRef{field: reference}
+	}
+	return templateRef{filter: strings.TrimSpace(filter), field: strings.TrimSpace(field)}
+}
+
+// templateClozeFields returns the fields a template references with {{cloze:Field}}
+func templateClozeFields(template string) []string {
+	var fields []string
+	for _, reference := range templateReferences(template) {
+		if reference.filter == clozeFilter {
+			fields = append(fields, reference.field)
+		}
+	}
+	return fields
+}
+
+// templateOcclusionFields returns the distinct fields a template references with
+// {{occlusion:Field}}
+func templateOcclusionFields(template string) []string {
+	var fields []string
+	for _, reference := range templateReferences(template) {
+		if reference.filter == occlusionFilter && !slices.Contains(fields, reference.field) {
+			fields = append(fields, reference.field)
+		}
+	}
+	return fields
+}
+
+// cardContent is what a card of a note is rendered from besides its template
+type cardContent struct {
+	fields     models.NoteFields
+	index      int // Deletion or occlusion index of the card, 0 for cards of standard note types
+	occlusions models.Occlusions
+	images     map[string]*models.Media // Images {{occlusion:Field}} draws on, by hash
+}
+
+// renderTemplate replaces the field references of a template with the note's values.
+// {{FrontSide}} is replaced with frontSide, {{cloze:Field}} with the field rendered for
+// the card's deletion index and {{occlusion:Field}} with the image the field holds the
+// hash of, drawn with the card's occlusions. Both are revealed when reveal is set.
+func renderTemplate(template string, content cardContent, frontSide string, reveal bool) string {
+	return templateReference.ReplaceAllStringFunc(template, func(match string) string {
+		reference := parseTemplateRef(templateReference.FindStringSubmatch(match)[1])
+		switch {
+		case reference.filter == clozeFilter:
+			return renderCloze(content.fields[reference.field], content.index, reveal)
+		case reference.filter == occlusionFilter:
+			image := content.images[strings.TrimSpace(content.fields[reference.field])]
+			return renderOcclusion(image, content.occlusions, content.index, reveal)
+		case reference.field == frontSideField:
+			return frontSide
+		default:
+			return content.fields[reference.field]
+		}
+	})
+}
+
+// cardHasContent reports whether a template of a standard note type generates a card
+// for the note: a field its front references must be filled in
+func cardHasContent(template models.CardTemplate, fields models.NoteFields) bool {
+	for _, reference := range templateReferences(template.Front) {
+		if strings.TrimSpace(fields[reference.field]) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// renderCard renders the front and back of a template's card
+func renderCard(template models.CardTemplate, content cardContent) (front, back string) {
+	front = strings.TrimSpace(renderTemplate(template.Front, content, "", false))
+	back = strings.TrimSpace(renderTemplate(template.Back, content, front, true))
+	return front, back
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+// MockNoteTypeRepository is a mock implementation of NoteTypeRepository for testing
+type MockNoteTypeRepository struct {
+	mock.Mock
+}
+
+func (m *MockNoteTypeRepository) Create(noteType *models.NoteType) (*models.NoteType, error) {
+	args := m.Called(noteType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NoteType), args.Error(1)
+}
+
+func (m *MockNoteTypeRepository) GetByID(id uuid.UUID) (*models.NoteType, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NoteType), args.Error(1)
+}
+
+func (m *MockNoteTypeRepository) GetByUser(userID uuid.UUID) ([]*models.NoteType, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.NoteType), args.Error(1)
+}
+
+func (m *MockNoteTypeRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// basicReversedNoteType mirrors the built-in "Basic (and reversed card)" note type
+func basicReversedNoteType() *models.NoteType {
+	return &models.NoteType{
+		ID:     models.BasicReversedNoteTypeID,
+		Name:   "Basic (and reversed card)",
+		Kind:   models.NoteTypeKindStandard,
+		Fields: models.NoteFieldList{"Front", "Back"},
+		Templates: models.CardTemplates{
+			{Name: "Card 1", Front: "{{Front}}", Back: "{{Back}}"},
+			{Name: "Card 2", Front: "{{Back}}", Back: "{{Front}}"},
+		},
+	}
+}
+
+// clozeNoteType mirrors the built-in "Cloze" note type
+func clozeNoteType() *models.NoteType {
+	return &models.NoteType{
+		ID:        models.ClozeNoteTypeID,
+		Name:      "Cloze",
+		Kind:      models.NoteTypeKindCloze,
+		Fields:    models.NoteFieldList{"Text", "Back Extra"},
+		Templates: models.CardTemplates{{Name: "Cloze", Front: "{{cloze:Text}}", Back: "{{cloze:Text}}\n\n{{Back Extra}}"}},
+	}
+}
+
+// imageOcclusionNoteType mirrors the built-in "Image Occlusion" note type
+func imageOcclusionNoteType() *models.NoteType {
+	return &models.NoteType{
+		ID:     models.ImageOcclusionNoteTypeID,
+		Name:   "Image Occlusion",
+		Kind:   models.NoteTypeKindImageOcclusion,
+		Fields: models.NoteFieldList{"Image", "Header", "Back Extra"},
+		Templates: models.CardTemplates{{
+			Name:  "Image Occlusion",
+			Front: "{{Header}}\n\n{{occlusion:Image}}",
+			Back:  "{{Header}}\n\n{{occlusion:Image}}\n\n{{Back Extra}}",
+		}},
+	}
+}
+
+func TestRenderCard(t *testing.T) {
+	template := models.CardTemplate{Name: "Card 1", Front: "What is {{ Word }}?", Back: "{{FrontSide}} {{Meaning}}"}
+	fields := models.NoteFields{"Word": "Hund", "Meaning": "dog"}
+
+	front, back := renderCard(template, cardContent{fields: fields})
+
+	assert.Equal(t, "What is Hund?", front)
+	assert.Equal(t, "What is Hund? dog", back)
+	assert.True(t, cardHasContent(template, fields))
+	assert.False(t, cardHasContent(template, models.NoteFields{"Word": "  ", "Meaning": "dog"}))
+}
+
+func TestRenderCard_Cloze(t *testing.T) {
+	template := clozeNoteType().Templates[0]
+	fields := models.NoteFields{"Text": "{{c1::Mitochondria}} produce {{c2::ATP}}", "Back Extra": ""}
+
+	front, back := renderCard(template, cardContent{fields: fields, index: 2})
+
+	assert.Equal(t, "Mitochondria produce [...]", front)
+	assert.Equal(t, "Mitochondria produce ATP", back)
+}
+
+func TestNoteTypeService_Create_Success(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockNoteTypeRepository{}
+	service := NewNoteTypeService(mockRepo, &MockNoteRepository{}, logger)
+
+	userID := uuid.New()
+	req := &models.CreateNoteTypeRequest{
+		Name:   "Vocabulary",
+		Fields: []string{" Word ", "Meaning"},
+		Templates: []models.CardTemplate{
+			{Name: "Recognition", Front: "{{Word}}", Back: "{{Meaning}}"},
+			{Name: "Recall", Front: "{{Meaning}}", Back: "{{FrontSide}}: {{Word}}"},
+		},
+	}
+
+	mockRepo.On("Create", mock.MatchedBy(func(noteType *models.NoteType) bool {
+		return *noteType.UserID == userID &&
+			noteType.Kind == models.NoteTypeKindStandard &&
+			assert.ObjectsAreEqual(models.NoteFieldList{"Word", "Meaning"}, noteType.Fields) &&
+			len(noteType.Templates) == 2
+	})).Return(&models.NoteType{ID: uuid.New(), Name: "Vocabulary"}, nil)
+
+	result, err := service.Create(req, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Vocabulary", result.Name)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestNoteTypeService_Create_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		kind      string
+		fields    []string
+		templates []models.CardTemplate
+		want      string
+	}{
+		{
+			name:      "duplicate field",
+			fields:    []string{"Front", "Front"},
+			templates: []models.CardTemplate{{Name: "Card 1", Front: "{{Front}}", Back: "x"}},
+			want:      `invalid note type: duplicate field "Front"`,
+		},
+		{
+			name:      "reserved field",
+			fields:    []string{"FrontSide"},
+			templates: []models.CardTemplate{{Name: "Card 1", Front: "{{FrontSide}}", Back: "x"}},
+			want:      `invalid note type: "FrontSide" is reserved`,
+		},
+		{
+			name:      "unknown field",
+			fields:    []string{"Front", "Back"},
+			templates: []models.CardTemplate{{Name: "Card 1", Front: "{{Front}}", Back: "{{Answer}}"}},
+			want:      `invalid note type: template "Card 1" references unknown field "Answer"`,
+		},
+		{
+			name:      "front without field",
+			fields:    []string{"Front", "Back"},
+			templates: []models.CardTemplate{{Name: "Card 1", Front: "Question", Back: "{{Back}}"}},
+			want:      `invalid note type: front of template "Card 1" references no field`,
+		},
+		{
+			name:   "duplicate template",
+			fields: []string{"Front", "Back"},
+			templates: []models.CardTemplate{
+				{Name: "Card 1", Front: "{{Front}}", Back: "{{Back}}"},
+				{Name: "Card 1", Front: "{{Back}}", Back: "{{Front}}"},
+			},
+			want: `invalid note type: duplicate template "Card 1"`,
+		},
+		{
+			name:      "cloze in standard note type",
+			fields:    []string{"Text"},
+			templates: []models.CardTemplate{{Name: "Card 1", Front: "{{cloze:Text}}", Back: "{{Text}}"}},
+			want:      `invalid note type: template "Card 1" uses cloze outside a cloze note type`,
+		},
+		{
+			name:      "unknown filter",
+			fields:    []string{"Text"},
+			templates: []models.CardTemplate{{Name: "Card 1", Front: "{{hint:Text}}", Back: "{{Text}}"}},
+			want:      `invalid note type: template "Card 1" uses unknown filter "hint"`,
+		},
+		{
+			name:      "cloze without cloze reference",
+			kind:      models.NoteTypeKindCloze,
+			fields:    []string{"Text"},
+			templates: []models.CardTemplate{{Name: "Cloze", Front: "{{Text}}", Back: "{{Text}}"}},
+			want:      `invalid note type: front of template "Cloze" has no {{cloze:Field}} reference`,
+		},
+		{
+			name:   "cloze with two templates",
+			kind:   models.NoteTypeKindCloze,
+			fields: []string{"Text"},
+			templates: []models.CardTemplate{
+				{Name: "Cloze", Front: "{{cloze:Text}}", Back: "{{cloze:Text}}"},
+				{Name: "Other", Front: "{{cloze:Text}}", Back: "{{cloze:Text}}"},
+			},
+			want: "invalid note type: a cloze note type has exactly one template",
+		},
+		{
+			name:      "occlusion in cloze note type",
+			kind:      models.NoteTypeKindCloze,
+			fields:    []string{"Text", "Image"},
+			templates: []models.CardTemplate{{Name: "Cloze", Front: "{{cloze:Text}}{{occlusion:Image}}", Back: "{{cloze:Text}}"}},
+			want:      `invalid note type: template "Cloze" uses occlusion outside an image occlusion note type`,
+		},
+		{
+			name:      "image occlusion without occlusion reference",
+			kind:      models.NoteTypeKindImageOcclusion,
+			fields:    []string{"Image"},
+			templates: []models.CardTemplate{{Name: "Occlusion", Front: "{{Image}}", Back: "{{occlusion:Image}}"}},
+			want:      `invalid note type: front of template "Occlusion" has no {{occlusion:Field}} reference`,
+		},
+		{
+			name:      "image occlusion on two images",
+			kind:      models.NoteTypeKindImageOcclusion,
+			fields:    []string{"Image", "Other"},
+			templates: []models.CardTemplate{{Name: "Occlusion", Front: "{{occlusion:Image}}", Back: "{{occlusion:Other}}"}},
+			want:      `invalid note type: template "Occlusion" draws occlusions on more than one field`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockNoteTypeRepository{}
+			service := NewNoteTypeService(mockRepo, &MockNoteRepository{}, testutils.TestLogger())
+
+			result, err := service.Create(&models.CreateNoteTypeRequest{Name: "Broken", Kind: tt.kind, Fields: tt.fields, Templates: tt.templates}, uuid.New())
+
+			assert.Nil(t, result)
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+			mockRepo.AssertNotCalled(t, "Create")
+		})
+	}
+}
+
+func TestNoteTypeService_GetByIDWithOwnership(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockNoteTypeRepository{}
+	service := NewNoteTypeService(mockRepo, &MockNoteRepository{}, logger)
+
+	userID := uuid.New()
+	otherID := uuid.New()
+	builtIn := basicReversedNoteType()
+	owned := &models.NoteType{ID: uuid.New(), UserID: &otherID, Name: "Private"}
+
+	mockRepo.On("GetByID", builtIn.ID).Return(builtIn, nil)
+	mockRepo.On("GetByID", owned.ID).Return(owned, nil)
+
+	result, err := service.GetByIDWithOwnership(builtIn.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, builtIn, result)
+
+	_, err = service.GetByIDWithOwnership(owned.ID, userID)
+	require.Error(t, err)
+	assert.Equal(t, "unauthorized: note type does not belong to user", err.Error())
+}
+
+func TestNoteTypeService_DeleteWithOwnership(t *testing.T) {
+	logger := testutils.TestLogger()
+	userID := uuid.New()
+
+	t.Run("built-in", func(t *testing.T) {
+		mockRepo := &MockNoteTypeRepository{}
+		service := NewNoteTypeService(mockRepo, &MockNoteRepository{}, logger)
+		builtIn := basicReversedNoteType()
+		mockRepo.On("GetByID", builtIn.ID).Return(builtIn, nil)
+
+		err := service.DeleteWithOwnership(builtIn.ID, userID)
+
+		require.Error(t, err)
+		assert.Equal(t, "cannot delete a built-in note type", err.Error())
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("in use", func(t *testing.T) {
+		mockRepo := &MockNoteTypeRepository{}
+		mockNoteRepo := &MockNoteRepository{}
+		service := NewNoteTypeService(mockRepo, mockNoteRepo, logger)
+		noteType := &models.NoteType{ID: uuid.New(), UserID: &userID}
+		mockRepo.On("GetByID", noteType.ID).Return(noteType, nil)
+		mockNoteRepo.On("CountByNoteType", noteType.ID).Return(3, nil)
+
+		err := service.DeleteWithOwnership(noteType.ID, userID)
+
+		require.Error(t, err)
+		assert.Equal(t, "note type is in use", err.Error())
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("unused", func(t *testing.T) {
+		mockRepo := &MockNoteTypeRepository{}
+		mockNoteRepo := &MockNoteRepository{}
+		service := NewNoteTypeService(mockRepo, mockNoteRepo, logger)
+		noteType := &models.NoteType{ID: uuid.New(), UserID: &userID}
+		mockRepo.On("GetByID", noteType.ID).Return(noteType, nil)
+		mockNoteRepo.On("CountByNoteType", noteType.ID).Return(0, nil)
+		mockRepo.On("Delete", noteType.ID).Return(nil)
+
+		require.NoError(t, service.DeleteWithOwnership(noteType.ID, userID))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo := &MockNoteTypeRepository{}
+		service := NewNoteTypeService(mockRepo, &MockNoteRepository{}, logger)
+		id := uuid.New()
+		mockRepo.On("GetByID", id).Return(nil, errors.New("note type not found"))
+
+		err := service.DeleteWithOwnership(id, userID)
+
+		require.Error(t, err)
+		assert.Equal(t, "failed to get note type: note type not found", err.Error())
+	})
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/repositories"
+	"swipelearn-api/internal/utils"
+)
+
+// optimizerStaleHeartbeats is the number of heartbeats an unfinished run can miss
+// before it is taken for interrupted
+const optimizerStaleHeartbeats = 4
+
+// OptimizerService fits each user's FSRS weights to their review history. Runs are
+// background jobs inside the API process: starting one returns right away and the
+// result is read back from the run. While a run is unfinished the process keeps its
+// heartbeat fresh, so that any server can fail the runs of one that stopped.
+type OptimizerService struct {
+	runRepo           repositories.OptimizerRunRepositoryInterface
+	reviewLogRepo     repositories.ReviewLogRepositoryInterface
+	userRepo          repositories.UserRepositoryInterface
+	iterations        int           // Gradient descent steps per run
+	minReviews        int           // Cross-day reviews needed before weights are fitted
+	workers           chan struct{} // Limits how many runs fit at the same time
+	heartbeatInterval time.Duration // Time between heartbeats of the runs of this process
+	mu                sync.Mutex
+	active            map[uuid.UUID]bool // Unfinished runs of this process
+	ctx               context.Context
+	cancel            context.CancelFunc
+	wg                sync.WaitGroup
+	Logger            *logrus.Logger
+}
+
+func NewOptimizerService(
+	runRepo repositories.OptimizerRunRepositoryInterface,
+	reviewLogRepo repositories.ReviewLogRepositoryInterface,
+	userRepo repositories.UserRepositoryInterface,
+	logger *logrus.Logger,
+) *OptimizerService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &OptimizerService{
+		runRepo:           runRepo,
+		reviewLogRepo:     reviewLogRepo,
+		userRepo:          userRepo,
+		iterations:        utils.GetEnvAsInt("OPTIMIZER_ITERATIONS", 200),
+		minReviews:        max(1, utils.GetEnvAsInt("OPTIMIZER_MIN_REVIEWS", 400)),
+		workers:           make(chan struct{}, max(1, utils.GetEnvAsInt("OPTIMIZER_WORKERS", 2))),
+		heartbeatInterval: max(time.Second, utils.GetEnvAsDuration("OPTIMIZER_HEARTBEAT_INTERVAL", 30*time.Second)),
+		active:            make(map[uuid.UUID]bool),
+		ctx:               ctx,
+		cancel:            cancel,
+		Logger:            logger,
+	}
+}
+
+// Start queues an optimizer run for a user and returns it without waiting for the fit.
+// A user can only have one unfinished run at a time.
+func (s *OptimizerService) Start(userID uuid.UUID) (*models.OptimizerRun, error) {
+	if _, err := s.runRepo.GetActiveByUser(userID); err == nil {
+		return nil, fmt.Errorf("optimizer run already in progress")
+	} else if err.Error() != "optimizer run not found" {
+		return nil, fmt.Errorf("failed to check optimizer runs: %w", err)
+	}
+
+	run, err := s.runRepo.Create(&models.OptimizerRun{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    models.OptimizerRunPending,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		if err.Error() == "optimizer run already in progress" {
+			return nil, err
+		}
+		s.Logger.WithError(err).WithField("user_id", userID).Error("Service failed to create optimizer run")
+		return nil, fmt.Errorf("failed to create optimizer run: %w", err)
+	}
+
+	s.mu.Lock()
+	s.active[run.ID] = true
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func(run models.OptimizerRun) {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.active, run.ID)
+			s.mu.Unlock()
+		}()
+		s.execute(&run)
+	}(*run)
+
+	s.Logger.WithFields(logrus.Fields{
+		"run_id":  run.ID,
+		"user_id": userID,
+	}).Info("Optimizer run queued")
+
+	return run, nil
+}
+
+// GetByIDWithOwnership retrieves an optimizer run with user ownership validation
+func (s *OptimizerService) GetByIDWithOwnership(id uuid.UUID, userID uuid.UUID) (*models.OptimizerRun, error) {
+	run, err := s.runRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get optimizer run: %w", err)
+	}
+
+	if run.UserID != userID {
+		s.Logger.WithFields(logrus.Fields{
+			"run_id":   id,
+			"user_id":  userID,
+			"owner_id": run.UserID,
+		}).Warn("Unauthorized attempt to access optimizer run")
+		return nil, fmt.Errorf("unauthorized: optimizer run does not belong to user")
+	}
+
+	return run, nil
+}
+
+// FailInterrupted marks unfinished runs whose heartbeat stopped as failed. Runs only
+// live in the process that started them, so once it is gone they can never complete.
+// Runs of other processes that are still alive are left alone.
+func (s *OptimizerService) FailInterrupted() error {
+	count, err := s.runRepo.FailStale(optimizerStaleHeartbeats*s.heartbeatInterval, "interrupted: server running it stopped")
+	if err != nil {
+		return fmt.Errorf("failed to clean up optimizer runs: %w", err)
+	}
+
+	if count > 0 {
+		s.Logger.WithField("run_count", count).Warn("Marked interrupted optimizer runs as failed")
+	}
+	return nil
+}
+
+// StartHeartbeat refreshes the heartbeat of this process's runs and fails the runs of
+// stopped processes every heartbeat interval until Shutdown
+func (s *OptimizerService) StartHeartbeat() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.heartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.heartbeat(); err != nil {
+					s.Logger.WithError(err).Error("Optimizer run heartbeat failed")
+				}
+				if err := s.FailInterrupted(); err != nil {
+					s.Logger.WithError(err).Error("Failed to clean up interrupted optimizer runs")
+				}
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// heartbeat marks the unfinished runs of this process as alive
+func (s *OptimizerService) heartbeat() error {
+	s.mu.Lock()
+	ids := make([]uuid.UUID, 0, len(s.active))
+	for id := range s.active {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.runRepo.Heartbeat(ids)
+}
+
+// Shutdown stops every run and waits, until ctx is done, for them to record that
+// they were interrupted
+func (s *OptimizerService) Shutdown(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// execute waits for a free worker, runs the optimization and records its outcome
+func (s *OptimizerService) execute(run *models.OptimizerRun) {
+	select {
+	case s.workers <- struct{}{}:
+		defer func() { <-s.workers }()
+	case <-s.ctx.Done():
+		s.finish(run, s.ctx.Err())
+		return
+	}
+
+	startedAt := time.Now()
+	run.Status = models.OptimizerRunRunning
+	run.StartedAt = &startedAt
+	if _, err := s.runRepo.Update(run); err != nil {
+		s.Logger.WithError(err).WithField("run_id", run.ID).Error("Service failed to start optimizer run")
+		s.finish(run, err)
+		return
+	}
+
+	s.finish(run, s.optimize(run))
+}
+
+// optimize fits the user's weights, saves them on the user and fills in the run's results
+func (s *OptimizerService) optimize(run *models.OptimizerRun) error {
+	user, err := s.userRepo.GetByID(run.UserID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	logs, err := s.reviewLogRepo.GetByUser(run.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get review history: %w", err)
+	}
+
+	data := newFSRSDataset(logs)
+	run.ReviewCount = data.predictions
+	if data.predictions < s.minReviews {
+		return fmt.Errorf("not enough review history: %d of %d reviews needed", data.predictions, s.minReviews)
+	}
+
+	start := DefaultFSRSParameters().Weights
+	if len(user.FSRSWeights) == len(start) {
+		start = user.FSRSWeights
+	}
+
+	fitted, err := fitFSRSWeights(s.ctx, start, data, s.iterations)
+	if err != nil {
+		return err
+	}
+
+	before := evaluateFSRS(start, data)
+	after := evaluateFSRS(fitted, data)
+	run.LogLossBefore, run.RMSEBefore = &before.logLoss, &before.rmse
+	run.LogLossAfter, run.RMSEAfter = &after.logLoss, &after.rmse
+	run.Weights = fitted
+
+	if _, err := s.userRepo.Update(user.ID, map[string]interface{}{"fsrs_weights": fitted}); err != nil {
+		return fmt.Errorf("failed to save fitted weights: %w", err)
+	}
+
+	return nil
+}
+
+// finish records the outcome of a run
+func (s *OptimizerService) finish(run *models.OptimizerRun, runErr error) {
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = models.OptimizerRunSucceeded
+
+	if runErr != nil {
+		message := runErr.Error()
+		if errors.Is(runErr, context.Canceled) {
+			message = "interrupted by server shutdown"
+		}
+		run.Status = models.OptimizerRunFailed
+		run.Error = &message
+	}
+
+	if _, err := s.runRepo.Update(run); err != nil {
+		s.Logger.WithError(err).WithField("run_id", run.ID).Error("Service failed to record optimizer run outcome")
+		return
+	}
+
+	fields := logrus.Fields{
+		"run_id":       run.ID,
+		"user_id":      run.UserID,
+		"status":       run.Status,
+		"review_count": run.ReviewCount,
+	}
+	if runErr != nil {
+		s.Logger.WithFields(fields).WithError(runErr).Warn("Optimizer run failed")
+		return
+	}
+
+	fields["log_loss_before"] = *run.LogLossBefore
+	fields["log_loss_after"] = *run.LogLossAfter
+	fields["rmse_before"] = *run.RMSEBefore
+	fields["rmse_after"] = *run.RMSEAfter
+	s.Logger.WithFields(fields).Info("Optimizer run finished")
+}
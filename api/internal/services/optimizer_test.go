@@ -0,0 +1,197 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+// MockOptimizerRunRepository is a mock implementation of OptimizerRunRepository for testing
+type MockOptimizerRunRepository struct {
+	mock.Mock
+}
+
+func (m *MockOptimizerRunRepository) Create(run *models.OptimizerRun) (*models.OptimizerRun, error) {
+	args := m.Called(run)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	if fn, ok := args.Get(0).(func(*models.OptimizerRun) *models.OptimizerRun); ok {
+		return fn(run), args.Error(1)
+	}
+	return args.Get(0).(*models.OptimizerRun), args.Error(1)
+}
+
+func (m *MockOptimizerRunRepository) GetByID(id uuid.UUID) (*models.OptimizerRun, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OptimizerRun), args.Error(1)
+}
+
+func (m *MockOptimizerRunRepository) GetActiveByUser(userID uuid.UUID) (*models.OptimizerRun, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OptimizerRun), args.Error(1)
+}
+
+func (m *MockOptimizerRunRepository) Update(run *models.OptimizerRun) (*models.OptimizerRun, error) {
+	args := m.Called(run)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OptimizerRun), args.Error(1)
+}
+
+func (m *MockOptimizerRunRepository) Heartbeat(ids []uuid.UUID) error {
+	args := m.Called(ids)
+	return args.Error(0)
+}
+
+func (m *MockOptimizerRunRepository) FailStale(staleAfter time.Duration, reason string) (int, error) {
+	args := m.Called(staleAfter, reason)
+	return args.Int(0), args.Error(1)
+}
+
+// startOptimizerRun starts a run for user over logs, waits for it and returns the
+// run as last saved
+func startOptimizerRun(t *testing.T, user *models.User, logs []*models.ReviewLog) (*models.OptimizerRun, *MockUserRepository) {
+	t.Helper()
+
+	mockRunRepo := &MockOptimizerRunRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewOptimizerService(mockRunRepo, mockReviewLogRepo, mockUserRepo, testutils.TestLogger())
+	service.iterations = 10
+	service.minReviews = 100
+
+	var saved models.OptimizerRun
+	mockRunRepo.On("GetActiveByUser", user.ID).Return(nil, fmt.Errorf("optimizer run not found"))
+	mockRunRepo.On("Create", mock.AnythingOfType("*models.OptimizerRun")).Return(
+		func(run *models.OptimizerRun) *models.OptimizerRun { return run }, nil)
+	mockRunRepo.On("Update", mock.AnythingOfType("*models.OptimizerRun")).
+		Run(func(args mock.Arguments) { saved = *args.Get(0).(*models.OptimizerRun) }).
+		Return(&models.OptimizerRun{}, nil)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockUserRepo.On("Update", user.ID, mock.Anything).Return(user, nil)
+	mockReviewLogRepo.On("GetByUser", user.ID).Return(logs, nil)
+
+	run, err := service.Start(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OptimizerRunPending, run.Status)
+
+	service.wg.Wait()
+	return &saved, mockUserRepo
+}
+
+func TestOptimizerService_Start_FitsWeights(t *testing.T) {
+	user := &models.User{ID: uuid.New()}
+	logs := simulatedReviewLogs(DefaultFSRSParameters().Weights, 60, 4)
+
+	run, mockUserRepo := startOptimizerRun(t, user, logs)
+
+	require.Equal(t, models.OptimizerRunSucceeded, run.Status, "run error: %v", run.Error)
+	assert.Equal(t, 240, run.ReviewCount)
+	require.NotNil(t, run.LogLossBefore)
+	require.NotNil(t, run.LogLossAfter)
+	require.NotNil(t, run.RMSEBefore)
+	require.NotNil(t, run.RMSEAfter)
+	assert.LessOrEqual(t, *run.LogLossAfter, *run.LogLossBefore)
+	assert.Len(t, run.Weights, 17)
+	assert.NotNil(t, run.StartedAt)
+	assert.NotNil(t, run.FinishedAt)
+	mockUserRepo.AssertCalled(t, "Update", user.ID, map[string]any{"fsrs_weights": run.Weights})
+}
+
+func TestOptimizerService_Start_NotEnoughHistory(t *testing.T) {
+	user := &models.User{ID: uuid.New()}
+	logs := simulatedReviewLogs(DefaultFSRSParameters().Weights, 5, 4)
+
+	run, mockUserRepo := startOptimizerRun(t, user, logs)
+
+	assert.Equal(t, models.OptimizerRunFailed, run.Status)
+	require.NotNil(t, run.Error)
+	assert.Equal(t, "not enough review history: 20 of 100 reviews needed", *run.Error)
+	assert.Nil(t, run.Weights)
+	mockUserRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestOptimizerService_Start_AlreadyInProgress(t *testing.T) {
+	mockRunRepo := &MockOptimizerRunRepository{}
+	service := NewOptimizerService(mockRunRepo, &MockReviewLogRepository{}, &MockUserRepository{}, testutils.TestLogger())
+
+	userID := uuid.New()
+	mockRunRepo.On("GetActiveByUser", userID).Return(&models.OptimizerRun{ID: uuid.New(), UserID: userID}, nil)
+
+	run, err := service.Start(userID)
+
+	assert.Nil(t, run)
+	assert.EqualError(t, err, "optimizer run already in progress")
+	mockRunRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestOptimizerService_Start_ConcurrentStartLoses(t *testing.T) {
+	mockRunRepo := &MockOptimizerRunRepository{}
+	service := NewOptimizerService(mockRunRepo, &MockReviewLogRepository{}, &MockUserRepository{}, testutils.TestLogger())
+
+	userID := uuid.New()
+	mockRunRepo.On("GetActiveByUser", userID).Return(nil, fmt.Errorf("optimizer run not found"))
+	mockRunRepo.On("Create", mock.Anything).Return(nil, fmt.Errorf("optimizer run already in progress"))
+
+	run, err := service.Start(userID)
+
+	assert.Nil(t, run)
+	assert.EqualError(t, err, "optimizer run already in progress")
+	mockRunRepo.AssertExpectations(t)
+}
+
+func TestOptimizerService_GetByIDWithOwnership_Unauthorized(t *testing.T) {
+	mockRunRepo := &MockOptimizerRunRepository{}
+	service := NewOptimizerService(mockRunRepo, &MockReviewLogRepository{}, &MockUserRepository{}, testutils.TestLogger())
+
+	runID := uuid.New()
+	mockRunRepo.On("GetByID", runID).Return(&models.OptimizerRun{ID: runID, UserID: uuid.New()}, nil)
+
+	run, err := service.GetByIDWithOwnership(runID, uuid.New())
+
+	assert.Nil(t, run)
+	assert.EqualError(t, err, "unauthorized: optimizer run does not belong to user")
+}
+
+func TestOptimizerService_FailInterrupted_OnlyStaleRuns(t *testing.T) {
+	mockRunRepo := &MockOptimizerRunRepository{}
+	service := NewOptimizerService(mockRunRepo, &MockReviewLogRepository{}, &MockUserRepository{}, testutils.TestLogger())
+	service.heartbeatInterval = 30 * time.Second
+
+	mockRunRepo.On("FailStale", 2*time.Minute, mock.AnythingOfType("string")).Return(1, nil)
+
+	require.NoError(t, service.FailInterrupted())
+	mockRunRepo.AssertExpectations(t)
+}
+
+func TestOptimizerService_Heartbeat(t *testing.T) {
+	mockRunRepo := &MockOptimizerRunRepository{}
+	service := NewOptimizerService(mockRunRepo, &MockReviewLogRepository{}, &MockUserRepository{}, testutils.TestLogger())
+
+	// Nothing to refresh without runs
+	require.NoError(t, service.heartbeat())
+	mockRunRepo.AssertNotCalled(t, "Heartbeat", mock.Anything)
+
+	runID := uuid.New()
+	service.active[runID] = true
+	mockRunRepo.On("Heartbeat", []uuid.UUID{runID}).Return(nil)
+
+	require.NoError(t, service.heartbeat())
+	mockRunRepo.AssertExpectations(t)
+}
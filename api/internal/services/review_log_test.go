@@ -0,0 +1,262 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+// MockReviewLogRepository is a mock implementation of ReviewLogRepository for testing
+type MockReviewLogRepository struct {
+	mock.Mock
+}
+
+func (m *MockReviewLogRepository) Create(log *models.ReviewLog) (*models.ReviewLog, error) {
+	args := m.Called(log)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReviewLog), args.Error(1)
+}
+
+func (m *MockReviewLogRepository) GetByFlashcard(flashcardID uuid.UUID) ([]*models.ReviewLog, error) {
+	args := m.Called(flashcardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ReviewLog), args.Error(1)
+}
+
+func (m *MockReviewLogRepository) GetByUser(userID uuid.UUID) ([]*models.ReviewLog, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ReviewLog), args.Error(1)
+}
+
+func (m *MockReviewLogRepository) GetByUserSince(userID uuid.UUID, since time.Time) ([]*models.ReviewLog, error) {
+	args := m.Called(userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ReviewLog), args.Error(1)
+}
+
+func (m *MockReviewLogRepository) GetLatestByFlashcard(flashcardID uuid.UUID) (*models.ReviewLog, error) {
+	args := m.Called(flashcardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReviewLog), args.Error(1)
+}
+
+func (m *MockReviewLogRepository) CountUndoneBySession(sessionID uuid.UUID) (int, error) {
+	args := m.Called(sessionID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockReviewLogRepository) MarkUndone(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockReviewLogRepository) CountReviewOutcomes(userID uuid.UUID, since time.Time) ([]*models.ReviewOutcomeCount, error) {
+	args := m.Called(userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ReviewOutcomeCount), args.Error(1)
+}
+
+func (m *MockReviewLogRepository) CountStudiedSince(userID uuid.UUID, since time.Time) ([]*models.StudiedCount, error) {
+	args := m.Called(userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.StudiedCount), args.Error(1)
+}
+
+func TestFlashcardService_ReviewFlashcard_RecordsReviewLog(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+	service.config.IntervalFuzz = false // Keep the logged interval exact
+
+	cardID := uuid.New()
+	existingCard := &models.Flashcard{
+		ID:          cardID,
+		UserID:      uuid.New(),
+		DeckID:      uuid.New(),
+		Difficulty:  2.5,
+		Interval:    6,
+		EaseFactor:  2.5,
+		ReviewCount: 2,
+	}
+
+	mockRepo.On("GetByID", cardID).Return(existingCard, nil)
+	mockDeckRepo.On("GetByID", existingCard.DeckID).Return(&models.Deck{ID: existingCard.DeckID, Scheduler: models.SchedulerSM2}, nil)
+	mockUserRepo.On("GetByID", existingCard.UserID).Return(&models.User{ID: existingCard.UserID}, nil)
+	mockRepo.On("UpdateSchedulingState", cardID, mock.AnythingOfType("models.SchedulingState")).Return(existingCard, nil)
+	mockReviewLogRepo.On("Create", mock.MatchedBy(func(log *models.ReviewLog) bool {
+		return log.FlashcardID == cardID &&
+			log.UserID == existingCard.UserID &&
+			log.DeckID == existingCard.DeckID &&
+			log.Scheduler == models.SchedulerSM2 &&
+			log.Quality == 4 &&
+			log.TimeTakenMs == 3200 &&
+			log.StateBefore.Interval == 6 &&
+			log.StateBefore.ReviewCount == 2 &&
+			log.StateAfter.Interval == 15 &&
+			log.StateAfter.ReviewCount == 3
+	})).Return(&models.ReviewLog{}, nil)
+
+	_, err := service.ReviewFlashcard(cardID, &models.ReviewFlashcardRequest{Quality: 4, TimeTakenMs: 3200})
+
+	require.NoError(t, err)
+	mockReviewLogRepo.AssertExpectations(t)
+}
+
+func TestFlashcardService_GetReviewHistoryWithOwnership_Success(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	cardID := uuid.New()
+	userID := uuid.New()
+	logs := []*models.ReviewLog{
+		{ID: uuid.New(), FlashcardID: cardID, UserID: userID, Quality: 4},
+		{ID: uuid.New(), FlashcardID: cardID, UserID: userID, Quality: 2},
+	}
+
+	mockRepo.On("GetByID", cardID).Return(&models.Flashcard{ID: cardID, UserID: userID}, nil)
+	mockReviewLogRepo.On("GetByFlashcard", cardID).Return(logs, nil)
+
+	result, err := service.GetReviewHistoryWithOwnership(cardID, userID)
+
+	require.NoError(t, err)
+	assert.Len(t, result, 2)
+	mockReviewLogRepo.AssertExpectations(t)
+}
+
+func TestFlashcardService_GetReviewHistoryWithOwnership_Unauthorized(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	cardID := uuid.New()
+	mockRepo.On("GetByID", cardID).Return(&models.Flashcard{ID: cardID, UserID: uuid.New()}, nil)
+
+	result, err := service.GetReviewHistoryWithOwnership(cardID, uuid.New())
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "does not belong to user")
+	mockReviewLogRepo.AssertNotCalled(t, "GetByFlashcard")
+}
+
+func TestFlashcardService_UndoLastReviewWithOwnership_Success(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	cardID := uuid.New()
+	userID := uuid.New()
+	sessionID := uuid.New()
+	before := models.SchedulingState{Interval: 6, EaseFactor: 2.5, ReviewCount: 2}
+	lastReview := &models.ReviewLog{
+		ID:          uuid.New(),
+		FlashcardID: cardID,
+		SessionID:   &sessionID,
+		StateBefore: before,
+		StateAfter:  models.SchedulingState{Interval: 1, EaseFactor: 1.96, ReviewCount: 0},
+	}
+	restored := &models.Flashcard{ID: cardID, UserID: userID, Interval: 6, EaseFactor: 2.5, ReviewCount: 2}
+
+	mockRepo.On("GetByID", cardID).Return(&models.Flashcard{ID: cardID, UserID: userID}, nil)
+	mockReviewLogRepo.On("GetLatestByFlashcard", cardID).Return(lastReview, nil)
+	mockReviewLogRepo.On("CountUndoneBySession", sessionID).Return(0, nil)
+	mockRepo.On("UpdateSchedulingState", cardID, before).Return(restored, nil)
+	mockReviewLogRepo.On("MarkUndone", lastReview.ID).Return(nil)
+
+	result, err := service.UndoLastReviewWithOwnership(cardID, userID, sessionID)
+
+	require.NoError(t, err)
+	assert.Equal(t, 6, result.Interval)
+	assert.Equal(t, 2, result.ReviewCount)
+	mockRepo.AssertExpectations(t)
+	mockReviewLogRepo.AssertExpectations(t)
+}
+
+func TestFlashcardService_UndoLastReviewWithOwnership_LimitReached(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	cardID := uuid.New()
+	userID := uuid.New()
+	sessionID := uuid.New()
+
+	mockRepo.On("GetByID", cardID).Return(&models.Flashcard{ID: cardID, UserID: userID}, nil)
+	mockReviewLogRepo.On("GetLatestByFlashcard", cardID).Return(&models.ReviewLog{ID: uuid.New(), SessionID: &sessionID}, nil)
+	mockReviewLogRepo.On("CountUndoneBySession", sessionID).Return(service.undoLimit, nil)
+
+	result, err := service.UndoLastReviewWithOwnership(cardID, userID, sessionID)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "undo limit reached for this study session", err.Error())
+	mockRepo.AssertNotCalled(t, "UpdateSchedulingState")
+	mockReviewLogRepo.AssertNotCalled(t, "MarkUndone")
+}
+
+func TestFlashcardService_UndoLastReviewWithOwnership_OtherSession(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	cardID := uuid.New()
+	userID := uuid.New()
+	otherSession := uuid.New()
+
+	mockRepo.On("GetByID", cardID).Return(&models.Flashcard{ID: cardID, UserID: userID}, nil)
+	mockReviewLogRepo.On("GetLatestByFlashcard", cardID).Return(&models.ReviewLog{ID: uuid.New(), SessionID: &otherSession}, nil)
+
+	result, err := service.UndoLastReviewWithOwnership(cardID, userID, uuid.New())
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "last review does not belong to this study session", err.Error())
+	mockReviewLogRepo.AssertNotCalled(t, "CountUndoneBySession")
+}
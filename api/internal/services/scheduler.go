@@ -0,0 +1,233 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/utils"
+)
+
+// Scheduler computes the next scheduling state of a flashcard from a review grade.
+// Implementations must be pure: they never persist anything, so the same card
+// can be scheduled repeatedly (e.g. to preview every possible answer).
+type Scheduler interface {
+	// Name returns the identifier stored on decks (models.SchedulerSM2, models.SchedulerFSRS)
+	Name() string
+	// Schedule returns the state of the card after answering it with quality (0-5) at now
+	Schedule(state models.SchedulingState, quality int, now time.Time) models.SchedulingState
+}
+
+// Answer ratings, derived from the 0-5 review quality
+const (
+	ratingAgain = 1
+	ratingHard  = 2
+	ratingGood  = 3
+	ratingEasy  = 4
+)
+
+// SchedulerConfig holds the options shared by every scheduler
+type SchedulerConfig struct {
+	LearningSteps      []time.Duration // Same-day steps a new card goes through before graduating
+	RelearningSteps    []time.Duration // Same-day steps a lapsed card goes through before returning to review
+	GraduatingInterval int             // Days until the first review after passing the last learning step
+	EasyInterval       int             // Days until the first review when a learning card is answered easy
+	MinimumEase        float64         // Lowest ease factor SM-2 may reach
+	IntervalModifier   float64         // Multiplier applied to SM-2 review intervals
+	MaximumInterval    int             // Upper bound for review intervals, in days
+	IntervalFuzz       bool            // Spread review intervals over a few days around the computed one
+	LoadBalancing      bool            // Within the fuzz range, pick the day with the fewest reviews due
+	FSRSWeights        []float64       // Weights fitted to the user's reviews, nil for the FSRS defaults
+	DesiredRetention   float64         // Recall probability reviews are scheduled for, 0 for the FSRS default of 90%
+}
+
+// DefaultSchedulerConfig returns the built-in scheduler options
+func DefaultSchedulerConfig() SchedulerConfig {
+	return SchedulerConfig{
+		LearningSteps:      []time.Duration{time.Minute, 10 * time.Minute},
+		RelearningSteps:    []time.Duration{10 * time.Minute},
+		GraduatingInterval: 1,
+		EasyInterval:       4,
+		MinimumEase:        SM2MinEaseFactor,
+		IntervalModifier:   1.0,
+		MaximumInterval:    36500,
+		IntervalFuzz:       true,
+	}
+}
+
+// SchedulerConfigFromEnv returns the default scheduler options overridden by
+// LEARNING_STEPS, RELEARNING_STEPS, GRADUATING_INTERVAL, EASY_INTERVAL, INTERVAL_FUZZ
+// and LOAD_BALANCING
+func SchedulerConfigFromEnv(logger *logrus.Logger) SchedulerConfig {
+	config := DefaultSchedulerConfig()
+
+	if steps, err := ParseSteps(utils.GetEnvAsString("LEARNING_STEPS", "1m 10m")); err != nil {
+		logger.WithError(err).Warn("Invalid LEARNING_STEPS, using defaults")
+	} else {
+		config.LearningSteps = steps
+	}
+
+	if steps, err := ParseSteps(utils.GetEnvAsString("RELEARNING_STEPS", "10m")); err != nil {
+		logger.WithError(err).Warn("Invalid RELEARNING_STEPS, using defaults")
+	} else {
+		config.RelearningSteps = steps
+	}
+
+	config.GraduatingInterval = utils.GetEnvAsInt("GRADUATING_INTERVAL", config.GraduatingInterval)
+	config.EasyInterval = utils.GetEnvAsInt("EASY_INTERVAL", config.EasyInterval)
+	config.IntervalFuzz = utils.GetEnvAsBool("INTERVAL_FUZZ", config.IntervalFuzz)
+	config.LoadBalancing = utils.GetEnvAsBool("LOAD_BALANCING", config.LoadBalancing)
+
+	return config
+}
+
+// ParseSteps parses a space or comma separated list of steps such as "1m 10m 1h"
+func ParseSteps(value string) ([]time.Duration, error) {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+
+	steps := make([]time.Duration, 0, len(fields))
+	for _, field := range fields {
+		step, err := time.ParseDuration(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid step %q: %w", field, err)
+		}
+		if step <= 0 {
+			return nil, fmt.Errorf("invalid step %q: must be positive", field)
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// NewScheduler returns the scheduler registered under name.
+// An empty name selects the default SM-2 scheduler.
+func NewScheduler(name string, config SchedulerConfig) (Scheduler, error) {
+	switch name {
+	case "", models.SchedulerSM2:
+		return NewSM2Scheduler(config), nil
+	case models.SchedulerFSRS:
+		params := DefaultFSRSParameters()
+		if len(config.FSRSWeights) == len(params.Weights) {
+			params.Weights = config.FSRSWeights
+		}
+		if config.MaximumInterval > 0 {
+			params.MaximumInterval = config.MaximumInterval
+		}
+		if config.DesiredRetention > 0 {
+			params.RequestRetention = config.DesiredRetention
+		}
+		return NewFSRSScheduler(params, config), nil
+	default:
+		return nil, fmt.Errorf("unknown scheduler: %s", name)
+	}
+}
+
+// minimumEase returns the lowest ease factor allowed, defaulting to the SM-2 minimum
+func (c SchedulerConfig) minimumEase() float64 {
+	if c.MinimumEase > 0 {
+		return c.MinimumEase
+	}
+	return SM2MinEaseFactor
+}
+
+// reviewInterval applies the interval modifier and maximum interval to a computed interval in days
+func (c SchedulerConfig) reviewInterval(interval float64) int {
+	if c.IntervalModifier > 0 {
+		interval *= c.IntervalModifier
+	}
+	rounded := max(int(math.Round(interval)), 1)
+	if c.MaximumInterval > 0 {
+		rounded = min(rounded, c.MaximumInterval)
+	}
+	return rounded
+}
+
+// retentionInterval scales an interval that recall is expected to hold for at 90% so
+// the next review lands when recall drops to the desired retention instead. Both are
+// read off the FSRS forgetting curve, on which such an interval is the card's stability.
+func (c SchedulerConfig) retentionInterval(interval float64) int {
+	if c.DesiredRetention > 0 {
+		interval *= (math.Pow(c.DesiredRetention, 1/fsrsDecay) - 1) / fsrsFactor
+	}
+	rounded := max(int(math.Round(interval)), 1)
+	if c.MaximumInterval > 0 {
+		rounded = min(rounded, c.MaximumInterval)
+	}
+	return rounded
+}
+
+// stepsFor returns the learning or relearning steps a card is working through.
+// Cards in review, or in a state whose steps are not configured, get none.
+func (c SchedulerConfig) stepsFor(state models.SchedulingState) []time.Duration {
+	switch state.CardState() {
+	case models.CardStateNew, models.CardStateLearning:
+		return c.LearningSteps
+	case models.CardStateRelearning:
+		return c.RelearningSteps
+	default:
+		return nil
+	}
+}
+
+// learningStep moves a card through its steps. It returns the next step and the
+// delay until it is shown again, or graduated once the card leaves the steps.
+// Again restarts the steps, hard repeats the current one, good advances and easy
+// graduates immediately. steps must not be empty.
+func learningStep(steps []time.Duration, step int, rating int) (next int, delay time.Duration, graduated bool) {
+	// The steps may have been shortened since the card entered them
+	step = min(max(step, 0), len(steps)-1)
+
+	switch rating {
+	case ratingAgain:
+		return 0, steps[0], false
+	case ratingHard:
+		if step == 0 && len(steps) > 1 {
+			return 0, (steps[0] + steps[1]) / 2, false
+		}
+		if step == 0 {
+			return 0, min(steps[0]*3/2, steps[0]+days(1)), false
+		}
+		return step, steps[step], false
+	case ratingGood:
+		if step+1 < len(steps) {
+			return step + 1, steps[step+1], false
+		}
+		return 0, 0, true
+	default:
+		return 0, 0, true
+	}
+}
+
+// stepState returns the state a card stays in while it works through its steps
+func stepState(state models.SchedulingState) string {
+	if state.CardState() == models.CardStateRelearning {
+		return models.CardStateRelearning
+	}
+	return models.CardStateLearning
+}
+
+// ratingFromQuality maps SM-2 quality (0-5) onto the four answer ratings
+func ratingFromQuality(quality int) int {
+	switch {
+	case quality < 3:
+		return ratingAgain
+	case quality == 3:
+		return ratingHard
+	case quality == 4:
+		return ratingGood
+	default:
+		return ratingEasy
+	}
+}
+
+// days converts a whole number of days into a duration
+func days(n int) time.Duration {
+	return time.Hour * 24 * time.Duration(n)
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+func TestNewScheduler(t *testing.T) {
+	sm2, err := NewScheduler("", DefaultSchedulerConfig())
+	require.NoError(t, err)
+	assert.Equal(t, models.SchedulerSM2, sm2.Name())
+
+	fsrs, err := NewScheduler(models.SchedulerFSRS, DefaultSchedulerConfig())
+	require.NoError(t, err)
+	assert.Equal(t, models.SchedulerFSRS, fsrs.Name())
+
+	_, err = NewScheduler("leitner", DefaultSchedulerConfig())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown scheduler")
+}
+
+func TestSM2Scheduler_Schedule_FirstCorrectReview(t *testing.T) {
+	now := time.Now()
+	state := models.SchedulingState{Difficulty: 2.5, Interval: 1, EaseFactor: 2.5}
+
+	next := NewSM2Scheduler(SchedulerConfig{}).Schedule(state, 5, now)
+
+	assert.InDelta(t, 2.6, next.EaseFactor, 1e-9)
+	assert.InDelta(t, 2.6, next.Difficulty, 1e-9)
+	assert.Equal(t, 1, next.Interval)
+	assert.Equal(t, 1, next.ReviewCount)
+	require.NotNil(t, next.NextReview)
+	assert.Equal(t, now.Add(24*time.Hour), *next.NextReview)
+	require.NotNil(t, next.LastReview)
+	assert.Equal(t, now, *next.LastReview)
+}
+
+func TestSM2Scheduler_Schedule_Progression(t *testing.T) {
+	now := time.Now()
+	state := models.SchedulingState{Interval: 6, EaseFactor: 2.5, ReviewCount: 2}
+
+	next := NewSM2Scheduler(SchedulerConfig{}).Schedule(state, 4, now)
+
+	// EF stays at 2.5 for quality 4, interval = round(6 * 2.5)
+	assert.InDelta(t, 2.5, next.EaseFactor, 1e-9)
+	assert.Equal(t, 15, next.Interval)
+	assert.Equal(t, 3, next.ReviewCount)
+}
+
+func TestSM2Scheduler_Schedule_Failure(t *testing.T) {
+	now := time.Now()
+	state := models.SchedulingState{Interval: 15, EaseFactor: 1.4, ReviewCount: 3}
+
+	next := NewSM2Scheduler(SchedulerConfig{}).Schedule(state, 0, now)
+
+	assert.Equal(t, SM2MinEaseFactor, next.EaseFactor)
+	assert.Equal(t, 1, next.Interval)
+	assert.Equal(t, 0, next.ReviewCount)
+}
+
+func TestSM2Scheduler_Schedule_DesiredRetention(t *testing.T) {
+	now := time.Now()
+	second := models.SchedulingState{Interval: 1, EaseFactor: 2.5, ReviewCount: 1, State: models.CardStateReview}
+
+	interval := func(retention float64, state models.SchedulingState) int {
+		return NewSM2Scheduler(SchedulerConfig{DesiredRetention: retention}).Schedule(state, 4, now).Interval
+	}
+
+	// 90% is the retention plain SM-2 intervals are taken to hold
+	assert.Equal(t, 6, interval(0.9, second))
+	// Lower retention lets recall drop further before the review, higher retention less
+	assert.Equal(t, 10, interval(0.85, second))
+	assert.Equal(t, 2, interval(0.97, second))
+
+	// Later intervals grow from the previous one by ease, so the scaling is not applied twice
+	third := models.SchedulingState{Interval: 10, EaseFactor: 2.5, ReviewCount: 2, State: models.CardStateReview}
+	assert.Equal(t, 25, interval(0.85, third))
+}
+
+func TestFSRSScheduler_Schedule_DesiredRetention(t *testing.T) {
+	now := time.Now()
+	interval := func(retention float64) int {
+		scheduler, err := NewScheduler(models.SchedulerFSRS, SchedulerConfig{DesiredRetention: retention})
+		require.NoError(t, err)
+		return scheduler.Schedule(models.SchedulingState{}, 5, now).Interval
+	}
+
+	// Easy on a new card gives a stability of 13.8206 days
+	assert.Equal(t, 14, interval(0))
+	assert.Equal(t, 14, interval(0.9))
+	assert.Equal(t, 23, interval(0.85))
+	assert.Equal(t, 4, interval(0.97))
+}
+
+func TestParseSteps(t *testing.T) {
+	steps, err := ParseSteps("1m 10m, 1h")
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Minute, 10 * time.Minute, time.Hour}, steps)
+
+	steps, err = ParseSteps("")
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+
+	_, err = ParseSteps("1m ten")
+	assert.Error(t, err)
+
+	_, err = ParseSteps("-5m")
+	assert.Error(t, err)
+}
+
+func TestLearningStep(t *testing.T) {
+	steps := []time.Duration{time.Minute, 10 * time.Minute}
+
+	tests := []struct {
+		name      string
+		step      int
+		rating    int
+		wantStep  int
+		wantDelay time.Duration
+		graduated bool
+	}{
+		{"again restarts", 1, ratingAgain, 0, time.Minute, false},
+		{"hard on first step averages the first two", 0, ratingHard, 0, 330 * time.Second, false},
+		{"hard repeats later steps", 1, ratingHard, 1, 10 * time.Minute, false},
+		{"good advances", 0, ratingGood, 1, 10 * time.Minute, false},
+		{"good on last step graduates", 1, ratingGood, 0, 0, true},
+		{"easy graduates", 0, ratingEasy, 0, 0, true},
+		{"out of range step is clamped", 5, ratingHard, 1, 10 * time.Minute, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, delay, graduated := learningStep(steps, tt.step, tt.rating)
+			assert.Equal(t, tt.wantStep, step)
+			assert.Equal(t, tt.wantDelay, delay)
+			assert.Equal(t, tt.graduated, graduated)
+		})
+	}
+}
+
+func TestSM2Scheduler_Schedule_LearningSteps(t *testing.T) {
+	scheduler := NewSM2Scheduler(DefaultSchedulerConfig())
+	now := time.Now()
+	state := models.SchedulingState{Difficulty: 2.5, Interval: 1, EaseFactor: 2.5, State: models.CardStateNew}
+
+	// First step
+	first := scheduler.Schedule(state, 4, now)
+	assert.Equal(t, models.CardStateLearning, first.State)
+	assert.Equal(t, 1, first.Step)
+	assert.Equal(t, now.Add(10*time.Minute), *first.NextReview)
+	assert.Equal(t, 2.5, first.EaseFactor)
+	assert.Equal(t, 0, first.ReviewCount)
+
+	// Failing a step starts over
+	failed := scheduler.Schedule(first, 1, now)
+	assert.Equal(t, models.CardStateLearning, failed.State)
+	assert.Equal(t, 0, failed.Step)
+	assert.Equal(t, now.Add(time.Minute), *failed.NextReview)
+
+	// Passing the last step graduates with the graduating interval
+	graduated := scheduler.Schedule(first, 4, now)
+	assert.Equal(t, models.CardStateReview, graduated.State)
+	assert.Equal(t, 1, graduated.Interval)
+	assert.Equal(t, 1, graduated.ReviewCount)
+	assert.Equal(t, now.Add(24*time.Hour), *graduated.NextReview)
+
+	// Easy skips the remaining steps
+	easy := scheduler.Schedule(state, 5, now)
+	assert.Equal(t, models.CardStateReview, easy.State)
+	assert.Equal(t, 4, easy.Interval)
+}
+
+func TestSM2Scheduler_Schedule_LapseRelearning(t *testing.T) {
+	scheduler := NewSM2Scheduler(DefaultSchedulerConfig())
+	now := time.Now()
+	state := models.SchedulingState{Interval: 15, EaseFactor: 2.5, ReviewCount: 3, State: models.CardStateReview}
+
+	lapsed := scheduler.Schedule(state, 1, now)
+	assert.Equal(t, models.CardStateRelearning, lapsed.State)
+	assert.Equal(t, 0, lapsed.Step)
+	assert.Equal(t, 1, lapsed.Interval)
+	assert.Equal(t, 0, lapsed.ReviewCount)
+	assert.Less(t, lapsed.EaseFactor, state.EaseFactor)
+	assert.Equal(t, now.Add(10*time.Minute), *lapsed.NextReview)
+
+	relearned := scheduler.Schedule(lapsed, 4, now.Add(10*time.Minute))
+	assert.Equal(t, models.CardStateReview, relearned.State)
+	assert.Equal(t, 1, relearned.Interval)
+	assert.Equal(t, 1, relearned.ReviewCount)
+	assert.Equal(t, lapsed.EaseFactor, relearned.EaseFactor)
+}
+
+func TestFSRSScheduler_Schedule_LearningSteps(t *testing.T) {
+	scheduler := NewFSRSScheduler(DefaultFSRSParameters(), DefaultSchedulerConfig())
+	now := time.Now()
+
+	first := scheduler.Schedule(models.SchedulingState{State: models.CardStateNew}, 4, now)
+	assert.Equal(t, models.CardStateLearning, first.State)
+	assert.Equal(t, 1, first.Step)
+	assert.InDelta(t, 3.7145, first.Stability, 1e-9)
+	assert.Equal(t, now.Add(10*time.Minute), *first.NextReview)
+
+	// Same-day steps leave stability alone
+	graduated := scheduler.Schedule(first, 4, now.Add(10*time.Minute))
+	assert.Equal(t, models.CardStateReview, graduated.State)
+	assert.InDelta(t, first.Stability, graduated.Stability, 1e-9)
+	assert.Equal(t, 4, graduated.Interval)
+	assert.Equal(t, 1, graduated.ReviewCount)
+
+	lastReview := now.Add(-10 * 24 * time.Hour)
+	review := models.SchedulingState{Difficulty: 5, Stability: 10, Interval: 10, ReviewCount: 2, State: models.CardStateReview, LastReview: &lastReview}
+	lapsed := scheduler.Schedule(review, 1, now)
+	assert.Equal(t, models.CardStateRelearning, lapsed.State)
+	assert.Less(t, lapsed.Stability, review.Stability)
+	assert.Equal(t, now.Add(10*time.Minute), *lapsed.NextReview)
+}
+
+func TestFSRSScheduler_Schedule_NewCard(t *testing.T) {
+	now := time.Now()
+	scheduler := NewFSRSScheduler(DefaultFSRSParameters(), SchedulerConfig{})
+
+	good := scheduler.Schedule(models.SchedulingState{}, 4, now)
+	easy := scheduler.Schedule(models.SchedulingState{}, 5, now)
+
+	// Initial stability comes straight from the weights
+	assert.InDelta(t, 3.7145, good.Stability, 1e-9)
+	assert.InDelta(t, 13.8206, easy.Stability, 1e-9)
+	// At 90% requested retention the interval equals the stability
+	assert.Equal(t, 4, good.Interval)
+	assert.Equal(t, 14, easy.Interval)
+	assert.Less(t, easy.Difficulty, good.Difficulty)
+	assert.Equal(t, 1.0, good.Retrievability)
+	assert.Equal(t, 1, good.ReviewCount)
+}
+
+func TestFSRSScheduler_Schedule_RecallAndLapse(t *testing.T) {
+	scheduler := NewFSRSScheduler(DefaultFSRSParameters(), SchedulerConfig{})
+	lastReview := time.Now().Add(-10 * 24 * time.Hour)
+	now := time.Now()
+	state := models.SchedulingState{
+		Difficulty:  5,
+		Stability:   10,
+		Interval:    10,
+		ReviewCount: 2,
+		LastReview:  &lastReview,
+	}
+
+	recalled := scheduler.Schedule(state, 4, now)
+	forgotten := scheduler.Schedule(state, 1, now)
+
+	assert.InDelta(t, 0.9, recalled.Retrievability, 1e-3)
+	assert.Greater(t, recalled.Stability, state.Stability)
+	assert.Greater(t, recalled.Interval, state.Interval)
+	assert.Equal(t, 3, recalled.ReviewCount)
+
+	assert.Less(t, forgotten.Stability, state.Stability)
+	assert.Greater(t, forgotten.Difficulty, state.Difficulty)
+	assert.Equal(t, 0, forgotten.ReviewCount)
+}
+
+func TestFlashcardService_ReviewFlashcard_UsesDeckScheduler(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	cardID := uuid.New()
+	deckID := uuid.New()
+	existingCard := &models.Flashcard{
+		ID:         cardID,
+		DeckID:     deckID,
+		Difficulty: 2.5,
+		Interval:   1,
+		EaseFactor: 2.5,
+	}
+
+	mockRepo.On("GetByID", cardID).Return(existingCard, nil)
+	mockDeckRepo.On("GetByID", deckID).Return(&models.Deck{ID: deckID, Scheduler: models.SchedulerFSRS}, nil)
+	mockUserRepo.On("GetByID", existingCard.UserID).Return(&models.User{ID: existingCard.UserID}, nil)
+	mockRepo.On("UpdateSchedulingState", cardID, mock.MatchedBy(func(state models.SchedulingState) bool {
+		// FSRS initialises stability from the weights on the first review,
+		// while the card moves on to the second learning step
+		return state.Stability > 0 && state.State == models.CardStateLearning && state.Step == 1
+	})).Return(existingCard, nil)
+	mockReviewLogRepo.On("Create", mock.AnythingOfType("*models.ReviewLog")).Return(&models.ReviewLog{}, nil)
+
+	_, err := service.ReviewFlashcard(cardID, &models.ReviewFlashcardRequest{Quality: 4})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockDeckRepo.AssertExpectations(t)
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+// noteSibling returns a card of the same note as card
+func noteSibling(card *models.Flashcard, ord int, state string) *models.Flashcard {
+	sibling := &models.Flashcard{
+		ID:         uuid.New(),
+		UserID:     card.UserID,
+		DeckID:     card.DeckID,
+		NoteID:     card.NoteID,
+		Ord:        &ord,
+		EaseFactor: 2.5,
+		State:      state,
+	}
+	if state != models.CardStateNew {
+		due := time.Now().Add(-time.Hour)
+		sibling.NextReview = &due
+	}
+	return sibling
+}
+
+func TestFlashcardService_ReviewFlashcard_BuriesSiblings(t *testing.T) {
+	card := reviewCard(0)
+	noteID := uuid.New()
+	ord := 0
+	card.NoteID, card.Ord = &noteID, &ord
+	preset := testutils.CreateTestDeckPreset(card.UserID)
+	preset.BurySiblings = true
+
+	newSibling := noteSibling(card, 1, models.CardStateNew)
+	reviewSibling := noteSibling(card, 2, models.CardStateReview)
+	learningSibling := noteSibling(card, 3, models.CardStateLearning)
+	suspendedSibling := noteSibling(card, 4, models.CardStateReview)
+	suspendedSibling.Suspended = true
+
+	var buried []uuid.UUID
+	var until *time.Time
+	_, mockRepo := reviewCardWithPreset(t, card, preset, 4, func(mockRepo *MockFlashcardRepository) {
+		mockRepo.On("GetByNote", noteID).Return([]*models.Flashcard{card, newSibling, reviewSibling, learningSibling, suspendedSibling}, nil)
+		mockRepo.On("SetBuriedUntil", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				buried = args.Get(0).([]uuid.UUID)
+				until = args.Get(1).(*time.Time)
+			}).
+			Return([]*models.Flashcard{}, nil)
+	})
+
+	mockRepo.AssertCalled(t, "SetBuriedUntil", mock.Anything, mock.Anything)
+	assert.ElementsMatch(t, []uuid.UUID{newSibling.ID, reviewSibling.ID}, buried)
+	require.NotNil(t, until)
+	assert.True(t, until.After(time.Now()))
+	assert.True(t, until.Before(time.Now().Add(24*time.Hour+time.Minute)))
+}
+
+func TestFlashcardService_ReviewFlashcard_SiblingsNotBuriedByDefault(t *testing.T) {
+	card := reviewCard(0)
+	noteID := uuid.New()
+	card.NoteID = &noteID
+	preset := testutils.CreateTestDeckPreset(card.UserID)
+
+	_, mockRepo := reviewCardWithPreset(t, card, preset, 4)
+
+	mockRepo.AssertNotCalled(t, "GetByNote", mock.Anything)
+	mockRepo.AssertNotCalled(t, "SetBuriedUntil", mock.Anything, mock.Anything)
+}
+
+func TestFlashcardService_UndoLastReviewWithOwnership_UnburiesSiblings(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	service := NewFlashcardService(mockRepo, &MockDeckRepository{}, &MockDeckPresetRepository{}, mockReviewLogRepo, &MockUserRepository{}, logger)
+
+	cardID := uuid.New()
+	userID := uuid.New()
+	sessionID := uuid.New()
+	siblingIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	before := models.SchedulingState{Interval: 6, EaseFactor: 2.5, ReviewCount: 2, State: models.CardStateReview}
+	lastReview := &models.ReviewLog{
+		ID:               uuid.New(),
+		FlashcardID:      cardID,
+		SessionID:        &sessionID,
+		StateBefore:      before,
+		StateAfter:       models.SchedulingState{Interval: 15, EaseFactor: 2.5, ReviewCount: 3, State: models.CardStateReview},
+		BuriedSiblingIDs: siblingIDs,
+	}
+
+	mockRepo.On("GetByID", cardID).Return(&models.Flashcard{ID: cardID, UserID: userID}, nil)
+	mockReviewLogRepo.On("GetLatestByFlashcard", cardID).Return(lastReview, nil)
+	mockReviewLogRepo.On("CountUndoneBySession", sessionID).Return(0, nil)
+	mockRepo.On("UpdateSchedulingState", cardID, before).Return(&models.Flashcard{ID: cardID, UserID: userID, Interval: 6}, nil)
+	mockRepo.On("SetBuriedUntil", siblingIDs, (*time.Time)(nil)).Return([]*models.Flashcard{}, nil)
+	mockReviewLogRepo.On("MarkUndone", lastReview.ID).Return(nil)
+
+	_, err := service.UndoLastReviewWithOwnership(cardID, userID, sessionID)
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFlashcardService_GetDueCards_OneCardPerNote(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	preset := testutils.CreateTestDeckPreset(userID)
+	preset.BurySiblings = true
+	burying := testutils.CreateTestDeck(userID)
+	burying.PresetID = &preset.ID
+	plain := testutils.CreateTestDeck(userID)
+
+	mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+	mockDeckRepo.On("GetByUser", userID).Return([]*models.Deck{burying, plain}, nil)
+	mockPresetRepo.On("GetByUser", userID).Return([]*models.DeckPreset{preset}, nil)
+	mockReviewLogRepo.On("CountStudiedSince", userID, mock.AnythingOfType("time.Time")).Return([]*models.StudiedCount{}, nil)
+
+	noteID, plainNoteID := uuid.New(), uuid.New()
+	forward := &models.Flashcard{ID: uuid.New(), UserID: userID, DeckID: burying.ID, NoteID: &noteID, State: models.CardStateNew}
+	reverse := noteSibling(forward, 1, models.CardStateNew)
+	learning := noteSibling(forward, 2, models.CardStateLearning)
+	plainForward := &models.Flashcard{ID: uuid.New(), UserID: userID, DeckID: plain.ID, NoteID: &plainNoteID, State: models.CardStateNew}
+	plainReverse := noteSibling(plainForward, 1, models.CardStateNew)
+	mockRepo.On("GetByUser", userID).Return([]*models.Flashcard{forward, reverse, learning, plainForward, plainReverse}, nil)
+
+	result, err := service.GetDueCards(userID)
+
+	require.NoError(t, err)
+	var ids []uuid.UUID
+	for _, card := range result.Cards {
+		ids = append(ids, card.ID)
+	}
+	// The learning card comes first, so neither new sibling of the burying deck is shown
+	assert.Equal(t, []uuid.UUID{learning.ID, plainForward.ID, plainReverse.ID}, ids)
+}
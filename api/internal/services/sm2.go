@@ -0,0 +1,127 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"swipelearn-api/internal/models"
+)
+
+// SM2MinEaseFactor is the lowest ease factor SM-2 allows
+const SM2MinEaseFactor = 1.3
+
+// SM2Scheduler implements the classic SuperMemo-2 algorithm, with learning
+// and relearning steps in front of the day-based reviews
+type SM2Scheduler struct {
+	config SchedulerConfig
+}
+
+func NewSM2Scheduler(config SchedulerConfig) *SM2Scheduler {
+	return &SM2Scheduler{config: config}
+}
+
+// Name returns the scheduler identifier
+func (s *SM2Scheduler) Name() string {
+	return models.SchedulerSM2
+}
+
+// Schedule moves learning cards through their steps and applies the SM-2 formula to review cards
+func (s *SM2Scheduler) Schedule(state models.SchedulingState, quality int, now time.Time) models.SchedulingState {
+	if steps := s.config.stepsFor(state); len(steps) > 0 {
+		return s.scheduleStep(state, steps, ratingFromQuality(quality), now)
+	}
+	return s.scheduleReview(state, quality, now)
+}
+
+// scheduleStep handles an answer to a card in learning or relearning.
+// The ease factor is left alone until the card is reviewed on a later day.
+func (s *SM2Scheduler) scheduleStep(state models.SchedulingState, steps []time.Duration, rating int, now time.Time) models.SchedulingState {
+	lastReview := now
+	next := state
+	next.LastReview = &lastReview
+
+	step, delay, graduated := learningStep(steps, state.Step, rating)
+	if !graduated {
+		nextReview := now.Add(delay)
+		next.State = stepState(state)
+		next.Step = step
+		next.NextReview = &nextReview
+		return next
+	}
+
+	interval := s.config.GraduatingInterval
+	switch {
+	case state.CardState() == models.CardStateRelearning:
+		// Lapsed cards return to the interval set when they were failed
+		interval = state.Interval
+	case rating == ratingEasy:
+		interval = s.config.EasyInterval
+	}
+	interval = max(interval, 1)
+
+	// Graduating counts as the first successful repetition
+	nextReview := now.Add(days(interval))
+	next.State = models.CardStateReview
+	next.Step = 0
+	next.Interval = interval
+	next.ReviewCount = 1
+	next.NextReview = &nextReview
+	return next
+}
+
+// scheduleReview applies the SM-2 formula to the card state
+func (s *SM2Scheduler) scheduleReview(state models.SchedulingState, quality int, now time.Time) models.SchedulingState {
+	q := float64(quality)
+
+	// Correct SM-2 ease factor formula:
+	// EF' = EF + (0.1 - (5-q) * (0.08 + (5-q) * 0.02))
+	newEaseFactor := state.EaseFactor + (0.1 - (5.0-q)*(0.08+(5.0-q)*0.02))
+
+	// Enforce the minimum ease factor (1.3 unless the deck preset says otherwise)
+	newEaseFactor = math.Max(s.config.minimumEase(), newEaseFactor)
+
+	var newInterval int
+	var newRepetitions int
+
+	if q < 3 {
+		// Incorrect response (quality 0, 1, or 2), reset interval and repetitions
+		newInterval = 1
+		newRepetitions = 0
+	} else {
+		// Correct response (quality 3, 4, or 5)
+		newRepetitions = state.ReviewCount + 1
+
+		// Calculate new interval based on repetitions. The first two intervals are the
+		// SM-2 ones, moved to the desired retention; later ones grow from them by ease.
+		switch newRepetitions {
+		case 1:
+			newInterval = s.config.retentionInterval(1)
+		case 2:
+			newInterval = s.config.retentionInterval(6)
+		default:
+			newInterval = s.config.reviewInterval(float64(state.Interval) * newEaseFactor)
+		}
+	}
+
+	nextReview := now.Add(days(newInterval))
+	lastReview := now
+
+	next := state
+	next.Difficulty = newEaseFactor
+	next.EaseFactor = newEaseFactor
+	next.Interval = newInterval
+	next.ReviewCount = newRepetitions
+	next.State = models.CardStateReview
+	next.Step = 0
+	next.LastReview = &lastReview
+	next.NextReview = &nextReview
+
+	// A lapse sends the card through the relearning steps before the 1 day interval
+	if q < 3 && state.CardState() == models.CardStateReview && len(s.config.RelearningSteps) > 0 {
+		relearnAt := now.Add(s.config.RelearningSteps[0])
+		next.State = models.CardStateRelearning
+		next.NextReview = &relearnAt
+	}
+
+	return next
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/repositories"
+	"swipelearn-api/internal/utils"
+)
+
+// Defaults used when a forecast has little to go on
+const (
+	defaultForecastDays      = 30
+	defaultForecastRetention = 0.9
+	minRetentionReviews      = 20 // Answers needed before the user's own retention is trusted
+	retentionWindowDays      = 30 // How far back the user's retention is measured
+)
+
+// A forecast simulates each card once per run, so its cost grows with the cards times
+// the runs. Long horizons with many new cards get fewer runs to stay within the budget.
+const (
+	forecastCardBudget     = 1_000_000 // Cards simulated across all runs of a forecast
+	minForecastSimulations = 10        // Runs a forecast never goes below, so percentiles stay meaningful
+)
+
+type StatsService struct {
+	flashcardRepo    repositories.FlashcardRepositoryInterface
+	deckRepo         repositories.DeckRepositoryInterface
+	reviewLogRepo    repositories.ReviewLogRepositoryInterface
+	userRepo         repositories.UserRepositoryInterface
+	flashcardService *FlashcardService
+	simulations      int // Monte Carlo runs per forecast
+	Logger           *logrus.Logger
+}
+
+func NewStatsService(
+	flashcardRepo repositories.FlashcardRepositoryInterface,
+	deckRepo repositories.DeckRepositoryInterface,
+	reviewLogRepo repositories.ReviewLogRepositoryInterface,
+	userRepo repositories.UserRepositoryInterface,
+	flashcardService *FlashcardService,
+	logger *logrus.Logger,
+) *StatsService {
+	return &StatsService{
+		flashcardRepo:    flashcardRepo,
+		deckRepo:         deckRepo,
+		reviewLogRepo:    reviewLogRepo,
+		userRepo:         userRepo,
+		flashcardService: flashcardService,
+		simulations:      max(1, utils.GetEnvAsInt("FORECAST_SIMULATIONS", 100)),
+		Logger:           logger,
+	}
+}
+
+// Forecast projects the daily review workload of a user over the coming days, in all
+// decks or in one. The same cards and history always give the same forecast. It stops
+// early with ctx's error once ctx is done.
+func (s *StatsService) Forecast(ctx context.Context, userID uuid.UUID, req *models.ForecastRequest) (*models.Forecast, error) {
+	days := req.Days
+	if days == 0 {
+		days = defaultForecastDays
+	}
+	if req.NewPerDay > 0 && req.DeckID == nil {
+		return nil, fmt.Errorf("deck_id is required to add new cards")
+	}
+
+	if req.DeckID != nil {
+		deck, err := s.deckRepo.GetByID(*req.DeckID)
+		if err != nil {
+			return nil, fmt.Errorf("deck not found")
+		}
+		if deck.UserID != userID {
+			return nil, fmt.Errorf("unauthorized: deck does not belong to user")
+		}
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	planners, err := s.deckPlanners(user)
+	if err != nil {
+		return nil, err
+	}
+
+	flashcards, err := s.flashcardRepo.GetByUser(userID)
+	if err != nil {
+		s.Logger.WithError(err).WithField("user_id", userID).Error("Service failed to get flashcards for forecast")
+		return nil, fmt.Errorf("failed to get flashcards: %w", err)
+	}
+
+	retention, err := s.recentRetention(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	clock := studyClockForUser(user)
+	simulation := &workloadSimulation{
+		clock:     clock,
+		today:     clock.dayStart(now),
+		days:      days,
+		retention: retention,
+		newPerDay: req.NewPerDay,
+	}
+
+	for _, card := range flashcards {
+		// Cards in a filtered deck are forecast with their home deck
+		if card.Suspended || (req.DeckID != nil && card.HomeDeck() != *req.DeckID) {
+			continue
+		}
+		planner, ok := planners[card.HomeDeck()]
+		if !ok {
+			continue
+		}
+		simulation.cards = append(simulation.cards, forecastCard{planner: planner, state: card.SchedulingState()})
+	}
+
+	if req.NewPerDay > 0 {
+		simulation.newPlanner = planners[*req.DeckID]
+		simulation.startingEase, err = s.flashcardService.startingEase(*req.DeckID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	runs := make([][]int, s.forecastSimulations(len(simulation.cards)+days*req.NewPerDay))
+	for i := range runs {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("forecast canceled: %w", err)
+		}
+		runs[i] = simulation.run(forecastSeed(userID, i))
+	}
+
+	forecast := &models.Forecast{
+		Days:        days,
+		NewPerDay:   req.NewPerDay,
+		DeckID:      req.DeckID,
+		Simulations: len(runs),
+		Retention:   retention,
+		Daily:       forecastDays(simulation.today, simulation.scheduled(), runs, req.NewPerDay),
+	}
+	for _, day := range forecast.Daily {
+		forecast.TotalExpectedReviews += day.ExpectedReviews
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"user_id":     userID,
+		"days":        days,
+		"card_count":  len(simulation.cards),
+		"new_per_day": req.NewPerDay,
+		"simulations": len(runs),
+	}).Info("Computed review forecast")
+
+	return forecast, nil
+}
+
+// forecastSimulations returns how many runs a forecast simulating cards cards per run
+// makes: the configured number, fewer if that would go over the card budget
+func (s *StatsService) forecastSimulations(cards int) int {
+	return max(min(s.simulations, forecastCardBudget/max(cards, 1)), min(s.simulations, minForecastSimulations))
+}
+
+// Retention reports, per deck and overall, how often the user recalled cards in review
+// over the last days against the retention their decks schedule for
+func (s *StatsService) Retention(userID uuid.UUID, req *models.RetentionRequest) (*models.RetentionStats, error) {
+	days := req.Days
+	if days == 0 {
+		days = retentionWindowDays
+	}
+
+	decks, err := s.deckRepo.GetByUser(userID)
+	if err != nil {
+		s.Logger.WithError(err).WithField("user_id", userID).Error("Service failed to get decks for retention")
+		return nil, fmt.Errorf("failed to get decks: %w", err)
+	}
+
+	outcomes, err := s.reviewLogRepo.CountReviewOutcomes(userID, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count review outcomes: %w", err)
+	}
+	byDeck := make(map[uuid.UUID]*models.ReviewOutcomeCount, len(outcomes))
+	for _, outcome := range outcomes {
+		byDeck[outcome.DeckID] = outcome
+	}
+
+	stats := &models.RetentionStats{
+		Days:  days,
+		Decks: make([]models.DeckRetention, 0, len(decks)),
+	}
+	var weightedTarget float64
+	for _, deck := range decks {
+		// Reviews in filtered decks are logged against the home deck
+		if deck.Filter != nil {
+			continue
+		}
+		retention := models.DeckRetention{
+			DeckID:          deck.ID,
+			DeckName:        deck.Name,
+			Scheduler:       deck.Scheduler,
+			TargetRetention: deck.DesiredRetention,
+		}
+		if outcome, ok := byDeck[deck.ID]; ok {
+			retention.Reviews = outcome.Reviews
+			retention.Recalled = outcome.Recalled
+			retention.ActualRetention = recallRate(outcome.Reviews, outcome.Recalled)
+		}
+
+		stats.Reviews += retention.Reviews
+		stats.Recalled += retention.Recalled
+		weightedTarget += deck.DesiredRetention * float64(retention.Reviews)
+		stats.Decks = append(stats.Decks, retention)
+	}
+
+	stats.ActualRetention = recallRate(stats.Reviews, stats.Recalled)
+	if stats.Reviews > 0 {
+		target := weightedTarget / float64(stats.Reviews)
+		stats.TargetRetention = &target
+	}
+
+	return stats, nil
+}
+
+// recallRate returns the share of reviews that were recalled, or nil without reviews
+func recallRate(reviews, recalled int) *float64 {
+	if reviews == 0 {
+		return nil
+	}
+	rate := float64(recalled) / float64(reviews)
+	return &rate
+}
+
+// deckPlanners returns the review planner of every deck of a user
+func (s *StatsService) deckPlanners(user *models.User) (map[uuid.UUID]*reviewPlanner, error) {
+	decks, err := s.deckRepo.GetByUser(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decks: %w", err)
+	}
+
+	planners := make(map[uuid.UUID]*reviewPlanner, len(decks))
+	for _, deck := range decks {
+		planner, err := s.flashcardService.deckPlanner(deck, user)
+		if err != nil {
+			return nil, err
+		}
+		planners[deck.ID] = planner
+	}
+
+	return planners, nil
+}
+
+// recentRetention returns the share of reviews the user recalled recently, or the
+// default retention when there are too few to go on
+func (s *StatsService) recentRetention(userID uuid.UUID) (float64, error) {
+	outcomes, err := s.reviewLogRepo.CountReviewOutcomes(userID, time.Now().AddDate(0, 0, -retentionWindowDays))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count review outcomes: %w", err)
+	}
+
+	var reviews, recalled int
+	for _, outcome := range outcomes {
+		reviews += outcome.Reviews
+		recalled += outcome.Recalled
+	}
+	if reviews < minRetentionReviews {
+		return defaultForecastRetention, nil
+	}
+
+	return float64(recalled) / float64(reviews), nil
+}
+
+// forecastSeed returns the random source of one simulation run of a user's forecast
+func forecastSeed(userID uuid.UUID, run int) *rand.Rand {
+	return rand.New(rand.NewPCG(binary.BigEndian.Uint64(userID[:8]), binary.BigEndian.Uint64(userID[8:])^uint64(run)))
+}
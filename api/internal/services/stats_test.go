@@ -0,0 +1,75 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+func TestStatsService_Retention(t *testing.T) {
+	deck := testutils.CreateTestDeck(uuid.New())
+	deck.DesiredRetention = 0.85
+	outcomes := []*models.ReviewOutcomeCount{{DeckID: deck.ID, Reviews: 40, Recalled: 30}}
+	service := newTestStatsService(deck, nil, outcomes)
+
+	stats, err := service.Retention(deck.UserID, &models.RetentionRequest{})
+
+	require.NoError(t, err)
+	assert.Equal(t, retentionWindowDays, stats.Days)
+	require.Len(t, stats.Decks, 1)
+	assert.Equal(t, deck.ID, stats.Decks[0].DeckID)
+	assert.Equal(t, 0.85, stats.Decks[0].TargetRetention)
+	require.NotNil(t, stats.Decks[0].ActualRetention)
+	assert.InDelta(t, 0.75, *stats.Decks[0].ActualRetention, 1e-9)
+	assert.Equal(t, 40, stats.Reviews)
+	require.NotNil(t, stats.TargetRetention)
+	assert.InDelta(t, 0.85, *stats.TargetRetention, 1e-9)
+}
+
+func TestStatsService_Retention_WeightsTargetByReviews(t *testing.T) {
+	userID := uuid.New()
+	strict := testutils.CreateTestDeck(userID)
+	strict.DesiredRetention = 0.95
+	relaxed := testutils.CreateTestDeck(userID)
+	relaxed.DesiredRetention = 0.85
+	unused := testutils.CreateTestDeck(userID)
+
+	mockDeckRepo := &MockDeckRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	service := NewStatsService(&MockFlashcardRepository{}, mockDeckRepo, mockReviewLogRepo, &MockUserRepository{}, nil, testutils.TestLogger())
+
+	mockDeckRepo.On("GetByUser", userID).Return([]*models.Deck{strict, relaxed, unused}, nil)
+	mockReviewLogRepo.On("CountReviewOutcomes", userID, mock.AnythingOfType("time.Time")).Return([]*models.ReviewOutcomeCount{
+		{DeckID: strict.ID, Reviews: 10, Recalled: 9},
+		{DeckID: relaxed.ID, Reviews: 30, Recalled: 27},
+	}, nil)
+
+	stats, err := service.Retention(userID, &models.RetentionRequest{Days: 7})
+
+	require.NoError(t, err)
+	assert.Equal(t, 7, stats.Days)
+	require.Len(t, stats.Decks, 3)
+	assert.Nil(t, stats.Decks[2].ActualRetention, "a deck without reviews has no actual retention")
+	require.NotNil(t, stats.ActualRetention)
+	assert.InDelta(t, 0.9, *stats.ActualRetention, 1e-9)
+	require.NotNil(t, stats.TargetRetention)
+	assert.InDelta(t, (0.95*10+0.85*30)/40, *stats.TargetRetention, 1e-9)
+}
+
+func TestStatsService_Retention_NoReviews(t *testing.T) {
+	deck := testutils.CreateTestDeck(uuid.New())
+	service := newTestStatsService(deck, nil, nil)
+
+	stats, err := service.Retention(deck.UserID, &models.RetentionRequest{})
+
+	require.NoError(t, err)
+	assert.Nil(t, stats.ActualRetention)
+	assert.Nil(t, stats.TargetRetention)
+	assert.Equal(t, models.DefaultDesiredRetention, stats.Decks[0].TargetRetention)
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"time"
+
+	"swipelearn-api/internal/models"
+)
+
+// studyClock places instants on a user's study days. A study day runs from the
+// user's day start hour in their timezone until the same hour the next day.
+type studyClock struct {
+	location     *time.Location
+	dayStartHour int
+}
+
+// studyClockForUser returns the study clock for a user, falling back to UTC
+// when the stored timezone is missing or unknown
+func studyClockForUser(user *models.User) studyClock {
+	location, err := time.LoadLocation(user.Timezone)
+	if user.Timezone == "" || err != nil {
+		location = time.UTC
+	}
+	return studyClock{location: location, dayStartHour: user.DayStartHour}
+}
+
+// dayStart returns the start of the study day containing now
+func (c studyClock) dayStart(now time.Time) time.Time {
+	local := now.In(c.location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), c.dayStartHour, 0, 0, 0, c.location)
+	if local.Before(start) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start
+}
+
+// nextDayStart returns the start of the study day after the one containing now
+func (c studyClock) nextDayStart(now time.Time) time.Time {
+	return c.dayStart(now).AddDate(0, 0, 1)
+}
+
+// alignToStudyDay moves a day-based review onto the start of the study day it
+// falls due, so a card scheduled "in 3 days" becomes due when that day begins
+// for the user rather than at the exact time of day it was answered
+func (c studyClock) alignToStudyDay(state *models.SchedulingState, now time.Time) {
+	if state.CardState() != models.CardStateReview || state.Interval <= 0 {
+		return
+	}
+	due := c.dayStart(now).AddDate(0, 0, state.Interval)
+	state.NextReview = &due
+}
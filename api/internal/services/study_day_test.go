@@ -0,0 +1,173 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+func TestStudyClock_DayStart(t *testing.T) {
+	clock := studyClockForUser(&models.User{Timezone: "America/Los_Angeles", DayStartHour: 4})
+	la := clock.location
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected time.Time
+	}{
+		{"evening", time.Date(2024, 3, 1, 22, 0, 0, 0, la), time.Date(2024, 3, 1, 4, 0, 0, 0, la)},
+		{"after midnight before day start", time.Date(2024, 3, 2, 2, 0, 0, 0, la), time.Date(2024, 3, 1, 4, 0, 0, 0, la)},
+		{"at day start", time.Date(2024, 3, 2, 4, 0, 0, 0, la), time.Date(2024, 3, 2, 4, 0, 0, 0, la)},
+		{"server clock in UTC", time.Date(2024, 3, 2, 6, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 4, 0, 0, 0, la)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.expected.Equal(clock.dayStart(tt.now)), "got %s", clock.dayStart(tt.now))
+			assert.True(t, tt.expected.AddDate(0, 0, 1).Equal(clock.nextDayStart(tt.now)))
+		})
+	}
+}
+
+func TestStudyClockForUser_UnknownTimezone(t *testing.T) {
+	clock := studyClockForUser(&models.User{Timezone: "Not/AZone"})
+	assert.Equal(t, time.UTC, clock.location)
+
+	now := time.Date(2024, 3, 1, 15, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), clock.dayStart(now))
+}
+
+func TestStudyClock_AlignToStudyDay(t *testing.T) {
+	clock := studyClockForUser(&models.User{Timezone: "America/Los_Angeles", DayStartHour: 4})
+	now := time.Date(2024, 3, 1, 22, 15, 0, 0, clock.location)
+
+	review := models.SchedulingState{State: models.CardStateReview, Interval: 3}
+	clock.alignToStudyDay(&review, now)
+	require.NotNil(t, review.NextReview)
+	assert.True(t, time.Date(2024, 3, 4, 4, 0, 0, 0, clock.location).Equal(*review.NextReview))
+
+	// Learning steps keep their exact due time
+	stepDue := now.Add(10 * time.Minute)
+	learning := models.SchedulingState{State: models.CardStateLearning, NextReview: &stepDue}
+	clock.alignToStudyDay(&learning, now)
+	assert.Equal(t, &stepDue, learning.NextReview)
+}
+
+func TestFlashcardService_GetDueCards_ReviewsDueForWholeStudyDay(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	user := &models.User{ID: uuid.New(), Timezone: "Pacific/Kiritimati", DayStartHour: 4}
+	deckID := uuid.New()
+	mockStudyDay(mockUserRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, user, []*models.Deck{{ID: deckID, UserID: user.ID}}, nil)
+
+	now := time.Now()
+	clock := studyClockForUser(user)
+	laterToday := clock.nextDayStart(now).Add(-time.Minute)
+	tomorrow := clock.nextDayStart(now).Add(time.Minute)
+	learningLater := now.Add(time.Minute)
+
+	cards := []*models.Flashcard{
+		{ID: uuid.New(), UserID: user.ID, DeckID: deckID, Front: "Later Today", State: models.CardStateReview, NextReview: &laterToday},
+		{ID: uuid.New(), UserID: user.ID, DeckID: deckID, Front: "Tomorrow", State: models.CardStateReview, NextReview: &tomorrow},
+		{ID: uuid.New(), UserID: user.ID, DeckID: deckID, Front: "Learning Later", State: models.CardStateLearning, NextReview: &learningLater},
+	}
+	mockRepo.On("GetByUser", user.ID).Return(cards, nil)
+
+	result, err := service.GetDueCards(user.ID)
+
+	require.NoError(t, err)
+	require.Len(t, result.Cards, 1)
+	assert.Equal(t, "Later Today", result.Cards[0].Front)
+	mockReviewLogRepo.AssertCalled(t, "CountStudiedSince", user.ID, clock.dayStart(now))
+}
+
+func TestFlashcardService_ReviewFlashcard_AlignsToStudyDay(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	user := &models.User{ID: uuid.New(), Timezone: "America/Los_Angeles", DayStartHour: 4}
+	cardID := uuid.New()
+	lastReview := time.Now().AddDate(0, 0, -6)
+	card := &models.Flashcard{
+		ID:          cardID,
+		UserID:      user.ID,
+		DeckID:      uuid.New(),
+		Interval:    6,
+		EaseFactor:  2.5,
+		ReviewCount: 2,
+		State:       models.CardStateReview,
+		LastReview:  &lastReview,
+	}
+
+	mockRepo.On("GetByID", cardID).Return(card, nil)
+	mockDeckRepo.On("GetByID", card.DeckID).Return(&models.Deck{ID: card.DeckID, Scheduler: models.SchedulerSM2}, nil)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockRepo.On("UpdateSchedulingState", cardID, mock.MatchedBy(func(state models.SchedulingState) bool {
+		if state.NextReview == nil {
+			return false
+		}
+		due := state.NextReview.In(studyClockForUser(user).location)
+		return due.Hour() == 4 && due.Minute() == 0 && state.Interval >= 13 && state.Interval <= 17
+	})).Return(card, nil)
+	mockReviewLogRepo.On("Create", mock.AnythingOfType("*models.ReviewLog")).Return(&models.ReviewLog{}, nil)
+
+	_, err := service.ReviewFlashcard(cardID, &models.ReviewFlashcardRequest{Quality: 4})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_Update_StudyDay(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockUserRepository{}
+	service := NewUserService(mockRepo, logger)
+
+	user := testutils.CreateTestUser()
+	timezone := "Europe/Berlin"
+	dayStartHour := 6
+
+	mockRepo.On("GetByID", user.ID).Return(user, nil)
+	mockRepo.On("Update", user.ID, map[string]any{"timezone": timezone, "day_start_hour": dayStartHour}).
+		Return(&models.User{ID: user.ID, Timezone: timezone, DayStartHour: dayStartHour}, nil)
+
+	result, err := service.Update(user.ID, &models.UpdateUserRequest{Timezone: &timezone, DayStartHour: &dayStartHour})
+
+	require.NoError(t, err)
+	assert.Equal(t, timezone, result.Timezone)
+	assert.Equal(t, dayStartHour, result.DayStartHour)
+}
+
+func TestUserService_Update_InvalidTimezone(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockUserRepository{}
+	service := NewUserService(mockRepo, logger)
+
+	user := testutils.CreateTestUser()
+	timezone := "Mars/Olympus_Mons"
+	mockRepo.On("GetByID", user.ID).Return(user, nil)
+
+	result, err := service.Update(user.ID, &models.UpdateUserRequest{Timezone: &timezone})
+
+	assert.Nil(t, result)
+	assert.Equal(t, errors.New("invalid timezone"), err)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,554 @@
+package services
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/repositories"
+)
+
+type StudySessionService struct {
+	sessionRepo      repositories.StudySessionRepositoryInterface
+	flashcardRepo    repositories.FlashcardRepositoryInterface
+	deckRepo         repositories.DeckRepositoryInterface
+	flashcardService *FlashcardService
+	Logger           *logrus.Logger
+}
+
+func NewStudySessionService(
+	sessionRepo repositories.StudySessionRepositoryInterface,
+	flashcardRepo repositories.FlashcardRepositoryInterface,
+	deckRepo repositories.DeckRepositoryInterface,
+	flashcardService *FlashcardService,
+	logger *logrus.Logger,
+) *StudySessionService {
+	return &StudySessionService{
+		sessionRepo:      sessionRepo,
+		flashcardRepo:    flashcardRepo,
+		deckRepo:         deckRepo,
+		flashcardService: flashcardService,
+		Logger:           logger,
+	}
+}
+
+// Create starts a study session, in one deck or all of them. A normal session studies
+// the cards due now; a cram session drills every card that is not suspended, in the
+// requested order, without rescheduling them.
+func (s *StudySessionService) Create(req *models.CreateStudySessionRequest, userID uuid.UUID) (*models.StudySession, error) {
+	mode := req.Mode
+	if mode == "" {
+		mode = models.StudySessionModeNormal
+	}
+	if req.Order != "" && mode != models.StudySessionModeCram {
+		return nil, fmt.Errorf("order is only supported in cram mode")
+	}
+
+	if req.DeckID != nil {
+		deck, err := s.deckRepo.GetByID(*req.DeckID)
+		if err != nil {
+			return nil, fmt.Errorf("deck not found")
+		}
+		if deck.UserID != userID {
+			return nil, fmt.Errorf("unauthorized: deck does not belong to user")
+		}
+	}
+
+	session := &models.StudySession{
+		ID:        uuid.New(),
+		UserID:    userID,
+		DeckID:    req.DeckID,
+		Status:    models.StudySessionActive,
+		Mode:      mode,
+		StartedAt: time.Now(),
+	}
+
+	if mode == models.StudySessionModeCram {
+		order := req.Order
+		if order == "" {
+			order = models.CramOrderRandom
+		}
+
+		cards, err := s.cramCards(userID, req.DeckID)
+		if err != nil {
+			return nil, err
+		}
+		sortCramCards(cards, order)
+
+		session.CramOrder = &order
+		session.Queue = make(models.StudyQueue, 0, len(cards))
+		for _, card := range cards {
+			session.Queue = append(session.Queue, models.StudyQueueItem{
+				FlashcardID: card.ID,
+				State:       card.SchedulingState().CardState(),
+			})
+		}
+	} else {
+		due, err := s.flashcardService.dueQueue(userID, req.DeckID)
+		if err != nil {
+			return nil, err
+		}
+		session.Queue = buildStudyQueue(due.Cards)
+	}
+
+	created, err := s.sessionRepo.Create(session)
+	if err != nil {
+		s.Logger.WithError(err).WithField("user_id", userID).Error("Service failed to create study session")
+		return nil, fmt.Errorf("failed to create study session: %w", err)
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"session_id": created.ID,
+		"user_id":    userID,
+		"mode":       created.Mode,
+		"card_count": len(created.Queue),
+	}).Info("Study session started")
+
+	return created, nil
+}
+
+// GetByIDWithOwnership retrieves a study session with user ownership validation
+func (s *StudySessionService) GetByIDWithOwnership(id uuid.UUID, userID uuid.UUID) (*models.StudySession, error) {
+	session, err := s.sessionRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get study session: %w", err)
+	}
+
+	if session.UserID != userID {
+		s.Logger.WithFields(logrus.Fields{
+			"session_id": id,
+			"user_id":    userID,
+			"owner_id":   session.UserID,
+		}).Warn("Unauthorized attempt to access study session")
+		return nil, fmt.Errorf("unauthorized: study session does not belong to user")
+	}
+
+	return session, nil
+}
+
+// Next returns the card to study next without changing the queue. Cards deleted or
+// suspended since the session started are dropped from it, and so are cards buried
+// since, such as the siblings of an answered card, unless the session crams.
+func (s *StudySessionService) Next(id uuid.UUID, userID uuid.UUID) (*models.StudySessionNext, error) {
+	session, err := s.activeSession(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dropped := make(map[uuid.UUID]bool)
+	next := &models.StudySessionNext{}
+	for {
+		now := time.Now()
+		index := nextQueueIndex(session.Queue, now)
+		if index < 0 {
+			break
+		}
+
+		card, err := s.flashcardRepo.GetByID(session.Queue[index].FlashcardID)
+		if err != nil || !studiable(card, session.Mode, now) {
+			dropped[session.Queue[index].FlashcardID] = true
+			session.Queue = append(session.Queue[:index], session.Queue[index+1:]...)
+			continue
+		}
+
+		next.Card = card
+		break
+	}
+	next.Remaining = len(session.Queue)
+
+	if len(dropped) > 0 {
+		// Answers may have changed the queue since it was read
+		_, err := s.sessionRepo.Modify(id, func(session *models.StudySession) error {
+			session.Queue = slices.DeleteFunc(session.Queue, func(item models.StudyQueueItem) bool {
+				return dropped[item.FlashcardID]
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update study session: %w", err)
+		}
+	}
+
+	return next, nil
+}
+
+// studiable reports whether a card of a session queue can still be studied in it
+func studiable(card *models.Flashcard, mode string, now time.Time) bool {
+	if card.Suspended {
+		return false
+	}
+	buried := card.BuriedUntil != nil && card.BuriedUntil.After(now)
+	return !buried || mode == models.StudySessionModeCram
+}
+
+// Answer reviews a card of the session queue and records the progress. Cards that
+// land on a learning step are queued again and come back once the step has passed.
+// The card is taken off the queue before it is reviewed, so concurrent answers never
+// review it twice, and the session is not kept locked while the review is saved.
+func (s *StudySessionService) Answer(id uuid.UUID, userID uuid.UUID, req *models.AnswerStudySessionRequest) (*models.Flashcard, *models.StudySession, error) {
+	if _, err := s.activeSession(id, userID); err != nil {
+		return nil, nil, err
+	}
+
+	var item models.StudyQueueItem
+	var mode string
+	_, err := s.sessionRepo.Modify(id, func(session *models.StudySession) error {
+		if session.Status == models.StudySessionFinished {
+			return fmt.Errorf("study session already finished")
+		}
+
+		index := slices.IndexFunc(session.Queue, func(item models.StudyQueueItem) bool {
+			return item.FlashcardID == req.FlashcardID
+		})
+		if index < 0 {
+			return fmt.Errorf("flashcard is not in the study session queue")
+		}
+		item = session.Queue[index]
+		mode = session.Mode
+		session.Queue = append(session.Queue[:index], session.Queue[index+1:]...)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	review := &models.ReviewFlashcardRequest{
+		Quality:     req.Quality,
+		TimeTakenMs: req.TimeTakenMs,
+		SessionID:   &id,
+	}
+	var card *models.Flashcard
+	if mode == models.StudySessionModeCram {
+		card, err = s.flashcardService.CramFlashcardWithOwnership(req.FlashcardID, userID, review)
+	} else {
+		card, err = s.flashcardService.ReviewFlashcardWithOwnership(req.FlashcardID, userID, review)
+	}
+	if err != nil {
+		// The card was not answered, so it goes back to the front of the queue
+		_, requeueErr := s.sessionRepo.Modify(id, func(session *models.StudySession) error {
+			session.Queue = append(models.StudyQueue{item}, session.Queue...)
+			return nil
+		})
+		if requeueErr != nil {
+			s.Logger.WithError(requeueErr).WithFields(logrus.Fields{
+				"session_id":   id,
+				"flashcard_id": req.FlashcardID,
+			}).Error("Failed to requeue unanswered study session card")
+		}
+		return nil, nil, err
+	}
+
+	updated, err := s.sessionRepo.Modify(id, func(session *models.StudySession) error {
+		recordAnswer(session, item, card, req)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update study session: %w", err)
+	}
+
+	return card, updated, nil
+}
+
+// recordAnswer counts an answer to a card taken off the session queue and queues the
+// card again when it has to come back in the session
+func recordAnswer(session *models.StudySession, item models.StudyQueueItem, card *models.Flashcard, req *models.AnswerStudySessionRequest) {
+	switch item.State {
+	case models.CardStateNew:
+		session.NewCount++
+	case models.CardStateLearning, models.CardStateRelearning:
+		session.LearningCount++
+	default:
+		session.ReviewCount++
+	}
+	if req.Quality < 3 {
+		session.AgainCount++
+	}
+	session.TotalTimeMs += req.TimeTakenMs
+
+	state := card.SchedulingState().CardState()
+	switch {
+	case session.Mode == models.StudySessionModeCram:
+		// Forgotten cards go to the back of the queue until they are recalled
+		if req.Quality < 3 {
+			session.Queue = append(session.Queue, item)
+		}
+	case (state == models.CardStateLearning || state == models.CardStateRelearning) && card.NextReview != nil:
+		session.Queue = append(session.Queue, models.StudyQueueItem{
+			FlashcardID: card.ID,
+			State:       state,
+			DueAt:       card.NextReview,
+		})
+	}
+}
+
+// UndoReview reverts the last review of a flashcard answered in a study session, like
+// FlashcardService.UndoLastReviewWithOwnership. While the session is active the card
+// also goes back to the front of its queue and the answer is taken out of its counts.
+// Session IDs that are not study sessions only restore the card.
+func (s *StudySessionService) UndoReview(flashcardID uuid.UUID, userID uuid.UUID, sessionID uuid.UUID) (*models.Flashcard, error) {
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		if err.Error() != "study session not found" {
+			return nil, fmt.Errorf("failed to get study session: %w", err)
+		}
+		return s.flashcardService.UndoLastReviewWithOwnership(flashcardID, userID, sessionID)
+	}
+	if session.UserID != userID {
+		s.Logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"user_id":    userID,
+			"owner_id":   session.UserID,
+		}).Warn("Unauthorized attempt to undo study session answer")
+		return nil, fmt.Errorf("unauthorized: study session does not belong to user")
+	}
+
+	card, review, err := s.flashcardService.undoLastReview(flashcardID, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.sessionRepo.Modify(sessionID, func(session *models.StudySession) error {
+		if session.Status != models.StudySessionFinished {
+			restoreAnswer(session, review)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update study session: %w", err)
+	}
+
+	return card, nil
+}
+
+// restoreAnswer takes an undone answer out of a study session. The card goes back to the
+// front of the queue in the state it was answered in, replacing the entry it was
+// requeued with, and the answer no longer counts.
+func restoreAnswer(session *models.StudySession, review *models.ReviewLog) {
+	state := review.StateBefore.CardState()
+	queue := models.StudyQueue{{FlashcardID: review.FlashcardID, State: state}}
+	for _, item := range session.Queue {
+		if item.FlashcardID != review.FlashcardID {
+			queue = append(queue, item)
+		}
+	}
+	session.Queue = queue
+
+	switch state {
+	case models.CardStateNew:
+		session.NewCount = max(session.NewCount-1, 0)
+	case models.CardStateLearning, models.CardStateRelearning:
+		session.LearningCount = max(session.LearningCount-1, 0)
+	default:
+		session.ReviewCount = max(session.ReviewCount-1, 0)
+	}
+	if review.Quality < 3 {
+		session.AgainCount = max(session.AgainCount-1, 0)
+	}
+	session.TotalTimeMs = max(session.TotalTimeMs-review.TimeTakenMs, 0)
+}
+
+// Finish ends a study session and returns its summary. Finishing an already
+// finished session returns the same summary again.
+func (s *StudySessionService) Finish(id uuid.UUID, userID uuid.UUID) (*models.StudySessionSummary, error) {
+	session, err := s.GetByIDWithOwnership(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Status != models.StudySessionFinished {
+		now := time.Now()
+		session.Status = models.StudySessionFinished
+		session.FinishedAt = &now
+
+		session, err = s.sessionRepo.Update(session)
+		if err != nil {
+			s.Logger.WithError(err).WithField("session_id", id).Error("Service failed to finish study session")
+			return nil, fmt.Errorf("failed to update study session: %w", err)
+		}
+
+		s.Logger.WithFields(logrus.Fields{
+			"session_id": id,
+			"user_id":    userID,
+			"new":        session.NewCount,
+			"learning":   session.LearningCount,
+			"review":     session.ReviewCount,
+			"again":      session.AgainCount,
+			"unanswered": len(session.Queue),
+			"total_ms":   session.TotalTimeMs,
+		}).Info("Study session finished")
+	}
+
+	return studySessionSummary(session), nil
+}
+
+// activeSession retrieves a study session of the user that can still be answered
+func (s *StudySessionService) activeSession(id uuid.UUID, userID uuid.UUID) (*models.StudySession, error) {
+	session, err := s.GetByIDWithOwnership(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Status == models.StudySessionFinished {
+		return nil, fmt.Errorf("study session already finished")
+	}
+
+	return session, nil
+}
+
+// cramCards returns the cards of a user that can be crammed, in one deck or all of them.
+// Cards lent to a filtered deck belong to both that deck and their home deck.
+func (s *StudySessionService) cramCards(userID uuid.UUID, deckID *uuid.UUID) ([]*models.Flashcard, error) {
+	flashcards, err := s.flashcardRepo.GetByUser(userID)
+	if err != nil {
+		s.Logger.WithError(err).WithField("user_id", userID).Error("Service failed to get flashcards to cram")
+		return nil, fmt.Errorf("failed to get flashcards: %w", err)
+	}
+
+	var cards []*models.Flashcard
+	for _, card := range flashcards {
+		if card.Suspended {
+			continue
+		}
+		if deckID != nil && card.DeckID != *deckID && card.HomeDeck() != *deckID {
+			continue
+		}
+		cards = append(cards, card)
+	}
+
+	return cards, nil
+}
+
+// sortCramCards puts the cards of a cram session in the requested order
+func sortCramCards(cards []*models.Flashcard, order string) {
+	switch order {
+	case models.CramOrderHardest:
+		// Ease only moves under SM-2 and difficulty only under FSRS, so each breaks the
+		// ties the other leaves
+		sort.SliceStable(cards, func(i, j int) bool {
+			a, b := cards[i], cards[j]
+			if a.Lapses != b.Lapses {
+				return a.Lapses > b.Lapses
+			}
+			if a.EaseFactor != b.EaseFactor {
+				return a.EaseFactor < b.EaseFactor
+			}
+			return a.Difficulty > b.Difficulty
+		})
+	case models.CramOrderOldest:
+		// Cards never reviewed come first, oldest added first
+		sort.SliceStable(cards, func(i, j int) bool {
+			a, b := cards[i], cards[j]
+			if a.LastReview == nil || b.LastReview == nil {
+				if a.LastReview == nil && b.LastReview == nil {
+					return a.CreatedAt.Before(b.CreatedAt)
+				}
+				return a.LastReview == nil
+			}
+			return a.LastReview.Before(*b.LastReview)
+		})
+	default:
+		rand.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+	}
+}
+
+// buildStudyQueue interleaves the learning, review and new cards of a due queue. Each
+// kind keeps its own order and is spread evenly across the session, so a run of new
+// cards is broken up by reviews instead of all coming at the end.
+func buildStudyQueue(cards []*models.Flashcard) models.StudyQueue {
+	groups := make([][]*models.Flashcard, 3)
+	for _, card := range cards {
+		rank := dueQueueRank(card)
+		groups[rank] = append(groups[rank], card)
+	}
+
+	type placed struct {
+		item     models.StudyQueueItem
+		position float64
+		rank     int
+	}
+
+	var all []placed
+	for rank, group := range groups {
+		for i, card := range group {
+			all = append(all, placed{
+				item: models.StudyQueueItem{
+					FlashcardID: card.ID,
+					State:       card.SchedulingState().CardState(),
+				},
+				position: (float64(i) + 0.5) / float64(len(group)),
+				rank:     rank,
+			})
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].position != all[j].position {
+			return all[i].position < all[j].position
+		}
+		return all[i].rank < all[j].rank
+	})
+
+	queue := make(models.StudyQueue, 0, len(all))
+	for _, p := range all {
+		queue = append(queue, p.item)
+	}
+	return queue
+}
+
+// nextQueueIndex picks the next card of a session queue: a requeued learning card whose
+// step has passed, otherwise the first card of the queue, otherwise the learning card
+// due soonest. Returns -1 when the queue is empty.
+func nextQueueIndex(queue models.StudyQueue, now time.Time) int {
+	firstWaiting, soonest := -1, -1
+	for i, item := range queue {
+		if item.DueAt == nil {
+			if firstWaiting < 0 {
+				firstWaiting = i
+			}
+			continue
+		}
+		if soonest < 0 || item.DueAt.Before(*queue[soonest].DueAt) {
+			soonest = i
+		}
+	}
+
+	if soonest >= 0 && !queue[soonest].DueAt.After(now) {
+		return soonest
+	}
+	if firstWaiting >= 0 {
+		return firstWaiting
+	}
+	return soonest
+}
+
+// studySessionSummary describes the progress of a study session
+func studySessionSummary(session *models.StudySession) *models.StudySessionSummary {
+	answered := session.NewCount + session.LearningCount + session.ReviewCount
+
+	summary := &models.StudySessionSummary{
+		SessionID:     session.ID,
+		Mode:          session.Mode,
+		Answered:      answered,
+		NewCount:      session.NewCount,
+		LearningCount: session.LearningCount,
+		ReviewCount:   session.ReviewCount,
+		AgainCount:    session.AgainCount,
+		Skipped:       len(session.Queue),
+		TotalTimeMs:   session.TotalTimeMs,
+		StartedAt:     session.StartedAt,
+	}
+	if answered > 0 {
+		summary.Retention = float64(answered-session.AgainCount) / float64(answered)
+	}
+	if session.FinishedAt != nil {
+		summary.FinishedAt = *session.FinishedAt
+	}
+
+	return summary
+}
@@ -0,0 +1,610 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+// MockStudySessionRepository is a mock implementation of StudySessionRepository for testing
+type MockStudySessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockStudySessionRepository) Create(session *models.StudySession) (*models.StudySession, error) {
+	args := m.Called(session)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	if fn, ok := args.Get(0).(func(*models.StudySession) *models.StudySession); ok {
+		return fn(session), args.Error(1)
+	}
+	return args.Get(0).(*models.StudySession), args.Error(1)
+}
+
+func (m *MockStudySessionRepository) GetByID(id uuid.UUID) (*models.StudySession, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StudySession), args.Error(1)
+}
+
+func (m *MockStudySessionRepository) Update(session *models.StudySession) (*models.StudySession, error) {
+	args := m.Called(session)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	if fn, ok := args.Get(0).(func(*models.StudySession) *models.StudySession); ok {
+		return fn(session), args.Error(1)
+	}
+	return args.Get(0).(*models.StudySession), args.Error(1)
+}
+
+// Modify applies change to the session returned by GetByID and saves it through Update,
+// so tests set up the lock and the save like two separate calls
+func (m *MockStudySessionRepository) Modify(id uuid.UUID, change func(*models.StudySession) error) (*models.StudySession, error) {
+	session, err := m.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(session); err != nil {
+		return nil, err
+	}
+	return m.Update(session)
+}
+
+type studySessionMocks struct {
+	sessionRepo   *MockStudySessionRepository
+	flashcardRepo *MockFlashcardRepository
+	deckRepo      *MockDeckRepository
+	presetRepo    *MockDeckPresetRepository
+	reviewLogRepo *MockReviewLogRepository
+	userRepo      *MockUserRepository
+}
+
+func newTestStudySessionService() (*StudySessionService, *studySessionMocks) {
+	logger := testutils.TestLogger()
+	m := &studySessionMocks{
+		sessionRepo:   &MockStudySessionRepository{},
+		flashcardRepo: &MockFlashcardRepository{},
+		deckRepo:      &MockDeckRepository{},
+		presetRepo:    &MockDeckPresetRepository{},
+		reviewLogRepo: &MockReviewLogRepository{},
+		userRepo:      &MockUserRepository{},
+	}
+	flashcardService := NewFlashcardService(m.flashcardRepo, m.deckRepo, m.presetRepo, m.reviewLogRepo, m.userRepo, logger)
+	return NewStudySessionService(m.sessionRepo, m.flashcardRepo, m.deckRepo, flashcardService, logger), m
+}
+
+func TestBuildStudyQueue_Interleaves(t *testing.T) {
+	yesterday := time.Now().Add(-24 * time.Hour)
+	card := func(front, state string) *models.Flashcard {
+		return &models.Flashcard{ID: uuid.New(), Front: front, State: state, NextReview: &yesterday}
+	}
+	cards := []*models.Flashcard{
+		card("L1", models.CardStateLearning),
+		card("R1", models.CardStateReview),
+		card("R2", models.CardStateReview),
+		card("R3", models.CardStateReview),
+		card("R4", models.CardStateReview),
+		card("N1", models.CardStateNew),
+		card("N2", models.CardStateNew),
+	}
+	fronts := make(map[uuid.UUID]string, len(cards))
+	for _, c := range cards {
+		fronts[c.ID] = c.Front
+	}
+
+	queue := buildStudyQueue(cards)
+
+	var order []string
+	for _, item := range queue {
+		order = append(order, fronts[item.FlashcardID])
+	}
+	assert.Equal(t, []string{"R1", "N1", "R2", "L1", "R3", "N2", "R4"}, order)
+	assert.Equal(t, models.CardStateLearning, queue[3].State)
+
+	// The same cards always give the same queue
+	assert.Equal(t, queue, buildStudyQueue(cards))
+}
+
+func TestNextQueueIndex(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(5 * time.Minute)
+	later := now.Add(10 * time.Minute)
+
+	waiting := models.StudyQueueItem{FlashcardID: uuid.New()}
+	stepPassed := models.StudyQueueItem{FlashcardID: uuid.New(), DueAt: &past}
+	stepPending := models.StudyQueueItem{FlashcardID: uuid.New(), DueAt: &future}
+	stepLater := models.StudyQueueItem{FlashcardID: uuid.New(), DueAt: &later}
+
+	assert.Equal(t, -1, nextQueueIndex(nil, now))
+	assert.Equal(t, 1, nextQueueIndex(models.StudyQueue{waiting, stepPassed}, now))
+	assert.Equal(t, 0, nextQueueIndex(models.StudyQueue{waiting, stepPending}, now))
+	assert.Equal(t, 1, nextQueueIndex(models.StudyQueue{stepLater, stepPending}, now)) // learn ahead
+}
+
+func TestStudySessionService_Create_OtherUsersDeck(t *testing.T) {
+	service, m := newTestStudySessionService()
+
+	userID := uuid.New()
+	deck := testutils.CreateTestDeck(uuid.New())
+	m.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+
+	result, err := service.Create(&models.CreateStudySessionRequest{DeckID: &deck.ID}, userID)
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "unauthorized: deck does not belong to user")
+	m.sessionRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestStudySessionService_Create_ScopedToDeck(t *testing.T) {
+	service, m := newTestStudySessionService()
+
+	userID := uuid.New()
+	deck := testutils.CreateTestDeck(userID)
+	otherDeck := testutils.CreateTestDeck(userID)
+	mockStudyDay(m.userRepo, m.deckRepo, m.presetRepo, m.reviewLogRepo, &models.User{ID: userID}, []*models.Deck{deck, otherDeck}, nil)
+	m.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+
+	inDeck := testutils.CreateTestFlashcard(userID, deck.ID)
+	inOtherDeck := testutils.CreateTestFlashcard(userID, otherDeck.ID)
+	m.flashcardRepo.On("GetByUser", userID).Return([]*models.Flashcard{inOtherDeck, inDeck}, nil)
+	m.sessionRepo.On("Create", mock.AnythingOfType("*models.StudySession")).Return(func(session *models.StudySession) *models.StudySession {
+		return session
+	}, nil)
+
+	session, err := service.Create(&models.CreateStudySessionRequest{DeckID: &deck.ID}, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.StudySessionActive, session.Status)
+	assert.Equal(t, &deck.ID, session.DeckID)
+	assert.Equal(t, models.StudyQueue{{FlashcardID: inDeck.ID, State: models.CardStateNew}}, session.Queue)
+}
+
+func TestStudySessionService_Answer_RequeuesLearningCard(t *testing.T) {
+	service, m := newTestStudySessionService()
+
+	userID := uuid.New()
+	card := testutils.CreateTestFlashcard(userID, uuid.New())
+	otherID := uuid.New()
+	session := &models.StudySession{
+		ID:     uuid.New(),
+		UserID: userID,
+		Status: models.StudySessionActive,
+		Queue: models.StudyQueue{
+			{FlashcardID: card.ID, State: models.CardStateNew},
+			{FlashcardID: otherID, State: models.CardStateReview},
+		},
+	}
+
+	stepDue := time.Now().Add(time.Minute)
+	learning := *card
+	learning.State = models.CardStateLearning
+	learning.NextReview = &stepDue
+
+	m.sessionRepo.On("GetByID", session.ID).Return(session, nil)
+	m.flashcardRepo.On("GetByID", card.ID).Return(card, nil)
+	m.deckRepo.On("GetByID", card.DeckID).Return(&models.Deck{ID: card.DeckID, Scheduler: models.SchedulerSM2}, nil)
+	m.userRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+	m.flashcardRepo.On("UpdateSchedulingState", card.ID, mock.AnythingOfType("models.SchedulingState")).Return(&learning, nil)
+	m.reviewLogRepo.On("Create", mock.MatchedBy(func(log *models.ReviewLog) bool {
+		return log.SessionID != nil && *log.SessionID == session.ID
+	})).Return(&models.ReviewLog{}, nil)
+	m.sessionRepo.On("Update", mock.AnythingOfType("*models.StudySession")).Return(func(session *models.StudySession) *models.StudySession {
+		return session
+	}, nil)
+
+	reviewed, updated, err := service.Answer(session.ID, userID, &models.AnswerStudySessionRequest{
+		FlashcardID: card.ID,
+		Quality:     1,
+		TimeTakenMs: 2500,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.CardStateLearning, reviewed.State)
+	assert.Equal(t, 1, updated.NewCount)
+	assert.Equal(t, 1, updated.AgainCount)
+	assert.Equal(t, 2500, updated.TotalTimeMs)
+	require.Len(t, updated.Queue, 2)
+	assert.Equal(t, otherID, updated.Queue[0].FlashcardID)
+	assert.Equal(t, models.StudyQueueItem{FlashcardID: card.ID, State: models.CardStateLearning, DueAt: &stepDue}, updated.Queue[1])
+}
+
+func TestStudySessionService_Answer_NotInQueue(t *testing.T) {
+	service, m := newTestStudySessionService()
+
+	userID := uuid.New()
+	session := &models.StudySession{ID: uuid.New(), UserID: userID, Status: models.StudySessionActive}
+	m.sessionRepo.On("GetByID", session.ID).Return(session, nil)
+
+	_, _, err := service.Answer(session.ID, userID, &models.AnswerStudySessionRequest{FlashcardID: uuid.New(), Quality: 4})
+
+	assert.EqualError(t, err, "flashcard is not in the study session queue")
+	m.flashcardRepo.AssertNotCalled(t, "UpdateSchedulingState", mock.Anything, mock.Anything)
+}
+
+func TestStudySessionService_Answer_FailedReviewRequeuesCard(t *testing.T) {
+	service, m := newTestStudySessionService()
+
+	userID := uuid.New()
+	cardID := uuid.New()
+	otherID := uuid.New()
+	session := &models.StudySession{
+		ID:     uuid.New(),
+		UserID: userID,
+		Status: models.StudySessionActive,
+		Queue: models.StudyQueue{
+			{FlashcardID: otherID, State: models.CardStateReview},
+			{FlashcardID: cardID, State: models.CardStateNew},
+		},
+	}
+
+	m.sessionRepo.On("GetByID", session.ID).Return(session, nil)
+	m.flashcardRepo.On("GetByID", cardID).Return(nil, fmt.Errorf("flashcard not found"))
+	m.sessionRepo.On("Update", mock.AnythingOfType("*models.StudySession")).Return(func(session *models.StudySession) *models.StudySession {
+		return session
+	}, nil)
+
+	_, _, err := service.Answer(session.ID, userID, &models.AnswerStudySessionRequest{FlashcardID: cardID, Quality: 4})
+
+	assert.EqualError(t, err, "flashcard not found: flashcard not found")
+	assert.Equal(t, models.StudyQueue{
+		{FlashcardID: cardID, State: models.CardStateNew},
+		{FlashcardID: otherID, State: models.CardStateReview},
+	}, session.Queue)
+	assert.Zero(t, session.NewCount)
+}
+
+func TestStudySessionService_Next_SkipsDeletedCards(t *testing.T) {
+	service, m := newTestStudySessionService()
+
+	userID := uuid.New()
+	deletedID := uuid.New()
+	card := testutils.CreateTestFlashcard(userID, uuid.New())
+	session := &models.StudySession{
+		ID:     uuid.New(),
+		UserID: userID,
+		Status: models.StudySessionActive,
+		Queue: models.StudyQueue{
+			{FlashcardID: deletedID, State: models.CardStateReview},
+			{FlashcardID: card.ID, State: models.CardStateNew},
+		},
+	}
+
+	m.sessionRepo.On("GetByID", session.ID).Return(session, nil)
+	m.flashcardRepo.On("GetByID", deletedID).Return(nil, fmt.Errorf("flashcard not found"))
+	m.flashcardRepo.On("GetByID", card.ID).Return(card, nil)
+	m.sessionRepo.On("Update", mock.AnythingOfType("*models.StudySession")).Return(session, nil)
+
+	next, err := service.Next(session.ID, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, card.ID, next.Card.ID)
+	assert.Equal(t, 1, next.Remaining)
+	m.sessionRepo.AssertCalled(t, "Update", mock.AnythingOfType("*models.StudySession"))
+}
+
+func TestStudySessionService_Next_SkipsBuriedSiblings(t *testing.T) {
+	service, m := newTestStudySessionService()
+
+	userID := uuid.New()
+	noteID := uuid.New()
+	tomorrow := time.Now().Add(12 * time.Hour)
+	buried := testutils.CreateTestFlashcard(userID, uuid.New())
+	buried.NoteID, buried.BuriedUntil = &noteID, &tomorrow
+	card := testutils.CreateTestFlashcard(userID, uuid.New())
+	session := &models.StudySession{
+		ID:     uuid.New(),
+		UserID: userID,
+		Mode:   models.StudySessionModeNormal,
+		Status: models.StudySessionActive,
+		Queue: models.StudyQueue{
+			{FlashcardID: buried.ID, State: models.CardStateNew},
+			{FlashcardID: card.ID, State: models.CardStateNew},
+		},
+	}
+
+	m.sessionRepo.On("GetByID", session.ID).Return(session, nil)
+	m.flashcardRepo.On("GetByID", buried.ID).Return(buried, nil)
+	m.flashcardRepo.On("GetByID", card.ID).Return(card, nil)
+	m.sessionRepo.On("Update", mock.AnythingOfType("*models.StudySession")).Return(session, nil)
+
+	next, err := service.Next(session.ID, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, card.ID, next.Card.ID)
+	assert.Equal(t, 1, next.Remaining)
+}
+
+func TestStudySessionService_Next_SkipsSuspendedCards(t *testing.T) {
+	service, m := newTestStudySessionService()
+
+	userID := uuid.New()
+	suspended := testutils.CreateTestFlashcard(userID, uuid.New())
+	suspended.Suspended = true
+	card := testutils.CreateTestFlashcard(userID, uuid.New())
+	session := &models.StudySession{
+		ID:     uuid.New(),
+		UserID: userID,
+		Mode:   models.StudySessionModeCram,
+		Status: models.StudySessionActive,
+		Queue: models.StudyQueue{
+			{FlashcardID: suspended.ID, State: models.CardStateReview},
+			{FlashcardID: card.ID, State: models.CardStateNew},
+		},
+	}
+
+	m.sessionRepo.On("GetByID", session.ID).Return(session, nil)
+	m.flashcardRepo.On("GetByID", suspended.ID).Return(suspended, nil)
+	m.flashcardRepo.On("GetByID", card.ID).Return(card, nil)
+	m.sessionRepo.On("Update", mock.MatchedBy(func(s *models.StudySession) bool {
+		return len(s.Queue) == 1 && s.Queue[0].FlashcardID == card.ID
+	})).Return(session, nil)
+
+	next, err := service.Next(session.ID, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, card.ID, next.Card.ID)
+	assert.Equal(t, 1, next.Remaining)
+	m.sessionRepo.AssertExpectations(t)
+}
+
+func TestStudySessionService_UndoReview_RequeuesCard(t *testing.T) {
+	service, m := newTestStudySessionService()
+
+	userID := uuid.New()
+	cardID := uuid.New()
+	otherID := uuid.New()
+	before := models.SchedulingState{Interval: 6, EaseFactor: 2.5, ReviewCount: 2, State: models.CardStateReview}
+	session := &models.StudySession{
+		ID:     uuid.New(),
+		UserID: userID,
+		Status: models.StudySessionActive,
+		Queue: models.StudyQueue{
+			{FlashcardID: otherID, State: models.CardStateNew},
+			{FlashcardID: cardID, State: models.CardStateRelearning},
+		},
+		ReviewCount: 3,
+		AgainCount:  1,
+		TotalTimeMs: 20000,
+	}
+	lastReview := &models.ReviewLog{
+		ID:          uuid.New(),
+		FlashcardID: cardID,
+		Quality:     1,
+		TimeTakenMs: 8000,
+		SessionID:   &session.ID,
+		StateBefore: before,
+		StateAfter:  models.SchedulingState{Interval: 1, EaseFactor: 2.3, State: models.CardStateRelearning, Lapses: 1},
+	}
+	restored := &models.Flashcard{ID: cardID, UserID: userID, Interval: 6, EaseFactor: 2.5, ReviewCount: 2}
+
+	m.sessionRepo.On("GetByID", session.ID).Return(session, nil)
+	m.flashcardRepo.On("GetByID", cardID).Return(&models.Flashcard{ID: cardID, UserID: userID}, nil)
+	m.reviewLogRepo.On("GetLatestByFlashcard", cardID).Return(lastReview, nil)
+	m.reviewLogRepo.On("CountUndoneBySession", session.ID).Return(0, nil)
+	m.flashcardRepo.On("UpdateSchedulingState", cardID, before).Return(restored, nil)
+	m.reviewLogRepo.On("MarkUndone", lastReview.ID).Return(nil)
+	m.sessionRepo.On("Update", mock.MatchedBy(func(s *models.StudySession) bool {
+		return len(s.Queue) == 2 &&
+			s.Queue[0] == models.StudyQueueItem{FlashcardID: cardID, State: models.CardStateReview} &&
+			s.Queue[1].FlashcardID == otherID &&
+			s.ReviewCount == 2 && s.AgainCount == 0 && s.TotalTimeMs == 12000
+	})).Return(session, nil)
+
+	card, err := service.UndoReview(cardID, userID, session.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, 6, card.Interval)
+	m.sessionRepo.AssertExpectations(t)
+}
+
+func TestStudySessionService_UndoReview_WithoutStudySession(t *testing.T) {
+	service, m := newTestStudySessionService()
+
+	userID := uuid.New()
+	cardID := uuid.New()
+	sessionID := uuid.New()
+	before := models.SchedulingState{Interval: 6, EaseFactor: 2.5, ReviewCount: 2}
+	lastReview := &models.ReviewLog{
+		ID:          uuid.New(),
+		FlashcardID: cardID,
+		SessionID:   &sessionID,
+		StateBefore: before,
+	}
+
+	m.sessionRepo.On("GetByID", sessionID).Return(nil, fmt.Errorf("study session not found"))
+	m.flashcardRepo.On("GetByID", cardID).Return(&models.Flashcard{ID: cardID, UserID: userID}, nil)
+	m.reviewLogRepo.On("GetLatestByFlashcard", cardID).Return(lastReview, nil)
+	m.reviewLogRepo.On("CountUndoneBySession", sessionID).Return(0, nil)
+	m.flashcardRepo.On("UpdateSchedulingState", cardID, before).Return(&models.Flashcard{ID: cardID, UserID: userID, Interval: 6}, nil)
+	m.reviewLogRepo.On("MarkUndone", lastReview.ID).Return(nil)
+
+	card, err := service.UndoReview(cardID, userID, sessionID)
+
+	require.NoError(t, err)
+	assert.Equal(t, 6, card.Interval)
+	m.sessionRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestStudySessionService_Finish_Summary(t *testing.T) {
+	service, m := newTestStudySessionService()
+
+	userID := uuid.New()
+	session := &models.StudySession{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Status:        models.StudySessionActive,
+		Queue:         models.StudyQueue{{FlashcardID: uuid.New(), State: models.CardStateNew}},
+		NewCount:      2,
+		LearningCount: 1,
+		ReviewCount:   5,
+		AgainCount:    2,
+		TotalTimeMs:   60000,
+		StartedAt:     time.Now().Add(-10 * time.Minute),
+	}
+
+	m.sessionRepo.On("GetByID", session.ID).Return(session, nil)
+	m.sessionRepo.On("Update", mock.MatchedBy(func(s *models.StudySession) bool {
+		return s.Status == models.StudySessionFinished && s.FinishedAt != nil
+	})).Return(func(session *models.StudySession) *models.StudySession {
+		return session
+	}, nil)
+
+	summary, err := service.Finish(session.ID, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, 8, summary.Answered)
+	assert.Equal(t, 1, summary.Skipped)
+	assert.InDelta(t, 0.75, summary.Retention, 1e-9)
+	assert.False(t, summary.FinishedAt.IsZero())
+
+	// Answering after the session finished is rejected
+	_, _, err = service.Answer(session.ID, userID, &models.AnswerStudySessionRequest{FlashcardID: uuid.New(), Quality: 4})
+	assert.EqualError(t, err, "study session already finished")
+}
+
+func TestSortCramCards(t *testing.T) {
+	userID, deckID := uuid.New(), uuid.New()
+	newCard := func(lapses int, ease, difficulty float64) *models.Flashcard {
+		card := testutils.CreateTestFlashcard(userID, deckID)
+		card.Lapses, card.EaseFactor, card.Difficulty = lapses, ease, difficulty
+		return card
+	}
+
+	easy := newCard(0, 2.8, 2.8)
+	struggling := newCard(0, 1.9, 1.9)
+	leech := newCard(6, 1.3, 1.3)
+	hardFSRS := newCard(0, 2.5, 8.5)
+	easyFSRS := newCard(0, 2.5, 3)
+	cards := []*models.Flashcard{easy, hardFSRS, leech, easyFSRS, struggling}
+
+	sortCramCards(cards, models.CramOrderHardest)
+	assert.Equal(t, []*models.Flashcard{leech, struggling, hardFSRS, easyFSRS, easy}, cards)
+
+	now := time.Now()
+	lastWeek, yesterday := now.AddDate(0, 0, -7), now.AddDate(0, 0, -1)
+	easy.LastReview, struggling.LastReview, leech.LastReview = &yesterday, &lastWeek, &now
+	hardFSRS.CreatedAt, easyFSRS.CreatedAt = now, lastWeek
+
+	sortCramCards(cards, models.CramOrderOldest)
+	assert.Equal(t, []*models.Flashcard{easyFSRS, hardFSRS, struggling, easy, leech}, cards)
+
+	sortCramCards(cards, models.CramOrderRandom)
+	assert.ElementsMatch(t, []*models.Flashcard{easy, struggling, leech, hardFSRS, easyFSRS}, cards)
+}
+
+func TestStudySessionService_Create_Cram(t *testing.T) {
+	service, m := newTestStudySessionService()
+
+	userID := uuid.New()
+	deck := testutils.CreateTestDeck(userID)
+	filtered := testutils.CreateTestDeck(userID)
+	m.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+
+	nextMonth := time.Now().AddDate(0, 1, 0)
+	notDue := testutils.CreateTestFlashcard(userID, deck.ID)
+	notDue.State, notDue.NextReview, notDue.Lapses = models.CardStateReview, &nextMonth, 1
+	fresh := testutils.CreateTestFlashcard(userID, deck.ID)
+	lent := testutils.CreateTestFlashcard(userID, filtered.ID)
+	lent.HomeDeckID, lent.Lapses = &deck.ID, 3
+	suspended := testutils.CreateTestFlashcard(userID, deck.ID)
+	suspended.Suspended = true
+	elsewhere := testutils.CreateTestFlashcard(userID, uuid.New())
+	m.flashcardRepo.On("GetByUser", userID).Return([]*models.Flashcard{notDue, fresh, lent, suspended, elsewhere}, nil)
+	m.sessionRepo.On("Create", mock.AnythingOfType("*models.StudySession")).Return(func(session *models.StudySession) *models.StudySession {
+		return session
+	}, nil)
+
+	session, err := service.Create(&models.CreateStudySessionRequest{
+		DeckID: &deck.ID,
+		Mode:   models.StudySessionModeCram,
+		Order:  models.CramOrderHardest,
+	}, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.StudySessionModeCram, session.Mode)
+	require.NotNil(t, session.CramOrder)
+	assert.Equal(t, models.CramOrderHardest, *session.CramOrder)
+	assert.Equal(t, models.StudyQueue{
+		{FlashcardID: lent.ID, State: models.CardStateNew},
+		{FlashcardID: notDue.ID, State: models.CardStateReview},
+		{FlashcardID: fresh.ID, State: models.CardStateNew},
+	}, session.Queue)
+	// Cramming ignores what is due and the daily limits
+	m.reviewLogRepo.AssertNotCalled(t, "CountStudiedSince", mock.Anything, mock.Anything)
+}
+
+func TestStudySessionService_Create_OrderWithoutCram(t *testing.T) {
+	service, m := newTestStudySessionService()
+
+	_, err := service.Create(&models.CreateStudySessionRequest{Order: models.CramOrderOldest}, uuid.New())
+
+	assert.EqualError(t, err, "order is only supported in cram mode")
+	m.sessionRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestStudySessionService_Answer_Cram(t *testing.T) {
+	service, m := newTestStudySessionService()
+
+	userID := uuid.New()
+	deck := testutils.CreateTestDeck(userID)
+	lastReview := time.Now().AddDate(0, 0, -3)
+	nextReview := time.Now().AddDate(0, 0, 10)
+	card := testutils.CreateTestFlashcard(userID, deck.ID)
+	card.State, card.Interval, card.ReviewCount = models.CardStateReview, 13, 4
+	card.LastReview, card.NextReview = &lastReview, &nextReview
+	otherID := uuid.New()
+	order := models.CramOrderRandom
+	session := &models.StudySession{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    models.StudySessionActive,
+		Mode:      models.StudySessionModeCram,
+		CramOrder: &order,
+		Queue: models.StudyQueue{
+			{FlashcardID: card.ID, State: models.CardStateReview},
+			{FlashcardID: otherID, State: models.CardStateNew},
+		},
+	}
+
+	m.sessionRepo.On("GetByID", session.ID).Return(session, nil)
+	m.flashcardRepo.On("GetByID", card.ID).Return(card, nil)
+	m.deckRepo.On("GetByID", deck.ID).Return(deck, nil)
+	m.reviewLogRepo.On("Create", mock.MatchedBy(func(log *models.ReviewLog) bool {
+		return log.Cram && log.Quality == 1 && log.DeckID == deck.ID &&
+			log.SessionID != nil && *log.SessionID == session.ID &&
+			log.StateBefore.Interval == 13 && log.StateAfter.Interval == 13 && log.StateAfter.ReviewCount == 4
+	})).Return(&models.ReviewLog{}, nil)
+	m.sessionRepo.On("Update", mock.AnythingOfType("*models.StudySession")).Return(func(session *models.StudySession) *models.StudySession {
+		return session
+	}, nil)
+
+	crammed, updated, err := service.Answer(session.ID, userID, &models.AnswerStudySessionRequest{
+		FlashcardID: card.ID,
+		Quality:     1,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, card, crammed)
+	assert.Equal(t, 1, updated.ReviewCount)
+	assert.Equal(t, 1, updated.AgainCount)
+	// The forgotten card comes back after the rest of the queue
+	assert.Equal(t, models.StudyQueue{
+		{FlashcardID: otherID, State: models.CardStateNew},
+		{FlashcardID: card.ID, State: models.CardStateReview},
+	}, updated.Queue)
+	m.flashcardRepo.AssertNotCalled(t, "UpdateSchedulingState", mock.Anything, mock.Anything)
+	m.reviewLogRepo.AssertExpectations(t)
+}
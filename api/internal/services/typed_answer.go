@@ -0,0 +1,312 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/text/unicode/norm"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/internal/render"
+)
+
+// Similarity a typed answer needs at least for each suggested quality below 5. An
+// answer matching exactly suggests 5 and an empty one 0.
+const (
+	typedAnswerQuality4 = 0.9
+	typedAnswerQuality3 = 0.75
+	typedAnswerQuality2 = 0.5
+	typedAnswerQuality1 = 0.25
+)
+
+// maxExpectedAnswerLength bounds the characters of a back typed answers are checked
+// against, as the comparison takes time and memory in proportion to both lengths
+const maxExpectedAnswerLength = 2000
+
+// answerOptions say which differences between a typed and an expected answer are ignored
+type answerOptions struct {
+	ignoreCase        bool
+	ignoreAccents     bool
+	ignorePunctuation bool
+}
+
+func presetAnswerOptions(preset models.DeckPreset) answerOptions {
+	return answerOptions{
+		ignoreCase:        preset.TypedAnswerIgnoreCase,
+		ignoreAccents:     preset.TypedAnswerIgnoreAccents,
+		ignorePunctuation: preset.TypedAnswerIgnorePunctuation,
+	}
+}
+
+// answerRune is a character of an answer with the key it is compared by
+type answerRune struct {
+	char    rune
+	key     rune
+	ignored bool // Left out of the comparison
+}
+
+// CheckAnswerWithOwnership compares an answer typed for a flashcard with its back, after
+// checking that the card belongs to the user. Nothing is saved: the suggested quality
+// is meant to be sent as a review.
+func (s *FlashcardService) CheckAnswerWithOwnership(id uuid.UUID, userID uuid.UUID, req *models.CheckAnswerRequest) (*models.AnswerCheck, error) {
+	card, err := s.flashcardRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("flashcard not found: %w", err)
+	}
+
+	if card.UserID != userID {
+		s.Logger.WithFields(logrus.Fields{
+			"flashcard_id": id,
+			"user_id":      userID,
+			"owner_id":     card.UserID,
+		}).Warn("Unauthorized attempt to check answer to flashcard")
+		return nil, fmt.Errorf("unauthorized: flashcard does not belong to user")
+	}
+
+	deck, err := s.deckRepo.GetByID(card.HomeDeck())
+	if err != nil {
+		return nil, fmt.Errorf("deck not found: %w", err)
+	}
+	preset, err := s.deckPreset(deck)
+	if err != nil {
+		return nil, err
+	}
+	options := DefaultDeckPreset()
+	if preset != nil {
+		options = *preset
+	}
+
+	expected := expectedAnswer(card)
+	if utf8.RuneCountInString(expected) > maxExpectedAnswerLength {
+		return nil, fmt.Errorf("answer too long to check: flashcard back exceeds %d characters", maxExpectedAnswerLength)
+	}
+
+	return compareAnswer(expected, req.Answer, presetAnswerOptions(options)), nil
+}
+
+// expectedAnswer returns the text of a card's back as it is shown, without markup
+func expectedAnswer(card *models.Flashcard) string {
+	if card.Format == models.ContentFormatPlain || card.BackHTML == "" {
+		return card.Back
+	}
+	return render.Text(card.BackHTML)
+}
+
+// compareAnswer diffs a typed answer against the expected one character by character.
+// Runs of whitespace count as a single space. Equal and insert segments spell out the
+// expected answer, delete segments hold what was typed but not expected; punctuation
+// typed while ignored is left out of the diff.
+func compareAnswer(expected, typed string, options answerOptions) *models.AnswerCheck {
+	expected = normalizeSpace(norm.NFC.String(expected))
+	typed = normalizeSpace(norm.NFC.String(typed))
+	want := options.runes(expected)
+	got := options.runes(typed)
+
+	wantKept := keptRunes(want)
+	gotKept := keptRunes(got)
+	ops, distance := editScript(gotKept, wantKept)
+
+	check := &models.AnswerCheck{
+		Answer:   typed,
+		Expected: expected,
+		Correct:  distance == 0 && typed != "",
+		Distance: distance,
+		Diff:     answerDiff(ops, want, got),
+	}
+
+	longest := max(len(wantKept), len(gotKept), 1)
+	check.Similarity = 1 - float64(distance)/float64(longest)
+	check.SuggestedQuality = suggestedQuality(check)
+	return check
+}
+
+// normalizeSpace trims a text and makes its runs of whitespace single spaces
+func normalizeSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// runes splits an answer into characters keyed for comparison
+func (o answerOptions) runes(text string) []answerRune {
+	runes := make([]answerRune, 0, len(text))
+	for _, char := range text {
+		r := answerRune{char: char, key: char}
+		switch {
+		case o.ignorePunctuation && unicode.IsPunct(char):
+			r.ignored = true
+		case o.ignoreAccents && unicode.Is(unicode.Mn, char):
+			// A combining mark left over from normalization
+			r.ignored = true
+		}
+		if o.ignoreAccents {
+			r.key = baseRune(r.key)
+		}
+		if o.ignoreCase {
+			r.key = unicode.ToLower(r.key)
+		}
+		runes = append(runes, r)
+	}
+	return runes
+}
+
+// baseRune strips the accents off a character, turning é into e
+func baseRune(char rune) rune {
+	for _, base := range norm.NFD.String(string(char)) {
+		return base
+	}
+	return char
+}
+
+// keptRunes returns the keys of the characters that are compared
+func keptRunes(runes []answerRune) []rune {
+	kept := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if !r.ignored {
+			kept = append(kept, r.key)
+		}
+	}
+	return kept
+}
+
+// Steps of an edit script turning a typed answer into the expected one
+const (
+	editKeep   byte = iota // Take a character of both
+	editDelete             // Drop a typed character
+	editInsert             // Add an expected character
+)
+
+// editScript returns the shortest edit script turning got into want, by Levenshtein
+// distance, along with the distance. A substitution is a delete followed by an insert.
+func editScript(got, want []rune) ([]byte, int) {
+	rows, cols := len(got)+1, len(want)+1
+	dist := make([]int, rows*cols)
+	for i := 0; i < rows; i++ {
+		dist[i*cols] = i
+	}
+	for j := 0; j < cols; j++ {
+		dist[j] = j
+	}
+	for i := 1; i < rows; i++ {
+		for j := 1; j < cols; j++ {
+			cost := 1
+			if got[i-1] == want[j-1] {
+				cost = 0
+			}
+			dist[i*cols+j] = min(dist[(i-1)*cols+j-1]+cost, dist[(i-1)*cols+j]+1, dist[i*cols+j-1]+1)
+		}
+	}
+
+	// Walk back from the end, preferring matches so that equal runs stay together
+	var ops []byte
+	i, j := rows-1, cols-1
+	for i > 0 || j > 0 {
+		here := dist[i*cols+j]
+		switch {
+		case i > 0 && j > 0 && got[i-1] == want[j-1] && here == dist[(i-1)*cols+j-1]:
+			ops = append(ops, editKeep)
+			i, j = i-1, j-1
+		case i > 0 && j > 0 && here == dist[(i-1)*cols+j-1]+1:
+			ops = append(ops, editInsert, editDelete)
+			i, j = i-1, j-1
+		case i > 0 && here == dist[(i-1)*cols+j]+1:
+			ops = append(ops, editDelete)
+			i--
+		default:
+			ops = append(ops, editInsert)
+			j--
+		}
+	}
+	for l, r := 0, len(ops)-1; l < r; l, r = l+1, r-1 {
+		ops[l], ops[r] = ops[r], ops[l]
+	}
+
+	// Group every run of changes as its deletes then its inserts, so a mistyped word
+	// reads as what was typed followed by what was expected
+	for start := 0; start < len(ops); {
+		if ops[start] == editKeep {
+			start++
+			continue
+		}
+		end := start
+		for end < len(ops) && ops[end] != editKeep {
+			end++
+		}
+		sort.SliceStable(ops[start:end], func(a, b int) bool { return ops[start+a] < ops[start+b] })
+		start = end
+	}
+
+	return ops, dist[rows*cols-1]
+}
+
+// answerDiff turns an edit script over the compared characters into diff segments.
+// Ignored expected characters come back as equal text where they stood.
+func answerDiff(ops []byte, want, got []answerRune) []models.DiffSegment {
+	var segments []models.DiffSegment
+	add := func(op string, char rune) {
+		if n := len(segments); n > 0 && segments[n-1].Op == op {
+			segments[n-1].Text += string(char)
+			return
+		}
+		segments = append(segments, models.DiffSegment{Op: op, Text: string(char)})
+	}
+
+	wantIndex, gotIndex := 0, 0
+	nextWanted := func(op string) {
+		for want[wantIndex].ignored {
+			add(models.DiffOpEqual, want[wantIndex].char)
+			wantIndex++
+		}
+		add(op, want[wantIndex].char)
+		wantIndex++
+	}
+	nextTyped := func() rune {
+		for got[gotIndex].ignored {
+			gotIndex++
+		}
+		gotIndex++
+		return got[gotIndex-1].char
+	}
+	for _, op := range ops {
+		switch op {
+		case editKeep:
+			nextWanted(models.DiffOpEqual)
+			nextTyped()
+		case editInsert:
+			nextWanted(models.DiffOpInsert)
+		case editDelete:
+			add(models.DiffOpDelete, nextTyped())
+		}
+	}
+	for ; wantIndex < len(want); wantIndex++ {
+		add(models.DiffOpEqual, want[wantIndex].char)
+	}
+
+	if segments == nil {
+		segments = []models.DiffSegment{}
+	}
+	return segments
+}
+
+// suggestedQuality grades a checked answer from 0 to 5 by its similarity
+func suggestedQuality(check *models.AnswerCheck) int {
+	switch {
+	case check.Answer == "":
+		return 0
+	case check.Correct:
+		return 5
+	case check.Similarity >= typedAnswerQuality4:
+		return 4
+	case check.Similarity >= typedAnswerQuality3:
+		return 3
+	case check.Similarity >= typedAnswerQuality2:
+		return 2
+	case check.Similarity >= typedAnswerQuality1:
+		return 1
+	default:
+		return 0
+	}
+}
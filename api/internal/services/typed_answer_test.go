@@ -0,0 +1,200 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"swipelearn-api/internal/models"
+	"swipelearn-api/pkg/testutils"
+)
+
+func TestCompareAnswer(t *testing.T) {
+	lenient := answerOptions{ignoreCase: true, ignoreAccents: true, ignorePunctuation: true}
+	strict := answerOptions{}
+
+	tests := []struct {
+		name     string
+		expected string
+		typed    string
+		options  answerOptions
+		correct  bool
+		distance int
+		quality  int
+		diff     []models.DiffSegment
+	}{
+		{
+			name:     "exact answer",
+			expected: "Paris",
+			typed:    "Paris",
+			options:  strict,
+			correct:  true,
+			quality:  5,
+			diff:     []models.DiffSegment{{Op: models.DiffOpEqual, Text: "Paris"}},
+		},
+		{
+			name:     "ignored differences",
+			expected: "Café, s'il vous plaît!",
+			typed:    "  cafe sil VOUS plait ",
+			options:  lenient,
+			correct:  true,
+			quality:  5,
+			diff:     []models.DiffSegment{{Op: models.DiffOpEqual, Text: "Café, s'il vous plaît!"}},
+		},
+		{
+			name:     "differences count when not ignored",
+			expected: "Café!",
+			typed:    "cafe",
+			options:  strict,
+			distance: 3,
+			quality:  1,
+			diff: []models.DiffSegment{
+				{Op: models.DiffOpDelete, Text: "c"},
+				{Op: models.DiffOpInsert, Text: "C"},
+				{Op: models.DiffOpEqual, Text: "af"},
+				{Op: models.DiffOpDelete, Text: "e"},
+				{Op: models.DiffOpInsert, Text: "é!"},
+			},
+		},
+		{
+			name:     "typo",
+			expected: "photosynthesis",
+			typed:    "fotosynthesis",
+			options:  lenient,
+			distance: 2,
+			quality:  3,
+			diff: []models.DiffSegment{
+				{Op: models.DiffOpDelete, Text: "f"},
+				{Op: models.DiffOpInsert, Text: "ph"},
+				{Op: models.DiffOpEqual, Text: "otosynthesis"},
+			},
+		},
+		{
+			name:     "missing word",
+			expected: "the quick brown fox",
+			typed:    "the brown fox",
+			options:  lenient,
+			distance: 6,
+			quality:  2,
+			diff: []models.DiffSegment{
+				{Op: models.DiffOpEqual, Text: "the"},
+				{Op: models.DiffOpInsert, Text: " quick"},
+				{Op: models.DiffOpEqual, Text: " brown fox"},
+			},
+		},
+		{
+			name:     "empty answer",
+			expected: "Paris",
+			typed:    " ",
+			options:  lenient,
+			distance: 5,
+			quality:  0,
+			diff:     []models.DiffSegment{{Op: models.DiffOpInsert, Text: "Paris"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := compareAnswer(tt.expected, tt.typed, tt.options)
+
+			assert.Equal(t, tt.correct, check.Correct)
+			assert.Equal(t, tt.distance, check.Distance)
+			assert.Equal(t, tt.quality, check.SuggestedQuality)
+			assert.Equal(t, tt.diff, check.Diff)
+		})
+	}
+}
+
+func TestCompareAnswer_Similarity(t *testing.T) {
+	check := compareAnswer("abcd", "abxd", answerOptions{})
+
+	assert.InDelta(t, 0.75, check.Similarity, 1e-9)
+	assert.Equal(t, 3, check.SuggestedQuality)
+
+	// Answers longer than expected cannot go below 0
+	check = compareAnswer("a", "xyz", answerOptions{})
+	assert.InDelta(t, 0, check.Similarity, 1e-9)
+	assert.Equal(t, 0, check.SuggestedQuality)
+}
+
+func TestFlashcardService_CheckAnswerWithOwnership(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	deck := testutils.CreateTestDeck(userID)
+	card := testutils.CreateTestFlashcard(userID, deck.ID)
+	card.Format = models.ContentFormatMarkdown
+	card.Back = "**Paris**, France"
+	card.BackHTML = "<p><strong>Paris</strong>, France</p>\n"
+
+	mockRepo.On("GetByID", card.ID).Return(card, nil)
+	mockDeckRepo.On("GetByID", deck.ID).Return(deck, nil)
+
+	// The default options ignore case and punctuation
+	check, err := service.CheckAnswerWithOwnership(card.ID, userID, &models.CheckAnswerRequest{Answer: "paris france"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Paris, France", check.Expected)
+	assert.True(t, check.Correct)
+	assert.Equal(t, 5, check.SuggestedQuality)
+}
+
+func TestFlashcardService_CheckAnswerWithOwnership_Preset(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	userID := uuid.New()
+	preset := testutils.CreateTestDeckPreset(userID)
+	preset.TypedAnswerIgnoreAccents = true
+	deck := testutils.CreateTestDeck(userID)
+	deck.PresetID = &preset.ID
+	card := testutils.CreateTestFlashcard(userID, deck.ID)
+	card.Format = models.ContentFormatPlain
+	card.Back = "Mañana"
+
+	mockRepo.On("GetByID", card.ID).Return(card, nil)
+	mockDeckRepo.On("GetByID", deck.ID).Return(deck, nil)
+	mockPresetRepo.On("GetByID", preset.ID).Return(preset, nil)
+
+	check, err := service.CheckAnswerWithOwnership(card.ID, userID, &models.CheckAnswerRequest{Answer: "Manana"})
+	require.NoError(t, err)
+	assert.True(t, check.Correct)
+
+	// The preset does not ignore case
+	check, err = service.CheckAnswerWithOwnership(card.ID, userID, &models.CheckAnswerRequest{Answer: "manana"})
+	require.NoError(t, err)
+	assert.False(t, check.Correct)
+	assert.Equal(t, 1, check.Distance)
+	assert.Equal(t, 3, check.SuggestedQuality)
+}
+
+func TestFlashcardService_CheckAnswerWithOwnership_Unauthorized(t *testing.T) {
+	logger := testutils.TestLogger()
+	mockRepo := &MockFlashcardRepository{}
+	mockDeckRepo := &MockDeckRepository{}
+	mockPresetRepo := &MockDeckPresetRepository{}
+	mockReviewLogRepo := &MockReviewLogRepository{}
+	mockUserRepo := &MockUserRepository{}
+	service := NewFlashcardService(mockRepo, mockDeckRepo, mockPresetRepo, mockReviewLogRepo, mockUserRepo, logger)
+
+	card := testutils.CreateTestFlashcard(uuid.New(), uuid.New())
+	mockRepo.On("GetByID", card.ID).Return(card, nil)
+
+	check, err := service.CheckAnswerWithOwnership(card.ID, uuid.New(), &models.CheckAnswerRequest{Answer: "Test Back"})
+
+	assert.Nil(t, check)
+	assert.EqualError(t, err, "unauthorized: flashcard does not belong to user")
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
@@ -108,6 +109,25 @@ func (s *UserService) Update(id uuid.UUID, req *models.UpdateUserRequest) (*mode
 		updates["name"] = *req.Name
 	}
 
+	if req.NewPerDay != nil {
+		updates["new_per_day"] = *req.NewPerDay
+	}
+
+	if req.ReviewsPerDay != nil {
+		updates["reviews_per_day"] = *req.ReviewsPerDay
+	}
+
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); *req.Timezone == "" || err != nil {
+			return nil, fmt.Errorf("invalid timezone")
+		}
+		updates["timezone"] = *req.Timezone
+	}
+
+	if req.DayStartHour != nil {
+		updates["day_start_hour"] = *req.DayStartHour
+	}
+
 	if len(updates) == 0 {
 		return existingUser, nil // No changes needed
 	}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLocalStore_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocalStore(dir)
+	require.NoError(t, err)
+
+	data := []byte("diagram of the heart")
+	hash := contentHash(data)
+	require.NoError(t, store.Put(hash, data, "image/png"))
+	require.NoError(t, store.Put(hash, data, "image/png")) // Storing the same content again is a no-op
+
+	assert.FileExists(t, filepath.Join(dir, hash[:2], hash))
+	entries, err := os.ReadDir(filepath.Join(dir, hash[:2]))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+
+	file, err := store.Open(hash)
+	require.NoError(t, err)
+	content, err := io.ReadAll(file)
+	require.NoError(t, file.Close())
+	require.NoError(t, err)
+	assert.Equal(t, data, content)
+
+	require.NoError(t, store.Delete(hash))
+	_, err = store.Open(hash)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Delete(hash))
+}
+
+func TestLocalStore_RejectsInvalidHashes(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, hash := range []string{"", "../../etc/passwd", "ABCDEF", contentHash(nil)[:63] + "/"} {
+		assert.Error(t, store.Put(hash, []byte("x"), "image/png"), hash)
+		_, err := store.Open(hash)
+		assert.Error(t, err, hash)
+		assert.NotErrorIs(t, err, ErrNotFound, hash)
+	}
+}
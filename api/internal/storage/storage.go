@@ -0,0 +1,84 @@
+// Package storage keeps media files on local disk or in an S3-compatible object store.
+// Files are content-addressed: each is stored under the hex SHA-256 hash of its bytes.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"swipelearn-api/internal/utils"
+)
+
+// ErrNotFound is returned when no file is stored under a hash
+var ErrNotFound = errors.New("media file not found")
+
+// Store keeps files under their content hash. Putting a hash that is already stored
+// leaves the stored file as it is, and deleting a hash that is not stored is no error.
+type Store interface {
+	Put(hash string, data []byte, contentType string) error
+	Open(hash string) (io.ReadCloser, error)
+	Delete(hash string) error
+}
+
+// NewStore creates the store selected by MEDIA_STORAGE: "local" (the default) keeps
+// files under MEDIA_DIR, "s3" in the S3_BUCKET bucket of an S3-compatible endpoint
+func NewStore(logger *logrus.Logger) (Store, error) {
+	switch backend := utils.GetEnvAsString("MEDIA_STORAGE", "local"); backend {
+	case "local":
+		dir := utils.GetEnvAsString("MEDIA_DIR", "data/media")
+		store, err := NewLocalStore(dir)
+		if err != nil {
+			return nil, err
+		}
+		logger.WithField("dir", dir).Info("Storing media on local disk")
+		return store, nil
+	case "s3":
+		config := S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          utils.GetEnvAsString("S3_REGION", "us-east-1"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			Prefix:          utils.GetEnvAsString("S3_PREFIX", "media/"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			Timeout:         utils.GetEnvAsDuration("S3_TIMEOUT", 30*time.Second),
+		}
+		store, err := NewS3Store(config)
+		if err != nil {
+			return nil, err
+		}
+		logger.WithFields(logrus.Fields{
+			"endpoint": config.Endpoint,
+			"bucket":   config.Bucket,
+		}).Info("Storing media in S3-compatible bucket")
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown MEDIA_STORAGE %q, use local or s3", backend)
+	}
+}
+
+// ValidHash reports whether s is a lowercase hex SHA-256 hash
+func ValidHash(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// checkHash rejects keys that are not content hashes, so a key can never name a path
+// outside the store
+func checkHash(hash string) error {
+	if !ValidHash(hash) {
+		return fmt.Errorf("invalid media hash %q", hash)
+	}
+	return nil
+}
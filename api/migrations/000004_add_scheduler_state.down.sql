@@ -0,0 +1,6 @@
+-- Remove pluggable scheduler support from SwipeLearn Database
+
+ALTER TABLE flashcards DROP COLUMN IF EXISTS retrievability;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS stability;
+
+ALTER TABLE decks DROP COLUMN IF EXISTS scheduler;
@@ -0,0 +1,8 @@
+-- Add pluggable scheduler support to SwipeLearn Database
+
+-- Scheduler used for reviews of cards in a deck (sm2 or fsrs)
+ALTER TABLE decks ADD COLUMN IF NOT EXISTS scheduler VARCHAR(20) NOT NULL DEFAULT 'sm2';
+
+-- FSRS memory state; difficulty is reused for the FSRS difficulty (1-10)
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS stability FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS retrievability FLOAT NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- Remove review history from SwipeLearn Database
+
+DROP INDEX IF EXISTS idx_review_logs_user_id;
+DROP INDEX IF EXISTS idx_review_logs_flashcard_id;
+
+DROP TABLE IF EXISTS review_logs;
@@ -0,0 +1,18 @@
+-- Add review history to SwipeLearn Database
+
+-- One row per answered review, with the scheduling state before and after it
+CREATE TABLE IF NOT EXISTS review_logs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    flashcard_id UUID NOT NULL REFERENCES flashcards(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    deck_id UUID NOT NULL REFERENCES decks(id) ON DELETE CASCADE,
+    scheduler VARCHAR(20) NOT NULL,
+    quality SMALLINT NOT NULL CHECK (quality BETWEEN 0 AND 5),
+    time_taken_ms INTEGER NOT NULL DEFAULT 0,
+    state_before JSONB NOT NULL,
+    state_after JSONB NOT NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_logs_flashcard_id ON review_logs(flashcard_id, reviewed_at);
+CREATE INDEX IF NOT EXISTS idx_review_logs_user_id ON review_logs(user_id, reviewed_at);
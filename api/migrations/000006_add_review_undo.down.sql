@@ -0,0 +1,6 @@
+-- Remove review undo support
+
+DROP INDEX IF EXISTS idx_review_logs_session_id;
+
+ALTER TABLE review_logs DROP COLUMN IF EXISTS undone_at;
+ALTER TABLE review_logs DROP COLUMN IF EXISTS session_id;
@@ -0,0 +1,9 @@
+-- Allow reviews to be undone within a study session
+
+-- Client-provided study session the review was answered in
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS session_id UUID;
+
+-- Set when the review was reverted; undone reviews stay for analytics
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS undone_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_review_logs_session_id ON review_logs(session_id);
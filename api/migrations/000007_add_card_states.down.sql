@@ -0,0 +1,6 @@
+-- Remove the card state machine
+
+DROP INDEX IF EXISTS idx_flashcards_state;
+
+ALTER TABLE flashcards DROP COLUMN IF EXISTS step;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS state;
@@ -0,0 +1,11 @@
+-- Add the card state machine (new -> learning -> review -> relearning)
+
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS state VARCHAR(20) NOT NULL DEFAULT 'new';
+
+-- Index into the learning or relearning steps while in those states
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS step INTEGER NOT NULL DEFAULT 0;
+
+-- Cards that have already been reviewed are in the review state
+UPDATE flashcards SET state = 'review' WHERE last_review IS NOT NULL OR review_count > 0;
+
+CREATE INDEX IF NOT EXISTS idx_flashcards_state ON flashcards(state);
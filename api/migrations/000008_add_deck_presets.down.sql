@@ -0,0 +1,6 @@
+-- Remove deck option presets
+
+ALTER TABLE decks DROP COLUMN IF EXISTS preset_id;
+
+DROP INDEX IF EXISTS idx_deck_presets_user_id;
+DROP TABLE IF EXISTS deck_presets;
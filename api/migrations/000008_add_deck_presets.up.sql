@@ -0,0 +1,22 @@
+-- Add deck option presets: named scheduling options shared by decks
+
+CREATE TABLE IF NOT EXISTS deck_presets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    starting_ease FLOAT NOT NULL DEFAULT 2.5,
+    minimum_ease FLOAT NOT NULL DEFAULT 1.3,
+    interval_modifier FLOAT NOT NULL DEFAULT 1.0,
+    maximum_interval INTEGER NOT NULL DEFAULT 36500,
+    learning_steps INTEGER[] NOT NULL DEFAULT '{1,10}', -- minutes
+    relearning_steps INTEGER[] NOT NULL DEFAULT '{10}', -- minutes
+    new_per_day INTEGER NOT NULL DEFAULT 20,
+    reviews_per_day INTEGER NOT NULL DEFAULT 200,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_deck_presets_user_id ON deck_presets(user_id);
+
+-- Decks without a preset use the server defaults
+ALTER TABLE decks ADD COLUMN IF NOT EXISTS preset_id UUID REFERENCES deck_presets(id) ON DELETE SET NULL;
@@ -0,0 +1,6 @@
+-- Remove user-wide daily limits
+
+DROP INDEX IF EXISTS idx_review_logs_user_deck;
+
+ALTER TABLE users DROP COLUMN IF EXISTS reviews_per_day;
+ALTER TABLE users DROP COLUMN IF EXISTS new_per_day;
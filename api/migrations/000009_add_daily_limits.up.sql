@@ -0,0 +1,7 @@
+-- Add user-wide daily limits for new cards and reviews (NULL means only deck limits apply)
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS new_per_day INTEGER;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS reviews_per_day INTEGER;
+
+-- Today's studied counts are read from the review history
+CREATE INDEX IF NOT EXISTS idx_review_logs_user_deck ON review_logs(user_id, reviewed_at, deck_id);
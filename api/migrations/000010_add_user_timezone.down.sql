@@ -0,0 +1,4 @@
+-- Remove the user's timezone and study day start hour
+
+ALTER TABLE users DROP COLUMN IF EXISTS day_start_hour;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
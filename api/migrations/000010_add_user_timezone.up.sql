@@ -0,0 +1,5 @@
+-- Add the user's timezone and the local hour at which a new study day starts
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS day_start_hour SMALLINT NOT NULL DEFAULT 4
+    CHECK (day_start_hour BETWEEN 0 AND 23);
@@ -0,0 +1,4 @@
+-- Remove study sessions
+
+DROP INDEX IF EXISTS idx_study_sessions_user_id;
+DROP TABLE IF EXISTS study_sessions;
@@ -0,0 +1,4 @@
+-- Remove card suspension and burying
+
+ALTER TABLE flashcards DROP COLUMN IF EXISTS buried_until;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS suspended;
@@ -0,0 +1,4 @@
+-- Allow taking cards out of the due queue by suspending or burying them
+
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS suspended BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS buried_until TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,9 @@
+-- Remove lapse tracking and leech options
+
+ALTER TABLE deck_presets DROP COLUMN IF EXISTS leech_action;
+ALTER TABLE deck_presets DROP COLUMN IF EXISTS leech_threshold;
+
+DROP INDEX IF EXISTS idx_flashcards_leech;
+
+ALTER TABLE flashcards DROP COLUMN IF EXISTS leech;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS lapses;
@@ -0,0 +1,25 @@
+-- Track lapses so cards that keep being forgotten can be flagged as leeches
+
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS lapses INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS leech BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Count the lapses already in the review history: failed answers to cards in review
+UPDATE flashcards f
+SET lapses = l.lapses
+FROM (
+    SELECT flashcard_id, COUNT(*) AS lapses
+    FROM review_logs
+    WHERE quality < 3 AND state_before->>'state' = 'review' AND undone_at IS NULL
+    GROUP BY flashcard_id
+) l
+WHERE f.id = l.flashcard_id;
+
+CREATE INDEX IF NOT EXISTS idx_flashcards_leech ON flashcards(user_id) WHERE leech;
+
+-- Leech action: 'tag' only flags the card, 'suspend' also suspends it
+ALTER TABLE deck_presets ADD COLUMN IF NOT EXISTS leech_threshold INTEGER NOT NULL DEFAULT 8;
+ALTER TABLE deck_presets ADD COLUMN IF NOT EXISTS leech_action VARCHAR(20) NOT NULL DEFAULT 'tag';
+
+-- Flag the cards the counted lapses already make leeches, at the default threshold
+-- every preset starts with
+UPDATE flashcards SET leech = TRUE WHERE lapses >= 8;
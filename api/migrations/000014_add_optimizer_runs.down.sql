@@ -0,0 +1,5 @@
+-- Remove the FSRS optimizer
+
+DROP INDEX IF EXISTS idx_review_logs_user_flashcard;
+DROP TABLE IF EXISTS optimizer_runs;
+ALTER TABLE users DROP COLUMN IF EXISTS fsrs_weights;
@@ -0,0 +1,29 @@
+-- Fit FSRS weights to each user's review history
+
+-- Fitted weights (w0..w16); NULL means the published defaults are used
+ALTER TABLE users ADD COLUMN IF NOT EXISTS fsrs_weights DOUBLE PRECISION[];
+
+CREATE TABLE IF NOT EXISTS optimizer_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    review_count INTEGER NOT NULL DEFAULT 0,
+    log_loss_before DOUBLE PRECISION,
+    log_loss_after DOUBLE PRECISION,
+    rmse_before DOUBLE PRECISION,
+    rmse_after DOUBLE PRECISION,
+    weights DOUBLE PRECISION[],
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_optimizer_runs_user_id ON optimizer_runs(user_id, created_at);
+
+-- At most one unfinished run per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_optimizer_runs_active ON optimizer_runs(user_id)
+    WHERE status IN ('pending', 'running');
+
+-- Replaying a user's history reads their reviews card by card
+CREATE INDEX IF NOT EXISTS idx_review_logs_user_flashcard ON review_logs(user_id, flashcard_id, reviewed_at);
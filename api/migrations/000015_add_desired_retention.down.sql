@@ -0,0 +1,3 @@
+-- Remove the desired retention of decks
+
+ALTER TABLE decks DROP COLUMN IF EXISTS desired_retention;
@@ -0,0 +1,4 @@
+-- Add the recall probability each deck schedules reviews for
+
+ALTER TABLE decks ADD COLUMN IF NOT EXISTS desired_retention DOUBLE PRECISION NOT NULL DEFAULT 0.9
+    CHECK (desired_retention BETWEEN 0.85 AND 0.97);
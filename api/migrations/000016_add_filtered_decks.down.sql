@@ -0,0 +1,9 @@
+-- Remove filtered decks, returning their cards to their home decks first
+
+UPDATE flashcards SET deck_id = home_deck_id WHERE home_deck_id IS NOT NULL;
+DELETE FROM decks WHERE filter IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_flashcards_home_deck_id;
+
+ALTER TABLE flashcards DROP COLUMN IF EXISTS home_deck_id;
+ALTER TABLE decks DROP COLUMN IF EXISTS filter;
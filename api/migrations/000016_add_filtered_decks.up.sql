@@ -0,0 +1,8 @@
+-- Add filtered decks: decks defined by a card search that temporarily hold the
+-- matching cards, which remember the home deck they return to
+
+ALTER TABLE decks ADD COLUMN IF NOT EXISTS filter JSONB;
+
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS home_deck_id UUID REFERENCES decks(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_flashcards_home_deck_id ON flashcards(home_deck_id) WHERE home_deck_id IS NOT NULL;
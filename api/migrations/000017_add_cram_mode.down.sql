@@ -0,0 +1,6 @@
+-- Remove cram study sessions
+
+ALTER TABLE review_logs DROP COLUMN IF EXISTS cram;
+
+ALTER TABLE study_sessions DROP COLUMN IF EXISTS cram_order;
+ALTER TABLE study_sessions DROP COLUMN IF EXISTS mode;
@@ -0,0 +1,8 @@
+-- Add cram study sessions, whose answers are logged without rescheduling cards
+
+ALTER TABLE study_sessions ADD COLUMN IF NOT EXISTS mode VARCHAR(20) NOT NULL DEFAULT 'normal'
+    CHECK (mode IN ('normal', 'cram'));
+ALTER TABLE study_sessions ADD COLUMN IF NOT EXISTS cram_order VARCHAR(20)
+    CHECK (cram_order IN ('random', 'hardest', 'oldest'));
+
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS cram BOOLEAN NOT NULL DEFAULT FALSE;
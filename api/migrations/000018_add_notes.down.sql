@@ -0,0 +1,8 @@
+-- Remove notes and note types; their cards are kept as plain flashcards
+
+DROP INDEX IF EXISTS idx_flashcards_note_ord;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS ord;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS note_id;
+
+DROP TABLE IF EXISTS notes;
+DROP TABLE IF EXISTS note_types;
@@ -0,0 +1,40 @@
+-- Add notes and note types. A note holds named fields; its note type's card templates
+-- turn those fields into one flashcard per template. Note types without a user are
+-- built in and shared by everyone.
+
+CREATE TABLE IF NOT EXISTS note_types (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    fields JSONB NOT NULL,
+    templates JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_types_user_id ON note_types(user_id);
+
+INSERT INTO note_types (id, user_id, name, fields, templates) VALUES
+    ('00000000-0000-0000-0000-000000000001', NULL, 'Basic', '["Front", "Back"]',
+     '[{"name": "Card 1", "front": "{{Front}}", "back": "{{Back}}"}]'),
+    ('00000000-0000-0000-0000-000000000002', NULL, 'Basic (and reversed card)', '["Front", "Back"]',
+     '[{"name": "Card 1", "front": "{{Front}}", "back": "{{Back}}"}, {"name": "Card 2", "front": "{{Back}}", "back": "{{Front}}"}]')
+ON CONFLICT (id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS notes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    deck_id UUID NOT NULL REFERENCES decks(id) ON DELETE CASCADE,
+    note_type_id UUID NOT NULL REFERENCES note_types(id),
+    fields JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notes_user_id ON notes(user_id);
+CREATE INDEX IF NOT EXISTS idx_notes_note_type_id ON notes(note_type_id);
+
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS note_id UUID REFERENCES notes(id) ON DELETE CASCADE;
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS ord INTEGER;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_flashcards_note_ord ON flashcards(note_id, ord) WHERE note_id IS NOT NULL;
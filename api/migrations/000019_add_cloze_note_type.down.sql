@@ -0,0 +1,6 @@
+-- Remove cloze note types. Notes of cloze note types are removed with them.
+
+DELETE FROM notes WHERE note_type_id IN (SELECT id FROM note_types WHERE kind = 'cloze');
+DELETE FROM note_types WHERE kind = 'cloze';
+
+ALTER TABLE note_types DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,9 @@
+-- Add cloze note types, whose notes get one card per {{c1::...}} deletion index
+
+ALTER TABLE note_types ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'standard'
+    CHECK (kind IN ('standard', 'cloze'));
+
+INSERT INTO note_types (id, user_id, name, kind, fields, templates) VALUES
+    ('00000000-0000-0000-0000-000000000003', NULL, 'Cloze', 'cloze', '["Text", "Back Extra"]',
+     '[{"name": "Cloze", "front": "{{cloze:Text}}", "back": "{{cloze:Text}}\n\n{{Back Extra}}"}]')
+ON CONFLICT (id) DO NOTHING;
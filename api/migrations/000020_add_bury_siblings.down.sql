@@ -0,0 +1,3 @@
+-- Remove the deck preset option to bury siblings
+
+ALTER TABLE deck_presets DROP COLUMN IF EXISTS bury_siblings;
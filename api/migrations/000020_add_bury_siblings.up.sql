@@ -0,0 +1,3 @@
+-- Add the deck preset option to bury the siblings of an answered card until the next study day
+
+ALTER TABLE deck_presets ADD COLUMN IF NOT EXISTS bury_siblings BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,13 @@
+-- Remove image occlusion note types and media. Notes of image occlusion note types are
+-- removed with them. Stored media files are left on the storage backend.
+
+DELETE FROM notes WHERE note_type_id IN (SELECT id FROM note_types WHERE kind = 'image_occlusion');
+DELETE FROM note_types WHERE kind = 'image_occlusion';
+
+ALTER TABLE note_types DROP CONSTRAINT IF EXISTS note_types_kind_check;
+ALTER TABLE note_types ADD CONSTRAINT note_types_kind_check
+    CHECK (kind IN ('standard', 'cloze'));
+
+ALTER TABLE notes DROP COLUMN IF EXISTS occlusions;
+
+DROP TABLE IF EXISTS media;
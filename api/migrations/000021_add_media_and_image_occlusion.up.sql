@@ -0,0 +1,28 @@
+-- Add media and image occlusion note types. Media files are stored content-addressed by
+-- their SHA-256 hash; a row records that a user uploaded the file. An image occlusion
+-- note masks regions of an image and gets one card per occlusion index.
+
+CREATE TABLE IF NOT EXISTS media (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    hash CHAR(64) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    width INTEGER,
+    height INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (user_id, hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_hash ON media(hash);
+
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS occlusions JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE note_types DROP CONSTRAINT IF EXISTS note_types_kind_check;
+ALTER TABLE note_types ADD CONSTRAINT note_types_kind_check
+    CHECK (kind IN ('standard', 'cloze', 'image_occlusion'));
+
+INSERT INTO note_types (id, user_id, name, kind, fields, templates) VALUES
+    ('00000000-0000-0000-0000-000000000004', NULL, 'Image Occlusion', 'image_occlusion', '["Image", "Header", "Back Extra"]',
+     '[{"name": "Image Occlusion", "front": "{{Header}}\n\n{{occlusion:Image}}", "back": "{{Header}}\n\n{{occlusion:Image}}\n\n{{Back Extra}}"}]')
+ON CONFLICT (id) DO NOTHING;
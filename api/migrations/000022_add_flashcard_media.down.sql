@@ -0,0 +1,3 @@
+-- Stop tracking media references of flashcards
+
+DROP TABLE IF EXISTS flashcard_media;
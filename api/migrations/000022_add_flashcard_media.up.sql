@@ -0,0 +1,17 @@
+-- Track which media each flashcard references with media:HASH in its front or back.
+-- Media no card references is orphaned and removed by the media garbage collector.
+
+CREATE TABLE IF NOT EXISTS flashcard_media (
+    flashcard_id UUID NOT NULL REFERENCES flashcards(id) ON DELETE CASCADE,
+    media_id UUID NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+    PRIMARY KEY (flashcard_id, media_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_flashcard_media_media_id ON flashcard_media(media_id);
+
+INSERT INTO flashcard_media (flashcard_id, media_id)
+SELECT f.id, m.id
+FROM flashcards f
+JOIN media m ON m.user_id = f.user_id
+    AND (f.front LIKE '%media:' || m.hash || '%' OR f.back LIKE '%media:' || m.hash || '%')
+ON CONFLICT DO NOTHING;
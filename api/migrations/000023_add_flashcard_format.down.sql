@@ -0,0 +1,5 @@
+-- Remove the content format and rendered HTML of flashcards
+
+ALTER TABLE flashcards DROP COLUMN IF EXISTS back_html;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS front_html;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS format;
@@ -0,0 +1,10 @@
+-- Add a content format to flashcards and store their front and back rendered as sanitized HTML.
+-- Cards rendered before a renderer change have NULL HTML and are rendered when read.
+
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS format VARCHAR(20) NOT NULL DEFAULT 'plain'
+    CHECK (format IN ('plain', 'markdown', 'html'));
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS front_html TEXT;
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS back_html TEXT;
+
+-- Note templates produce HTML
+UPDATE flashcards SET format = 'html' WHERE note_id IS NOT NULL;
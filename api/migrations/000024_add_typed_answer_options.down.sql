@@ -0,0 +1,5 @@
+-- Remove the typed answer options of deck presets
+
+ALTER TABLE deck_presets DROP COLUMN IF EXISTS typed_answer_ignore_punctuation;
+ALTER TABLE deck_presets DROP COLUMN IF EXISTS typed_answer_ignore_accents;
+ALTER TABLE deck_presets DROP COLUMN IF EXISTS typed_answer_ignore_case;
@@ -0,0 +1,5 @@
+-- Add the deck preset options deciding what typed answers may differ in from the expected answer
+
+ALTER TABLE deck_presets ADD COLUMN IF NOT EXISTS typed_answer_ignore_case BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE deck_presets ADD COLUMN IF NOT EXISTS typed_answer_ignore_accents BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE deck_presets ADD COLUMN IF NOT EXISTS typed_answer_ignore_punctuation BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,3 @@
+-- Remove the heartbeat of optimizer runs
+
+ALTER TABLE optimizer_runs DROP COLUMN IF EXISTS heartbeat_at;
@@ -0,0 +1,4 @@
+-- Runs are refreshed by the server fitting them, so any server can tell which runs
+-- were left behind by one that stopped
+
+ALTER TABLE optimizer_runs ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
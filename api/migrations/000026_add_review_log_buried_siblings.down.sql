@@ -0,0 +1,3 @@
+-- Forget the siblings buried by reviews
+
+ALTER TABLE review_logs DROP COLUMN IF EXISTS buried_sibling_ids;
@@ -0,0 +1,3 @@
+-- Reviews remember the siblings they buried, so that undoing a review unburies them
+
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS buried_sibling_ids UUID[] NOT NULL DEFAULT '{}';
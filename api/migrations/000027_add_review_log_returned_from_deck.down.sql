@@ -0,0 +1,3 @@
+-- Forget the filtered decks reviews returned cards from
+
+ALTER TABLE review_logs DROP COLUMN IF EXISTS returned_from_deck_id;
@@ -0,0 +1,4 @@
+-- Reviews remember the filtered deck they returned a card from, so that undoing a
+-- review puts the card back in it
+
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS returned_from_deck_id UUID REFERENCES decks(id) ON DELETE SET NULL;
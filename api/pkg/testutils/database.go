@@ -72,6 +72,34 @@ func (td *TestDatabase) RunMigrations(t *testing.T) {
 			email VARCHAR(255) UNIQUE NOT NULL,
 			name VARCHAR(255) NOT NULL,
 			password_hash VARCHAR(255) NOT NULL,
+			new_per_day INTEGER,
+			reviews_per_day INTEGER,
+			timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+			day_start_hour SMALLINT NOT NULL DEFAULT 4,
+			fsrs_weights DOUBLE PRECISION[],
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+
+		// Deck presets table
+		`CREATE TABLE IF NOT EXISTS deck_presets (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			starting_ease FLOAT NOT NULL DEFAULT 2.5,
+			minimum_ease FLOAT NOT NULL DEFAULT 1.3,
+			interval_modifier FLOAT NOT NULL DEFAULT 1.0,
+			maximum_interval INTEGER NOT NULL DEFAULT 36500,
+			learning_steps INTEGER[] NOT NULL DEFAULT '{1,10}',
+			relearning_steps INTEGER[] NOT NULL DEFAULT '{10}',
+			new_per_day INTEGER NOT NULL DEFAULT 20,
+			reviews_per_day INTEGER NOT NULL DEFAULT 200,
+			leech_threshold INTEGER NOT NULL DEFAULT 8,
+			leech_action VARCHAR(20) NOT NULL DEFAULT 'tag',
+			bury_siblings BOOLEAN NOT NULL DEFAULT FALSE,
+			typed_answer_ignore_case BOOLEAN NOT NULL DEFAULT TRUE,
+			typed_answer_ignore_accents BOOLEAN NOT NULL DEFAULT FALSE,
+			typed_answer_ignore_punctuation BOOLEAN NOT NULL DEFAULT TRUE,
 			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
 			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 		);`,
@@ -82,6 +110,61 @@ func (td *TestDatabase) RunMigrations(t *testing.T) {
 			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
 			name VARCHAR(255) NOT NULL,
 			description TEXT,
+			scheduler VARCHAR(20) NOT NULL DEFAULT 'sm2',
+			preset_id UUID REFERENCES deck_presets(id) ON DELETE SET NULL,
+			desired_retention DOUBLE PRECISION NOT NULL DEFAULT 0.9 CHECK (desired_retention BETWEEN 0.85 AND 0.97),
+			filter JSONB,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+
+		// Note types table, with the built-in note types
+		`CREATE TABLE IF NOT EXISTS note_types (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			kind VARCHAR(20) NOT NULL DEFAULT 'standard' CHECK (kind IN ('standard', 'cloze', 'image_occlusion')),
+			fields JSONB NOT NULL,
+			templates JSONB NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`INSERT INTO note_types (id, user_id, name, fields, templates) VALUES
+			('00000000-0000-0000-0000-000000000001', NULL, 'Basic', '["Front", "Back"]',
+			 '[{"name": "Card 1", "front": "{{Front}}", "back": "{{Back}}"}]'),
+			('00000000-0000-0000-0000-000000000002', NULL, 'Basic (and reversed card)', '["Front", "Back"]',
+			 '[{"name": "Card 1", "front": "{{Front}}", "back": "{{Back}}"}, {"name": "Card 2", "front": "{{Back}}", "back": "{{Front}}"}]')
+		ON CONFLICT (id) DO NOTHING;`,
+		`INSERT INTO note_types (id, user_id, name, kind, fields, templates) VALUES
+			('00000000-0000-0000-0000-000000000003', NULL, 'Cloze', 'cloze', '["Text", "Back Extra"]',
+			 '[{"name": "Cloze", "front": "{{cloze:Text}}", "back": "{{cloze:Text}}\n\n{{Back Extra}}"}]')
+		ON CONFLICT (id) DO NOTHING;`,
+		`INSERT INTO note_types (id, user_id, name, kind, fields, templates) VALUES
+			('00000000-0000-0000-0000-000000000004', NULL, 'Image Occlusion', 'image_occlusion', '["Image", "Header", "Back Extra"]',
+			 '[{"name": "Image Occlusion", "front": "{{Header}}\n\n{{occlusion:Image}}", "back": "{{Header}}\n\n{{occlusion:Image}}\n\n{{Back Extra}}"}]')
+		ON CONFLICT (id) DO NOTHING;`,
+
+		// Media table
+		`CREATE TABLE IF NOT EXISTS media (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			hash CHAR(64) NOT NULL,
+			content_type VARCHAR(100) NOT NULL,
+			size BIGINT NOT NULL,
+			width INTEGER,
+			height INTEGER,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			UNIQUE (user_id, hash)
+		);`,
+
+		// Notes table
+		`CREATE TABLE IF NOT EXISTS notes (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			deck_id UUID NOT NULL REFERENCES decks(id) ON DELETE CASCADE,
+			note_type_id UUID NOT NULL REFERENCES note_types(id),
+			fields JSONB NOT NULL,
+			occlusions JSONB NOT NULL DEFAULT '[]',
 			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
 			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 		);`,
@@ -91,18 +174,95 @@ func (td *TestDatabase) RunMigrations(t *testing.T) {
 			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
 			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
 			deck_id UUID NOT NULL REFERENCES decks(id) ON DELETE CASCADE,
+			home_deck_id UUID REFERENCES decks(id) ON DELETE CASCADE,
+			note_id UUID REFERENCES notes(id) ON DELETE CASCADE,
+			ord INTEGER,
 			front TEXT NOT NULL,
 			back TEXT NOT NULL,
+			format VARCHAR(20) NOT NULL DEFAULT 'plain' CHECK (format IN ('plain', 'markdown', 'html')),
+			front_html TEXT,
+			back_html TEXT,
 			difficulty FLOAT DEFAULT 2.5,
 			interval INTEGER DEFAULT 1,
 			ease_factor FLOAT DEFAULT 2.5,
 			review_count INTEGER DEFAULT 0,
+			stability FLOAT NOT NULL DEFAULT 0,
+			retrievability FLOAT NOT NULL DEFAULT 0,
+			state VARCHAR(20) NOT NULL DEFAULT 'new',
+			step INTEGER NOT NULL DEFAULT 0,
 			last_review TIMESTAMP WITH TIME ZONE,
 			next_review TIMESTAMP WITH TIME ZONE,
+			suspended BOOLEAN NOT NULL DEFAULT FALSE,
+			buried_until TIMESTAMP WITH TIME ZONE,
+			lapses INTEGER NOT NULL DEFAULT 0,
+			leech BOOLEAN NOT NULL DEFAULT FALSE,
 			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
 			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 		);`,
 
+		// Flashcard media references table
+		`CREATE TABLE IF NOT EXISTS flashcard_media (
+			flashcard_id UUID NOT NULL REFERENCES flashcards(id) ON DELETE CASCADE,
+			media_id UUID NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+			PRIMARY KEY (flashcard_id, media_id)
+		);`,
+
+		// Review logs table
+		`CREATE TABLE IF NOT EXISTS review_logs (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			flashcard_id UUID NOT NULL REFERENCES flashcards(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			deck_id UUID NOT NULL REFERENCES decks(id) ON DELETE CASCADE,
+			scheduler VARCHAR(20) NOT NULL,
+			quality SMALLINT NOT NULL CHECK (quality BETWEEN 0 AND 5),
+			time_taken_ms INTEGER NOT NULL DEFAULT 0,
+			state_before JSONB NOT NULL,
+			state_after JSONB NOT NULL,
+			session_id UUID,
+			reviewed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			undone_at TIMESTAMP WITH TIME ZONE,
+			cram BOOLEAN NOT NULL DEFAULT FALSE,
+			buried_sibling_ids UUID[] NOT NULL DEFAULT '{}',
+			returned_from_deck_id UUID REFERENCES decks(id) ON DELETE SET NULL
+		);`,
+
+		// Study sessions table
+		`CREATE TABLE IF NOT EXISTS study_sessions (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			deck_id UUID REFERENCES decks(id) ON DELETE CASCADE,
+			status VARCHAR(20) NOT NULL DEFAULT 'active',
+			mode VARCHAR(20) NOT NULL DEFAULT 'normal' CHECK (mode IN ('normal', 'cram')),
+			cram_order VARCHAR(20) CHECK (cram_order IN ('random', 'hardest', 'oldest')),
+			queue JSONB NOT NULL DEFAULT '[]',
+			new_count INTEGER NOT NULL DEFAULT 0,
+			learning_count INTEGER NOT NULL DEFAULT 0,
+			review_count INTEGER NOT NULL DEFAULT 0,
+			again_count INTEGER NOT NULL DEFAULT 0,
+			total_time_ms INTEGER NOT NULL DEFAULT 0,
+			started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			finished_at TIMESTAMP WITH TIME ZONE,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+
+		// Optimizer runs table
+		`CREATE TABLE IF NOT EXISTS optimizer_runs (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			review_count INTEGER NOT NULL DEFAULT 0,
+			log_loss_before DOUBLE PRECISION,
+			log_loss_after DOUBLE PRECISION,
+			rmse_before DOUBLE PRECISION,
+			rmse_after DOUBLE PRECISION,
+			weights DOUBLE PRECISION[],
+			error TEXT,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			started_at TIMESTAMP WITH TIME ZONE,
+			finished_at TIMESTAMP WITH TIME ZONE,
+			heartbeat_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		);`,
+
 		// Refresh tokens table
 		`CREATE TABLE IF NOT EXISTS refresh_tokens (
 			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -117,7 +277,10 @@ func (td *TestDatabase) RunMigrations(t *testing.T) {
 		`CREATE INDEX IF NOT EXISTS idx_flashcards_user_id ON flashcards(user_id);`,
 		`CREATE INDEX IF NOT EXISTS idx_flashcards_deck_id ON flashcards(deck_id);`,
 		`CREATE INDEX IF NOT EXISTS idx_flashcards_next_review ON flashcards(next_review);`,
+		`CREATE INDEX IF NOT EXISTS idx_review_logs_flashcard_id ON review_logs(flashcard_id, reviewed_at);`,
 		`CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_flashcards_note_ord ON flashcards(note_id, ord) WHERE note_id IS NOT NULL;`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_optimizer_runs_active ON optimizer_runs(user_id) WHERE status IN ('pending', 'running');`,
 	}
 
 	for _, migration := range migrations {
@@ -131,7 +294,7 @@ func (td *TestDatabase) RunMigrations(t *testing.T) {
 
 // CleanupDatabase removes all data from database tables
 func (td *TestDatabase) CleanupDatabase(t *testing.T) {
-	tables := []string{"refresh_tokens", "flashcards", "decks", "users"}
+	tables := []string{"refresh_tokens", "optimizer_runs", "study_sessions", "review_logs", "flashcard_media", "flashcards", "notes", "media", "decks", "deck_presets", "users"}
 
 	for _, table := range tables {
 		_, err := td.DB.Exec(fmt.Sprintf("DELETE FROM %s;", table))
@@ -141,7 +304,7 @@ func (td *TestDatabase) CleanupDatabase(t *testing.T) {
 
 // TruncateTables truncates all tables (faster than DELETE for large datasets)
 func (td *TestDatabase) TruncateTables(t *testing.T) {
-	tables := []string{"refresh_tokens", "flashcards", "decks", "users"}
+	tables := []string{"refresh_tokens", "optimizer_runs", "study_sessions", "review_logs", "flashcard_media", "flashcards", "notes", "media", "decks", "deck_presets", "users"}
 
 	// Disable foreign key constraints temporarily
 	_, err := td.DB.Exec("SET session_replication_role = replica;")
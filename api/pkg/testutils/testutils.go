@@ -30,16 +30,39 @@ func CreateTestUser() *models.User {
 		Email:        "test@example.com",
 		Name:         "Test User",
 		PasswordHash: "$2a$10$example.hash",
+		Timezone:     "UTC",
+		DayStartHour: 4,
 	}
 }
 
 // CreateTestDeck creates a test deck model
 func CreateTestDeck(userID uuid.UUID) *models.Deck {
 	return &models.Deck{
-		ID:          uuid.New(),
-		UserID:      userID,
-		Name:        "Test Deck",
-		Description: "Test Description",
+		ID:               uuid.New(),
+		UserID:           userID,
+		Name:             "Test Deck",
+		Description:      "Test Description",
+		Scheduler:        models.SchedulerSM2,
+		DesiredRetention: models.DefaultDesiredRetention,
+	}
+}
+
+// CreateTestDeckPreset creates a test deck preset model with the default options
+func CreateTestDeckPreset(userID uuid.UUID) *models.DeckPreset {
+	return &models.DeckPreset{
+		ID:               uuid.New(),
+		UserID:           userID,
+		Name:             "Test Preset",
+		StartingEase:     2.5,
+		MinimumEase:      1.3,
+		IntervalModifier: 1.0,
+		MaximumInterval:  36500,
+		LearningSteps:    []int64{1, 10},
+		RelearningSteps:  []int64{10},
+		NewPerDay:        20,
+		ReviewsPerDay:    200,
+		LeechThreshold:   8,
+		LeechAction:      models.LeechActionTag,
 	}
 }
 
@@ -55,6 +78,7 @@ func CreateTestFlashcard(userID, deckID uuid.UUID) *models.Flashcard {
 		Interval:    1,
 		EaseFactor:  2.5,
 		ReviewCount: 0,
+		State:       models.CardStateNew,
 	}
 }
 